@@ -0,0 +1,34 @@
+// cmd/api/config.go
+package main
+
+import (
+    "fmt"
+    "os"
+
+    "github.com/javice/vet-clinic-api/internal/config"
+)
+
+// runConfig implementa el subcomando config:
+//
+//    vet-clinic-api config print [-config fichero] [opciones]
+//
+// Muestra la configuración efectiva, con las mismas capas y opciones que el
+// servidor, ocultando los secretos. Devuelve el código de salida.
+func runConfig(args []string) int {
+    if len(args) == 0 || args[0] != "print" {
+        fmt.Fprintln(os.Stderr, "Uso: vet-clinic-api config print [opciones]")
+        return 2
+    }
+
+    cfg, err := config.Load(args[1:], os.LookupEnv)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Configuración inválida:\n%v\n", err)
+        return 2
+    }
+
+    if err := cfg.Print(os.Stdout); err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        return 1
+    }
+    return 0
+}
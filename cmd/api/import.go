@@ -0,0 +1,125 @@
+// cmd/api/import.go
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+
+    "github.com/javice/vet-clinic-api/internal/audit"
+    "github.com/javice/vet-clinic-api/internal/config"
+    "github.com/javice/vet-clinic-api/internal/importer"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "github.com/javice/vet-clinic-api/internal/tenant"
+    "gorm.io/gorm"
+)
+
+// importActor es el actor con el que se auditan las importaciones por consola
+const importActor = "import-cli"
+
+// runImport implementa el subcomando import:
+//
+//    vet-clinic-api import -kind clients -clinic 1 [-format csv] [-map Correo=email,Nombre=name] [-commit] fichero.csv
+//
+// Los registros se importan en la clínica indicada con -clinic. Sin -commit
+// solo valida y muestra el informe. Devuelve el código de salida.
+func runImport(args []string) int {
+    flags := flag.NewFlagSet("import", flag.ContinueOnError)
+    kind := flags.String("kind", "", "tipo de registro: clients o pets")
+    clinicID := flags.Uint("clinic", 0, "ID de la clínica de destino")
+    format := flags.String("format", "", "formato: csv o ndjson (por defecto según la extensión)")
+    mapping := flags.String("map", "", "mapeo de columnas, p. ej. Correo=email,Nombre=name (- descarta la columna)")
+    commit := flags.Bool("commit", false, "importar de verdad; sin esta opción solo se valida")
+    flags.Usage = func() {
+        fmt.Fprintln(flags.Output(), "Uso: vet-clinic-api import -kind clients|pets -clinic ID [opciones] fichero (- para la entrada estándar)")
+        flags.PrintDefaults()
+    }
+
+    if err := flags.Parse(args); err != nil {
+        return 2
+    }
+    if flags.NArg() != 1 || *kind == "" || *clinicID == 0 {
+        flags.Usage()
+        return 2
+    }
+
+    opts := importer.Options{Kind: *kind, Format: *format, Mapping: map[string]string{}, DryRun: !*commit}
+    for _, pair := range strings.Split(*mapping, ",") {
+        if pair = strings.TrimSpace(pair); pair == "" {
+            continue
+        }
+        column, field, ok := strings.Cut(pair, "=")
+        if !ok {
+            fmt.Fprintf(os.Stderr, "Mapeo inválido: %s\n", pair)
+            return 2
+        }
+        opts.Mapping[strings.TrimSpace(column)] = strings.TrimSpace(field)
+    }
+
+    var input io.Reader = os.Stdin
+    if path := flags.Arg(0); path != "-" {
+        file, err := os.Open(path)
+        if err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            return 1
+        }
+        defer file.Close()
+        input = file
+
+        if opts.Format == "" {
+            opts.Format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+            if opts.Format == "jsonl" {
+                opts.Format = importer.FormatNDJSON
+            }
+        }
+    }
+
+    cfg, err := config.Load(nil, os.LookupEnv)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Configuración inválida:\n%v\n", err)
+        return 2
+    }
+    setupLogging(cfg.Log)
+
+    db, err := setupDatabase(cfg.Database)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+        return 1
+    }
+
+    if _, err := repositories.NewClinicRepository(db).GetByID(*clinicID); err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            fmt.Fprintf(os.Stderr, "La clínica %d no existe\n", *clinicID)
+            return 2
+        }
+        fmt.Fprintf(os.Stderr, "Import failed: %v\n", err)
+        return 1
+    }
+
+    ctx := tenant.WithClinic(audit.WithActor(context.Background(), importActor), *clinicID)
+    report, err := importer.Run(db.WithContext(ctx), input, opts)
+    if report != nil {
+        encoder := json.NewEncoder(os.Stdout)
+        encoder.SetIndent("", "  ")
+        encoder.Encode(report)
+    }
+
+    switch {
+    case errors.Is(err, importer.ErrInvalidRows):
+        fmt.Fprintln(os.Stderr, err)
+        return 1
+    case err != nil:
+        fmt.Fprintf(os.Stderr, "Import failed: %v\n", err)
+        return 1
+    case report.Invalid > 0 || len(report.Errors) > 0:
+        // Simulación con errores
+        return 1
+    }
+    return 0
+}
@@ -2,59 +2,412 @@
 package main
 
 import (
-    "log"
+    "context"
+    "errors"
+    "fmt"
+    "log/slog"
+    "net/http"
     "os"
+    "os/signal"
+    "strconv"
+    "sync"
+    "syscall"
+    "time"
 
+    "github.com/javice/vet-clinic-api/internal/audit"
+    "github.com/javice/vet-clinic-api/internal/config"
     "github.com/javice/vet-clinic-api/internal/handlers"
+    "github.com/javice/vet-clinic-api/internal/logging"
+    "github.com/javice/vet-clinic-api/internal/metrics"
+    "github.com/javice/vet-clinic-api/internal/middleware"
+    "github.com/javice/vet-clinic-api/internal/ratelimit"
+    "github.com/javice/vet-clinic-api/internal/reminders"
     "github.com/javice/vet-clinic-api/internal/repositories"
     "github.com/javice/vet-clinic-api/internal/routes"
+    "github.com/javice/vet-clinic-api/internal/tenant"
+    "github.com/javice/vet-clinic-api/internal/timezone"
+    "github.com/javice/vet-clinic-api/internal/waitlist"
+    "github.com/gin-gonic/gin"
     "gorm.io/driver/sqlite"
     "gorm.io/gorm"
     "github.com/javice/vet-clinic-api/internal/models"
 )
 
 func main() {
+    // Subcomandos
+    if len(os.Args) > 1 {
+        switch os.Args[1] {
+        case "import":
+            os.Exit(runImport(os.Args[2:]))
+        case "config":
+            os.Exit(runConfig(os.Args[2:]))
+        }
+    }
+
+    // Cargar la configuración
+    cfg, err := config.Load(os.Args[1:], os.LookupEnv)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Configuración inválida:\n%v\n", err)
+        os.Exit(2)
+    }
+
+    // Configurar los logs
+    logger := setupLogging(cfg.Log)
+
+    // La zona horaria de la configuración es la de las clínicas que no
+    // indican otra; ya está validada
+    loc, err := time.LoadLocation(cfg.TimeZone)
+    if err != nil {
+        fatal("Invalid time zone", err)
+    }
+
     // Configurar la base de datos
-    db, err := setupDatabase()
+    db, err := setupDatabase(cfg.Database)
     if err != nil {
-        log.Fatalf("Failed to connect to database: %v", err)
+        fatal("Failed to connect to database", err)
     }
 
     // Crear repositorios
     clientRepo := repositories.NewClientRepository(db)
     petRepo := repositories.NewPetRepository(db)
 	appointmentRepo := repositories.NewAppointmentRepository(db)
+    auditRepo := repositories.NewAuditRepository(db)
+    searchRepo := repositories.NewSearchRepository(db)
 
     // Crear handler
-    handler := handlers.NewHandler(clientRepo, petRepo, appointmentRepo)
+    handler := handlers.NewHandler(clientRepo, petRepo, appointmentRepo, auditRepo, searchRepo)
+    handler.Retention = time.Duration(cfg.RetentionDays) * 24 * time.Hour
+
+    // Métricas de Prometheus, en el puerto principal o en METRICS_ADDR
+    appMetrics, err := metrics.New(db)
+    if err != nil {
+        fatal("Failed to configure metrics", err)
+    }
+    var metricsServer *http.Server
+    if cfg.Metrics.Addr != "" {
+        metricsServer = serveMetrics(cfg.Metrics.Addr, cfg.Server, appMetrics)
+    }
 
     // Configurar rutas
-    router := routes.SetupRouter(handler)
+    opts := routes.Options{
+        AdminToken:      cfg.Auth.AdminToken,
+        AllowAnonymous:  !cfg.Auth.RequireAPIKey,
+        AllowAllClinics: !cfg.Auth.RequireClinic,
+        TimeZone:        loc,
+        CORS:            corsPolicies(cfg.CORS),
+        Logger:          logger,
+        Metrics:         appMetrics,
+        ServeMetrics:    cfg.Metrics.Addr == "",
+    }
+    if cfg.RateLimit.Enabled {
+        opts.RateLimitStore = ratelimit.NewMemoryStore()
+        opts.RateLimits = rateLimits(cfg.RateLimit)
+    }
+    router := routes.SetupRouter(handler, opts)
+
+    // Los trabajos en segundo plano se detienen al cancelar este contexto
+    workersCtx, stopWorkers := context.WithCancel(timezone.WithLocation(context.Background(), loc))
+    var workers sync.WaitGroup
+
+    // Canales por los que se avisa a los clientes
+    providers, err := setupProviders(cfg.Reminders)
+    if err != nil {
+        fatal("Failed to configure reminders", err)
+    }
+
+    // Iniciar el envío de recordatorios de citas
+    scheduler, err := setupReminders(db, cfg.Reminders, providers, loc)
+    if err != nil {
+        fatal("Failed to configure reminders", err)
+    }
+    if scheduler != nil {
+        workers.Add(1)
+        go func() {
+            defer workers.Done()
+            scheduler.Start(workersCtx)
+        }()
+    }
+
+    // Revisar las ofertas de la lista de espera caducadas
+    handler.Waitlist = waitlist.New(handler.WaitlistRepo, providers, waitlist.Config{
+        OfferTTL: cfg.Waitlist.OfferTTL,
+        Interval: cfg.Waitlist.Interval,
+        TimeZone: loc,
+    })
+    workers.Add(1)
+    go func() {
+        defer workers.Done()
+        handler.Waitlist.Start(workersCtx)
+    }()
 
     // Iniciar el servidor
-    port := os.Getenv("PORT")
-    if port == "" {
-        port = "8080"
+    server := newServer(":"+strconv.Itoa(cfg.Server.Port), router, cfg.Server)
+
+    serverErr := make(chan error, 1)
+    go func() {
+        slog.Info("Server running", "port", cfg.Server.Port)
+        serverErr <- server.ListenAndServe()
+    }()
+
+    // Esperar a SIGINT/SIGTERM o a que el servidor falle
+    signals, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+    defer stop()
+
+    select {
+    case err := <-serverErr:
+        fatal("Failed to start server", err)
+    case <-signals.Done():
+    }
+
+    slog.Info("Shutting down")
+    handler.SetDraining()
+
+    ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+    defer cancel()
+
+    // Dejar de aceptar conexiones y esperar a las peticiones en curso
+    if err := server.Shutdown(ctx); err != nil {
+        slog.Error("Failed to drain connections", "error", err)
+    }
+    if metricsServer != nil {
+        metricsServer.Shutdown(ctx)
+    }
+
+    // Detener los trabajos en segundo plano
+    stopWorkers()
+    if !waitFor(ctx, workers.Wait, handler.Exports.Wait) {
+        slog.Warn("Background workers did not finish before the shutdown timeout")
+    }
+
+    // Cerrar el pool de conexiones
+    if sqlDB, err := db.DB(); err == nil {
+        if err := sqlDB.Close(); err != nil {
+            slog.Error("Failed to close database", "error", err)
+        }
+    }
+
+    slog.Info("Server stopped")
+}
+
+// waitFor ejecuta las esperas indicadas y devuelve false si el contexto
+// termina antes.
+func waitFor(ctx context.Context, waits ...func()) bool {
+    done := make(chan struct{})
+    go func() {
+        for _, wait := range waits {
+            wait()
+        }
+        close(done)
+    }()
+
+    select {
+    case <-done:
+        return true
+    case <-ctx.Done():
+        return false
+    }
+}
+
+// setupLogging configura slog y lo deja como logger por defecto.
+func setupLogging(cfg config.Log) *slog.Logger {
+    if !cfg.Debug {
+        gin.SetMode(gin.ReleaseMode)
+    }
+
+    logger := logging.New(os.Stderr, logging.Config{Level: cfg.SlogLevel(), Pretty: cfg.Pretty})
+    slog.SetDefault(logger)
+    return logger
+}
+
+// newServer crea un servidor HTTP con los tiempos máximos configurados.
+func newServer(addr string, handler http.Handler, cfg config.Server) *http.Server {
+    return &http.Server{
+        Addr:              addr,
+        Handler:           handler,
+        ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+        ReadTimeout:       cfg.ReadTimeout,
+        WriteTimeout:      cfg.WriteTimeout,
+        IdleTimeout:       cfg.IdleTimeout,
+    }
+}
+
+// serveMetrics publica /metrics en un puerto aparte, para no exponerlo junto
+// a la API.
+func serveMetrics(addr string, cfg config.Server, m *metrics.Metrics) *http.Server {
+    mux := http.NewServeMux()
+    mux.Handle(metrics.Path, m.Handler())
+    server := newServer(addr, mux, cfg)
+
+    go func() {
+        slog.Info("Metrics server running", "addr", addr)
+        if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+            fatal("Failed to start metrics server", err)
+        }
+    }()
+    return server
+}
+
+// corsPolicies crea la política CORS general y la de administración.
+func corsPolicies(cfg config.CORS) map[string]middleware.CORSPolicy {
+    policy := middleware.CORSPolicy{
+        AllowedOrigins:   cfg.AllowedOrigins,
+        AllowCredentials: cfg.AllowCredentials,
+        MaxAge:           cfg.MaxAge,
     }
+    admin := policy
+    if len(cfg.AdminAllowedOrigins) > 0 {
+        admin.AllowedOrigins = cfg.AdminAllowedOrigins
+    }
+    return map[string]middleware.CORSPolicy{"": policy, routes.AdminPrefix: admin}
+}
 
-    log.Printf("Server running on port %s", port)
-    if err := router.Run(":" + port); err != nil {
-        log.Fatalf("Failed to start server: %v", err)
+// rateLimits asigna a las rutas de administración y a la búsqueda de
+// mascotas por microchip su propio límite.
+func rateLimits(cfg config.RateLimit) map[string]ratelimit.Limit {
+    return map[string]ratelimit.Limit{
+        "":                  {Requests: cfg.Requests, Period: cfg.Period},
+        routes.AdminPrefix:  {Requests: cfg.AdminRequests, Period: cfg.AdminPeriod},
+        routes.LookupPrefix: {Requests: cfg.LookupRequests, Period: cfg.LookupPeriod},
     }
 }
 
-func setupDatabase() (*gorm.DB, error) {
+func fatal(msg string, err error) {
+    slog.Error(msg, "error", err)
+    os.Exit(1)
+}
+
+func setupDatabase(cfg config.Database) (*gorm.DB, error) {
     // Para desarrollo usamos SQLite
-    db, err := gorm.Open(sqlite.Open("vet_clinic.db"), &gorm.Config{})
+    db, err := gorm.Open(sqlite.Open(cfg.Path), &gorm.Config{
+        Logger: logging.NewGormLogger(slog.Default()),
+    })
     if err != nil {
         return nil, err
     }
 
+    sqlDB, err := db.DB()
+    if err != nil {
+        return nil, err
+    }
+    sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+    sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+    sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
     // Migrar esquemas
-    err = db.AutoMigrate(&models.Client{}, &models.Pet{}, &models.Appointment{})
+    err = db.AutoMigrate(models.All()...)
+    if err != nil {
+        return nil, err
+    }
+
+    // Guardar las fechas en UTC, también las anteriores, y devolverlas en
+    // la zona horaria de cada clínica
+    if err := timezone.Normalize(db, models.All()...); err != nil {
+        return nil, err
+    }
+    if err := timezone.Register(db); err != nil {
+        return nil, err
+    }
+
+    // Auditar todos los cambios de datos clínicos, también los de las
+    // migraciones siguientes
+    if err := audit.Register(db, &models.Client{}, &models.Pet{}, &models.Appointment{}); err != nil {
+        return nil, err
+    }
+
+    // Catálogo de especies y razas; las mascotas anteriores pasan a usar sus
+    // nombres del catálogo
+    species := repositories.NewSpeciesRepository(db)
+    if err := species.Seed(repositories.DefaultSpecies()); err != nil {
+        return nil, err
+    }
+    updated, unmatched, err := species.MigratePets()
     if err != nil {
         return nil, err
     }
+    if updated > 0 || len(unmatched) > 0 {
+        slog.Info("Pet species migrated to the catalog", "updated", updated, "unmatched", unmatched)
+    }
+
+    // Índice de búsqueda de texto completo
+    if err := repositories.SetupSearchIndex(db); err != nil {
+        return nil, err
+    }
+
+    // Separar los datos de cada clínica; los datos anteriores pasan a la
+    // clínica principal
+    if err := tenant.Backfill(db); err != nil {
+        return nil, err
+    }
+    if err := tenant.Register(db); err != nil {
+        return nil, err
+    }
 
     return db, nil
-}
\ No newline at end of file
+}
+
+// setupProviders configura los canales (email y SMS) por los que se envían
+// los recordatorios y los avisos de la lista de espera.
+func setupProviders(cfg config.Reminders) (map[string]reminders.Provider, error) {
+    providers := map[string]reminders.Provider{}
+
+    switch cfg.EmailProvider {
+    case "smtp":
+        providers[reminders.ChannelEmail] = &reminders.SMTPProvider{
+            Host:     cfg.SMTP.Host,
+            Port:     strconv.Itoa(cfg.SMTP.Port),
+            Username: cfg.SMTP.Username,
+            Password: cfg.SMTP.Password,
+            From:     cfg.SMTP.From,
+        }
+    case "log":
+        provider, err := logProvider(cfg.LogFile)
+        if err != nil {
+            return nil, err
+        }
+        providers[reminders.ChannelEmail] = provider
+    }
+
+    switch cfg.SMSProvider {
+    case "http":
+        providers[reminders.ChannelSMS] = &reminders.HTTPSMSProvider{
+            URL:   cfg.SMS.GatewayURL,
+            Token: cfg.SMS.Token,
+            From:  cfg.SMS.From,
+        }
+    case "log":
+        provider, err := logProvider(cfg.LogFile)
+        if err != nil {
+            return nil, err
+        }
+        providers[reminders.ChannelSMS] = provider
+    }
+
+    return providers, nil
+}
+
+// setupReminders configura el planificador de recordatorios. Devuelve nil si
+// no hay ningún proveedor configurado.
+func setupReminders(db *gorm.DB, cfg config.Reminders, providers map[string]reminders.Provider, loc *time.Location) (*reminders.Scheduler, error) {
+    if len(providers) == 0 {
+        return nil, nil
+    }
+
+    templates, err := reminders.LoadTemplates(cfg.TemplatesDir)
+    if err != nil {
+        return nil, err
+    }
+
+    return reminders.NewScheduler(repositories.NewReminderRepository(db), providers, reminders.Config{
+        LeadTimes: cfg.LeadTimes,
+        Interval:  cfg.Interval,
+        Templates: templates,
+        TimeZone:  loc,
+    }), nil
+}
+
+func logProvider(path string) (*reminders.LogProvider, error) {
+    if path != "" {
+        return reminders.NewFileProvider(path)
+    }
+    return reminders.NewLogProvider(os.Stdout), nil
+}
@@ -0,0 +1,429 @@
+// internal/audit/audit.go
+package audit
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "reflect"
+    "sync"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+    "gorm.io/gorm/schema"
+)
+
+// SystemActor es el actor de los cambios que no proceden de una petición
+const SystemActor = "system"
+
+type actorKey struct{}
+
+// WithActor devuelve un contexto que identifica a quien realiza los cambios.
+func WithActor(ctx context.Context, actor string) context.Context {
+    return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext devuelve el actor del contexto o SystemActor si no hay ninguno.
+func ActorFromContext(ctx context.Context) string {
+    if ctx != nil {
+        if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+            return actor
+        }
+    }
+    return SystemActor
+}
+
+// Campos que no aportan información en el diff
+var ignoredFields = map[string]bool{"updated_at": true, "version": true}
+
+// ErrChainConflict indica que otra escritura avanzó la cadena de auditoría a
+// la vez; la entrada no se escribe para no bifurcarla.
+var ErrChainConflict = errors.New("la cadena de auditoría cambió durante la escritura")
+
+// chainHeadID es el ID de la fila de models.AuditChainHead
+const chainHeadID = 1
+
+// Las escrituras del proceso se ordenan aquí para no competir entre sí por la
+// cabeza de la cadena; la base de datos ordena las de distintos procesos
+var chainMu sync.Mutex
+
+const beforeKey = "audit:before"
+
+type auditor struct {
+    // entities asocia cada tabla auditada con su nombre de entidad
+    entities map[string]string
+}
+
+// Register instala en db los callbacks que auditan cada alta, modificación y
+// borrado de los modelos indicados.
+func Register(db *gorm.DB, tracked ...interface{}) error {
+    a := &auditor{entities: map[string]string{}}
+    for _, model := range tracked {
+        s, err := schema.Parse(model, &sync.Map{}, db.NamingStrategy)
+        if err != nil {
+            return err
+        }
+        a.entities[s.Table] = db.NamingStrategy.ColumnName("", s.Name)
+    }
+
+    if err := db.Callback().Create().After("gorm:create").Register("audit:after_create", a.afterCreate); err != nil {
+        return err
+    }
+    if err := db.Callback().Update().After("gorm:before_update").Before("gorm:update").Register("audit:before_update", a.capture); err != nil {
+        return err
+    }
+    if err := db.Callback().Update().After("gorm:update").Register("audit:after_update", a.afterUpdate); err != nil {
+        return err
+    }
+    if err := db.Callback().Delete().After("gorm:before_delete").Before("gorm:delete").Register("audit:before_delete", a.capture); err != nil {
+        return err
+    }
+    return db.Callback().Delete().After("gorm:delete").Register("audit:after_delete", a.afterDelete)
+}
+
+// Record añade una entrada a la cadena de auditoría. Se usa para acciones que
+// no son una simple escritura de un modelo, como las fusiones de clientes.
+//
+// La entrada y el avance de la cabeza de la cadena se escriben en la misma
+// transacción, que empieza reclamando la cabeza: hasta que termina, ninguna
+// otra conexión puede escribir en la base de datos. Si aun así la cabeza
+// cambia, se devuelve ErrChainConflict.
+func Record(db *gorm.DB, entry *models.AuditLog) error {
+    chainMu.Lock()
+    defer chainMu.Unlock()
+
+    if entry.Actor == "" {
+        entry.Actor = ActorFromContext(db.Statement.Context)
+    }
+    if entry.CreatedAt.IsZero() {
+        entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+    }
+    if len(entry.Changes) == 0 {
+        entry.Changes = json.RawMessage("{}")
+    }
+
+    session := db.Session(&gorm.Session{NewDB: true, SkipHooks: true})
+    return session.Transaction(func(tx *gorm.DB) error {
+        head, err := claimHead(tx)
+        if err != nil {
+            return err
+        }
+
+        entry.PrevHash = head.Hash
+        entry.Hash = entry.ComputeHash()
+        if err := tx.Create(entry).Error; err != nil {
+            return err
+        }
+
+        result := tx.Model(&models.AuditChainHead{}).
+            Where("id = ? AND hash = ?", chainHeadID, head.Hash).
+            Updates(map[string]interface{}{"hash": entry.Hash, "last_entry_id": entry.ID})
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected != 1 {
+            return ErrChainConflict
+        }
+        return nil
+    })
+}
+
+// claimHead bloquea la cabeza de la cadena para el resto de la transacción y
+// la devuelve. En las bases de datos anteriores a la cabeza la crea con la
+// última entrada.
+func claimHead(tx *gorm.DB) (models.AuditChainHead, error) {
+    head := models.AuditChainHead{ID: chainHeadID}
+
+    // Una escritura, aunque no cambie nada, toma el bloqueo de escritura
+    claimed := tx.Model(&head).Update("hash", gorm.Expr("hash"))
+    if claimed.Error != nil {
+        return head, claimed.Error
+    }
+    if claimed.RowsAffected == 0 {
+        var last models.AuditLog
+        if err := tx.Select("id", "hash").Order("id DESC").Limit(1).Find(&last).Error; err != nil {
+            return head, err
+        }
+        head.Hash = last.Hash
+        head.LastEntryID = last.ID
+        if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&head).Error; err != nil {
+            return head, err
+        }
+    }
+
+    err := tx.First(&head, chainHeadID).Error
+    return head, err
+}
+
+func (a *auditor) tracked(db *gorm.DB) (string, bool) {
+    if db.Error != nil || db.Statement.Schema == nil {
+        return "", false
+    }
+    entity, ok := a.entities[db.Statement.Schema.Table]
+    return entity, ok
+}
+
+func (a *auditor) afterCreate(db *gorm.DB) {
+    entity, ok := a.tracked(db)
+    if !ok {
+        return
+    }
+
+    ids := primaryKeys(db)
+    after, err := snapshot(db, ids)
+    if err != nil {
+        db.AddError(err)
+        return
+    }
+
+    for _, id := range ids {
+        a.write(db, entity, id, models.AuditCreate, nil, after[id])
+    }
+}
+
+// capture guarda el estado de las filas afectadas antes de modificarlas.
+func (a *auditor) capture(db *gorm.DB) {
+    if _, ok := a.tracked(db); !ok {
+        return
+    }
+
+    ids := primaryKeys(db)
+    if len(ids) == 0 {
+        ids = matchingKeys(db)
+    }
+    before, err := snapshot(db, ids)
+    if err != nil {
+        db.AddError(err)
+        return
+    }
+
+    db.Statement.Settings.Store(beforeKey, before)
+}
+
+func (a *auditor) afterUpdate(db *gorm.DB) {
+    entity, ok := a.tracked(db)
+    if !ok {
+        return
+    }
+
+    before := captured(db)
+    after, err := snapshot(db, keys(before))
+    if err != nil {
+        db.AddError(err)
+        return
+    }
+
+    for id, row := range before {
+        action := models.AuditUpdate
+        switch {
+        case row["deleted_at"] == nil && after[id]["deleted_at"] != nil:
+            action = models.AuditDelete
+        case row["deleted_at"] != nil && after[id]["deleted_at"] == nil:
+            action = models.AuditRestore
+        }
+        a.write(db, entity, id, action, row, after[id])
+    }
+}
+
+func (a *auditor) afterDelete(db *gorm.DB) {
+    entity, ok := a.tracked(db)
+    if !ok {
+        return
+    }
+
+    before := captured(db)
+    after, err := snapshot(db, keys(before))
+    if err != nil {
+        db.AddError(err)
+        return
+    }
+
+    for id, row := range before {
+        if after[id] == nil {
+            a.write(db, entity, id, models.AuditPurge, row, nil)
+        } else {
+            a.write(db, entity, id, models.AuditDelete, row, after[id])
+        }
+    }
+}
+
+func (a *auditor) write(db *gorm.DB, entity string, id uint, action string, before, after map[string]interface{}) {
+    changes := diff(before, after)
+    if len(changes) == 0 {
+        return
+    }
+
+    payload, err := json.Marshal(changes)
+    if err != nil {
+        db.AddError(err)
+        return
+    }
+
+    err = Record(db, &models.AuditLog{
+        Actor:    ActorFromContext(db.Statement.Context),
+        Entity:   entity,
+        EntityID: id,
+        Action:   action,
+        Changes:  payload,
+    })
+    if err != nil {
+        db.AddError(err)
+    }
+}
+
+// diff devuelve los campos cuyo valor cambia entre before y after.
+func diff(before, after map[string]interface{}) map[string]models.FieldChange {
+    changes := map[string]models.FieldChange{}
+    for field := range merge(before, after) {
+        if ignoredFields[field] {
+            continue
+        }
+        old, new := normalize(before[field]), normalize(after[field])
+        if !reflect.DeepEqual(old, new) {
+            changes[field] = models.FieldChange{Before: old, After: new}
+        }
+    }
+    return changes
+}
+
+func merge(maps ...map[string]interface{}) map[string]struct{} {
+    fields := map[string]struct{}{}
+    for _, m := range maps {
+        for field := range m {
+            fields[field] = struct{}{}
+        }
+    }
+    return fields
+}
+
+func normalize(value interface{}) interface{} {
+    switch v := value.(type) {
+    case time.Time:
+        return v.UTC().Format(time.RFC3339Nano)
+    case []byte:
+        return string(v)
+    default:
+        return v
+    }
+}
+
+func captured(db *gorm.DB) map[uint]map[string]interface{} {
+    if value, ok := db.Statement.Settings.Load(beforeKey); ok {
+        return value.(map[uint]map[string]interface{})
+    }
+    return nil
+}
+
+func keys(rows map[uint]map[string]interface{}) []uint {
+    ids := make([]uint, 0, len(rows))
+    for id := range rows {
+        ids = append(ids, id)
+    }
+    return ids
+}
+
+// primaryKeys devuelve las claves primarias de los registros de la sentencia.
+func primaryKeys(db *gorm.DB) []uint {
+    field := db.Statement.Schema.PrioritizedPrimaryField
+    if field == nil {
+        return nil
+    }
+
+    var ids []uint
+    add := func(rv reflect.Value) {
+        for rv.Kind() == reflect.Ptr {
+            rv = rv.Elem()
+        }
+        if rv.Kind() != reflect.Struct || rv.Type() != db.Statement.Schema.ModelType {
+            return
+        }
+        if value, zero := field.ValueOf(db.Statement.Context, rv); !zero {
+            if id, ok := toUint(value); ok {
+                ids = append(ids, id)
+            }
+        }
+    }
+
+    rv := db.Statement.ReflectValue
+    switch rv.Kind() {
+    case reflect.Slice, reflect.Array:
+        for i := 0; i < rv.Len(); i++ {
+            add(rv.Index(i))
+        }
+    default:
+        add(rv)
+    }
+    return ids
+}
+
+// matchingKeys resuelve las claves primarias de las filas que cumplen las
+// condiciones de la sentencia.
+func matchingKeys(db *gorm.DB) []uint {
+    c, ok := db.Statement.Clauses["WHERE"]
+    if !ok {
+        return nil
+    }
+    where, ok := c.Expression.(clause.Where)
+    if !ok {
+        return nil
+    }
+
+    var ids []uint
+    err := newSession(db).Model(reflect.New(db.Statement.Schema.ModelType).Interface()).
+        Clauses(where).
+        Pluck(db.Statement.Schema.PrioritizedPrimaryField.DBName, &ids).Error
+    if err != nil {
+        db.AddError(err)
+    }
+    return ids
+}
+
+// snapshot lee el estado actual de las filas indicadas, incluidas las archivadas.
+func snapshot(db *gorm.DB, ids []uint) (map[uint]map[string]interface{}, error) {
+    rows := map[uint]map[string]interface{}{}
+    if len(ids) == 0 {
+        return rows, nil
+    }
+
+    var result []map[string]interface{}
+    pk := db.Statement.Schema.PrioritizedPrimaryField.DBName
+    err := newSession(db).Model(reflect.New(db.Statement.Schema.ModelType).Interface()).
+        Where(pk+" IN ?", ids).
+        Find(&result).Error
+    if err != nil {
+        return nil, err
+    }
+
+    for _, row := range result {
+        if id, ok := toUint(row[pk]); ok {
+            rows[id] = row
+        }
+    }
+    return rows, nil
+}
+
+func newSession(db *gorm.DB) *gorm.DB {
+    return db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Unscoped()
+}
+
+func toUint(value interface{}) (uint, bool) {
+    switch v := value.(type) {
+    case uint:
+        return v, true
+    case uint32:
+        return uint(v), true
+    case uint64:
+        return uint(v), true
+    case int:
+        return uint(v), v >= 0
+    case int64:
+        return uint(v), v >= 0
+    default:
+        var id uint
+        _, err := fmt.Sscan(fmt.Sprint(v), &id)
+        return id, err == nil
+    }
+}
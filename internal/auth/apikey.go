@@ -0,0 +1,35 @@
+package auth
+
+import (
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/hex"
+    "strings"
+)
+
+// APIKeyPrefix identifica las claves de API de la clínica
+const APIKeyPrefix = "vck_"
+
+// displayPrefixLength es la parte de la clave que se guarda en claro para
+// poder reconocerla en los listados
+const displayPrefixLength = len(APIKeyPrefix) + 8
+
+// NewAPIKey genera una clave aleatoria. Devuelve la clave, que solo se
+// muestra una vez, su prefijo visible y el hash que se guarda.
+func NewAPIKey() (key, prefix, hash string, err error) {
+    b := make([]byte, 32)
+    if _, err := rand.Read(b); err != nil {
+        return "", "", "", err
+    }
+
+    key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+    return key, key[:displayPrefixLength], HashAPIKey(key), nil
+}
+
+// HashAPIKey devuelve el hash con el que se guarda y se busca una clave. Las
+// claves son aleatorias y largas, así que basta con SHA-256 sin sal.
+func HashAPIKey(key string) string {
+    sum := sha256.Sum256([]byte(strings.TrimSpace(key)))
+    return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,146 @@
+// Package auth identifica a quien llama a la API (el principal) y sus
+// permisos. Los principales son las claves de API de las integraciones entre
+// sistemas, el token de administración, que hace las veces de la sesión del
+// personal de la clínica, y, solo si se permite, los anónimos.
+package auth
+
+import (
+    "context"
+    "net/http"
+    "slices"
+)
+
+// Tipos de principal
+const (
+    PrincipalAPIKey    = "api_key"
+    PrincipalAdmin     = "admin"
+    PrincipalAnonymous = "anonymous"
+)
+
+// Roles de los principales. El rol fija lo que puede hacer un principal; los
+// permisos de una clave de API lo restringen a los recursos que necesita.
+const (
+    // RoleAdmin puede hacer todo, en cualquier clínica
+    RoleAdmin = "admin"
+    // RoleIntegration solo puede lo que permiten los permisos de su clave
+    RoleIntegration = "integration"
+    // RoleAnonymous solo puede leer los catálogos comunes
+    RoleAnonymous = "anonymous"
+)
+
+// AnonymousScopes son los permisos de las peticiones anónimas: los catálogos
+// comunes a todas las clínicas, sin datos de clientes.
+var AnonymousScopes = []string{"species:read", "services:read"}
+
+// Principal es quien realiza la petición y lo que puede hacer.
+type Principal struct {
+    Type   string
+    Role   string
+    ID     uint
+    Name   string
+    Scopes []string
+    // ClinicID es la clínica a la que está limitado el principal, si lo está
+    ClinicID *uint
+}
+
+// Admin es el principal de quien presenta el token de administración.
+func Admin() Principal {
+    return Principal{Type: PrincipalAdmin, Role: RoleAdmin, Name: "admin"}
+}
+
+// Anonymous es el principal de las peticiones sin credenciales, cuando se
+// permiten.
+func Anonymous() Principal {
+    return Principal{Type: PrincipalAnonymous, Role: RoleAnonymous, Name: "anonymous"}
+}
+
+// Actor es el nombre con el que la auditoría atribuye los cambios.
+func (p Principal) Actor() string {
+    switch p.Type {
+    case PrincipalAPIKey:
+        return "apikey:" + p.Name
+    case PrincipalAdmin:
+        return "admin"
+    default:
+        return "anonymous"
+    }
+}
+
+// Allows indica si el rol del principal y, en las claves de API, sus
+// permisos le dejan hacer lo que pide scope.
+func (p Principal) Allows(scope string) bool {
+    switch p.Role {
+    case RoleAdmin:
+        return true
+    case RoleIntegration:
+        return slices.Contains(p.Scopes, scope)
+    case RoleAnonymous:
+        return slices.Contains(AnonymousScopes, scope)
+    default:
+        return false
+    }
+}
+
+type principalKey struct{}
+
+// WithPrincipal devuelve un contexto con el principal de la petición.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+    return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext devuelve el principal de la petición, si lo hay.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+    if ctx == nil {
+        return Principal{}, false
+    }
+    p, ok := ctx.Value(principalKey{}).(Principal)
+    return p, ok
+}
+
+// Recursos con permisos propios. Cada uno tiene un permiso de lectura
+// (recurso:read) y, salvo los de solo lectura, uno de escritura
+// (recurso:write).
+const (
+    ResourceClients          = "clients"
+    ResourcePets             = "pets"
+    ResourceAppointments     = "appointments"
+    ResourceVets             = "vets"
+    ResourceResources        = "resources"
+    ResourceWaitlist         = "waitlist"
+    ResourceAppointmentTypes = "appointment_types"
+    ResourceServices         = "services"
+    ResourceAdmissions       = "admissions"
+    ResourceSpecies          = "species"
+    ResourceSearch           = "search"
+    ResourceExport           = "export"
+)
+
+// Scopes son todos los permisos que se pueden asignar a una clave.
+var Scopes = []string{
+    "clients:read", "clients:write",
+    "pets:read", "pets:write",
+    "appointments:read", "appointments:write",
+    "vets:read", "vets:write",
+    "resources:read", "resources:write",
+    "waitlist:read", "waitlist:write",
+    "appointment_types:read", "appointment_types:write",
+    "services:read", "services:write",
+    "admissions:read", "admissions:write",
+    "species:read", "species:write",
+    "search:read",
+    "export:read",
+}
+
+// ValidScope indica si scope es un permiso conocido.
+func ValidScope(scope string) bool {
+    return slices.Contains(Scopes, scope)
+}
+
+// ScopeFor devuelve el permiso necesario para hacer una petición con ese
+// método sobre el recurso: lectura para GET y HEAD, escritura para el resto.
+func ScopeFor(resource, method string) string {
+    if method == http.MethodGet || method == http.MethodHead {
+        return resource + ":read"
+    }
+    return resource + ":write"
+}
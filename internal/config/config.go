@@ -0,0 +1,243 @@
+// Package config carga la configuración de la API en un struct tipado. Cada
+// capa sobrescribe a la anterior: valores por defecto, fichero YAML o TOML,
+// fichero .env, variables de entorno y opciones de la línea de comandos.
+package config
+
+import (
+    "errors"
+    "fmt"
+    "log/slog"
+    "strings"
+    "time"
+)
+
+// Config es la configuración completa de la API.
+//
+// Las etiquetas de cada campo indican su clave en el fichero de
+// configuración (key), la variable de entorno (env), la opción de la línea
+// de comandos (flag), el valor por defecto (default) y si es un secreto que
+// no debe mostrarse (secret).
+type Config struct {
+    Server    Server    `key:"server"`
+    Database  Database  `key:"database"`
+    CORS      CORS      `key:"cors"`
+    Auth      Auth      `key:"auth"`
+    Log       Log       `key:"log"`
+    Metrics   Metrics   `key:"metrics"`
+    RateLimit RateLimit `key:"rate_limit"`
+    Reminders Reminders `key:"reminders"`
+    Waitlist  Waitlist  `key:"waitlist"`
+    // RetentionDays es el tiempo que se conservan los registros archivados
+    // antes de poder purgarlos
+    RetentionDays int `key:"retention_days" env:"RETENTION_DAYS" flag:"retention-days" default:"1825" help:"días que se conservan los registros archivados"`
+    // TimeZone es la zona horaria de las clínicas que no indican otra.
+    // "Local" es la del sistema
+    TimeZone string `key:"time_zone" env:"CLINIC_TIME_ZONE" default:"Local"`
+}
+
+type Server struct {
+    Port              int           `key:"port" env:"PORT" flag:"port" default:"8080" help:"puerto HTTP"`
+    ReadHeaderTimeout time.Duration `key:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT" default:"5s"`
+    ReadTimeout       time.Duration `key:"read_timeout" env:"SERVER_READ_TIMEOUT" default:"30s"`
+    // WriteTimeout es amplio para permitir exportaciones síncronas de tamaño
+    // medio
+    WriteTimeout time.Duration `key:"write_timeout" env:"SERVER_WRITE_TIMEOUT" default:"2m"`
+    IdleTimeout  time.Duration `key:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" default:"2m"`
+    // ShutdownTimeout es lo que se espera a las peticiones en curso y a los
+    // trabajos en segundo plano al apagar
+    ShutdownTimeout time.Duration `key:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" default:"30s"`
+}
+
+type Database struct {
+    // Path es el fichero SQLite
+    Path            string        `key:"path" env:"DB_PATH" flag:"db" default:"vet_clinic.db" help:"fichero de la base de datos SQLite"`
+    MaxOpenConns    int           `key:"max_open_conns" env:"DB_MAX_OPEN_CONNS" default:"0"`
+    MaxIdleConns    int           `key:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" default:"2"`
+    ConnMaxLifetime time.Duration `key:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" default:"0s"`
+}
+
+type CORS struct {
+    // AllowedOrigins son los orígenes permitidos; "*" permite cualquiera y
+    // https://*.example.com cualquier subdominio
+    AllowedOrigins   []string      `key:"allowed_origins" env:"CORS_ALLOWED_ORIGINS" flag:"cors-origins" default:"*" help:"orígenes permitidos separados por comas"`
+    AllowCredentials bool          `key:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS"`
+    MaxAge           time.Duration `key:"max_age" env:"CORS_MAX_AGE" default:"10m"`
+    // AdminAllowedOrigins son los orígenes de las rutas de administración;
+    // vacío usa AllowedOrigins
+    AdminAllowedOrigins []string `key:"admin_allowed_origins" env:"CORS_ADMIN_ALLOWED_ORIGINS"`
+}
+
+type Auth struct {
+    // AdminToken protege las rutas de administración; vacío las desactiva
+    AdminToken string `key:"admin_token" env:"ADMIN_TOKEN" secret:"true"`
+    // RequireAPIKey exige una clave de API o el token de administración en
+    // todas las rutas de /api/v1. Sin él las peticiones anónimas solo pueden
+    // leer los catálogos comunes
+    RequireAPIKey bool `key:"require_api_key" env:"AUTH_REQUIRE_API_KEY" default:"true"`
+    // RequireClinic exige también a los administradores indicar la clínica
+    // (X-Clinic-ID) al trabajar con clientes, mascotas y citas. Sin él ven
+    // las de todo el grupo; las claves de API siempre necesitan la suya
+    RequireClinic bool `key:"require_clinic" env:"AUTH_REQUIRE_CLINIC" default:"true"`
+}
+
+type Log struct {
+    // Level es debug, info, warn o error. Si está vacío se usa debug con
+    // Debug e info en otro caso
+    Level  string `key:"level" env:"LOG_LEVEL" flag:"log-level" help:"nivel de log: debug, info, warn o error"`
+    Debug  bool   `key:"debug" env:"DEBUG" flag:"debug" help:"modo depuración"`
+    Pretty bool   `key:"pretty" env:"LOG_PRETTY" flag:"log-pretty" help:"logs en texto legible en lugar de JSON"`
+}
+
+// SlogLevel devuelve el nivel de log efectivo.
+func (l Log) SlogLevel() slog.Level {
+    level := slog.LevelInfo
+    if l.Debug {
+        level = slog.LevelDebug
+    }
+    if l.Level != "" {
+        level.UnmarshalText([]byte(l.Level))
+    }
+    return level
+}
+
+type Metrics struct {
+    // Addr publica /metrics en otra dirección en lugar de en el puerto de la API
+    Addr string `key:"addr" env:"METRICS_ADDR" flag:"metrics-addr" help:"dirección del servidor de métricas (vacío: en el puerto de la API)"`
+}
+
+type RateLimit struct {
+    Enabled bool `key:"enabled" env:"RATE_LIMIT_ENABLED" default:"true"`
+    // Requests por Period y por cliente en la API
+    Requests int           `key:"requests" env:"RATE_LIMIT_REQUESTS" default:"300"`
+    Period   time.Duration `key:"period" env:"RATE_LIMIT_PERIOD" default:"1m"`
+    // AdminRequests por AdminPeriod en las rutas de administración, más
+    // estricto para dificultar que se adivine el token
+    AdminRequests int           `key:"admin_requests" env:"RATE_LIMIT_ADMIN_REQUESTS" default:"30"`
+    AdminPeriod   time.Duration `key:"admin_period" env:"RATE_LIMIT_ADMIN_PERIOD" default:"1m"`
+    // LookupRequests por LookupPeriod en la búsqueda de mascotas por
+    // microchip, para que no se pueda recorrer el registro
+    LookupRequests int           `key:"lookup_requests" env:"RATE_LIMIT_LOOKUP_REQUESTS" default:"10"`
+    LookupPeriod   time.Duration `key:"lookup_period" env:"RATE_LIMIT_LOOKUP_PERIOD" default:"1m"`
+}
+
+type Reminders struct {
+    // EmailProvider es smtp, log o vacío para desactivar el email
+    EmailProvider string `key:"email_provider" env:"REMINDER_EMAIL_PROVIDER"`
+    // SMSProvider es http, log o vacío para desactivar el SMS
+    SMSProvider  string          `key:"sms_provider" env:"REMINDER_SMS_PROVIDER"`
+    LeadTimes    []time.Duration `key:"lead_times" env:"REMINDER_LEAD_TIMES" default:"24h,2h"`
+    Interval     time.Duration   `key:"interval" env:"REMINDER_INTERVAL" default:"1m"`
+    TemplatesDir string          `key:"templates_dir" env:"REMINDER_TEMPLATES_DIR"`
+    // LogFile es el fichero del proveedor log; vacío escribe en la salida estándar
+    LogFile string `key:"log_file" env:"REMINDER_LOG_FILE"`
+    SMTP    SMTP   `key:"smtp"`
+    SMS     SMS    `key:"sms"`
+}
+
+type Waitlist struct {
+    // OfferTTL es el plazo para aceptar un hueco ofrecido antes de que pase
+    // al siguiente de la lista
+    OfferTTL time.Duration `key:"offer_ttl" env:"WAITLIST_OFFER_TTL" default:"30m"`
+    // Interval es la frecuencia con la que se revisan las ofertas caducadas
+    Interval time.Duration `key:"interval" env:"WAITLIST_INTERVAL" default:"1m"`
+}
+
+type SMTP struct {
+    Host     string `key:"host" env:"SMTP_HOST"`
+    Port     int    `key:"port" env:"SMTP_PORT" default:"587"`
+    Username string `key:"username" env:"SMTP_USERNAME"`
+    Password string `key:"password" env:"SMTP_PASSWORD" secret:"true"`
+    From     string `key:"from" env:"SMTP_FROM"`
+}
+
+type SMS struct {
+    GatewayURL string `key:"gateway_url" env:"SMS_GATEWAY_URL"`
+    Token      string `key:"token" env:"SMS_GATEWAY_TOKEN" secret:"true"`
+    From       string `key:"from" env:"SMS_FROM"`
+}
+
+// Validate comprueba que la configuración es coherente y devuelve todos los
+// errores encontrados.
+func (c *Config) Validate() error {
+    var errs []error
+    check := func(ok bool, key, format string, args ...interface{}) {
+        if !ok {
+            errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+        }
+    }
+
+    check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port", "debe estar entre 1 y 65535 (es %d)", c.Server.Port)
+    for _, timeout := range []struct {
+        key   string
+        value time.Duration
+    }{
+        {"server.read_header_timeout", c.Server.ReadHeaderTimeout},
+        {"server.read_timeout", c.Server.ReadTimeout},
+        {"server.write_timeout", c.Server.WriteTimeout},
+        {"server.idle_timeout", c.Server.IdleTimeout},
+        {"server.shutdown_timeout", c.Server.ShutdownTimeout},
+    } {
+        check(timeout.value > 0, timeout.key, "debe ser mayor que cero")
+    }
+
+    check(strings.TrimSpace(c.Database.Path) != "", "database.path", "es obligatorio")
+    check(c.Database.MaxOpenConns >= 0, "database.max_open_conns", "no puede ser negativo")
+    check(c.Database.MaxIdleConns >= 0, "database.max_idle_conns", "no puede ser negativo")
+    check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime", "no puede ser negativo")
+
+    checkOrigins := func(key string, origins []string) {
+        for _, origin := range origins {
+            check(origin == "*" || strings.HasPrefix(origin, "http://") || strings.HasPrefix(origin, "https://"),
+                key, "origen inválido %q; use * o una URL http(s)://", origin)
+            check(origin != "*" || !c.CORS.AllowCredentials,
+                key, "* no puede combinarse con allow_credentials; indique los orígenes")
+        }
+    }
+    checkOrigins("cors.allowed_origins", c.CORS.AllowedOrigins)
+    checkOrigins("cors.admin_allowed_origins", c.CORS.AdminAllowedOrigins)
+    check(c.CORS.MaxAge >= 0, "cors.max_age", "no puede ser negativo")
+
+    if c.RateLimit.Enabled {
+        check(c.RateLimit.Requests > 0, "rate_limit.requests", "debe ser mayor que cero")
+        check(c.RateLimit.Period > 0, "rate_limit.period", "debe ser mayor que cero")
+        check(c.RateLimit.AdminRequests > 0, "rate_limit.admin_requests", "debe ser mayor que cero")
+        check(c.RateLimit.AdminPeriod > 0, "rate_limit.admin_period", "debe ser mayor que cero")
+        check(c.RateLimit.LookupRequests > 0, "rate_limit.lookup_requests", "debe ser mayor que cero")
+        check(c.RateLimit.LookupPeriod > 0, "rate_limit.lookup_period", "debe ser mayor que cero")
+    }
+
+    if c.Log.Level != "" {
+        var level slog.Level
+        check(level.UnmarshalText([]byte(c.Log.Level)) == nil, "log.level", "debe ser debug, info, warn o error (es %q)", c.Log.Level)
+    }
+
+    check(c.RetentionDays >= 0, "retention_days", "no puede ser negativo")
+    _, err := time.LoadLocation(c.TimeZone)
+    check(c.TimeZone != "" && err == nil, "time_zone", "zona horaria desconocida (%q)", c.TimeZone)
+
+    r := c.Reminders
+    switch r.EmailProvider {
+    case "", "log":
+    case "smtp":
+        check(r.SMTP.Host != "", "reminders.smtp.host", "es obligatorio con el proveedor smtp")
+        check(r.SMTP.From != "", "reminders.smtp.from", "es obligatorio con el proveedor smtp")
+    default:
+        check(false, "reminders.email_provider", "debe ser smtp, log o vacío (es %q)", r.EmailProvider)
+    }
+    switch r.SMSProvider {
+    case "", "log":
+    case "http":
+        check(r.SMS.GatewayURL != "", "reminders.sms.gateway_url", "es obligatorio con el proveedor http")
+    default:
+        check(false, "reminders.sms_provider", "debe ser http, log o vacío (es %q)", r.SMSProvider)
+    }
+    for _, lead := range r.LeadTimes {
+        check(lead > 0, "reminders.lead_times", "las antelaciones deben ser mayores que cero")
+    }
+    check(r.Interval > 0, "reminders.interval", "debe ser mayor que cero")
+
+    check(c.Waitlist.OfferTTL > 0, "waitlist.offer_ttl", "debe ser mayor que cero")
+    check(c.Waitlist.Interval > 0, "waitlist.interval", "debe ser mayor que cero")
+
+    return errors.Join(errs...)
+}
@@ -0,0 +1,391 @@
+package config
+
+import (
+    "bufio"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "reflect"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/pelletier/go-toml/v2"
+    "gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv indica el fichero de configuración si no se pasa -config
+const ConfigFileEnv = "CONFIG_FILE"
+
+// DefaultEnvFile es el fichero .env que se carga si existe
+const DefaultEnvFile = ".env"
+
+// LookupFunc busca una variable de entorno (normalmente os.LookupEnv).
+type LookupFunc func(key string) (string, bool)
+
+// setting es un campo configurable de Config con sus etiquetas.
+type setting struct {
+    key    string
+    env    string
+    flag   string
+    def    string
+    help   string
+    secret bool
+    value  reflect.Value
+}
+
+// settings recorre Config y devuelve sus campos finales en orden.
+func settings(cfg *Config) []setting {
+    var result []setting
+    var walk func(v reflect.Value, prefix string)
+    walk = func(v reflect.Value, prefix string) {
+        t := v.Type()
+        for i := 0; i < t.NumField(); i++ {
+            f := t.Field(i)
+            key := prefix + f.Tag.Get("key")
+            if f.Type.Kind() == reflect.Struct {
+                walk(v.Field(i), key+".")
+                continue
+            }
+            result = append(result, setting{
+                key:    key,
+                env:    f.Tag.Get("env"),
+                flag:   f.Tag.Get("flag"),
+                def:    f.Tag.Get("default"),
+                help:   f.Tag.Get("help"),
+                secret: f.Tag.Get("secret") == "true",
+                value:  v.Field(i),
+            })
+        }
+    }
+    walk(reflect.ValueOf(cfg).Elem(), "")
+    return result
+}
+
+// Load construye la configuración aplicando, por este orden, los valores por
+// defecto, el fichero de configuración (-config o CONFIG_FILE), el fichero
+// .env (-env-file, por defecto .env si existe), las variables de entorno y
+// las opciones de args. Después la valida.
+func Load(args []string, lookupEnv LookupFunc) (*Config, error) {
+    cfg := &Config{}
+    all := settings(cfg)
+
+    // Las opciones se leen primero para conocer los ficheros, pero se
+    // aplican al final
+    flags := flag.NewFlagSet("vet-clinic-api", flag.ContinueOnError)
+    configFile := flags.String("config", "", "fichero de configuración YAML o TOML")
+    envFile := flags.String("env-file", DefaultEnvFile, "fichero .env")
+    provided := map[string]string{}
+    for _, s := range all {
+        if s.flag == "" {
+            continue
+        }
+        name := s.flag
+        flags.Func(name, s.help, func(value string) error {
+            provided[name] = value
+            return nil
+        })
+    }
+    if err := flags.Parse(args); err != nil {
+        return nil, err
+    }
+    if flags.NArg() > 0 {
+        return nil, fmt.Errorf("argumentos no reconocidos: %s", strings.Join(flags.Args(), " "))
+    }
+    explicitEnvFile := false
+    flags.Visit(func(f *flag.Flag) {
+        if f.Name == "env-file" {
+            explicitEnvFile = true
+        }
+    })
+
+    for _, s := range all {
+        if s.def == "" {
+            continue
+        }
+        if err := setValue(s.value, s.def); err != nil {
+            return nil, fmt.Errorf("valor por defecto de %s: %w", s.key, err)
+        }
+    }
+
+    if *configFile == "" {
+        *configFile, _ = lookupEnv(ConfigFileEnv)
+    }
+    if *configFile != "" {
+        if err := loadFile(*configFile, all); err != nil {
+            return nil, fmt.Errorf("fichero de configuración %s: %w", *configFile, err)
+        }
+    }
+
+    dotenv, err := readEnvFile(*envFile)
+    if err != nil && (explicitEnvFile || !errors.Is(err, os.ErrNotExist)) {
+        return nil, fmt.Errorf("fichero %s: %w", *envFile, err)
+    }
+
+    // Las variables de entorno tienen prioridad sobre el fichero .env
+    for _, s := range all {
+        if s.env == "" {
+            continue
+        }
+        value, ok := lookupEnv(s.env)
+        if !ok {
+            value, ok = dotenv[s.env]
+        }
+        if !ok {
+            continue
+        }
+        if err := setValue(s.value, value); err != nil {
+            return nil, fmt.Errorf("variable %s: %w", s.env, err)
+        }
+    }
+
+    for _, s := range all {
+        value, ok := provided[s.flag]
+        if s.flag == "" || !ok {
+            continue
+        }
+        if err := setValue(s.value, value); err != nil {
+            return nil, fmt.Errorf("opción -%s: %w", s.flag, err)
+        }
+    }
+
+    if err := cfg.Validate(); err != nil {
+        return nil, err
+    }
+    return cfg, nil
+}
+
+// loadFile aplica un fichero YAML o TOML según su extensión.
+func loadFile(path string, all []setting) error {
+    content, err := os.ReadFile(path)
+    if err != nil {
+        return err
+    }
+
+    values := map[string]interface{}{}
+    switch ext := strings.ToLower(filepath.Ext(path)); ext {
+    case ".yaml", ".yml":
+        err = yaml.Unmarshal(content, &values)
+    case ".toml":
+        err = toml.Unmarshal(content, &values)
+    default:
+        return fmt.Errorf("extensión %q no soportada; use .yaml, .yml o .toml", ext)
+    }
+    if err != nil {
+        return err
+    }
+
+    flat := map[string]interface{}{}
+    flatten(values, "", flat)
+
+    byKey := map[string]setting{}
+    for _, s := range all {
+        byKey[s.key] = s
+    }
+
+    var errs []error
+    for key, value := range flat {
+        s, ok := byKey[key]
+        if !ok {
+            errs = append(errs, fmt.Errorf("clave desconocida %s", key))
+            continue
+        }
+        if err := setValue(s.value, fileValue(value)); err != nil {
+            errs = append(errs, fmt.Errorf("%s: %w", key, err))
+        }
+    }
+    return errors.Join(errs...)
+}
+
+// flatten convierte las secciones anidadas en claves con puntos
+// (server.port).
+func flatten(values map[string]interface{}, prefix string, out map[string]interface{}) {
+    for key, value := range values {
+        if nested, ok := value.(map[string]interface{}); ok {
+            flatten(nested, prefix+key+".", out)
+            continue
+        }
+        out[prefix+key] = value
+    }
+}
+
+// fileValue convierte un valor del fichero en el texto que entiende setValue.
+func fileValue(value interface{}) string {
+    if list, ok := value.([]interface{}); ok {
+        items := make([]string, len(list))
+        for i, item := range list {
+            items[i] = fmt.Sprint(item)
+        }
+        return strings.Join(items, ",")
+    }
+    if value == nil {
+        return ""
+    }
+    return fmt.Sprint(value)
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setValue interpreta raw según el tipo del campo. Las listas se separan
+// por comas.
+func setValue(v reflect.Value, raw string) error {
+    raw = strings.TrimSpace(raw)
+
+    switch {
+    case v.Type() == durationType:
+        d, err := time.ParseDuration(raw)
+        if err != nil {
+            return fmt.Errorf("duración inválida %q (p. ej. 30s, 5m, 2h)", raw)
+        }
+        v.SetInt(int64(d))
+    case v.Kind() == reflect.Slice:
+        items := []string{}
+        for _, item := range strings.Split(raw, ",") {
+            if item = strings.TrimSpace(item); item != "" {
+                items = append(items, item)
+            }
+        }
+        list := reflect.MakeSlice(v.Type(), len(items), len(items))
+        for i, item := range items {
+            if err := setValue(list.Index(i), item); err != nil {
+                return err
+            }
+        }
+        v.Set(list)
+    case v.Kind() == reflect.String:
+        v.SetString(raw)
+    case v.Kind() == reflect.Int:
+        n, err := strconv.Atoi(raw)
+        if err != nil {
+            return fmt.Errorf("número entero inválido %q", raw)
+        }
+        v.SetInt(int64(n))
+    case v.Kind() == reflect.Bool:
+        b, err := strconv.ParseBool(raw)
+        if err != nil {
+            return fmt.Errorf("valor booleano inválido %q (use true o false)", raw)
+        }
+        v.SetBool(b)
+    default:
+        return fmt.Errorf("tipo no soportado %s", v.Type())
+    }
+    return nil
+}
+
+// readEnvFile lee un fichero .env con líneas CLAVE=valor. Admite
+// comentarios con #, el prefijo export y valores entre comillas.
+func readEnvFile(path string) (map[string]string, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    return parseEnv(f)
+}
+
+func parseEnv(r io.Reader) (map[string]string, error) {
+    values := map[string]string{}
+    scanner := bufio.NewScanner(r)
+    line := 0
+    for scanner.Scan() {
+        line++
+        text := strings.TrimSpace(scanner.Text())
+        if text == "" || strings.HasPrefix(text, "#") {
+            continue
+        }
+        text = strings.TrimPrefix(text, "export ")
+
+        key, value, ok := strings.Cut(text, "=")
+        key = strings.TrimSpace(key)
+        if !ok || key == "" {
+            return nil, fmt.Errorf("línea %d: se esperaba CLAVE=valor", line)
+        }
+
+        value = strings.TrimSpace(value)
+        switch {
+        case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+            unquoted, err := strconv.Unquote(value)
+            if err != nil {
+                return nil, fmt.Errorf("línea %d: comillas inválidas", line)
+            }
+            value = unquoted
+        case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+            value = value[1 : len(value)-1]
+        default:
+            if i := strings.Index(value, " #"); i >= 0 {
+                value = strings.TrimSpace(value[:i])
+            }
+        }
+        values[key] = value
+    }
+    return values, scanner.Err()
+}
+
+// Print escribe la configuración efectiva en YAML, con los secretos
+// ocultos.
+func (c *Config) Print(w io.Writer) error {
+    root := &yaml.Node{Kind: yaml.MappingNode}
+    sections := map[string]*yaml.Node{"": root}
+
+    for _, s := range settings(c) {
+        parts := strings.Split(s.key, ".")
+        parent := root
+        for i := range parts[:len(parts)-1] {
+            path := strings.Join(parts[:i+1], ".")
+            section, ok := sections[path]
+            if !ok {
+                section = &yaml.Node{Kind: yaml.MappingNode}
+                parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: parts[i]}, section)
+                sections[path] = section
+            }
+            parent = section
+        }
+
+        parent.Content = append(parent.Content,
+            &yaml.Node{Kind: yaml.ScalarNode, Value: parts[len(parts)-1]},
+            printValue(s))
+    }
+
+    enc := yaml.NewEncoder(w)
+    enc.SetIndent(2)
+    if err := enc.Encode(root); err != nil {
+        return err
+    }
+    return enc.Close()
+}
+
+// Redacted sustituye a los secretos al mostrar la configuración
+const Redacted = "[REDACTED]"
+
+func printValue(s setting) *yaml.Node {
+    if s.secret {
+        value := ""
+        if s.value.String() != "" {
+            value = Redacted
+        }
+        return &yaml.Node{Kind: yaml.ScalarNode, Value: value, Tag: "!!str"}
+    }
+
+    if s.value.Kind() == reflect.Slice {
+        list := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+        for i := 0; i < s.value.Len(); i++ {
+            list.Content = append(list.Content, scalar(s.value.Index(i)))
+        }
+        return list
+    }
+    return scalar(s.value)
+}
+
+func scalar(v reflect.Value) *yaml.Node {
+    switch {
+    case v.Type() == durationType:
+        return &yaml.Node{Kind: yaml.ScalarNode, Value: time.Duration(v.Int()).String()}
+    case v.Kind() == reflect.String:
+        return &yaml.Node{Kind: yaml.ScalarNode, Value: v.String(), Tag: "!!str"}
+    default:
+        return &yaml.Node{Kind: yaml.ScalarNode, Value: fmt.Sprint(v.Interface())}
+    }
+}
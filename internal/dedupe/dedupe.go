@@ -0,0 +1,301 @@
+// Package dedupe detecta clientes que probablemente son la misma persona.
+package dedupe
+
+import (
+    "sort"
+    "strings"
+    "unicode"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+)
+
+// DefaultMinScore es la puntuación mínima para considerar dos clientes
+// posibles duplicados.
+const DefaultMinScore = 0.6
+
+// Peso de cada señal en la puntuación
+const (
+    phoneWeight   = 0.4
+    nameWeight    = 0.4
+    addressWeight = 0.2
+    emailBonus    = 0.2
+)
+
+// nationalDigits es la longitud de un número nacional (España). Los
+// teléfonos se comparan por sus últimos dígitos para ignorar el prefijo
+// internacional.
+const nationalDigits = 9
+
+// Candidate es una pareja de clientes que probablemente son la misma persona.
+type Candidate struct {
+    Clients           [2]models.Client `json:"clients"`
+    Score             float64          `json:"score"`
+    PhoneMatch        bool             `json:"phone_match"`
+    EmailMatch        bool             `json:"email_match"`
+    NameSimilarity    float64          `json:"name_similarity"`
+    AddressSimilarity float64          `json:"address_similarity"`
+}
+
+// FindCandidates compara los clientes entre sí y devuelve las parejas con
+// una puntuación de al menos minScore, de más a menos probable.
+//
+// Solo se comparan los clientes que comparten teléfono o alguna palabra del
+// nombre, para no comparar todos con todos.
+func FindCandidates(clients []models.Client, minScore float64) []Candidate {
+    blocks := make(map[string][]int)
+    for i, client := range clients {
+        keys := map[string]bool{}
+        if phone := NormalizePhone(client.Phone); phone != "" {
+            keys["tel:"+phone] = true
+        }
+        for _, token := range strings.Fields(normalizeText(client.Name)) {
+            if len(token) >= 3 {
+                keys["nombre:"+token] = true
+            }
+        }
+        for key := range keys {
+            blocks[key] = append(blocks[key], i)
+        }
+    }
+
+    seen := make(map[[2]int]bool)
+    candidates := []Candidate{}
+    for _, block := range blocks {
+        for x := 0; x < len(block); x++ {
+            for y := x + 1; y < len(block); y++ {
+                pair := [2]int{block[x], block[y]}
+                if seen[pair] {
+                    continue
+                }
+                seen[pair] = true
+
+                candidate := Compare(clients[pair[0]], clients[pair[1]])
+                if candidate.Score >= minScore {
+                    candidates = append(candidates, candidate)
+                }
+            }
+        }
+    }
+
+    sort.Slice(candidates, func(i, j int) bool {
+        if candidates[i].Score != candidates[j].Score {
+            return candidates[i].Score > candidates[j].Score
+        }
+        return candidates[i].Clients[0].ID < candidates[j].Clients[0].ID
+    })
+    return candidates
+}
+
+// Compare puntúa de 0 a 1 la probabilidad de que dos clientes sean la misma
+// persona a partir del teléfono normalizado, la similitud del nombre y de la
+// dirección y, como refuerzo, el email.
+func Compare(a, b models.Client) Candidate {
+    if a.ID > b.ID {
+        a, b = b, a
+    }
+
+    candidate := Candidate{
+        Clients:           [2]models.Client{a, b},
+        PhoneMatch:        NormalizePhone(a.Phone) != "" && NormalizePhone(a.Phone) == NormalizePhone(b.Phone),
+        EmailMatch:        normalizeEmail(a.Email) != "" && normalizeEmail(a.Email) == normalizeEmail(b.Email),
+        NameSimilarity:    round(NameSimilarity(a.Name, b.Name)),
+        AddressSimilarity: round(AddressSimilarity(a.Address, b.Address)),
+    }
+
+    score := nameWeight*candidate.NameSimilarity + addressWeight*candidate.AddressSimilarity
+    if candidate.PhoneMatch {
+        score += phoneWeight
+    }
+    if candidate.EmailMatch {
+        score += emailBonus
+    }
+    if score > 1 {
+        score = 1
+    }
+    candidate.Score = round(score)
+
+    return candidate
+}
+
+// NormalizePhone deja solo los dígitos del teléfono y se queda con los
+// últimos nationalDigits, de modo que "+34 612-345-678" y "612345678" son
+// iguales.
+func NormalizePhone(phone string) string {
+    digits := strings.Map(func(r rune) rune {
+        if r >= '0' && r <= '9' {
+            return r
+        }
+        return -1
+    }, phone)
+    if len(digits) > nationalDigits {
+        digits = digits[len(digits)-nationalDigits:]
+    }
+    return digits
+}
+
+// NameSimilarity compara dos nombres sin tener en cuenta mayúsculas, tildes
+// ni el orden de las palabras. Si todas las palabras del nombre más corto
+// aparecen en el otro ("Ana Ruiz" y "Ana Ruiz Gil") la similitud es alta.
+func NameSimilarity(a, b string) float64 {
+    tokensA, tokensB := strings.Fields(normalizeText(a)), strings.Fields(normalizeText(b))
+    if len(tokensA) == 0 || len(tokensB) == 0 {
+        return 0
+    }
+
+    sort.Strings(tokensA)
+    sort.Strings(tokensB)
+    similarity := ratio(strings.Join(tokensA, " "), strings.Join(tokensB, " "))
+
+    shorter, longer := tokensA, tokensB
+    if len(shorter) > len(longer) {
+        shorter, longer = longer, shorter
+    }
+    if len(shorter) >= 2 {
+        contained := 0
+        for _, token := range shorter {
+            for _, other := range longer {
+                if ratio(token, other) >= 0.8 {
+                    contained++
+                    break
+                }
+            }
+        }
+        if containment := 0.9 * float64(contained) / float64(len(shorter)); containment > similarity {
+            similarity = containment
+        }
+    }
+
+    return similarity
+}
+
+// AddressSimilarity compara dos direcciones por sus palabras (índice de
+// Jaccard) tras expandir las abreviaturas habituales. Devuelve 0 si alguna
+// está vacía.
+func AddressSimilarity(a, b string) float64 {
+    tokensA, tokensB := addressTokens(a), addressTokens(b)
+    if len(tokensA) == 0 || len(tokensB) == 0 {
+        return 0
+    }
+
+    common := 0
+    for token := range tokensA {
+        if tokensB[token] {
+            common++
+        }
+    }
+    return float64(common) / float64(len(tokensA)+len(tokensB)-common)
+}
+
+var addressAbbreviations = map[string]string{
+    "c":     "calle",
+    "cl":    "calle",
+    "av":    "avenida",
+    "avda":  "avenida",
+    "pza":   "plaza",
+    "pl":    "plaza",
+    "ps":    "paseo",
+    "pº":    "paseo",
+    "ctra":  "carretera",
+    "urb":   "urbanizacion",
+    "dcha":  "derecha",
+    "izq":   "izquierda",
+    "izqda": "izquierda",
+}
+
+// Palabras que no distinguen una dirección de otra
+var addressStopWords = map[string]bool{
+    "de": true, "del": true, "la": true, "el": true, "los": true, "las": true,
+    "n": true, "no": true, "num": true, "numero": true, "nº": true,
+}
+
+func addressTokens(address string) map[string]bool {
+    tokens := make(map[string]bool)
+    for _, token := range strings.FieldsFunc(normalizeText(address), func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != 'º'
+    }) {
+        if expanded, ok := addressAbbreviations[token]; ok {
+            token = expanded
+        }
+        if !addressStopWords[token] {
+            tokens[token] = true
+        }
+    }
+    return tokens
+}
+
+// normalizeEmail ignora mayúsculas, puntos y etiquetas (+algo) de la parte
+// local del email.
+func normalizeEmail(email string) string {
+    email = strings.ToLower(strings.TrimSpace(email))
+    at := strings.LastIndex(email, "@")
+    if at < 0 {
+        return email
+    }
+
+    local, domain := email[:at], email[at+1:]
+    if plus := strings.Index(local, "+"); plus >= 0 {
+        local = local[:plus]
+    }
+    return strings.ReplaceAll(local, ".", "") + "@" + domain
+}
+
+var accents = strings.NewReplacer(
+    "á", "a", "à", "a", "ä", "a", "â", "a",
+    "é", "e", "è", "e", "ë", "e", "ê", "e",
+    "í", "i", "ì", "i", "ï", "i", "î", "i",
+    "ó", "o", "ò", "o", "ö", "o", "ô", "o",
+    "ú", "u", "ù", "u", "ü", "u", "û", "u",
+    "ñ", "n", "ç", "c",
+)
+
+// normalizeText pasa a minúsculas, quita las tildes y separa las palabras
+// por un único espacio.
+func normalizeText(s string) string {
+    s = accents.Replace(strings.ToLower(s))
+    s = strings.Map(func(r rune) rune {
+        if unicode.IsLetter(r) || unicode.IsDigit(r) || r == 'º' {
+            return r
+        }
+        return ' '
+    }, s)
+    return strings.Join(strings.Fields(s), " ")
+}
+
+// ratio devuelve la similitud entre dos textos a partir de la distancia de
+// Levenshtein: 1 si son iguales y 0 si no tienen nada en común.
+func ratio(a, b string) float64 {
+    ra, rb := []rune(a), []rune(b)
+    longest := len(ra)
+    if len(rb) > longest {
+        longest = len(rb)
+    }
+    if longest == 0 {
+        return 1
+    }
+    return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+    previous := make([]int, len(b)+1)
+    current := make([]int, len(b)+1)
+    for j := range previous {
+        previous[j] = j
+    }
+
+    for i := 1; i <= len(a); i++ {
+        current[0] = i
+        for j := 1; j <= len(b); j++ {
+            cost := 1
+            if a[i-1] == b[j-1] {
+                cost = 0
+            }
+            current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+        }
+        previous, current = current, previous
+    }
+    return previous[len(b)]
+}
+
+func round(value float64) float64 {
+    return float64(int(value*100+0.5)) / 100
+}
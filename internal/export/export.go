@@ -0,0 +1,232 @@
+// Package export vuelca clientes, mascotas y citas a CSV, NDJSON o XLSX
+// leyendo las filas de la base de datos una a una, sin cargarlas todas en
+// memoria.
+package export
+
+import (
+    "encoding/csv"
+    "encoding/json"
+    "errors"
+    "io"
+    "strconv"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "gorm.io/gorm"
+)
+
+// Formatos admitidos
+const (
+    FormatCSV    = "csv"
+    FormatNDJSON = "ndjson"
+    FormatXLSX   = "xlsx"
+)
+
+// Recursos exportables
+const (
+    ResourceClients      = "clients"
+    ResourcePets         = "pets"
+    ResourceAppointments = "appointments"
+)
+
+var (
+    ErrUnknownFormat   = errors.New("formato desconocido; use csv, ndjson o xlsx")
+    ErrUnknownResource = errors.New("recurso desconocido; use clients, pets o appointments")
+)
+
+// ContentTypes indica el tipo MIME de cada formato.
+var ContentTypes = map[string]string{
+    FormatCSV:    "text/csv; charset=utf-8",
+    FormatNDJSON: "application/x-ndjson",
+    FormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// Filter son los filtros de los listados de cada recurso, que se aplican
+// igual al exportarlo.
+type Filter struct {
+    Pets         repositories.PetFilter
+    Appointments repositories.AppointmentFilter
+}
+
+// resource describe cómo leer y convertir en columnas un tipo de registro.
+type resource struct {
+    model   interface{}
+    columns []string
+    newRow  func() interface{}
+    values  func(row interface{}) []interface{}
+    filter  func(db *gorm.DB, f Filter) *gorm.DB
+}
+
+var resources = map[string]resource{
+    ResourceClients: {
+        model:   &models.Client{},
+        columns: []string{"id", "name", "email", "phone", "address", "created_at", "updated_at"},
+        newRow:  func() interface{} { return &models.Client{} },
+        values: func(row interface{}) []interface{} {
+            c := row.(*models.Client)
+            return []interface{}{c.ID, c.Name, c.Email, c.Phone, c.Address, c.CreatedAt, c.UpdatedAt}
+        },
+        filter: func(db *gorm.DB, f Filter) *gorm.DB { return db },
+    },
+    ResourcePets: {
+        model:   &models.Pet{},
+        columns: []string{"id", "name", "species", "breed", "birth_date", "weight", "client_id", "description", "created_at", "updated_at"},
+        newRow:  func() interface{} { return &models.Pet{} },
+        values: func(row interface{}) []interface{} {
+            p := row.(*models.Pet)
+            return []interface{}{p.ID, p.Name, p.Species, p.Breed, p.BirthDate, p.Weight, p.ClientID, p.Description, p.CreatedAt, p.UpdatedAt}
+        },
+        filter: func(db *gorm.DB, f Filter) *gorm.DB { return f.Pets.Apply(db) },
+    },
+    ResourceAppointments: {
+        model:   &models.Appointment{},
+        columns: []string{"id", "pet_id", "date", "duration", "reason", "notes", "completed", "created_at", "updated_at"},
+        newRow:  func() interface{} { return &models.Appointment{} },
+        values: func(row interface{}) []interface{} {
+            a := row.(*models.Appointment)
+            return []interface{}{a.ID, a.PetID, a.Date, a.Duration, a.Reason, a.Notes, a.Completed, a.CreatedAt, a.UpdatedAt}
+        },
+        filter: func(db *gorm.DB, f Filter) *gorm.DB { return f.Appointments.Apply(db) },
+    },
+}
+
+// Validate comprueba el recurso y el formato antes de empezar a escribir.
+func Validate(resourceName, format string) error {
+    if _, ok := resources[resourceName]; !ok {
+        return ErrUnknownResource
+    }
+    if _, ok := ContentTypes[format]; !ok {
+        return ErrUnknownFormat
+    }
+    return nil
+}
+
+// Count devuelve el número de filas que exportaría Write.
+func Count(db *gorm.DB, resourceName string, filter Filter) (int64, error) {
+    res, ok := resources[resourceName]
+    if !ok {
+        return 0, ErrUnknownResource
+    }
+    var count int64
+    err := res.filter(db.Model(res.model), filter).Count(&count).Error
+    return count, err
+}
+
+// Write escribe en w los registros activos del recurso, ordenados por ID, y
+// devuelve cuántos ha escrito.
+func Write(db *gorm.DB, w io.Writer, resourceName, format string, filter Filter) (int, error) {
+    if err := Validate(resourceName, format); err != nil {
+        return 0, err
+    }
+    res := resources[resourceName]
+
+    var out rowWriter
+    switch format {
+    case FormatCSV:
+        out = &csvWriter{w: csv.NewWriter(w)}
+    case FormatNDJSON:
+        out = &ndjsonWriter{enc: json.NewEncoder(w)}
+    case FormatXLSX:
+        out = newXLSXWriter(w, resourceName)
+    }
+
+    rows, err := res.filter(db.Model(res.model), filter).Order("id").Rows()
+    if err != nil {
+        return 0, err
+    }
+    defer rows.Close()
+
+    if err := out.header(res.columns); err != nil {
+        return 0, err
+    }
+
+    written := 0
+    for rows.Next() {
+        row := res.newRow()
+        if err := db.ScanRows(rows, row); err != nil {
+            return written, err
+        }
+        if err := out.row(row, res.values(row)); err != nil {
+            return written, err
+        }
+        written++
+    }
+    if err := rows.Err(); err != nil {
+        return written, err
+    }
+
+    return written, out.close()
+}
+
+// rowWriter es la salida de un formato concreto.
+type rowWriter interface {
+    header(columns []string) error
+    // row recibe el registro completo y sus valores en el orden de las columnas
+    row(record interface{}, values []interface{}) error
+    close() error
+}
+
+type csvWriter struct {
+    w *csv.Writer
+}
+
+func (c *csvWriter) header(columns []string) error {
+    return c.w.Write(columns)
+}
+
+func (c *csvWriter) row(_ interface{}, values []interface{}) error {
+    record := make([]string, len(values))
+    for i, value := range values {
+        record[i] = formatValue(value)
+    }
+    if err := c.w.Write(record); err != nil {
+        return err
+    }
+    // Vaciar el búfer en cada fila para que el cliente reciba los datos
+    // según se leen
+    c.w.Flush()
+    return c.w.Error()
+}
+
+func (c *csvWriter) close() error {
+    c.w.Flush()
+    return c.w.Error()
+}
+
+// ndjsonWriter escribe cada registro tal y como lo devuelve la API.
+type ndjsonWriter struct {
+    enc *json.Encoder
+}
+
+func (n *ndjsonWriter) header([]string) error { return nil }
+
+func (n *ndjsonWriter) row(record interface{}, _ []interface{}) error {
+    return n.enc.Encode(record)
+}
+
+func (n *ndjsonWriter) close() error { return nil }
+
+// formatValue convierte un valor en texto para CSV y para las celdas de
+// texto de XLSX. Las fechas sin valor quedan vacías.
+func formatValue(value interface{}) string {
+    switch v := value.(type) {
+    case string:
+        return v
+    case uint:
+        return strconv.FormatUint(uint64(v), 10)
+    case int:
+        return strconv.Itoa(v)
+    case float64:
+        return strconv.FormatFloat(v, 'f', -1, 64)
+    case bool:
+        return strconv.FormatBool(v)
+    case time.Time:
+        if v.IsZero() {
+            return ""
+        }
+        return v.Format(time.RFC3339)
+    default:
+        return ""
+    }
+}
@@ -0,0 +1,194 @@
+package export
+
+import (
+    "crypto/rand"
+    "encoding/hex"
+    "os"
+    "path/filepath"
+    "sync"
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// Estados de una exportación en segundo plano
+const (
+    JobPending = "pending"
+    JobRunning = "running"
+    JobDone    = "done"
+    JobFailed  = "failed"
+)
+
+const (
+    // DefaultAsyncThreshold es el número de filas a partir del cual una
+    // exportación se hace en segundo plano
+    DefaultAsyncThreshold = 10000
+    // DefaultJobTTL es el tiempo que se conserva el fichero generado
+    DefaultJobTTL = time.Hour
+)
+
+// Owner identifica quién pidió una exportación: la clínica de la petición
+// (0 en la vista de grupo) y la clave de API (0 si no la pidió una clave).
+// Solo quien la pidió puede consultarla y descargarla.
+type Owner struct {
+    ClinicID uint
+    KeyID    uint
+}
+
+// Job es una exportación en segundo plano.
+type Job struct {
+    ID         string     `json:"id"`
+    Resource   string     `json:"resource"`
+    Format     string     `json:"format"`
+    Status     string     `json:"status"`
+    Rows       int        `json:"rows"`
+    Error      string     `json:"error,omitempty"`
+    CreatedAt  time.Time  `json:"created_at"`
+    FinishedAt *time.Time `json:"finished_at,omitempty"`
+    // DownloadURL lo rellena la API cuando el fichero está listo
+    DownloadURL string `json:"download_url,omitempty"`
+    Owner       Owner  `json:"-"`
+
+    path string
+}
+
+// Filename es el nombre con el que se descarga el fichero.
+func (j Job) Filename() string {
+    return j.Resource + "." + j.Format
+}
+
+// Jobs guarda en memoria las exportaciones en segundo plano y genera los
+// ficheros en Dir. Los trabajos y sus ficheros se eliminan pasado TTL desde
+// que terminan.
+type Jobs struct {
+    Dir            string
+    TTL            time.Duration
+    AsyncThreshold int64
+
+    mu      sync.Mutex
+    jobs    map[string]*Job
+    running sync.WaitGroup
+}
+
+func NewJobs(dir string) *Jobs {
+    return &Jobs{
+        Dir:            dir,
+        TTL:            DefaultJobTTL,
+        AsyncThreshold: DefaultAsyncThreshold,
+        jobs:           make(map[string]*Job),
+    }
+}
+
+// Start lanza la exportación en segundo plano y devuelve el trabajo creado.
+// db no debe estar ligado a un contexto que se cancele al terminar la
+// petición.
+func (j *Jobs) Start(db *gorm.DB, resourceName, format string, filter Filter, owner Owner) (Job, error) {
+    if err := Validate(resourceName, format); err != nil {
+        return Job{}, err
+    }
+
+    id, err := newJobID()
+    if err != nil {
+        return Job{}, err
+    }
+
+    job := &Job{
+        ID:        id,
+        Resource:  resourceName,
+        Format:    format,
+        Status:    JobPending,
+        CreatedAt: time.Now(),
+        Owner:     owner,
+        path:      filepath.Join(j.Dir, "export-"+id+"."+format),
+    }
+
+    j.mu.Lock()
+    j.expire()
+    j.jobs[id] = job
+    snapshot := *job
+    j.mu.Unlock()
+
+    j.running.Add(1)
+    go j.run(db, job, filter)
+    return snapshot, nil
+}
+
+// Wait espera a que terminen las exportaciones en curso.
+func (j *Jobs) Wait() {
+    j.running.Wait()
+}
+
+// Get devuelve una copia del estado del trabajo.
+func (j *Jobs) Get(id string) (Job, bool) {
+    j.mu.Lock()
+    defer j.mu.Unlock()
+
+    j.expire()
+    job, ok := j.jobs[id]
+    if !ok {
+        return Job{}, false
+    }
+    return *job, true
+}
+
+// Open abre el fichero de un trabajo terminado.
+func (j *Jobs) Open(job Job) (*os.File, error) {
+    return os.Open(job.path)
+}
+
+func (j *Jobs) run(db *gorm.DB, job *Job, filter Filter) {
+    defer j.running.Done()
+    j.update(job, func() { job.Status = JobRunning })
+
+    rows, err := j.write(db, job, filter)
+
+    j.update(job, func() {
+        now := time.Now()
+        job.FinishedAt = &now
+        job.Rows = rows
+        if err != nil {
+            job.Status = JobFailed
+            job.Error = err.Error()
+            os.Remove(job.path)
+            return
+        }
+        job.Status = JobDone
+    })
+}
+
+func (j *Jobs) write(db *gorm.DB, job *Job, filter Filter) (int, error) {
+    f, err := os.Create(job.path)
+    if err != nil {
+        return 0, err
+    }
+
+    rows, err := Write(db, f, job.Resource, job.Format, filter)
+    if closeErr := f.Close(); err == nil {
+        err = closeErr
+    }
+    return rows, err
+}
+
+func (j *Jobs) update(job *Job, change func()) {
+    j.mu.Lock()
+    defer j.mu.Unlock()
+    change()
+}
+
+// expire elimina los trabajos caducados. Debe llamarse con mu bloqueado.
+func (j *Jobs) expire() {
+    for id, job := range j.jobs {
+        if job.FinishedAt != nil && time.Since(*job.FinishedAt) > j.TTL {
+            os.Remove(job.path)
+            delete(j.jobs, id)
+        }
+    }
+}
+
+func newJobID() (string, error) {
+    b := make([]byte, 16)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,127 @@
+package export
+
+import (
+    "archive/zip"
+    "bufio"
+    "bytes"
+    "encoding/xml"
+    "fmt"
+    "io"
+    "strconv"
+)
+
+// Partes fijas de un libro XLSX con una sola hoja
+const (
+    xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+        `<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+        `<Default Extension="xml" ContentType="application/xml"/>` +
+        `<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+        `<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+        `</Types>`
+    xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+        `<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+        `</Relationships>`
+    xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+        `<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+        `</Relationships>`
+    xlsxWorkbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+        `<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+)
+
+// xlsxWriter genera un libro XLSX mínimo. El zip se escribe en orden, así
+// que la hoja puede ir volcándose fila a fila; los textos van como cadenas
+// en línea para no tener que acumular una tabla de cadenas compartidas.
+type xlsxWriter struct {
+    zip   *zip.Writer
+    sheet *bufio.Writer
+    name  string
+    line  int
+}
+
+func newXLSXWriter(w io.Writer, sheetName string) *xlsxWriter {
+    return &xlsxWriter{zip: zip.NewWriter(w), name: sheetName}
+}
+
+func (x *xlsxWriter) header(columns []string) error {
+    var name bytes.Buffer
+    xml.EscapeText(&name, []byte(x.name))
+
+    parts := []struct{ path, content string }{
+        {"[Content_Types].xml", xlsxContentTypes},
+        {"_rels/.rels", xlsxRootRels},
+        {"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+        {"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, name.String())},
+    }
+    for _, part := range parts {
+        f, err := x.zip.Create(part.path)
+        if err != nil {
+            return err
+        }
+        if _, err := io.WriteString(f, part.content); err != nil {
+            return err
+        }
+    }
+
+    f, err := x.zip.Create("xl/worksheets/sheet1.xml")
+    if err != nil {
+        return err
+    }
+    x.sheet = bufio.NewWriter(f)
+    x.sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+    values := make([]interface{}, len(columns))
+    for i, column := range columns {
+        values[i] = column
+    }
+    return x.row(nil, values)
+}
+
+func (x *xlsxWriter) row(_ interface{}, values []interface{}) error {
+    x.line++
+    line := strconv.Itoa(x.line)
+    x.sheet.WriteString(`<row r="` + line + `">`)
+    for i, value := range values {
+        ref := columnName(i) + line
+        switch v := value.(type) {
+        case uint, int, float64:
+            x.sheet.WriteString(`<c r="` + ref + `"><v>` + formatValue(v) + `</v></c>`)
+        case bool:
+            b := "0"
+            if v {
+                b = "1"
+            }
+            x.sheet.WriteString(`<c r="` + ref + `" t="b"><v>` + b + `</v></c>`)
+        default:
+            text := formatValue(v)
+            if text == "" {
+                continue
+            }
+            x.sheet.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+            xml.EscapeText(x.sheet, []byte(text))
+            x.sheet.WriteString(`</t></is></c>`)
+        }
+    }
+    _, err := x.sheet.WriteString(`</row>`)
+    return err
+}
+
+func (x *xlsxWriter) close() error {
+    if _, err := x.sheet.WriteString(`</sheetData></worksheet>`); err != nil {
+        return err
+    }
+    if err := x.sheet.Flush(); err != nil {
+        return err
+    }
+    return x.zip.Close()
+}
+
+// columnName convierte un índice en el nombre de columna de la hoja
+// (0 -> A, 25 -> Z, 26 -> AA).
+func columnName(index int) string {
+    name := ""
+    for index >= 0 {
+        name = string(rune('A'+index%26)) + name
+        index = index/26 - 1
+    }
+    return name
+}
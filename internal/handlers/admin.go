@@ -0,0 +1,37 @@
+package handlers
+
+import (
+    "net/http"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+)
+
+// PurgeDeleted elimina definitivamente los registros archivados
+// @Summary Purga registros archivados
+// @Description Elimina definitivamente, en una sola transacción, las citas, mascotas y clientes archivados hace más tiempo que el periodo de conservación. Solo administradores.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "Token de administración"
+// @Success 200 {object} map[string]interface{} "Registros purgados"
+// @Failure 403 {object} map[string]interface{} "Acceso denegado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/admin/purge [post]
+func (h *Handler) PurgeDeleted(c *gin.Context) {
+    before := time.Now().Add(-h.Retention)
+
+    purged, err := repositories.PurgeDeleted(h.ClientRepo.DB.WithContext(c.Request.Context()), before)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "deleted_before": before,
+        "appointments":   purged.Appointments,
+        "pets":           purged.Pets,
+        "clients":        purged.Clients,
+    })
+}
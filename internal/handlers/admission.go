@@ -0,0 +1,451 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "slices"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "github.com/javice/vet-clinic-api/internal/tenant"
+    "gorm.io/gorm"
+)
+
+// Error messages
+const (
+    InvalidAdmissionIDFormat  = "Formato de ID de ingreso NO válido"
+    AdmissionNotFoundMessage  = "Ingreso NO encontrado"
+    AlreadyAdmittedMessage    = "La mascota ya está ingresada"
+    AlreadyDischargedMessage  = "La mascota ya tiene el alta"
+    DischargeBeforeAdmission  = "El alta NO puede ser anterior al ingreso"
+    AdmissionAppointmentError = "La cita NO existe o es de otra mascota"
+    KennelNotFoundMessage     = "Jaula NO encontrada"
+    KennelOccupiedMessage     = "La jaula está ocupada por otra mascota ingresada"
+    InvalidTreatmentIDFormat  = "Formato de ID de tarea NO válido"
+    TreatmentNotFoundMessage  = "Tarea NO encontrada"
+    TreatmentDoneMessage      = "La tarea ya está hecha"
+    InvalidTreatmentRepeat    = "Para repetir una tarea indique repeat_every en minutos"
+)
+
+var admissionIncludes = map[string]string{
+    "pet":         "Pet",
+    "pet.client":  "Pet.Client",
+    "appointment": "Appointment",
+}
+
+// TreatmentRequest programa una tarea de la hoja de tratamiento. Con
+// RepeatTimes se programa esa cantidad de veces, cada RepeatEvery minutos a
+// partir de ScheduledAt.
+type TreatmentRequest struct {
+    models.Treatment
+    RepeatEvery int `json:"repeat_every" binding:"omitempty,min=1"`
+    RepeatTimes int `json:"repeat_times" binding:"omitempty,min=1,max=100"`
+}
+
+// CompleteTreatmentRequest marca como hecha una tarea.
+type CompleteTreatmentRequest struct {
+    // Initials son las iniciales de quien la hizo
+    Initials string `json:"initials" binding:"required,max=10"`
+    // DoneAt es la hora a la que se hizo; por defecto, ahora
+    DoneAt *time.Time `json:"done_at"`
+    Notes  string     `json:"notes"`
+}
+
+// DischargeRequest da el alta a una mascota ingresada.
+type DischargeRequest struct {
+    // DischargedAt es la hora del alta; por defecto, ahora
+    DischargedAt *time.Time `json:"discharged_at"`
+    Notes        string     `json:"notes"`
+}
+
+// KennelRequest cambia de jaula a una mascota ingresada.
+type KennelRequest struct {
+    // KennelID es la nueva jaula; null la deja sin jaula
+    KennelID *uint `json:"kennel_id"`
+}
+
+// WardPatient es una mascota ingresada en el tablero de hospitalización.
+type WardPatient struct {
+    Admission models.Admission `json:"admission"`
+    // Overdue son las tareas pendientes cuya hora ya ha pasado
+    Overdue []models.Treatment `json:"overdue"`
+    // Upcoming son las tareas pendientes que aún no tocan
+    Upcoming []models.Treatment `json:"upcoming"`
+}
+
+// GetAdmissions lista los ingresos
+// @Summary Lista los ingresos
+// @Description Devuelve los ingresos, los más recientes primero, con su jaula. Con current=true solo las mascotas que siguen ingresadas.
+// @Tags Admissions
+// @Accept json
+// @Produce json
+// @Param current query bool false "Solo las mascotas ingresadas"
+// @Param pet_id query int false "ID de la mascota"
+// @Param include query string false "Asociaciones a incluir: pet, pet.client, appointment"
+// @Success 200 {array} models.Admission
+// @Failure 400 {object} map[string]interface{} "Parámetros inválidos"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/admissions [get]
+func (h *Handler) GetAdmissions(c *gin.Context) {
+    preloads, ok := includes(c, admissionIncludes)
+    if !ok {
+        return
+    }
+
+    current, err := strconv.ParseBool(c.DefaultQuery("current", "false"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Valor de current NO válido"})
+        return
+    }
+    var petID uint64
+    if value := c.Query("pet_id"); value != "" {
+        if petID, err = strconv.ParseUint(value, 10, 32); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Formato de ID mascota NO válido"})
+            return
+        }
+    }
+
+    admissions, err := h.admissions(c).GetAll(current, uint(petID), preloads...)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusOK, admissions)
+}
+
+// GetAdmission obtiene un ingreso
+// @Summary Obtiene un ingreso
+// @Description Devuelve el ingreso con su jaula y su hoja de tratamiento por orden de hora
+// @Tags Admissions
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del ingreso"
+// @Param include query string false "Asociaciones a incluir: pet, pet.client, appointment"
+// @Success 200 {object} models.Admission
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Ingreso no encontrado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/admissions/{id} [get]
+func (h *Handler) GetAdmission(c *gin.Context) {
+    preloads, ok := includes(c, admissionIncludes)
+    if !ok {
+        return
+    }
+
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidAdmissionIDFormat})
+        return
+    }
+
+    admission, err := h.admissions(c).GetByID(uint(id), preloads...)
+    if err != nil {
+        admissionError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, admission)
+}
+
+// CreateAdmission ingresa una mascota
+// @Summary Ingresa una mascota
+// @Description Ingresa una mascota en la hospitalización con el motivo, la cita que lo originó (appointment_id) y la jaula que ocupa (kennel_id, un recurso de tipo kennel). La hora de ingreso (admitted_at) es por defecto la actual.
+// @Tags Admissions
+// @Accept json
+// @Produce json
+// @Param admission body models.Admission true "Datos del ingreso"
+// @Success 201 {object} models.Admission
+// @Failure 400 {object} map[string]interface{} "Datos inválidos o cita de otra mascota"
+// @Failure 404 {object} map[string]interface{} "Mascota o jaula no encontrada"
+// @Failure 409 {object} map[string]interface{} "La mascota ya está ingresada o la jaula está ocupada"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/admissions [post]
+func (h *Handler) CreateAdmission(c *gin.Context) {
+    var admission models.Admission
+    if err := c.ShouldBindJSON(&admission); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    admission.ID = 0
+
+    exists, err := h.pets(c).Exists(admission.PetID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+    if !exists {
+        c.JSON(http.StatusNotFound, gin.H{"error": PetNotFound})
+        return
+    }
+
+    if err := h.admissions(c).Admit(&admission); err != nil {
+        if errors.Is(err, tenant.ErrNotInClinic) {
+            c.JSON(http.StatusNotFound, gin.H{"error": PetNotFound})
+            return
+        }
+        admissionError(c, err)
+        return
+    }
+
+    created, err := h.admissions(c).GetByID(admission.ID)
+    if err != nil {
+        admissionError(c, err)
+        return
+    }
+    c.JSON(http.StatusCreated, created)
+}
+
+// MoveAdmissionKennel cambia de jaula a una mascota ingresada
+// @Summary Cambia de jaula
+// @Tags Admissions
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del ingreso"
+// @Param kennel body KennelRequest true "Nueva jaula"
+// @Success 200 {object} models.Admission
+// @Failure 400 {object} map[string]interface{} "Datos inválidos"
+// @Failure 404 {object} map[string]interface{} "Ingreso o jaula no encontrados"
+// @Failure 409 {object} map[string]interface{} "La mascota ya tiene el alta o la jaula está ocupada"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/admissions/{id}/kennel [put]
+func (h *Handler) MoveAdmissionKennel(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidAdmissionIDFormat})
+        return
+    }
+
+    var req KennelRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    if err := h.admissions(c).MoveKennel(uint(id), req.KennelID); err != nil {
+        admissionError(c, err)
+        return
+    }
+
+    h.GetAdmission(c)
+}
+
+// DischargeAdmission da el alta a una mascota ingresada
+// @Summary Da el alta
+// @Description Da el alta a la mascota y deja libre su jaula. La hora del alta es por defecto la actual.
+// @Tags Admissions
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del ingreso"
+// @Param discharge body DischargeRequest false "Hora y notas del alta"
+// @Success 200 {object} models.Admission
+// @Failure 400 {object} map[string]interface{} "Datos inválidos o alta anterior al ingreso"
+// @Failure 404 {object} map[string]interface{} "Ingreso no encontrado"
+// @Failure 409 {object} map[string]interface{} "La mascota ya tiene el alta"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/admissions/{id}/discharge [post]
+func (h *Handler) DischargeAdmission(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidAdmissionIDFormat})
+        return
+    }
+
+    var req DischargeRequest
+    if c.Request.ContentLength != 0 {
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+    }
+    at := time.Now()
+    if req.DischargedAt != nil {
+        at = *req.DischargedAt
+    }
+
+    if err := h.admissions(c).Discharge(uint(id), at, req.Notes); err != nil {
+        admissionError(c, err)
+        return
+    }
+
+    h.GetAdmission(c)
+}
+
+// CreateTreatments programa tareas en la hoja de tratamiento
+// @Summary Programa tareas de tratamiento
+// @Description Añade a la hoja de tratamiento una medicación (kind medication, con su dosis) o una observación (kind observation) a la hora scheduled_at. Con repeat_times se programa esa cantidad de veces, cada repeat_every minutos.
+// @Tags Admissions
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del ingreso"
+// @Param treatment body TreatmentRequest true "Tarea"
+// @Success 201 {array} models.Treatment
+// @Failure 400 {object} map[string]interface{} "Datos inválidos"
+// @Failure 404 {object} map[string]interface{} "Ingreso no encontrado"
+// @Failure 409 {object} map[string]interface{} "La mascota ya tiene el alta"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/admissions/{id}/treatments [post]
+func (h *Handler) CreateTreatments(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidAdmissionIDFormat})
+        return
+    }
+
+    var req TreatmentRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    times := max(req.RepeatTimes, 1)
+    if times > 1 && req.RepeatEvery == 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidTreatmentRepeat})
+        return
+    }
+
+    treatments := make([]models.Treatment, times)
+    for i := range treatments {
+        treatments[i] = req.Treatment
+        treatments[i].ScheduledAt = req.ScheduledAt.Add(time.Duration(i*req.RepeatEvery) * time.Minute)
+    }
+
+    if err := h.admissions(c).AddTreatments(uint(id), treatments); err != nil {
+        admissionError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusCreated, treatments)
+}
+
+// CompleteTreatment marca como hecha una tarea de la hoja de tratamiento
+// @Summary Marca una tarea como hecha
+// @Description Registra la hora (por defecto la actual) y las iniciales de quien hizo la tarea
+// @Tags Admissions
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del ingreso"
+// @Param treatment_id path int true "ID de la tarea"
+// @Param done body CompleteTreatmentRequest true "Iniciales, hora y notas"
+// @Success 200 {object} models.Treatment
+// @Failure 400 {object} map[string]interface{} "Datos inválidos"
+// @Failure 404 {object} map[string]interface{} "Tarea no encontrada"
+// @Failure 409 {object} map[string]interface{} "La tarea ya está hecha o la mascota ya tiene el alta"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/admissions/{id}/treatments/{treatment_id}/done [post]
+func (h *Handler) CompleteTreatment(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidAdmissionIDFormat})
+        return
+    }
+    treatmentID, err := strconv.ParseUint(c.Param("treatment_id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidTreatmentIDFormat})
+        return
+    }
+
+    var req CompleteTreatmentRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    at := time.Now()
+    if req.DoneAt != nil {
+        at = *req.DoneAt
+    }
+
+    treatment, err := h.admissions(c).CompleteTreatment(uint(id), uint(treatmentID), at, req.Initials, req.Notes)
+    if err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            c.JSON(http.StatusNotFound, gin.H{"error": TreatmentNotFoundMessage})
+            return
+        }
+        admissionError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, treatment)
+}
+
+// GetWard devuelve el tablero de hospitalización
+// @Summary Tablero de hospitalización
+// @Description Devuelve las mascotas ingresadas con su jaula y sus tareas pendientes, separando las atrasadas (overdue) de las próximas (upcoming). Primero aparecen las que tienen tareas atrasadas, empezando por la más atrasada.
+// @Tags Admissions
+// @Accept json
+// @Produce json
+// @Success 200 {array} WardPatient
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/ward [get]
+func (h *Handler) GetWard(c *gin.Context) {
+    admissions, err := h.admissions(c).Ward()
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    now := time.Now()
+    var overdue, onTime []WardPatient
+    for _, admission := range admissions {
+        patient := WardPatient{Overdue: []models.Treatment{}, Upcoming: []models.Treatment{}}
+        for _, t := range admission.Treatments {
+            if t.Overdue(now) {
+                patient.Overdue = append(patient.Overdue, t)
+            } else {
+                patient.Upcoming = append(patient.Upcoming, t)
+            }
+        }
+        admission.Treatments = nil
+        patient.Admission = admission
+
+        if len(patient.Overdue) > 0 {
+            overdue = append(overdue, patient)
+        } else {
+            onTime = append(onTime, patient)
+        }
+    }
+
+    // Las tareas están ordenadas por hora: la primera atrasada es la que
+    // más lo está
+    slices.SortStableFunc(overdue, func(a, b WardPatient) int {
+        return a.Overdue[0].ScheduledAt.Compare(b.Overdue[0].ScheduledAt)
+    })
+
+    c.JSON(http.StatusOK, append(append([]WardPatient{}, overdue...), onTime...))
+}
+
+func admissionError(c *gin.Context, err error) {
+    statusCode := http.StatusInternalServerError
+    errorMsg := InternalServerErrMsg
+
+    switch {
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        statusCode = http.StatusNotFound
+        errorMsg = AdmissionNotFoundMessage
+    case errors.Is(err, repositories.ErrAlreadyAdmitted):
+        statusCode = http.StatusConflict
+        errorMsg = AlreadyAdmittedMessage
+    case errors.Is(err, repositories.ErrAlreadyDischarged):
+        statusCode = http.StatusConflict
+        errorMsg = AlreadyDischargedMessage
+    case errors.Is(err, repositories.ErrDischargeBeforeAdmission):
+        statusCode = http.StatusBadRequest
+        errorMsg = DischargeBeforeAdmission
+    case errors.Is(err, repositories.ErrAdmissionAppointment):
+        statusCode = http.StatusBadRequest
+        errorMsg = AdmissionAppointmentError
+    case errors.Is(err, repositories.ErrKennelNotFound):
+        statusCode = http.StatusNotFound
+        errorMsg = KennelNotFoundMessage
+    case errors.Is(err, repositories.ErrKennelOccupied):
+        statusCode = http.StatusConflict
+        errorMsg = KennelOccupiedMessage
+    case errors.Is(err, repositories.ErrTreatmentDone):
+        statusCode = http.StatusConflict
+        errorMsg = TreatmentDoneMessage
+    }
+
+    c.JSON(statusCode, gin.H{"error": errorMsg})
+}
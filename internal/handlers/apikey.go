@@ -0,0 +1,216 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/auth"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "gorm.io/gorm"
+)
+
+// Error messages
+const (
+    InvalidAPIKeyIDFormat = "Formato de ID de clave de API NO válido"
+    APIKeyNotFoundMessage = "Clave de API NO encontrada"
+    APIKeyRevokedMessage  = "La clave de API está anulada"
+    APIKeyExpiredMessage  = "La fecha de caducidad debe ser futura"
+    APIKeyClinicMessage   = "La clínica de la clave no existe"
+)
+
+// APIKeyRequest es el cuerpo del alta de una clave de API.
+type APIKeyRequest struct {
+    Name      string     `json:"name" binding:"required"`
+    Scopes    []string   `json:"scopes" binding:"required,min=1"`
+    ExpiresAt *time.Time `json:"expires_at"`
+    // ClinicID limita la clave a una clínica
+    ClinicID *uint `json:"clinic_id"`
+}
+
+// APIKeyResponse incluye la clave en claro. Solo se devuelve al crearla o
+// rotarla.
+type APIKeyResponse struct {
+    models.APIKey
+    Key string `json:"key"`
+}
+
+// CreateAPIKey crea una clave de API
+// @Summary Crea una clave de API
+// @Description Crea una clave para una integración con los permisos indicados. La clave solo se muestra en esta respuesta; se guarda únicamente su hash. Solo administradores.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "Token de administración"
+// @Param apiKey body APIKeyRequest true "Nombre, permisos, caducidad y clínica"
+// @Success 201 {object} APIKeyResponse
+// @Failure 400 {object} map[string]interface{} "Datos inválidos"
+// @Failure 403 {object} map[string]interface{} "Acceso denegado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/admin/api-keys [post]
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+    var req APIKeyRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    var unknown []string
+    for _, scope := range req.Scopes {
+        if !auth.ValidScope(scope) {
+            unknown = append(unknown, scope)
+        }
+    }
+    if len(unknown) > 0 {
+        c.JSON(http.StatusBadRequest, gin.H{
+            "error":  "Permisos desconocidos: " + strings.Join(unknown, ", "),
+            "scopes": auth.Scopes,
+        })
+        return
+    }
+    if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": APIKeyExpiredMessage})
+        return
+    }
+
+    if req.ClinicID != nil {
+        if _, err := h.clinics(c).GetByID(*req.ClinicID); err != nil {
+            if errors.Is(err, gorm.ErrRecordNotFound) {
+                c.JSON(http.StatusBadRequest, gin.H{"error": APIKeyClinicMessage})
+            } else {
+                c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+            }
+            return
+        }
+    }
+
+    plain, prefix, hash, err := auth.NewAPIKey()
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    key := models.APIKey{
+        Name:      strings.TrimSpace(req.Name),
+        Prefix:    prefix,
+        Hash:      hash,
+        Scopes:    req.Scopes,
+        ExpiresAt: req.ExpiresAt,
+        ClinicID:  req.ClinicID,
+    }
+    if err := h.apiKeys(c).Create(&key); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusCreated, APIKeyResponse{APIKey: key, Key: plain})
+}
+
+// GetAPIKeys lista las claves de API
+// @Summary Lista las claves de API
+// @Description Devuelve todas las claves de API con sus permisos, caducidad y último uso, sin la clave. Solo administradores.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "Token de administración"
+// @Success 200 {array} models.APIKey
+// @Failure 403 {object} map[string]interface{} "Acceso denegado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/admin/api-keys [get]
+func (h *Handler) GetAPIKeys(c *gin.Context) {
+    keys, err := h.apiKeys(c).GetAll()
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusOK, keys)
+}
+
+// RevokeAPIKey anula una clave de API
+// @Summary Anula una clave de API
+// @Description La clave deja de funcionar en el acto. Solo administradores.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "Token de administración"
+// @Param id path int true "ID de la clave"
+// @Success 200 {object} models.APIKey
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 403 {object} map[string]interface{} "Acceso denegado"
+// @Failure 404 {object} map[string]interface{} "Clave no encontrada"
+// @Failure 409 {object} map[string]interface{} "La clave ya está anulada"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/admin/api-keys/{id} [delete]
+func (h *Handler) RevokeAPIKey(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidAPIKeyIDFormat})
+        return
+    }
+
+    key, err := h.apiKeys(c).Revoke(uint(id), time.Now())
+    if err != nil {
+        apiKeyError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, key)
+}
+
+// RotateAPIKey genera una nueva clave para la misma integración
+// @Summary Rota una clave de API
+// @Description Sustituye el secreto conservando el ID, nombre, permisos y caducidad. La clave anterior deja de funcionar en el acto y la nueva solo se muestra en esta respuesta. Solo administradores.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "Token de administración"
+// @Param id path int true "ID de la clave"
+// @Success 200 {object} APIKeyResponse
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 403 {object} map[string]interface{} "Acceso denegado"
+// @Failure 404 {object} map[string]interface{} "Clave no encontrada"
+// @Failure 409 {object} map[string]interface{} "La clave está anulada"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/admin/api-keys/{id}/rotate [post]
+func (h *Handler) RotateAPIKey(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidAPIKeyIDFormat})
+        return
+    }
+
+    plain, prefix, hash, err := auth.NewAPIKey()
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    key, err := h.apiKeys(c).Rotate(uint(id), prefix, hash)
+    if err != nil {
+        apiKeyError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, APIKeyResponse{APIKey: key, Key: plain})
+}
+
+func apiKeyError(c *gin.Context, err error) {
+    statusCode := http.StatusInternalServerError
+    errorMsg := InternalServerErrMsg
+
+    switch {
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        statusCode = http.StatusNotFound
+        errorMsg = APIKeyNotFoundMessage
+    case errors.Is(err, repositories.ErrAPIKeyRevoked):
+        statusCode = http.StatusConflict
+        errorMsg = APIKeyRevokedMessage
+    }
+
+    c.JSON(statusCode, gin.H{"error": errorMsg})
+}
@@ -1,48 +1,57 @@
 package handlers
 
 import (
+	"errors"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/javice/vet-clinic-api/internal/models"
+	"github.com/javice/vet-clinic-api/internal/repositories"
+	"github.com/javice/vet-clinic-api/internal/tenant"
+	"github.com/javice/vet-clinic-api/internal/timezone"
+	"gorm.io/gorm"
 )
 
-// GetAppointments obtiene todas las citas o filtra por mascota.
+// GetAppointments obtiene todas las citas o filtra por mascota y día.
 // @Summary Obtiene citas
-// @Description Obtiene todas las citas o filtra por mascota si se especifica el parámetro `pet_id`.
+// @Description Obtiene todas las citas o filtra por mascota si se especifica el parámetro `pet_id`. Con `date` (AAAA-MM-DD) devuelve las citas de ese día en la zona horaria de la clínica, ordenadas por fecha.
 // @Tags Appointments
 // @Accept json
 // @Produce json
 // @Param pet_id query int false "ID de la mascota"
+// @Param date query string false "Día (AAAA-MM-DD) en la zona horaria de la clínica"
+// @Param include query string false "Asociaciones a incluir: pet, pet.client, type"
 // @Success 200 {array} models.Appointment
-// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 400 {object} map[string]interface{} "Formato de ID o de fecha inválido"
 // @Failure 404 {object} map[string]interface{} "Mascota no encontrada"
 // @Failure 500 {object} map[string]interface{} "Error interno del servidor"
 // @Router /api/v1/appointments [get]
 func (h *Handler) GetAppointments(c *gin.Context) {
-    // Si se especifica pet_id, filtrar por mascota
-    petID := c.Query("pet_id")
-    if petID != "" {
-        id, err := strconv.ParseUint(petID, 10, 32)
-        if err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "Formato de ID mascota NO válido"})
-            return
-        }
+    preloads, ok := includes(c, appointmentIncludes)
+    if !ok {
+        return
+    }
+
+    filter, ok := appointmentFilter(c)
+    if !ok {
+        return
+    }
 
-		appointments, err := h.AppointmentRepo.GetByPetID(uint(id))
+    if filter.PetID != 0 || !filter.From.IsZero() {
+        appointments, err := h.appointments(c).Find(filter, preloads...)
         if err != nil {
             c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
             return
         }
         c.JSON(http.StatusOK, appointments)
         return
-
-        
     }
 
-    // Si no hay mascota específica, devolver todas las citas
-    appointments, err := h.AppointmentRepo.GetAll()
+    // Si no hay filtros, devolver todas las citas
+    appointments, err := h.appointments(c).GetAll(preloads...)
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener las citas"})
         return
@@ -51,6 +60,33 @@ func (h *Handler) GetAppointments(c *gin.Context) {
 }
 
 
+// appointmentFilter lee los filtros del listado de citas, que también admite
+// su exportación. Si alguno no es válido responde 400 y devuelve false.
+func appointmentFilter(c *gin.Context) (repositories.AppointmentFilter, bool) {
+    // Si se especifica el día, filtrar por su inicio y su final en la zona
+    // horaria de la clínica
+    var filter repositories.AppointmentFilter
+    if date := c.Query("date"); date != "" {
+        from, to, err := timezone.Day(date, timezone.Location(c.Request.Context()))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": InvalidDayViewDate})
+            return filter, false
+        }
+        filter.From, filter.To = from, to
+    }
+
+    // Si se especifica pet_id, filtrar por mascota
+    if petID := c.Query("pet_id"); petID != "" {
+        id, err := strconv.ParseUint(petID, 10, 32)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Formato de ID mascota NO válido"})
+            return filter, false
+        }
+        filter.PetID = uint(id)
+    }
+    return filter, true
+}
+
 // GetAppointment obtiene Una cita por ID.
 // @Summary Obtiene cita
 // @Description Obtiene una cita
@@ -58,7 +94,10 @@ func (h *Handler) GetAppointments(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "ID de la cita"
+// @Param include query string false "Asociaciones a incluir: pet, pet.client, type"
+// @Param If-None-Match header string false "ETag conocido"
 // @Success 200 {object} models.Appointment
+// @Success 304 "No modificado"
 // @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
 // @Failure 404 {object} map[string]interface{} "Cita no encontrada"
 // @Failure 500 {object} map[string]interface{} "Error interno del servidor"
@@ -70,27 +109,36 @@ func (h *Handler) GetAppointment(c *gin.Context) {
         return
     }
 
-    appointment, err := h.AppointmentRepo.GetByID(uint(id))
+    preloads, ok := includes(c, appointmentIncludes)
+    if !ok {
+        return
+    }
+
+    appointment, err := h.appointments(c).GetByID(uint(id), preloads...)
     if err != nil {
         c.JSON(http.StatusNotFound, gin.H{"error": "Cita no encontrada"})
         return
     }
 
+    if notModified(c, representationETag(appointment.Version, preloads, appointment)) {
+        return
+    }
+
     c.JSON(http.StatusOK, appointment)
 }
 
 
 // CreateAppointment da de alta una nueva cita.
 // @Summary Crea una cita
-// @Description Crea una nueva cita
+// @Description Crea una nueva cita. Con un tipo de cita (type_id) se toman de él la duración, el motivo y los márgenes que no se indiquen, y se añaden sus recursos. Si indica veterinario (vet_id) o recursos (resource_ids), deben estar libres durante toda su duración, márgenes incluidos
 // @Tags Appointments
 // @Accept json
 // @Produce json
 // @Param appointment body models.Appointment true "Datos de la cita"
 // @Success 201 {object} models.Appointment
-// @Failure 400 {object} map[string]interface{} "Error en los datos enviados o fecha inválida"
-// @Failure 404 {object} map[string]interface{} "Mascota no encontrada"
-// @Failure 409 {object} map[string]interface{} "Conflicto con otra cita"
+// @Failure 400 {object} map[string]interface{} "Error en los datos enviados, fecha inválida o veterinario sin la especialidad del tipo de cita"
+// @Failure 404 {object} map[string]interface{} "Mascota, tipo de cita, veterinario o recurso no encontrado"
+// @Failure 409 {object} map[string]interface{} "El veterinario o algún recurso ya está ocupado a esa hora"
 // @Failure 500 {object} map[string]interface{} "Error interno del servidor"
 // @Router /api/v1/appointments [post]
 func (h *Handler) CreateAppointment(c *gin.Context) {
@@ -106,17 +154,25 @@ func (h *Handler) CreateAppointment(c *gin.Context) {
         return
     }
 
-    if appointment.Reason == "" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Motivo de la cita es requerido"})
+    if msg := missingAppointmentData(appointment); msg != "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": msg})
         return
     }
 
     // Usar el servicio para crear la cita, que incluye todas las validaciones
-    if err := h.AppointmentRepo.Create(&appointment); err != nil {
+    if err := h.appointments(c).Create(&appointment); err != nil {
+        if scheduleError(c, err) {
+            return
+        }
+        if errors.Is(err, tenant.ErrNotInClinic) {
+            c.JSON(http.StatusNotFound, gin.H{"error": PetNotFound})
+            return
+        }
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al crear la cita"})
         return // Este return debe estar dentro del bloque if
     }
 
+    setETag(c, appointment.Version)
     c.JSON(http.StatusCreated, appointment)
 }
 
@@ -128,11 +184,14 @@ func (h *Handler) CreateAppointment(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "ID de la cita"
+// @Param If-Match header string true "ETag actual de la cita"
 // @Param appointment body models.Appointment true "Datos de la cita"
 // @Success 200 {object} models.Appointment
-// @Failure 400 {object} map[string]interface{} "Error en los datos enviados o fecha inválida"
-// @Failure 404 {object} map[string]interface{} "Cita o mascota no encontrada"
-// @Failure 409 {object} map[string]interface{} "Conflicto con otra cita"
+// @Failure 400 {object} map[string]interface{} "Error en los datos enviados, fecha inválida o veterinario sin la especialidad del tipo de cita"
+// @Failure 404 {object} map[string]interface{} "Cita, mascota, tipo de cita, veterinario o recurso no encontrado"
+// @Failure 409 {object} map[string]interface{} "El veterinario o algún recurso ya está ocupado a esa hora"
+// @Failure 412 {object} map[string]interface{} "La cita ha sido modificada"
+// @Failure 428 {object} map[string]interface{} "Falta If-Match"
 // @Failure 500 {object} map[string]interface{} "Error interno del servidor"
 // @Router /api/v1/appointments/{id} [put]
 func (h *Handler) UpdateAppointment(c *gin.Context) {
@@ -157,30 +216,143 @@ func (h *Handler) UpdateAppointment(c *gin.Context) {
         return
     }
 
-    if appointment.Reason == "" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Motivo de la cita es requerido"})
+    if msg := missingAppointmentData(appointment); msg != "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+        return
+    }
+
+    current, err := h.appointments(c).GetByID(uint(id))
+    if err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            c.JSON(http.StatusNotFound, gin.H{"error": "Cita no encontrada"})
+        } else {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar la cita"})
+        }
+        return
+    }
+
+    if !checkIfMatch(c, current.Version) {
         return
     }
 
     // Usar el repo para actualizar la cita
-    if err := h.AppointmentRepo.Update(&appointment); err != nil { 
+    if err := h.appointments(c).Update(&appointment, current.Version); err != nil {
+        if scheduleError(c, err) {
+            return
+        }
+        if errors.Is(err, repositories.ErrVersionConflict) {
+            c.JSON(http.StatusPreconditionFailed, gin.H{"error": PreconditionFailedMsg})
+            return
+        }
+        if errors.Is(err, tenant.ErrNotInClinic) {
+            c.JSON(http.StatusNotFound, gin.H{"error": PetNotFound})
+            return
+        }
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar la cita"})
         return
     }
 
-    c.JSON(http.StatusOK, appointment)
+    updated, err := h.appointments(c).GetByID(uint(id))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar la cita"})
+        return
+    }
+
+    setETag(c, updated.Version)
+    c.JSON(http.StatusOK, updated)
 }
 
-// DeleteAppointment elimina una cita existente.
+// PatchAppointment modifica parcialmente una cita.
+// @Summary Modifica parcialmente cita
+// @Description Aplica un JSON Merge Patch (application/merge-patch+json) o un JSON Patch (application/json-patch+json) sobre la cita y valida el resultado
+// @Tags Appointments
+// @Accept json
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path int true "ID de la cita"
+// @Param If-Match header string true "ETag actual de la cita"
+// @Param patch body object true "Parche"
+// @Success 200 {object} models.Appointment
+// @Failure 400 {object} map[string]interface{} "Parche inválido"
+// @Failure 404 {object} map[string]interface{} "Cita no encontrada"
+// @Failure 409 {object} map[string]interface{} "No se cumple una operación test o el veterinario o algún recurso ya está ocupado"
+// @Failure 412 {object} map[string]interface{} "La cita ha sido modificada"
+// @Failure 415 {object} map[string]interface{} "Content-Type no soportado"
+// @Failure 422 {object} map[string]interface{} "La cita resultante no es válida"
+// @Failure 428 {object} map[string]interface{} "Falta If-Match"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/appointments/{id} [patch]
+func (h *Handler) PatchAppointment(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Formato de ID inválido"})
+        return
+    }
+
+    current, err := h.appointments(c).GetByID(uint(id))
+    if err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            c.JSON(http.StatusNotFound, gin.H{"error": "Cita no encontrada"})
+        } else {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar la cita"})
+        }
+        return
+    }
+
+    if !checkIfMatch(c, current.Version) {
+        return
+    }
+
+    var appointment models.Appointment
+    if !applyPatch(c, current, &appointment) {
+        return
+    }
+    appointment.ID = uint(id)
+    if msg := missingAppointmentData(appointment); msg != "" {
+        c.JSON(http.StatusUnprocessableEntity, gin.H{"error": msg})
+        return
+    }
+
+    if err := h.appointments(c).Update(&appointment, current.Version); err != nil {
+        if scheduleError(c, err) {
+            return
+        }
+        if errors.Is(err, repositories.ErrVersionConflict) {
+            c.JSON(http.StatusPreconditionFailed, gin.H{"error": PreconditionFailedMsg})
+            return
+        }
+        if errors.Is(err, tenant.ErrNotInClinic) {
+            c.JSON(http.StatusNotFound, gin.H{"error": PetNotFound})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar la cita"})
+        return
+    }
+
+    updated, err := h.appointments(c).GetByID(uint(id))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar la cita"})
+        return
+    }
+
+    setETag(c, updated.Version)
+    c.JSON(http.StatusOK, updated)
+}
+
+// DeleteAppointment archiva una cita existente.
 // @Summary Elimina cita
-// @Description Elimina una cita existente
+// @Description Archiva (borrado lógico) una cita existente
 // @Tags Appointments
 // @Accept json
 // @Produce json
 // @Param id path int true "ID de la cita"
+// @Param If-Match header string true "ETag actual de la cita"
 // @Success 200 {object} map[string]interface{} "Cita eliminada exitosamente"
 // @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
 // @Failure 404 {object} map[string]interface{} "Cita no encontrada"
+// @Failure 412 {object} map[string]interface{} "La cita ha sido modificada"
+// @Failure 428 {object} map[string]interface{} "Falta If-Match"
 // @Failure 500 {object} map[string]interface{} "Error interno del servidor"
 // @Router /api/v1/appointments/{id} [delete]
 func (h *Handler) DeleteAppointment(c *gin.Context) {
@@ -190,7 +362,25 @@ func (h *Handler) DeleteAppointment(c *gin.Context) {
         return
     }
 
-    if err := h.AppointmentRepo.Delete(uint(id)); err != nil {
+    current, err := h.appointments(c).GetByID(uint(id))
+    if err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            c.JSON(http.StatusNotFound, gin.H{"error": "Cita no encontrada"})
+        } else {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al eliminar la cita"})
+        }
+        return
+    }
+
+    if !checkIfMatch(c, current.Version) {
+        return
+    }
+
+    if err := h.appointments(c).Delete(uint(id), current.Version); err != nil {
+        if errors.Is(err, repositories.ErrVersionConflict) {
+            c.JSON(http.StatusPreconditionFailed, gin.H{"error": PreconditionFailedMsg})
+            return
+        }
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al eliminar la cita"})
         return
     }
@@ -198,6 +388,127 @@ func (h *Handler) DeleteAppointment(c *gin.Context) {
     c.JSON(http.StatusOK, gin.H{"message": "Cita eliminada exitosamente"})
 }
 
+// CancelAppointment cancela una cita.
+// @Summary Cancela cita
+// @Description Cancela una cita programada. Su hueco queda libre y se ofrece a la lista de espera.
+// @Tags Appointments
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la cita"
+// @Success 200 {object} models.Appointment
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Cita no encontrada"
+// @Failure 409 {object} map[string]interface{} "La cita ya está completada, cancelada o marcada como ausencia"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/appointments/{id}/cancel [post]
+func (h *Handler) CancelAppointment(c *gin.Context) {
+    h.setAppointmentStatus(c, models.AppointmentCancelled)
+}
+
+// MarkAppointmentNoShow marca una cita como ausencia.
+// @Summary Marca ausencia
+// @Description Marca que la mascota no se presentó a una cita ya empezada. El tiempo que le queda se ofrece a la lista de espera.
+// @Tags Appointments
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la cita"
+// @Success 200 {object} models.Appointment
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Cita no encontrada"
+// @Failure 409 {object} map[string]interface{} "La cita no ha empezado o ya no está programada"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/appointments/{id}/no-show [post]
+func (h *Handler) MarkAppointmentNoShow(c *gin.Context) {
+    h.setAppointmentStatus(c, models.AppointmentNoShow)
+}
+
+// setAppointmentStatus cancela la cita o la marca como ausencia y ofrece su
+// hueco a la lista de espera.
+func (h *Handler) setAppointmentStatus(c *gin.Context, status string) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Formato de ID inválido"})
+        return
+    }
+
+    if status == models.AppointmentNoShow {
+        current, err := h.appointments(c).GetByID(uint(id))
+        if err != nil {
+            if errors.Is(err, gorm.ErrRecordNotFound) {
+                c.JSON(http.StatusNotFound, gin.H{"error": "Cita no encontrada"})
+            } else {
+                c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar la cita"})
+            }
+            return
+        }
+        if current.Date.After(time.Now()) {
+            c.JSON(http.StatusConflict, gin.H{"error": "La cita todavía no ha empezado"})
+            return
+        }
+    }
+
+    appointment, err := h.appointments(c).SetStatus(uint(id), status)
+    if err != nil {
+        switch {
+        case errors.Is(err, gorm.ErrRecordNotFound):
+            c.JSON(http.StatusNotFound, gin.H{"error": "Cita no encontrada"})
+        case errors.Is(err, repositories.ErrNotScheduled):
+            c.JSON(http.StatusConflict, gin.H{"error": "La cita ya está completada, cancelada o marcada como ausencia"})
+        default:
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar la cita"})
+        }
+        return
+    }
+
+    // La cita ya está cancelada aunque no se pueda ofrecer el hueco
+    if _, err := h.Waitlist.SlotFreed(c.Request.Context(), appointment); err != nil {
+        slog.ErrorContext(c.Request.Context(), "Error ofreciendo el hueco a la lista de espera", "appointment_id", appointment.ID, "error", err)
+    }
+
+    setETag(c, appointment.Version)
+    c.JSON(http.StatusOK, appointment)
+}
+
+// RestoreAppointment restaura una cita archivada.
+// @Summary Restaura cita
+// @Description Restaura una cita archivada. La mascota debe estar activa.
+// @Tags Appointments
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la cita"
+// @Success 200 {object} models.Appointment
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Cita no encontrada"
+// @Failure 409 {object} map[string]interface{} "La cita no está eliminada, su mascota sí o su horario ya está ocupado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/appointments/{id}/restore [post]
+func (h *Handler) RestoreAppointment(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Formato de ID inválido"})
+        return
+    }
+
+    appointment, err := h.appointments(c).Restore(uint(id))
+    if err != nil {
+        switch {
+        case errors.Is(err, gorm.ErrRecordNotFound):
+            c.JSON(http.StatusNotFound, gin.H{"error": "Cita no encontrada"})
+        case errors.Is(err, repositories.ErrNotDeleted):
+            c.JSON(http.StatusConflict, gin.H{"error": NotDeletedMessage})
+        case errors.Is(err, repositories.ErrParentDeleted):
+            c.JSON(http.StatusConflict, gin.H{"error": "La mascota de la cita está eliminada"})
+        case scheduleError(c, err):
+        default:
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al restaurar la cita"})
+        }
+        return
+    }
+
+    setETag(c, appointment.Version)
+    c.JSON(http.StatusOK, appointment)
+}
+
 // GetAppointmentsByPet obtiene todas las citas de una mascota específica
 // @Summary Obtiene citas por mascota
 // @Description Obtiene todas las citas de una mascota específica
@@ -205,6 +516,7 @@ func (h *Handler) DeleteAppointment(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "ID de la mascota"
+// @Param include query string false "Asociaciones a incluir: pet, pet.client, type"
 // @Success 200 {array} models.Appointment
 // @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
 // @Failure 404 {object} map[string]interface{} "Mascota no encontrada"
@@ -216,8 +528,23 @@ func (h *Handler) GetAppointmentsByPet(c *gin.Context) {
         c.JSON(http.StatusBadRequest, gin.H{"error": "Formato de ID inválido"})
         return
     }
+
+    preloads, ok := includes(c, appointmentIncludes)
+    if !ok {
+        return
+    }
+
+    exists, err := h.pets(c).Exists(uint(id))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener las citas"})
+        return
+    }
+    if !exists {
+        c.JSON(http.StatusNotFound, gin.H{"error": PetNotFound})
+        return
+    }
     
-    appointments, err := h.AppointmentRepo.GetByPetID(uint(id))
+    appointments, err := h.appointments(c).GetByPetID(uint(id), preloads...)
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener las citas"})
         return
@@ -225,3 +552,41 @@ func (h *Handler) GetAppointmentsByPet(c *gin.Context) {
     
     c.JSON(http.StatusOK, appointments)
 }
+
+// scheduleError responde a los errores de agenda de una cita: tipo de cita,
+// veterinario o recurso inexistente (404), veterinario sin la especialidad
+// que requiere el tipo (400) y solapamiento con otras citas (409, con las
+// citas que se solapan). Devuelve false si err no es uno de ellos.
+func scheduleError(c *gin.Context, err error) bool {
+    var conflict *repositories.ScheduleConflictError
+    switch {
+    case errors.As(err, &conflict):
+        c.JSON(http.StatusConflict, gin.H{"error": ScheduleConflictMessage, "conflicts": conflict.Conflicts})
+    case errors.Is(err, repositories.ErrVetNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": VetNotFoundMessage})
+    case errors.Is(err, repositories.ErrResourceNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": ResourceNotFoundMessage})
+    case errors.Is(err, repositories.ErrAppointmentTypeNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": AppointmentTypeNotFoundMessage})
+    case errors.Is(err, repositories.ErrVetSpecialty):
+        c.JSON(http.StatusBadRequest, gin.H{"error": VetSpecialtyMessage})
+    default:
+        return false
+    }
+    return true
+}
+
+// missingAppointmentData devuelve el error de una cita sin motivo o sin
+// duración que no tiene un tipo del que tomarlos, o "" si no le falta nada.
+func missingAppointmentData(appointment models.Appointment) string {
+    if appointment.TypeID != nil {
+        return ""
+    }
+    if appointment.Reason == "" {
+        return "Motivo de la cita es requerido"
+    }
+    if appointment.Duration == 0 {
+        return "Duración de la cita es requerida"
+    }
+    return ""
+}
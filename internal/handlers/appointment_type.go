@@ -0,0 +1,174 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "gorm.io/gorm"
+)
+
+// Error messages
+const (
+    InvalidAppointmentTypeIDFormat = "Formato de ID de tipo de cita NO válido"
+    AppointmentTypeNotFoundMessage = "Tipo de cita NO encontrado"
+    VetSpecialtyMessage            = "El tipo de cita requiere un veterinario con la especialidad indicada"
+)
+
+// GetAppointmentTypes lista los tipos de cita de la clínica
+// @Summary Lista los tipos de cita
+// @Description Devuelve el catálogo de tipos de cita de la clínica ordenado por nombre, con su servicio facturable
+// @Tags AppointmentTypes
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.AppointmentType
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/appointment-types [get]
+func (h *Handler) GetAppointmentTypes(c *gin.Context) {
+    types, err := h.appointmentTypes(c).GetAll()
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusOK, types)
+}
+
+// GetAppointmentType obtiene un tipo de cita por ID
+// @Summary Obtiene un tipo de cita
+// @Tags AppointmentTypes
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del tipo de cita"
+// @Success 200 {object} models.AppointmentType
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Tipo de cita no encontrado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/appointment-types/{id} [get]
+func (h *Handler) GetAppointmentType(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidAppointmentTypeIDFormat})
+        return
+    }
+
+    appointmentType, err := h.appointmentTypes(c).GetByID(uint(id))
+    if err != nil {
+        appointmentTypeError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, appointmentType)
+}
+
+// CreateAppointmentType da de alta un tipo de cita
+// @Summary Crea un tipo de cita
+// @Description Da de alta un tipo de cita en la clínica de la petición, con su duración y márgenes en minutos, la especialidad y los recursos que necesita, el servicio facturable y el color para los calendarios
+// @Tags AppointmentTypes
+// @Accept json
+// @Produce json
+// @Param appointment_type body models.AppointmentType true "Datos del tipo de cita"
+// @Success 201 {object} models.AppointmentType
+// @Failure 400 {object} map[string]interface{} "Datos inválidos"
+// @Failure 404 {object} map[string]interface{} "Servicio o recurso no encontrado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/appointment-types [post]
+func (h *Handler) CreateAppointmentType(c *gin.Context) {
+    var appointmentType models.AppointmentType
+    if err := c.ShouldBindJSON(&appointmentType); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    appointmentType.ID = 0
+
+    if err := h.appointmentTypes(c).Create(&appointmentType); err != nil {
+        appointmentTypeError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusCreated, appointmentType)
+}
+
+// UpdateAppointmentType actualiza un tipo de cita
+// @Summary Actualiza un tipo de cita
+// @Description Actualiza un tipo de cita. Las citas ya creadas conservan sus valores
+// @Tags AppointmentTypes
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del tipo de cita"
+// @Param appointment_type body models.AppointmentType true "Datos del tipo de cita"
+// @Success 200 {object} models.AppointmentType
+// @Failure 400 {object} map[string]interface{} "Datos inválidos"
+// @Failure 404 {object} map[string]interface{} "Tipo de cita, servicio o recurso no encontrado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/appointment-types/{id} [put]
+func (h *Handler) UpdateAppointmentType(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidAppointmentTypeIDFormat})
+        return
+    }
+
+    var appointmentType models.AppointmentType
+    if err := c.ShouldBindJSON(&appointmentType); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    appointmentType.ID = uint(id)
+
+    if err := h.appointmentTypes(c).Update(&appointmentType); err != nil {
+        appointmentTypeError(c, err)
+        return
+    }
+
+    h.GetAppointmentType(c)
+}
+
+// DeleteAppointmentType da de baja un tipo de cita
+// @Summary Elimina un tipo de cita
+// @Description Da de baja (borrado lógico) un tipo de cita. Las citas que ya lo usan lo conservan
+// @Tags AppointmentTypes
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del tipo de cita"
+// @Success 200 {object} map[string]interface{} "Tipo de cita eliminado"
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Tipo de cita no encontrado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/appointment-types/{id} [delete]
+func (h *Handler) DeleteAppointmentType(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidAppointmentTypeIDFormat})
+        return
+    }
+
+    if err := h.appointmentTypes(c).Delete(uint(id)); err != nil {
+        appointmentTypeError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Tipo de cita eliminado correctamente"})
+}
+
+func appointmentTypeError(c *gin.Context, err error) {
+    statusCode := http.StatusInternalServerError
+    errorMsg := InternalServerErrMsg
+
+    switch {
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        statusCode = http.StatusNotFound
+        errorMsg = AppointmentTypeNotFoundMessage
+    case errors.Is(err, repositories.ErrServiceNotFound):
+        statusCode = http.StatusNotFound
+        errorMsg = ServiceNotFoundMessage
+    case errors.Is(err, repositories.ErrResourceNotFound):
+        statusCode = http.StatusNotFound
+        errorMsg = ResourceNotFoundMessage
+    }
+
+    c.JSON(statusCode, gin.H{"error": errorMsg})
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+)
+
+const maxAuditLimit = 1000
+
+// GetAuditLogs consulta el registro de auditoría
+// @Summary Consulta la auditoría
+// @Description Devuelve los cambios registrados, del más reciente al más antiguo, filtrados por entidad, ID, actor, acción o fechas
+// @Tags Audit
+// @Accept json
+// @Produce json
+// @Param entity query string false "Entidad (client, pet, appointment)"
+// @Param id query int false "ID de la entidad"
+// @Param actor query string false "Actor"
+// @Param action query string false "Acción (create, update, delete, restore, purge)"
+// @Param from query string false "Desde (RFC3339)"
+// @Param to query string false "Hasta (RFC3339)"
+// @Param limit query int false "Máximo de resultados (por defecto 100)"
+// @Param offset query int false "Desplazamiento"
+// @Success 200 {array} models.AuditLog
+// @Failure 400 {object} map[string]interface{} "Parámetros inválidos"
+// @Failure 403 {object} map[string]interface{} "Acceso denegado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/audit [get]
+func (h *Handler) GetAuditLogs(c *gin.Context) {
+    filter := repositories.AuditFilter{
+        Entity: c.Query("entity"),
+        Actor:  c.Query("actor"),
+        Action: c.Query("action"),
+        Limit:  100,
+    }
+
+    if id := c.Query("id"); id != "" {
+        value, err := strconv.ParseUint(id, 10, 32)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": InvalidIDFormat})
+            return
+        }
+        filter.EntityID = uint(value)
+    }
+
+    for param, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+        if value := c.Query(param); value != "" {
+            t, err := time.Parse(time.RFC3339, value)
+            if err != nil {
+                c.JSON(http.StatusBadRequest, gin.H{"error": "Fecha inválida en " + param})
+                return
+            }
+            *target = t
+        }
+    }
+
+    for param, target := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+        if value := c.Query(param); value != "" {
+            n, err := strconv.Atoi(value)
+            if err != nil || n < 0 {
+                c.JSON(http.StatusBadRequest, gin.H{"error": "Valor inválido en " + param})
+                return
+            }
+            *target = n
+        }
+    }
+    if filter.Limit == 0 || filter.Limit > maxAuditLimit {
+        filter.Limit = maxAuditLimit
+    }
+
+    logs, err := h.AuditRepo.WithContext(c.Request.Context()).List(filter)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusOK, logs)
+}
+
+// VerifyAuditLog comprueba la integridad del registro de auditoría
+// @Summary Verifica la auditoría
+// @Description Recorre la cadena de hashes, la compara con su cabeza y devuelve el ID de la primera entrada alterada o, si se borraron las últimas, de la última que falta
+// @Tags Audit
+// @Accept json
+// @Produce json
+// @Success 200 {object} repositories.AuditVerification
+// @Failure 403 {object} map[string]interface{} "Acceso denegado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/audit/verify [get]
+func (h *Handler) VerifyAuditLog(c *gin.Context) {
+    verification, err := h.AuditRepo.WithContext(c.Request.Context()).Verify()
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusOK, verification)
+}
@@ -6,7 +6,10 @@ import (
     "strconv"
 
     "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/dedupe"
     "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "gorm.io/gorm"
 )
 
 // Error messages
@@ -15,21 +18,36 @@ const (
     ClientNotFoundMessage   = "Cliente NO encontrado"
     ClientDeletedMessage    = "Cliente eliminado correctamente"
     InternalServerErrMsg    = "Error interno del servidor"
+    ClientHasPetsMessage    = "El cliente tiene mascotas activas; use cascade=true para archivarlas"
+    FutureAppointmentsMsg   = "No se puede eliminar: existen citas futuras pendientes"
+    NotDeletedMessage       = "El registro no está eliminado"
+    MergeSameClientMessage  = "No se puede fusionar un cliente consigo mismo"
 )
 
+// MergeRequest es el cuerpo de la fusión de clientes.
+type MergeRequest struct {
+    DuplicateID uint `json:"duplicate_id" binding:"required"`
+}
+
 // GetClients obtiene todos los clientes.
 // @Summary Obtiene clientes
 // @Description Obtiene todos los clientes.
 // @Tags Clients
 // @Accept json
 // @Produce json
+// @Param include query string false "Asociaciones a incluir: pets, pets.appointments"
 // @Success 200 {array} models.Client
 // @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
 // @Failure 404 {object} map[string]interface{} "Cliente no encontrado"
 // @Failure 500 {object} map[string]interface{} "Error interno del servidor"
 // @Router /api/v1/clients [get]
 func (h *Handler) GetClients(c *gin.Context) {
-    clients, err := h.ClientRepo.GetAll()
+    preloads, ok := includes(c, clientIncludes)
+    if !ok {
+        return
+    }
+
+    clients, err := h.clients(c).GetAll(preloads...)
     if err != nil {
         statusCode := http.StatusInternalServerError
         errorMsg := err.Error()
@@ -54,7 +72,10 @@ func (h *Handler) GetClients(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "ID del cliente"
+// @Param include query string false "Asociaciones a incluir: pets, pets.appointments"
+// @Param If-None-Match header string false "ETag conocido"
 // @Success 200 {object} models.Client
+// @Success 304 "No modificado"
 // @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
 // @Failure 404 {object} map[string]interface{} "Cliente no encontrada"
 // @Failure 500 {object} map[string]interface{} "Error interno del servidor"
@@ -66,14 +87,27 @@ func (h *Handler) GetClient(c *gin.Context) {
         return
     }
 
-    client, err := h.ClientRepo.GetByID(uint(id))
+    preloads, ok := includes(c, clientIncludes)
+    if !ok {
+        return
+    }
+
+    client, err := h.clients(c).GetByID(uint(id), preloads...)
     if err != nil {
         statusCode := http.StatusInternalServerError
         errorMsg := err.Error()
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            statusCode = http.StatusNotFound
+            errorMsg = ClientNotFoundMessage
+        }
         c.JSON(statusCode, gin.H{"error": errorMsg})
         return
     }
 
+    if notModified(c, representationETag(client.Version, preloads, client)) {
+        return
+    }
+
     c.JSON(http.StatusOK, client)
 }
 
@@ -97,29 +131,33 @@ func (h *Handler) CreateClient(c *gin.Context) {
         return
     }
 
-    if err := h.ClientRepo.Create(&client); err != nil {
+    if err := h.clients(c).Create(&client); err != nil {
         statusCode := http.StatusInternalServerError
         errorMsg := err.Error()
         c.JSON(statusCode, gin.H{"error": errorMsg})
         return
     }
 
+    setETag(c, client.Version)
     c.JSON(http.StatusCreated, client)
 }
 
-// UpdateClient modifica total (PUT) o parcialmente (PATCH) un cliente
+// UpdateClient reemplaza un cliente
 // @Summary Modifica cliente
-// @Description Modifica un cliente total o parcialmente
+// @Description Reemplaza todos los datos de un cliente
 // @Tags Clients
 // @Accept json
 // @Produce json
 // @Param id path int true "ID del cliente"
+// @Param If-Match header string true "ETag actual del cliente"
 // @Param pet body models.Client true "Datos del cliente"
 // @Success 200 {object} models.Client
 // @Failure 400 {object} map[string]interface{} "Error en los datos enviados"
 // @Failure 404 {object} map[string]interface{} "Cliente no encontrado"
+// @Failure 412 {object} map[string]interface{} "El cliente ha sido modificado"
+// @Failure 428 {object} map[string]interface{} "Falta If-Match"
 // @Failure 500 {object} map[string]interface{} "Error interno del servidor"
-// @Router /api/v1/clients/{id} [put][patch]
+// @Router /api/v1/clients/{id} [put]
 func (h *Handler) UpdateClient(c *gin.Context) {
     id, err := strconv.ParseUint(c.Param("id"), 10, 32)
     if err != nil {
@@ -133,14 +171,33 @@ func (h *Handler) UpdateClient(c *gin.Context) {
         return
     }
 
+    current, err := h.clients(c).GetByID(uint(id))
+    if err != nil {
+        statusCode := http.StatusInternalServerError
+        errorMsg := InternalServerErrMsg
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            statusCode = http.StatusNotFound
+            errorMsg = ClientNotFoundMessage
+        }
+        c.JSON(statusCode, gin.H{"error": errorMsg})
+        return
+    }
+
+    if !checkIfMatch(c, current.Version) {
+        return
+    }
+
     // Set the ID from the path parameter
     client.ID = uint(id)
 
     // Call the service to update the client
-    if err := h.ClientRepo.Update(&client); err != nil {
+    if err := h.clients(c).Update(&client, current.Version); err != nil {
         statusCode := http.StatusInternalServerError
         errorMsg := err.Error()
-        if errors.Is(err, h.ClientRepo.DB.Error) {
+        if errors.Is(err, repositories.ErrVersionConflict) {
+            statusCode = http.StatusPreconditionFailed
+            errorMsg = PreconditionFailedMsg
+        } else if errors.Is(err, h.ClientRepo.DB.Error) {
             // Already using the correct status code
             errorMsg = InternalServerErrMsg
         } else {
@@ -151,19 +208,102 @@ func (h *Handler) UpdateClient(c *gin.Context) {
         return
     }
 
-    c.JSON(http.StatusOK, client)
+    updated, err := h.clients(c).GetByID(uint(id))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    setETag(c, updated.Version)
+    c.JSON(http.StatusOK, updated)
+}
+
+// PatchClient modifica parcialmente un cliente
+// @Summary Modifica parcialmente cliente
+// @Description Aplica un JSON Merge Patch (application/merge-patch+json) o un JSON Patch (application/json-patch+json) sobre el cliente y valida el resultado
+// @Tags Clients
+// @Accept json
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path int true "ID del cliente"
+// @Param If-Match header string true "ETag actual del cliente"
+// @Param patch body object true "Parche"
+// @Success 200 {object} models.Client
+// @Failure 400 {object} map[string]interface{} "Parche inválido"
+// @Failure 404 {object} map[string]interface{} "Cliente no encontrado"
+// @Failure 409 {object} map[string]interface{} "No se cumple una operación test"
+// @Failure 412 {object} map[string]interface{} "El cliente ha sido modificado"
+// @Failure 415 {object} map[string]interface{} "Content-Type no soportado"
+// @Failure 422 {object} map[string]interface{} "El cliente resultante no es válido"
+// @Failure 428 {object} map[string]interface{} "Falta If-Match"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/clients/{id} [patch]
+func (h *Handler) PatchClient(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidClientIDFormat})
+        return
+    }
+
+    current, err := h.clients(c).GetByID(uint(id))
+    if err != nil {
+        statusCode := http.StatusInternalServerError
+        errorMsg := InternalServerErrMsg
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            statusCode = http.StatusNotFound
+            errorMsg = ClientNotFoundMessage
+        }
+        c.JSON(statusCode, gin.H{"error": errorMsg})
+        return
+    }
+
+    if !checkIfMatch(c, current.Version) {
+        return
+    }
+
+    var client models.Client
+    if !applyPatch(c, current, &client) {
+        return
+    }
+    client.ID = uint(id)
+
+    if err := h.clients(c).Update(&client, current.Version); err != nil {
+        statusCode := http.StatusBadRequest
+        errorMsg := err.Error()
+        if errors.Is(err, repositories.ErrVersionConflict) {
+            statusCode = http.StatusPreconditionFailed
+            errorMsg = PreconditionFailedMsg
+        }
+        c.JSON(statusCode, gin.H{"error": errorMsg})
+        return
+    }
+
+    updated, err := h.clients(c).GetByID(uint(id))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    setETag(c, updated.Version)
+    c.JSON(http.StatusOK, updated)
 }
 
-// DeleteClient elimina un cliente por ID
+// DeleteClient archiva un cliente por ID
 // @Summary Elimina cliente
-// @Description Elimina un cliente por su ID
+// @Description Archiva (borrado lógico) un cliente por su ID. Con `cascade=true` archiva también sus mascotas y citas; sin él, la operación se bloquea si el cliente tiene mascotas activas. Siempre se bloquea si hay citas futuras.
 // @Tags Clients
 // @Accept json
 // @Produce json
 // @Param id path int true "ID del cliente"
+// @Param cascade query bool false "Archivar también las mascotas del cliente"
+// @Param If-Match header string true "ETag actual del cliente"
 // @Success 200 {object} map[string]string "Cliente eliminado correctamente"
 // @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
 // @Failure 404 {object} map[string]interface{} "Cliente no encontrado"
+// @Failure 409 {object} map[string]interface{} "Mascotas activas o citas futuras"
+// @Failure 412 {object} map[string]interface{} "El cliente ha sido modificado"
+// @Failure 428 {object} map[string]interface{} "Falta If-Match"
 // @Failure 500 {object} map[string]interface{} "Error interno del servidor"
 // @Router /api/v1/clients/{id} [delete]
 func (h *Handler) DeleteClient(c *gin.Context) {
@@ -173,17 +313,43 @@ func (h *Handler) DeleteClient(c *gin.Context) {
         return
     }
 
-    if err := h.ClientRepo.Delete(uint(id)); err != nil {
+    cascade, _ := strconv.ParseBool(c.DefaultQuery("cascade", "false"))
+
+    current, err := h.clients(c).GetByID(uint(id))
+    if err != nil {
         statusCode := http.StatusInternalServerError
-        errorMsg := err.Error()
-        
-        if errors.Is(err, h.ClientRepo.DB.Error) {
-            // Already using the correct status code
-            errorMsg = InternalServerErrMsg
-        } else {
+        errorMsg := InternalServerErrMsg
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            statusCode = http.StatusNotFound
             errorMsg = ClientNotFoundMessage
         }
-        
+        c.JSON(statusCode, gin.H{"error": errorMsg})
+        return
+    }
+
+    if !checkIfMatch(c, current.Version) {
+        return
+    }
+
+    if err := h.clients(c).Delete(uint(id), current.Version, cascade); err != nil {
+        statusCode := http.StatusInternalServerError
+        errorMsg := InternalServerErrMsg
+
+        switch {
+        case errors.Is(err, gorm.ErrRecordNotFound):
+            statusCode = http.StatusNotFound
+            errorMsg = ClientNotFoundMessage
+        case errors.Is(err, repositories.ErrVersionConflict):
+            statusCode = http.StatusPreconditionFailed
+            errorMsg = PreconditionFailedMsg
+        case errors.Is(err, repositories.ErrHasActivePets):
+            statusCode = http.StatusConflict
+            errorMsg = ClientHasPetsMessage
+        case errors.Is(err, repositories.ErrHasFutureAppointments):
+            statusCode = http.StatusConflict
+            errorMsg = FutureAppointmentsMsg
+        }
+
         c.JSON(statusCode, gin.H{"error": errorMsg})
         return
     }
@@ -191,3 +357,121 @@ func (h *Handler) DeleteClient(c *gin.Context) {
     c.JSON(http.StatusOK, gin.H{"message": ClientDeletedMessage})
 }
 
+// RestoreClient restaura un cliente archivado
+// @Summary Restaura cliente
+// @Description Restaura un cliente archivado junto con las mascotas y citas que se archivaron con él
+// @Tags Clients
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del cliente"
+// @Success 200 {object} models.Client
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Cliente no encontrado"
+// @Failure 409 {object} map[string]interface{} "El cliente no está eliminado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/clients/{id}/restore [post]
+func (h *Handler) RestoreClient(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidClientIDFormat})
+        return
+    }
+
+    client, err := h.clients(c).Restore(uint(id))
+    if err != nil {
+        statusCode := http.StatusInternalServerError
+        errorMsg := InternalServerErrMsg
+
+        switch {
+        case errors.Is(err, gorm.ErrRecordNotFound):
+            statusCode = http.StatusNotFound
+            errorMsg = ClientNotFoundMessage
+        case errors.Is(err, repositories.ErrNotDeleted):
+            statusCode = http.StatusConflict
+            errorMsg = NotDeletedMessage
+        }
+
+        c.JSON(statusCode, gin.H{"error": errorMsg})
+        return
+    }
+
+    setETag(c, client.Version)
+    c.JSON(http.StatusOK, client)
+}
+
+// GetClientDuplicates informa de los posibles clientes duplicados
+// @Summary Posibles clientes duplicados
+// @Description Devuelve las parejas de clientes que probablemente son la misma persona, comparando el teléfono normalizado, la similitud del nombre y de la dirección y el email
+// @Tags Clients
+// @Accept json
+// @Produce json
+// @Param min_score query number false "Puntuación mínima entre 0 y 1 (por defecto 0.6)"
+// @Success 200 {array} dedupe.Candidate
+// @Failure 400 {object} map[string]interface{} "Puntuación inválida"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/clients/duplicates [get]
+func (h *Handler) GetClientDuplicates(c *gin.Context) {
+    minScore := dedupe.DefaultMinScore
+    if value := c.Query("min_score"); value != "" {
+        score, err := strconv.ParseFloat(value, 64)
+        if err != nil || score < 0 || score > 1 {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "min_score debe estar entre 0 y 1"})
+            return
+        }
+        minScore = score
+    }
+
+    clients, err := h.clients(c).GetAll()
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusOK, dedupe.FindCandidates(clients, minScore))
+}
+
+// MergeClient fusiona un cliente duplicado en este
+// @Summary Fusiona clientes
+// @Description Pasa las mascotas (y con ellas las citas) del cliente duplicado a este cliente, completa su dirección si falta y archiva el duplicado, todo en una transacción que queda registrada en la auditoría
+// @Tags Clients
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del cliente que se conserva"
+// @Param merge body MergeRequest true "Cliente duplicado"
+// @Success 200 {object} models.Client
+// @Failure 400 {object} map[string]interface{} "Datos inválidos"
+// @Failure 404 {object} map[string]interface{} "Cliente no encontrado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/clients/{id}/merge [post]
+func (h *Handler) MergeClient(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidClientIDFormat})
+        return
+    }
+
+    var request MergeRequest
+    if err := c.ShouldBindJSON(&request); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    if request.DuplicateID == uint(id) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": MergeSameClientMessage})
+        return
+    }
+
+    client, err := h.clients(c).Merge(uint(id), request.DuplicateID)
+    if err != nil {
+        statusCode := http.StatusInternalServerError
+        errorMsg := InternalServerErrMsg
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            statusCode = http.StatusNotFound
+            errorMsg = ClientNotFoundMessage
+        }
+        c.JSON(statusCode, gin.H{"error": errorMsg})
+        return
+    }
+
+    setETag(c, client.Version)
+    c.JSON(http.StatusOK, client)
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "github.com/javice/vet-clinic-api/internal/timezone"
+    "gorm.io/gorm"
+)
+
+// Error messages
+const (
+    InvalidClinicIDFormat = "Formato de ID de clínica NO válido"
+    ClinicNotFoundMessage = "Clínica NO encontrada"
+    ClinicNameTaken       = "Ya existe una clínica con ese nombre"
+    LastClinicMessage     = "El cliente tiene que estar en al menos una clínica"
+    InvalidTimeZone       = "Zona horaria NO válida; use un nombre IANA como Europe/Madrid"
+)
+
+// ShareClientRequest es el cuerpo para compartir un cliente con otra clínica.
+type ShareClientRequest struct {
+    ClinicID uint `json:"clinic_id" binding:"required"`
+}
+
+// GetClinics lista las clínicas del grupo
+// @Summary Lista las clínicas
+// @Description Devuelve las clínicas (sedes) del grupo, para elegir la cabecera X-Clinic-ID
+// @Tags Clinics
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.Clinic
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/clinics [get]
+func (h *Handler) GetClinics(c *gin.Context) {
+    clinics, err := h.clinics(c).GetAll()
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusOK, clinics)
+}
+
+// CreateClinic da de alta una clínica
+// @Summary Crea una clínica
+// @Description Da de alta una nueva sede del grupo con su zona horaria (time_zone). Solo administradores.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "Token de administración"
+// @Param clinic body models.Clinic true "Datos de la clínica"
+// @Success 201 {object} models.Clinic
+// @Failure 400 {object} map[string]interface{} "Datos inválidos"
+// @Failure 403 {object} map[string]interface{} "Acceso denegado"
+// @Failure 409 {object} map[string]interface{} "Nombre duplicado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/admin/clinics [post]
+func (h *Handler) CreateClinic(c *gin.Context) {
+    var clinic models.Clinic
+    if err := c.ShouldBindJSON(&clinic); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    clinic.ID = 0
+    if _, err := timezone.Load(clinic.TimeZone); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidTimeZone})
+        return
+    }
+
+    if err := h.clinics(c).Create(&clinic); err != nil {
+        if errors.Is(err, repositories.ErrClinicNameTaken) {
+            c.JSON(http.StatusConflict, gin.H{"error": ClinicNameTaken})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusCreated, clinic)
+}
+
+// GetClientClinics lista las clínicas de un cliente
+// @Summary Clínicas de un cliente
+// @Description Devuelve las clínicas con las que está compartido el cliente
+// @Tags Clients
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del cliente"
+// @Success 200 {array} models.Clinic
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Cliente no encontrado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/clients/{id}/clinics [get]
+func (h *Handler) GetClientClinics(c *gin.Context) {
+    id, ok := h.visibleClient(c)
+    if !ok {
+        return
+    }
+
+    clinics, err := h.clinics(c).GetByClient(id)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusOK, clinics)
+}
+
+// ShareClient comparte un cliente con otra clínica
+// @Summary Comparte un cliente con otra clínica
+// @Description Hace visible el cliente y sus mascotas en otra sede, para los clientes que acuden a varias
+// @Tags Clients
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del cliente"
+// @Param clinic body ShareClientRequest true "Clínica con la que compartir"
+// @Success 200 {array} models.Clinic
+// @Failure 400 {object} map[string]interface{} "Datos inválidos"
+// @Failure 404 {object} map[string]interface{} "Cliente o clínica no encontrados"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/clients/{id}/clinics [post]
+func (h *Handler) ShareClient(c *gin.Context) {
+    id, ok := h.visibleClient(c)
+    if !ok {
+        return
+    }
+
+    var req ShareClientRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    if _, err := h.clinics(c).GetByID(req.ClinicID); err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            c.JSON(http.StatusNotFound, gin.H{"error": ClinicNotFoundMessage})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    if err := h.clinics(c).Share(id, req.ClinicID); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    h.GetClientClinics(c)
+}
+
+// UnshareClient deja de compartir un cliente con una clínica
+// @Summary Deja de compartir un cliente
+// @Description El cliente y sus mascotas dejan de ser visibles en la clínica. El cliente tiene que seguir en al menos una.
+// @Tags Clients
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del cliente"
+// @Param clinic_id path int true "ID de la clínica"
+// @Success 200 {array} models.Clinic
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Cliente no encontrado o no compartido con la clínica"
+// @Failure 409 {object} map[string]interface{} "Es la única clínica del cliente"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/clients/{id}/clinics/{clinic_id} [delete]
+func (h *Handler) UnshareClient(c *gin.Context) {
+    id, ok := h.visibleClient(c)
+    if !ok {
+        return
+    }
+
+    clinicID, err := strconv.ParseUint(c.Param("clinic_id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidClinicIDFormat})
+        return
+    }
+
+    if err := h.clinics(c).Unshare(id, uint(clinicID)); err != nil {
+        statusCode := http.StatusInternalServerError
+        errorMsg := InternalServerErrMsg
+
+        switch {
+        case errors.Is(err, gorm.ErrRecordNotFound):
+            statusCode = http.StatusNotFound
+            errorMsg = ClinicNotFoundMessage
+        case errors.Is(err, repositories.ErrLastClinic):
+            statusCode = http.StatusConflict
+            errorMsg = LastClinicMessage
+        }
+
+        c.JSON(statusCode, gin.H{"error": errorMsg})
+        return
+    }
+
+    h.GetClientClinics(c)
+}
+
+// visibleClient lee el ID del cliente de la ruta y comprueba que es visible
+// desde la clínica de la petición.
+func (h *Handler) visibleClient(c *gin.Context) (uint, bool) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidClientIDFormat})
+        return 0, false
+    }
+
+    exists, err := h.clients(c).Exists(uint(id))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return 0, false
+    }
+    if !exists {
+        c.JSON(http.StatusNotFound, gin.H{"error": ClientNotFoundMessage})
+        return 0, false
+    }
+    return uint(id), true
+}
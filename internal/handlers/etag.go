@@ -0,0 +1,86 @@
+package handlers
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "net/http"
+    "strconv"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+)
+
+const (
+    PreconditionRequiredMsg = "Se requiere la cabecera If-Match con el ETag actual"
+    PreconditionFailedMsg   = "El registro ha sido modificado; vuelva a obtenerlo e inténtelo de nuevo"
+)
+
+// etag devuelve el ETag (fuerte) correspondiente a una versión.
+func etag(version uint) string {
+    return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// setETag añade la cabecera ETag de la versión a la respuesta.
+func setETag(c *gin.Context, version uint) {
+    c.Header("ETag", etag(version))
+}
+
+// representationETag devuelve el ETag de la respuesta de un registro. Sin
+// asociaciones incluidas es el de su versión; con ellas depende también de lo
+// incluido (qué asociaciones y sus versiones), que puede cambiar sin que
+// cambie el registro, así que no coincide con el que exige If-Match.
+func representationETag(version uint, preloads []string, body interface{}) string {
+    if len(preloads) == 0 {
+        return etag(version)
+    }
+    data, err := json.Marshal(body)
+    if err != nil {
+        return etag(version)
+    }
+    sum := sha256.Sum256(data)
+    return `"` + strconv.FormatUint(uint64(version), 10) + "-" + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// notModified añade el ETag y, si coincide con If-None-Match, responde 304.
+// Devuelve true si ya se ha respondido.
+func notModified(c *gin.Context, current string) bool {
+    c.Header("ETag", current)
+
+    header := c.GetHeader("If-None-Match")
+    if header == "" {
+        return false
+    }
+
+    for _, tag := range strings.Split(header, ",") {
+        // If-None-Match usa comparación débil
+        tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+        if tag == "*" || tag == current {
+            c.Status(http.StatusNotModified)
+            return true
+        }
+    }
+    return false
+}
+
+// checkIfMatch exige la cabecera If-Match y comprueba que corresponde a la
+// versión actual. Si no, responde 428 o 412 y devuelve false.
+func checkIfMatch(c *gin.Context, version uint) bool {
+    header := c.GetHeader("If-Match")
+    if header == "" {
+        c.JSON(http.StatusPreconditionRequired, gin.H{"error": PreconditionRequiredMsg})
+        return false
+    }
+
+    current := etag(version)
+    for _, tag := range strings.Split(header, ",") {
+        // If-Match usa comparación fuerte: las etiquetas débiles no coinciden
+        tag = strings.TrimSpace(tag)
+        if tag == "*" || tag == current {
+            return true
+        }
+    }
+
+    c.JSON(http.StatusPreconditionFailed, gin.H{"error": PreconditionFailedMsg})
+    return false
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+    "context"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/auth"
+    "github.com/javice/vet-clinic-api/internal/export"
+    "github.com/javice/vet-clinic-api/internal/tenant"
+)
+
+const (
+    ExportJobNotFoundMsg = "Exportación no encontrada o caducada"
+    ExportNotReadyMsg    = "La exportación todavía no ha terminado"
+)
+
+// ExportData exporta clientes, mascotas o citas
+// @Summary Exporta un recurso
+// @Description Devuelve todos los registros activos del recurso en CSV, NDJSON o XLSX, leyéndolos de la base de datos según se envían. Admite los mismos filtros que los listados. Si se pide async=true, o el número de filas supera el umbral configurado, la exportación se hace en segundo plano y se responde 202 con el trabajo, que se consulta en /api/v1/export/jobs/{id}.
+// @Tags Export
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param resource path string true "Recurso (clients, pets, appointments)"
+// @Param format query string false "Formato (csv, ndjson, xlsx); por defecto csv"
+// @Param client_id query int false "Filtrar mascotas por cliente"
+// @Param life_stage query string false "Filtrar mascotas por etapa de vida: puppy, adult o senior"
+// @Param pet_id query int false "Filtrar citas por mascota"
+// @Param date query string false "Filtrar citas por día (AAAA-MM-DD) en la zona horaria de la clínica"
+// @Param async query bool false "Exportar en segundo plano"
+// @Success 200 {file} file "Fichero exportado"
+// @Success 202 {object} export.Job "Exportación en segundo plano"
+// @Failure 400 {object} map[string]interface{} "Parámetros inválidos"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/export/{resource} [get]
+func (h *Handler) ExportData(c *gin.Context) {
+    resource := c.Param("resource")
+    format := c.DefaultQuery("format", export.FormatCSV)
+    if err := export.Validate(resource, format); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    // Los mismos filtros que el listado del recurso
+    var filter export.Filter
+    ok := true
+    switch resource {
+    case export.ResourcePets:
+        filter.Pets, ok = petFilter(c)
+    case export.ResourceAppointments:
+        filter.Appointments, ok = appointmentFilter(c)
+    }
+    if !ok {
+        return
+    }
+
+    async := false
+    if value := c.Query("async"); value != "" {
+        var err error
+        if async, err = strconv.ParseBool(value); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Valor inválido en async"})
+            return
+        }
+    }
+
+    db := h.clients(c).DB
+    if !async {
+        count, err := export.Count(db, resource, filter)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+            return
+        }
+        async = count > h.Exports.AsyncThreshold
+    }
+
+    if async {
+        // El trabajo sigue después de responder, así que no puede usar un
+        // contexto que se cancela al terminar la petición
+        background := h.ClientRepo.DB.WithContext(context.WithoutCancel(c.Request.Context()))
+        job, err := h.Exports.Start(background, resource, format, filter, exportOwner(c))
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+            return
+        }
+        c.Header("Location", exportJobURL(job.ID))
+        c.JSON(http.StatusAccepted, job)
+        return
+    }
+
+    c.Header("Content-Type", export.ContentTypes[format])
+    c.Header("Content-Disposition", `attachment; filename="`+resource+"."+format+`"`)
+    c.Status(http.StatusOK)
+    if _, err := export.Write(db, c.Writer, resource, format, filter); err != nil {
+        // La respuesta ya ha empezado; solo queda registrar el error
+        c.Error(err)
+    }
+}
+
+// GetExportJob consulta una exportación en segundo plano
+// @Summary Estado de una exportación
+// @Description Devuelve el estado de una exportación en segundo plano (pending, running, done, failed) y, cuando ha terminado, el enlace de descarga. Solo la encuentra quien la pidió, con la misma clínica y la misma clave de API.
+// @Tags Export
+// @Produce json
+// @Param id path string true "ID de la exportación"
+// @Success 200 {object} export.Job
+// @Failure 404 {object} map[string]interface{} "Exportación no encontrada"
+// @Router /api/v1/export/jobs/{id} [get]
+func (h *Handler) GetExportJob(c *gin.Context) {
+    job, ok := h.Exports.Get(c.Param("id"))
+    if !ok || job.Owner != exportOwner(c) {
+        c.JSON(http.StatusNotFound, gin.H{"error": ExportJobNotFoundMsg})
+        return
+    }
+
+    if job.Status == export.JobDone {
+        job.DownloadURL = exportJobURL(job.ID) + "/download"
+    }
+    c.JSON(http.StatusOK, job)
+}
+
+// DownloadExportJob descarga el fichero de una exportación terminada
+// @Summary Descarga una exportación
+// @Description Descarga el fichero generado por una exportación en segundo plano. Se conserva durante un tiempo limitado después de terminar.
+// @Tags Export
+// @Produce octet-stream
+// @Param id path string true "ID de la exportación"
+// @Success 200 {file} file "Fichero exportado"
+// @Failure 404 {object} map[string]interface{} "Exportación no encontrada"
+// @Failure 409 {object} map[string]interface{} "La exportación no ha terminado o ha fallado"
+// @Router /api/v1/export/jobs/{id}/download [get]
+func (h *Handler) DownloadExportJob(c *gin.Context) {
+    job, ok := h.Exports.Get(c.Param("id"))
+    if !ok || job.Owner != exportOwner(c) {
+        c.JSON(http.StatusNotFound, gin.H{"error": ExportJobNotFoundMsg})
+        return
+    }
+
+    if job.Status != export.JobDone {
+        message := ExportNotReadyMsg
+        if job.Status == export.JobFailed {
+            message = job.Error
+        }
+        c.JSON(http.StatusConflict, gin.H{"error": message})
+        return
+    }
+
+    f, err := h.Exports.Open(job)
+    if err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": ExportJobNotFoundMsg})
+        return
+    }
+    defer f.Close()
+
+    info, err := f.Stat()
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.DataFromReader(http.StatusOK, info.Size(), export.ContentTypes[job.Format], f, map[string]string{
+        "Content-Disposition": `attachment; filename="` + job.Filename() + `"`,
+    })
+}
+
+// exportOwner devuelve la clínica y la clave de API de la petición. Las
+// exportaciones de otra clínica o de otra clave no se encuentran (404).
+func exportOwner(c *gin.Context) export.Owner {
+    ctx := c.Request.Context()
+    var owner export.Owner
+    owner.ClinicID, _ = tenant.ClinicFromContext(ctx)
+    if principal, ok := auth.PrincipalFromContext(ctx); ok && principal.Type == auth.PrincipalAPIKey {
+        owner.KeyID = principal.ID
+    }
+    return owner
+}
+
+func exportJobURL(id string) string {
+    return "/api/v1/export/jobs/" + id
+}
@@ -1,19 +1,116 @@
 package handlers
 
 import (
+    "os"
+    "sync/atomic"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/export"
     "github.com/javice/vet-clinic-api/internal/repositories"
+    "github.com/javice/vet-clinic-api/internal/waitlist"
 )
 
+// DefaultRetention es el tiempo mínimo que se conservan los registros
+// archivados antes de poder purgarlos.
+const DefaultRetention = 5 * 365 * 24 * time.Hour
+
 type Handler struct {
     ClientRepo *repositories.ClientRepository
     PetRepo    *repositories.PetRepository
 	AppointmentRepo *repositories.AppointmentRepository
+    AuditRepo  *repositories.AuditRepository
+    SearchRepo *repositories.SearchRepository
+    APIKeyRepo *repositories.APIKeyRepository
+    ClinicRepo *repositories.ClinicRepository
+    VetRepo    *repositories.VetRepository
+    ResourceRepo *repositories.ResourceRepository
+    WaitlistRepo *repositories.WaitlistRepository
+    ServiceRepo *repositories.ServiceRepository
+    AppointmentTypeRepo *repositories.AppointmentTypeRepository
+    AdmissionRepo *repositories.AdmissionRepository
+    SpeciesRepo *repositories.SpeciesRepository
+    // Retention es el periodo de conservación de los registros archivados
+    Retention  time.Duration
+    // Exports gestiona las exportaciones en segundo plano
+    Exports    *export.Jobs
+    // Waitlist ofrece a la lista de espera los huecos que quedan libres
+    Waitlist   *waitlist.Service
+
+    // draining indica que el servidor se está apagando
+    draining atomic.Bool
 }
 
-func NewHandler(clientRepo *repositories.ClientRepository, petRepo *repositories.PetRepository, appointmentRepo *repositories.AppointmentRepository) *Handler {
+func NewHandler(clientRepo *repositories.ClientRepository, petRepo *repositories.PetRepository, appointmentRepo *repositories.AppointmentRepository, auditRepo *repositories.AuditRepository, searchRepo *repositories.SearchRepository) *Handler {
+    waitlistRepo := repositories.NewWaitlistRepository(clientRepo.DB)
     return &Handler{
         ClientRepo: clientRepo,
         PetRepo:    petRepo,
 		AppointmentRepo: appointmentRepo,
+        AuditRepo:  auditRepo,
+        SearchRepo: searchRepo,
+        APIKeyRepo: repositories.NewAPIKeyRepository(clientRepo.DB),
+        ClinicRepo: repositories.NewClinicRepository(clientRepo.DB),
+        VetRepo:    repositories.NewVetRepository(clientRepo.DB),
+        ResourceRepo: repositories.NewResourceRepository(clientRepo.DB),
+        WaitlistRepo: waitlistRepo,
+        ServiceRepo: repositories.NewServiceRepository(clientRepo.DB),
+        AppointmentTypeRepo: repositories.NewAppointmentTypeRepository(clientRepo.DB),
+        AdmissionRepo: repositories.NewAdmissionRepository(clientRepo.DB),
+        SpeciesRepo: repositories.NewSpeciesRepository(clientRepo.DB),
+        Retention:  DefaultRetention,
+        Exports:    export.NewJobs(os.TempDir()),
+        Waitlist:   waitlist.New(waitlistRepo, nil, waitlist.Config{}),
     }
-}
\ No newline at end of file
+}
+
+// Los repositorios se usan siempre con el contexto de la petición para que
+// la auditoría conozca al actor.
+
+func (h *Handler) clients(c *gin.Context) *repositories.ClientRepository {
+    return h.ClientRepo.WithContext(c.Request.Context())
+}
+
+func (h *Handler) pets(c *gin.Context) *repositories.PetRepository {
+    return h.PetRepo.WithContext(c.Request.Context())
+}
+
+func (h *Handler) appointments(c *gin.Context) *repositories.AppointmentRepository {
+    return h.AppointmentRepo.WithContext(c.Request.Context())
+}
+
+func (h *Handler) apiKeys(c *gin.Context) *repositories.APIKeyRepository {
+    return h.APIKeyRepo.WithContext(c.Request.Context())
+}
+
+func (h *Handler) clinics(c *gin.Context) *repositories.ClinicRepository {
+    return h.ClinicRepo.WithContext(c.Request.Context())
+}
+
+func (h *Handler) vets(c *gin.Context) *repositories.VetRepository {
+    return h.VetRepo.WithContext(c.Request.Context())
+}
+
+func (h *Handler) resources(c *gin.Context) *repositories.ResourceRepository {
+    return h.ResourceRepo.WithContext(c.Request.Context())
+}
+
+func (h *Handler) waitlist(c *gin.Context) *repositories.WaitlistRepository {
+    return h.WaitlistRepo.WithContext(c.Request.Context())
+}
+
+func (h *Handler) services(c *gin.Context) *repositories.ServiceRepository {
+    return h.ServiceRepo.WithContext(c.Request.Context())
+}
+
+func (h *Handler) appointmentTypes(c *gin.Context) *repositories.AppointmentTypeRepository {
+    return h.AppointmentTypeRepo.WithContext(c.Request.Context())
+}
+
+func (h *Handler) admissions(c *gin.Context) *repositories.AdmissionRepository {
+    return h.AdmissionRepo.WithContext(c.Request.Context())
+}
+
+func (h *Handler) species(c *gin.Context) *repositories.SpeciesRepository {
+    return h.SpeciesRepo.WithContext(c.Request.Context())
+}
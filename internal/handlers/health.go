@@ -0,0 +1,99 @@
+package handlers
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/models"
+)
+
+// readinessTimeout limita lo que puede tardar la comprobación de la base de
+// datos
+const readinessTimeout = 2 * time.Second
+
+// Healthz indica que el proceso está vivo
+// @Summary Comprobación de vida
+// @Description Responde 200 mientras el proceso pueda atender peticiones. No comprueba dependencias.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /healthz [get]
+func (h *Handler) Healthz(c *gin.Context) {
+    c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz indica si la API puede recibir tráfico
+// @Summary Comprobación de disponibilidad
+// @Description Comprueba que la base de datos responde y que todas las tablas y columnas de los modelos existen. Responde 503 si algo falla o si el servidor se está apagando.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /readyz [get]
+func (h *Handler) Readyz(c *gin.Context) {
+    if h.draining.Load() {
+        c.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting down"})
+        return
+    }
+
+    checks := gin.H{"database": "ok", "migrations": "ok"}
+    status := http.StatusOK
+
+    ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+    defer cancel()
+
+    sqlDB, err := h.ClientRepo.DB.DB()
+    if err == nil {
+        err = sqlDB.PingContext(ctx)
+    }
+    if err != nil {
+        checks["database"] = err.Error()
+        checks["migrations"] = "unknown"
+        status = http.StatusServiceUnavailable
+    } else if pending := h.pendingMigrations(ctx); len(pending) > 0 {
+        checks["migrations"] = pending
+        status = http.StatusServiceUnavailable
+    }
+
+    result := "ready"
+    if status != http.StatusOK {
+        result = "not ready"
+    }
+    c.JSON(status, gin.H{"status": result, "checks": checks})
+}
+
+// pendingMigrations devuelve las tablas y columnas de los modelos que no
+// existen en la base de datos.
+func (h *Handler) pendingMigrations(ctx context.Context) []string {
+    db := h.ClientRepo.DB.WithContext(ctx)
+    migrator := db.Migrator()
+
+    var pending []string
+    for _, model := range models.All() {
+        stmt := db.Model(model).Statement
+        if err := stmt.Parse(model); err != nil {
+            pending = append(pending, fmt.Sprintf("%T: %v", model, err))
+            continue
+        }
+
+        if !migrator.HasTable(model) {
+            pending = append(pending, stmt.Schema.Table)
+            continue
+        }
+        for _, field := range stmt.Schema.Fields {
+            if field.DBName != "" && !migrator.HasColumn(model, field.DBName) {
+                pending = append(pending, stmt.Schema.Table+"."+field.DBName)
+            }
+        }
+    }
+    return pending
+}
+
+// SetDraining hace que /readyz responda 503 para que el balanceador deje de
+// enviar peticiones mientras el servidor se apaga.
+func (h *Handler) SetDraining() {
+    h.draining.Store(true)
+}
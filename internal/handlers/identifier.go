@@ -0,0 +1,196 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "gorm.io/gorm"
+)
+
+// Error messages
+const (
+    InvalidLookup             = "Indique una sola identificación: chip, tattoo o license_tag"
+    LookupNotFound            = "Ninguna mascota tiene esa identificación"
+    InvalidIdentifierIDFormat = "Formato de ID de identificación NO válido"
+    IdentifierNotFound        = "Identificación NO encontrada"
+    IdentifierTaken           = "La identificación ya está registrada en otra mascota"
+    IdentifierDeleted         = "Identificación eliminada correctamente"
+)
+
+// LookupPet busca una mascota perdida por su identificación
+// @Summary Busca una mascota por su identificación
+// @Description Busca en todas las clínicas la mascota con ese microchip (`chip`), tatuaje (`tattoo`) o placa de licencia (`license_tag`) y devuelve solo lo necesario para contactar con su dueño. Las búsquedas tienen su propio límite de peticiones y quedan registradas en la auditoría.
+// @Tags Pets
+// @Accept json
+// @Produce json
+// @Param chip query string false "Microchip de 15 dígitos"
+// @Param tattoo query string false "Tatuaje"
+// @Param license_tag query string false "Placa de licencia"
+// @Success 200 {object} repositories.PetLookup
+// @Failure 400 {object} map[string]interface{} "Identificación inválida"
+// @Failure 404 {object} map[string]interface{} "Mascota no encontrada"
+// @Failure 429 {object} map[string]interface{} "Demasiadas búsquedas"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/pets/lookup [get]
+func (h *Handler) LookupPet(c *gin.Context) {
+    params := map[string]string{
+        "chip":        repositories.LookupMicrochip,
+        "tattoo":      repositories.LookupTattoo,
+        "license_tag": repositories.LookupLicenseTag,
+    }
+    var kind, value string
+    for param, k := range params {
+        if v, ok := c.GetQuery(param); ok {
+            if kind != "" {
+                c.JSON(http.StatusBadRequest, gin.H{"error": InvalidLookup})
+                return
+            }
+            kind, value = k, v
+        }
+    }
+    if kind == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidLookup})
+        return
+    }
+
+    if kind == repositories.LookupMicrochip {
+        chip, ok := models.NormalizeMicrochip(value)
+        if !ok {
+            c.JSON(http.StatusBadRequest, gin.H{"error": InvalidMicrochip})
+            return
+        }
+        value = chip
+    } else {
+        value = models.NormalizeIdentifier(value)
+        if value == "" {
+            c.JSON(http.StatusBadRequest, gin.H{"error": InvalidLookup})
+            return
+        }
+    }
+
+    found, err := h.pets(c).Lookup(kind, value)
+    if err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            c.JSON(http.StatusNotFound, gin.H{"error": LookupNotFound})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": ServerError})
+        return
+    }
+
+    c.JSON(http.StatusOK, found)
+}
+
+// GetPetIdentifiers lista los tatuajes y placas de una mascota
+// @Summary Lista las identificaciones de una mascota
+// @Description Devuelve los tatuajes y placas de licencia de la mascota; el microchip es un campo de la propia mascota
+// @Tags Pets
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la mascota"
+// @Success 200 {array} models.PetIdentifier
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Mascota no encontrada"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/pets/{id}/identifiers [get]
+func (h *Handler) GetPetIdentifiers(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidIDFormat})
+        return
+    }
+
+    identifiers, err := h.pets(c).GetIdentifiers(uint(id))
+    if err != nil {
+        identifierError(c, err, PetNotFound)
+        return
+    }
+
+    c.JSON(http.StatusOK, identifiers)
+}
+
+// CreatePetIdentifier añade un tatuaje o una placa a una mascota
+// @Summary Añade una identificación a una mascota
+// @Description Registra un tatuaje (`tattoo`) o una placa de licencia (`license_tag`). El valor se guarda en mayúsculas y no puede identificar ya a otra mascota de ninguna clínica
+// @Tags Pets
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la mascota"
+// @Param identifier body models.PetIdentifier true "Identificación"
+// @Success 201 {object} models.PetIdentifier
+// @Failure 400 {object} map[string]interface{} "Datos inválidos"
+// @Failure 404 {object} map[string]interface{} "Mascota no encontrada"
+// @Failure 409 {object} map[string]interface{} "Identificación en uso"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/pets/{id}/identifiers [post]
+func (h *Handler) CreatePetIdentifier(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidIDFormat})
+        return
+    }
+
+    var identifier models.PetIdentifier
+    if err := c.ShouldBindJSON(&identifier); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    identifier.ID = 0
+    identifier.PetID = uint(id)
+
+    if err := h.pets(c).AddIdentifier(&identifier); err != nil {
+        identifierError(c, err, PetNotFound)
+        return
+    }
+
+    c.JSON(http.StatusCreated, identifier)
+}
+
+// DeletePetIdentifier quita un tatuaje o una placa de una mascota
+// @Summary Quita una identificación de una mascota
+// @Tags Pets
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la mascota"
+// @Param identifier_id path int true "ID de la identificación"
+// @Success 200 {object} map[string]string "Identificación eliminada correctamente"
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Identificación no encontrada"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/pets/{id}/identifiers/{identifier_id} [delete]
+func (h *Handler) DeletePetIdentifier(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidIDFormat})
+        return
+    }
+    identifierID, err := strconv.ParseUint(c.Param("identifier_id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidIdentifierIDFormat})
+        return
+    }
+
+    if err := h.pets(c).DeleteIdentifier(uint(id), uint(identifierID)); err != nil {
+        identifierError(c, err, IdentifierNotFound)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": IdentifierDeleted})
+}
+
+// identifierError responde al error de una operación con identificaciones;
+// notFound es el mensaje si no existe la mascota o la identificación.
+func identifierError(c *gin.Context, err error, notFound string) {
+    switch {
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+    case errors.Is(err, repositories.ErrIdentifierTaken):
+        c.JSON(http.StatusConflict, gin.H{"error": IdentifierTaken})
+    default:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": ServerError})
+    }
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/importer"
+    "github.com/javice/vet-clinic-api/internal/tenant"
+    "gorm.io/gorm"
+)
+
+const (
+    ImportClinicRequiredMsg = "Indique la clínica de destino con clinic_id o X-Clinic-ID"
+    ImportClinicMismatchMsg = "clinic_id no coincide con X-Clinic-ID"
+)
+
+// ImportData importa clientes o mascotas desde CSV o NDJSON
+// @Summary Importa clientes o mascotas
+// @Description Valida cada fila con las mismas reglas que la API y devuelve un informe de errores por fila. Por defecto es una simulación (dry_run=true); con dry_run=false importa todas las filas en una transacción, o ninguna si alguna tiene errores. Las mascotas se enlazan con su cliente por la columna client_email. Los clientes se dan de alta en la clínica de destino, que es obligatoria.
+// @Tags Admin
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param X-Admin-Token header string true "Token de administración"
+// @Param kind path string true "Tipo de registro (clients, pets)"
+// @Param clinic_id query int false "Clínica de destino; obligatoria si no se envía X-Clinic-ID"
+// @Param format query string false "Formato (csv, ndjson); por defecto según el Content-Type"
+// @Param dry_run query bool false "Solo validar (por defecto true)"
+// @Param map[columna] query string false "Campo de destino de una columna del fichero, p. ej. map[Correo]=email; '-' la descarta"
+// @Success 200 {object} importer.Report
+// @Failure 400 {object} map[string]interface{} "Parámetros o fichero inválidos"
+// @Failure 403 {object} map[string]interface{} "Token de administración inválido"
+// @Failure 404 {object} map[string]interface{} "Clínica no encontrada"
+// @Failure 422 {object} importer.Report "Hay filas con errores; no se ha importado nada"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/admin/import/{kind} [post]
+func (h *Handler) ImportData(c *gin.Context) {
+    opts := importer.Options{
+        Kind:    c.Param("kind"),
+        Format:  c.Query("format"),
+        Mapping: c.QueryMap("map"),
+        DryRun:  true,
+    }
+
+    if opts.Format == "" {
+        switch contentType := c.ContentType(); {
+        case contentType == "text/csv":
+            opts.Format = importer.FormatCSV
+        case strings.Contains(contentType, "ndjson"):
+            opts.Format = importer.FormatNDJSON
+        }
+    }
+
+    if value := c.Query("dry_run"); value != "" {
+        dryRun, err := strconv.ParseBool(value)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Valor inválido en dry_run"})
+            return
+        }
+        opts.DryRun = dryRun
+    }
+
+    // Sin clínica los clientes importados no se verían desde ninguna
+    ctx := c.Request.Context()
+    clinicID, ok := tenant.ClinicFromContext(ctx)
+    if value := c.Query("clinic_id"); value != "" {
+        id, err := strconv.ParseUint(value, 10, 32)
+        if err != nil || id == 0 {
+            c.JSON(http.StatusBadRequest, gin.H{"error": InvalidIDFormat})
+            return
+        }
+        if ok && uint(id) != clinicID {
+            c.JSON(http.StatusBadRequest, gin.H{"error": ImportClinicMismatchMsg})
+            return
+        }
+        if _, err := h.clinics(c).GetByID(uint(id)); err != nil {
+            if errors.Is(err, gorm.ErrRecordNotFound) {
+                c.JSON(http.StatusNotFound, gin.H{"error": ClinicNotFoundMessage})
+            } else {
+                c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+            }
+            return
+        }
+        clinicID, ok = uint(id), true
+    }
+    if !ok {
+        c.JSON(http.StatusBadRequest, gin.H{"error": ImportClinicRequiredMsg})
+        return
+    }
+    c.Request = c.Request.WithContext(tenant.WithClinic(ctx, clinicID))
+
+    report, err := importer.Run(h.clients(c).DB, c.Request.Body, opts)
+    if err != nil {
+        switch {
+        case errors.Is(err, importer.ErrInvalidRows):
+            c.JSON(http.StatusUnprocessableEntity, report)
+        case errors.Is(err, importer.ErrUnknownKind), errors.Is(err, importer.ErrUnknownFormat), errors.Is(err, importer.ErrMalformed):
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        default:
+            c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        }
+        return
+    }
+
+    c.JSON(http.StatusOK, report)
+}
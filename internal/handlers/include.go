@@ -0,0 +1,57 @@
+package handlers
+
+import (
+    "net/http"
+    "sort"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+)
+
+const InvalidIncludeMsg = "Valor de include no permitido"
+
+// Asociaciones que cada recurso permite precargar con ?include=, junto con
+// su nombre en GORM.
+var (
+    clientIncludes = map[string]string{
+        "pets":              "Pets",
+        "pets.appointments": "Pets.Appointments",
+    }
+    petIncludes = map[string]string{
+        "client":       "Client",
+        "appointments": "Appointments",
+        "identifiers":  "Identifiers",
+    }
+    appointmentIncludes = map[string]string{
+        "pet":        "Pet",
+        "pet.client": "Pet.Client",
+        "type":       "Type",
+    }
+)
+
+// includes traduce el parámetro include (lista separada por comas) a las
+// asociaciones que hay que precargar. Si contiene un valor no permitido
+// responde 400 y devuelve false.
+func includes(c *gin.Context, allowed map[string]string) ([]string, bool) {
+    var preloads []string
+    for _, name := range strings.Split(c.Query("include"), ",") {
+        name = strings.TrimSpace(name)
+        if name == "" {
+            continue
+        }
+
+        association, ok := allowed[name]
+        if !ok {
+            names := make([]string, 0, len(allowed))
+            for allowedName := range allowed {
+                names = append(names, allowedName)
+            }
+            sort.Strings(names)
+
+            c.JSON(http.StatusBadRequest, gin.H{"error": InvalidIncludeMsg + ": " + name, "allowed": names})
+            return nil, false
+        }
+        preloads = append(preloads, association)
+    }
+    return preloads, true
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+    "encoding/json"
+    "errors"
+    "io"
+    "net/http"
+
+    jsonpatch "github.com/evanphx/json-patch/v5"
+    "github.com/gin-gonic/gin"
+    "github.com/gin-gonic/gin/binding"
+)
+
+// Tipos de contenido admitidos en PATCH
+const (
+    MergePatchContentType = "application/merge-patch+json"
+    JSONPatchContentType  = "application/json-patch+json"
+)
+
+const (
+    UnsupportedPatchMsg = "Content-Type no soportado; use application/merge-patch+json o application/json-patch+json"
+    InvalidPatchMsg     = "Documento de parche inválido"
+    PatchTestFailedMsg  = "No se cumple una operación test del parche"
+)
+
+// applyPatch aplica el cuerpo de la petición PATCH sobre current y deja el
+// resultado en target, validado con las mismas reglas que POST y PUT.
+//
+// Acepta JSON Merge Patch (RFC 7396), que es también lo que se asume con
+// application/json, y JSON Patch (RFC 6902). Si algo falla responde con el
+// error y devuelve false.
+func applyPatch(c *gin.Context, current interface{}, target interface{}) bool {
+    body, err := io.ReadAll(c.Request.Body)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return false
+    }
+
+    original, err := json.Marshal(current)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return false
+    }
+
+    var patched []byte
+    switch c.ContentType() {
+    case MergePatchContentType, binding.MIMEJSON:
+        patched, err = jsonpatch.MergePatch(original, body)
+    case JSONPatchContentType:
+        var patch jsonpatch.Patch
+        if patch, err = jsonpatch.DecodePatch(body); err == nil {
+            patched, err = patch.Apply(original)
+        }
+    default:
+        c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": UnsupportedPatchMsg})
+        return false
+    }
+
+    if err != nil {
+        if errors.Is(err, jsonpatch.ErrTestFailed) {
+            c.JSON(http.StatusConflict, gin.H{"error": PatchTestFailedMsg})
+        } else {
+            c.JSON(http.StatusBadRequest, gin.H{"error": InvalidPatchMsg + ": " + err.Error()})
+        }
+        return false
+    }
+
+    if err := json.Unmarshal(patched, target); err != nil {
+        c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+        return false
+    }
+
+    if err := binding.Validator.ValidateStruct(target); err != nil {
+        c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+        return false
+    }
+
+    return true
+}
@@ -4,10 +4,14 @@ import (
     "errors"
     "net/http"
     "strconv"
+    "time"
 
     "github.com/gin-gonic/gin"
     "github.com/javice/vet-clinic-api/internal/models"
-    
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "github.com/javice/vet-clinic-api/internal/tenant"
+    "github.com/javice/vet-clinic-api/internal/timezone"
+    "gorm.io/gorm"
 )
 
 const (
@@ -17,33 +21,54 @@ const (
     InvalidPetData  = "Datos de mascota inválidos"
     ServerError     = "Error interno del servidor"
     PetDeleted      = "Mascota eliminada correctamente"
+    ClientDeleted   = "El cliente de la mascota está eliminado"
+    InvalidLifeStage = "Etapa de vida NO válida; use puppy, adult o senior"
+    InvalidMicrochip = "Microchip NO válido; debe tener 15 dígitos (ISO 11784/11785)"
+    MicrochipTaken   = "El microchip ya está registrado en otra mascota"
 )
 
 
 
 // GetPets obtiene todas las mascotas o filtra por cliente.
 // @Summary Obtiene mascotas
-// @Description Obtiene todas las mascotas o filtra por cliente si se especifica el parámetro `client_id`.
+// @Description Obtiene todas las mascotas o filtra por cliente si se especifica el parámetro `client_id` y por etapa de vida con `life_stage`.
 // @Tags Pets
 // @Accept json
 // @Produce json
 // @Param client_id query int false "ID del cliente"
+// @Param life_stage query string false "Etapa de vida: puppy, adult o senior"
+// @Param include query string false "Asociaciones a incluir: client, appointments"
 // @Success 200 {array} models.Pet
 // @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
 // @Failure 404 {object} map[string]interface{} "Cliente no encontrado"
 // @Failure 500 {object} map[string]interface{} "Error interno del servidor"
 // @Router /api/v1/pets [get]
 func (h *Handler) GetPets(c *gin.Context) {
-    // Si se especifica client_id, filtrar por cliente
-    clientID := c.Query("client_id")
-    if clientID != "" {
-        id, err := strconv.ParseUint(clientID, 10, 32)
+    preloads, ok := includes(c, petIncludes)
+    if !ok {
+        return
+    }
+
+    filter, ok := petFilter(c)
+    if !ok {
+        return
+    }
+
+    // Filtrar por etapa de vida, y por cliente si se indica
+    if filter.LifeStage != "" {
+        pets, err := h.pets(c).Find(filter, preloads...)
         if err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": InvalidIDFormat})
+            c.JSON(http.StatusInternalServerError, gin.H{"error": ServerError})
             return
         }
+        c.JSON(http.StatusOK, pets)
+        return
+    }
+
+    if filter.ClientID != 0 {
+        id := filter.ClientID
 
-        pets, err := h.PetRepo.GetByClientID(uint(id))
+        pets, err := h.pets(c).GetByClientID(id, preloads...)
         if err != nil {
             status := http.StatusInternalServerError
             message := ServerError
@@ -61,7 +86,7 @@ func (h *Handler) GetPets(c *gin.Context) {
     }
 
     // Si no hay cliente específico, devolver todas las mascotas
-    pets, err := h.PetRepo.GetAll()
+    pets, err := h.pets(c).GetAll(preloads...)
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": ServerError})
         return
@@ -69,6 +94,32 @@ func (h *Handler) GetPets(c *gin.Context) {
     c.JSON(http.StatusOK, pets)
 }
 
+// petFilter lee los filtros del listado de mascotas, que también admite su
+// exportación. Si alguno no es válido responde 400 y devuelve false.
+func petFilter(c *gin.Context) (repositories.PetFilter, bool) {
+    filter := repositories.PetFilter{
+        LifeStage: c.Query("life_stage"),
+        Now:       time.Now().In(timezone.Location(c.Request.Context())),
+    }
+    switch filter.LifeStage {
+    case "", models.LifeStagePuppy, models.LifeStageAdult, models.LifeStageSenior:
+    default:
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidLifeStage})
+        return filter, false
+    }
+
+    // Si se especifica client_id, filtrar por cliente
+    if clientID := c.Query("client_id"); clientID != "" {
+        id, err := strconv.ParseUint(clientID, 10, 32)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": InvalidIDFormat})
+            return filter, false
+        }
+        filter.ClientID = uint(id)
+    }
+    return filter, true
+}
+
 // GetPet obtiene una mascota por su ID
 // @Summary Obtiene mascota por ID
 // @Description Obtiene los detalles de una mascota específica por su ID
@@ -76,7 +127,10 @@ func (h *Handler) GetPets(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "ID de la mascota"
+// @Param include query string false "Asociaciones a incluir: client, appointments"
+// @Param If-None-Match header string false "ETag conocido"
 // @Success 200 {object} models.Pet
+// @Success 304 "No modificado"
 // @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
 // @Failure 404 {object} map[string]interface{} "Mascota no encontrada"
 // @Failure 500 {object} map[string]interface{} "Error interno del servidor"
@@ -88,12 +142,17 @@ func (h *Handler) GetPet(c *gin.Context) {
         return
     }
 
-    pet, err := h.PetRepo.GetByID(uint(id))
+    preloads, ok := includes(c, petIncludes)
+    if !ok {
+        return
+    }
+
+    pet, err := h.pets(c).GetByID(uint(id), preloads...)
     if err != nil {
         status := http.StatusInternalServerError
         message := ServerError
 
-        if errors.Is(err, h.PetRepo.DB.Error) {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
             status = http.StatusNotFound
             message = PetNotFound
         }
@@ -102,6 +161,10 @@ func (h *Handler) GetPet(c *gin.Context) {
         return
     }
 
+    if notModified(c, representationETag(pet.Version, preloads, pet)) {
+        return
+    }
+
     c.JSON(http.StatusOK, pet)
 }
 
@@ -116,6 +179,7 @@ func (h *Handler) GetPet(c *gin.Context) {
 // @Success 201 {object} models.Pet
 // @Failure 400 {object} map[string]interface{} "Error en los datos enviados"
 // @Failure 404 {object} map[string]interface{} "Cliente no encontrado"
+// @Failure 409 {object} map[string]interface{} "Microchip en uso"
 // @Failure 500 {object} map[string]interface{} "Error interno del servidor"
 // @Router /api/v1/pets [post]
 func (h *Handler) CreatePet(c *gin.Context) {
@@ -124,13 +188,21 @@ func (h *Handler) CreatePet(c *gin.Context) {
         c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
+    if !h.checkPet(c, &pet, http.StatusBadRequest) {
+        return
+    }
 
-    
-    if err := h.PetRepo.Create(&pet); err != nil {
+    if err := h.pets(c).Create(&pet); err != nil {
         status := http.StatusInternalServerError
         message := ServerError
 
-        if errors.Is(err, h.PetRepo.DB.Error) {
+        if errors.Is(err, tenant.ErrNotInClinic) {
+            status = http.StatusNotFound
+            message = ClientNotExists
+        } else if errors.Is(err, repositories.ErrMicrochipTaken) {
+            status = http.StatusConflict
+            message = MicrochipTaken
+        } else if errors.Is(err, h.PetRepo.DB.Error) {
             status = http.StatusBadRequest
             message = InvalidPetData
         }
@@ -139,23 +211,28 @@ func (h *Handler) CreatePet(c *gin.Context) {
         return
     }
 
+    setETag(c, pet.Version)
     c.JSON(http.StatusCreated, pet)
 }
 
 
-// UpdatePet modifica total (PUT) o parcialmente (PATCH) una mascota
+// UpdatePet reemplaza una mascota
 // @Summary Modifica mascota
-// @Description Modifica una mascota total o parcialmente
+// @Description Reemplaza todos los datos de una mascota
 // @Tags Pets
 // @Accept json
 // @Produce json
 // @Param id path int true "ID de la mascota"
+// @Param If-Match header string true "ETag actual de la mascota"
 // @Param pet body models.Pet true "Datos de la mascota"
 // @Success 200 {object} models.Pet
 // @Failure 400 {object} map[string]interface{} "Error en los datos enviados"
 // @Failure 404 {object} map[string]interface{} "Mascota o cliente no encontrado"
+// @Failure 409 {object} map[string]interface{} "Microchip en uso"
+// @Failure 412 {object} map[string]interface{} "La mascota ha sido modificada"
+// @Failure 428 {object} map[string]interface{} "Falta If-Match"
 // @Failure 500 {object} map[string]interface{} "Error interno del servidor"
-// @Router /api/v1/pets/{id} [put][patch]
+// @Router /api/v1/pets/{id} [put]
 func (h *Handler) UpdatePet(c *gin.Context) {
     id, err := strconv.ParseUint(c.Param("id"), 10, 32)
     if err != nil {
@@ -168,13 +245,43 @@ func (h *Handler) UpdatePet(c *gin.Context) {
         c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
+    if !h.checkPet(c, &pet, http.StatusBadRequest) {
+        return
+    }
+
+    current, err := h.pets(c).GetByID(uint(id))
+    if err != nil {
+        status := http.StatusInternalServerError
+        message := ServerError
+
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            status = http.StatusNotFound
+            message = PetNotFound
+        }
+
+        c.JSON(status, gin.H{"error": message})
+        return
+    }
+
+    if !checkIfMatch(c, current.Version) {
+        return
+    }
 
     pet.ID = uint(id)
-    if err := h.PetRepo.Update(&pet); err != nil {
+    if err := h.pets(c).Update(&pet, current.Version); err != nil {
         status := http.StatusInternalServerError
         message := ServerError
 
-        if errors.Is(err, h.PetRepo.DB.Error) {
+        if errors.Is(err, repositories.ErrVersionConflict) {
+            status = http.StatusPreconditionFailed
+            message = PreconditionFailedMsg
+        } else if errors.Is(err, tenant.ErrNotInClinic) {
+            status = http.StatusNotFound
+            message = ClientNotExists
+        } else if errors.Is(err, repositories.ErrMicrochipTaken) {
+            status = http.StatusConflict
+            message = MicrochipTaken
+        } else if errors.Is(err, h.PetRepo.DB.Error) {
 			status = http.StatusNotFound
 			message = PetNotFound
 		}
@@ -183,19 +290,114 @@ func (h *Handler) UpdatePet(c *gin.Context) {
         return
     }
 
-    c.JSON(http.StatusOK, pet)
+    updated, err := h.pets(c).GetByID(uint(id))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": ServerError})
+        return
+    }
+
+    setETag(c, updated.Version)
+    c.JSON(http.StatusOK, updated)
 }
 
-// DeletePet elimina una mascota
+// PatchPet modifica parcialmente una mascota
+// @Summary Modifica parcialmente mascota
+// @Description Aplica un JSON Merge Patch (application/merge-patch+json) o un JSON Patch (application/json-patch+json) sobre la mascota y valida el resultado
+// @Tags Pets
+// @Accept json
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path int true "ID de la mascota"
+// @Param If-Match header string true "ETag actual de la mascota"
+// @Param patch body object true "Parche"
+// @Success 200 {object} models.Pet
+// @Failure 400 {object} map[string]interface{} "Parche inválido"
+// @Failure 404 {object} map[string]interface{} "Mascota no encontrada"
+// @Failure 409 {object} map[string]interface{} "No se cumple una operación test o microchip en uso"
+// @Failure 412 {object} map[string]interface{} "La mascota ha sido modificada"
+// @Failure 415 {object} map[string]interface{} "Content-Type no soportado"
+// @Failure 422 {object} map[string]interface{} "La mascota resultante no es válida"
+// @Failure 428 {object} map[string]interface{} "Falta If-Match"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/pets/{id} [patch]
+func (h *Handler) PatchPet(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidIDFormat})
+        return
+    }
+
+    current, err := h.pets(c).GetByID(uint(id))
+    if err != nil {
+        status := http.StatusInternalServerError
+        message := ServerError
+
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            status = http.StatusNotFound
+            message = PetNotFound
+        }
+
+        c.JSON(status, gin.H{"error": message})
+        return
+    }
+
+    if !checkIfMatch(c, current.Version) {
+        return
+    }
+
+    var pet models.Pet
+    if !applyPatch(c, current, &pet) {
+        return
+    }
+    if !h.checkPet(c, &pet, http.StatusUnprocessableEntity) {
+        return
+    }
+    pet.ID = uint(id)
+
+    if err := h.pets(c).Update(&pet, current.Version); err != nil {
+        status := http.StatusInternalServerError
+        message := ServerError
+
+        if errors.Is(err, repositories.ErrVersionConflict) {
+            status = http.StatusPreconditionFailed
+            message = PreconditionFailedMsg
+        } else if errors.Is(err, tenant.ErrNotInClinic) {
+            status = http.StatusNotFound
+            message = ClientNotExists
+        } else if errors.Is(err, repositories.ErrMicrochipTaken) {
+            status = http.StatusConflict
+            message = MicrochipTaken
+        }
+
+        c.JSON(status, gin.H{"error": message})
+        return
+    }
+
+    updated, err := h.pets(c).GetByID(uint(id))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": ServerError})
+        return
+    }
+
+    setETag(c, updated.Version)
+    c.JSON(http.StatusOK, updated)
+}
+
+// DeletePet archiva una mascota
 // @Summary Elimina mascota
-// @Description Elimina una mascota por su ID
+// @Description Archiva (borrado lógico) una mascota y sus citas. Se bloquea si la mascota tiene citas futuras.
 // @Tags Pets
 // @Accept json
 // @Produce json
 // @Param id path int true "ID de la mascota"
+// @Param If-Match header string true "ETag actual de la mascota"
 // @Success 200 {object} map[string]string "Mascota eliminada correctamente"
 // @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
 // @Failure 404 {object} map[string]interface{} "Mascota no encontrada"
+// @Failure 409 {object} map[string]interface{} "Citas futuras pendientes"
+// @Failure 412 {object} map[string]interface{} "La mascota ha sido modificada"
+// @Failure 428 {object} map[string]interface{} "Falta If-Match"
 // @Failure 500 {object} map[string]interface{} "Error interno del servidor"
 // @Router /api/v1/pets/{id} [delete]
 func (h *Handler) DeletePet(c *gin.Context) {
@@ -205,13 +407,38 @@ func (h *Handler) DeletePet(c *gin.Context) {
         return
     }
 
-    if err := h.PetRepo.Delete(uint(id)); err != nil {
+    current, err := h.pets(c).GetByID(uint(id))
+    if err != nil {
+        status := http.StatusInternalServerError
+        message := ServerError
+
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            status = http.StatusNotFound
+            message = PetNotFound
+        }
+
+        c.JSON(status, gin.H{"error": message})
+        return
+    }
+
+    if !checkIfMatch(c, current.Version) {
+        return
+    }
+
+    if err := h.pets(c).Delete(uint(id), current.Version); err != nil {
         status := http.StatusInternalServerError
         message := ServerError
 
-        if errors.Is(err, h.PetRepo.DB.Error) {
+        switch {
+        case errors.Is(err, gorm.ErrRecordNotFound):
             status = http.StatusNotFound
             message = PetNotFound
+        case errors.Is(err, repositories.ErrVersionConflict):
+            status = http.StatusPreconditionFailed
+            message = PreconditionFailedMsg
+        case errors.Is(err, repositories.ErrHasFutureAppointments):
+            status = http.StatusConflict
+            message = FutureAppointmentsMsg
         }
 
         c.JSON(status, gin.H{"error": message})
@@ -221,6 +448,51 @@ func (h *Handler) DeletePet(c *gin.Context) {
     c.JSON(http.StatusOK, gin.H{"message": PetDeleted})
 }
 
+// RestorePet restaura una mascota archivada
+// @Summary Restaura mascota
+// @Description Restaura una mascota archivada y las citas que se archivaron con ella. El cliente debe estar activo.
+// @Tags Pets
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la mascota"
+// @Success 200 {object} models.Pet
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Mascota no encontrada"
+// @Failure 409 {object} map[string]interface{} "La mascota no está eliminada o su cliente sí"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/pets/{id}/restore [post]
+func (h *Handler) RestorePet(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidIDFormat})
+        return
+    }
+
+    pet, err := h.pets(c).Restore(uint(id))
+    if err != nil {
+        status := http.StatusInternalServerError
+        message := ServerError
+
+        switch {
+        case errors.Is(err, gorm.ErrRecordNotFound):
+            status = http.StatusNotFound
+            message = PetNotFound
+        case errors.Is(err, repositories.ErrNotDeleted):
+            status = http.StatusConflict
+            message = NotDeletedMessage
+        case errors.Is(err, repositories.ErrParentDeleted):
+            status = http.StatusConflict
+            message = ClientDeleted
+        }
+
+        c.JSON(status, gin.H{"error": message})
+        return
+    }
+
+    setETag(c, pet.Version)
+    c.JSON(http.StatusOK, pet)
+}
+
 // GetPetsByClient obtiene todas las mascotas de un cliente específico
 // @Summary Obtiene mascotas por cliente
 // @Description Obtiene todas las mascotas de un cliente específico
@@ -228,6 +500,7 @@ func (h *Handler) DeletePet(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "ID del cliente"
+// @Param include query string false "Asociaciones a incluir: client, appointments"
 // @Success 200 {array} models.Pet
 // @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
 // @Failure 404 {object} map[string]interface{} "Cliente no encontrado"
@@ -239,20 +512,41 @@ func (h *Handler) GetPetsByClient(c *gin.Context) {
         c.JSON(http.StatusBadRequest, gin.H{"error": InvalidIDFormat})
         return
     }
+
+    preloads, ok := includes(c, petIncludes)
+    if !ok {
+        return
+    }
+
+    exists, err := h.clients(c).Exists(uint(id))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": ServerError})
+        return
+    }
+    if !exists {
+        c.JSON(http.StatusNotFound, gin.H{"error": ClientNotExists})
+        return
+    }
     
-    pets, err := h.PetRepo.GetByClientID(uint(id))
+    pets, err := h.pets(c).GetByClientID(uint(id), preloads...)
     if err != nil {
-        status := http.StatusInternalServerError
-        message := ServerError
-        
-        if errors.Is(err, h.PetRepo.DB.Error) {
-            status = http.StatusNotFound
-            message = ClientNotExists
-        }
-        
-        c.JSON(status, gin.H{"error": message})
+        c.JSON(http.StatusInternalServerError, gin.H{"error": ServerError})
         return
     }
     
     c.JSON(http.StatusOK, pets)
 }
+
+// checkPet normaliza el microchip y la especie y la raza de la mascota. Si
+// alguno no es válido responde con status y devuelve false.
+func (h *Handler) checkPet(c *gin.Context, pet *models.Pet, status int) bool {
+    if pet.Microchip != "" {
+        chip, ok := models.NormalizeMicrochip(pet.Microchip)
+        if !ok {
+            c.JSON(status, gin.H{"error": InvalidMicrochip, "field": "microchip"})
+            return false
+        }
+        pet.Microchip = chip
+    }
+    return h.resolveSpecies(c, pet, status)
+}
@@ -0,0 +1,270 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "github.com/javice/vet-clinic-api/internal/timezone"
+    "gorm.io/gorm"
+)
+
+// Error messages
+const (
+    InvalidResourceIDFormat = "Formato de ID de recurso NO válido"
+    ResourceNotFoundMessage = "Recurso NO encontrado"
+    InvalidResourceKind     = "Tipo de recurso NO válido; use room, equipment o kennel"
+    InvalidDayViewDate      = "Fecha NO válida; use el formato AAAA-MM-DD"
+    InvalidDayViewHours     = "Horario NO válido; use HH:MM con from anterior a to"
+    ScheduleConflictMessage = "El veterinario o algún recurso ya está ocupado a esa hora"
+)
+
+// Horario por defecto de la vista diaria de ocupación
+const (
+    DayViewFrom = "08:00"
+    DayViewTo   = "20:00"
+)
+
+// ResourceDayView es la ocupación de los recursos durante un día.
+type ResourceDayView struct {
+    Date      string                       `json:"date"`
+    From      time.Time                    `json:"from"`
+    To        time.Time                    `json:"to"`
+    Resources []repositories.ResourceUsage `json:"resources"`
+}
+
+// GetResources lista las salas y equipos de la clínica
+// @Summary Lista los recursos
+// @Description Devuelve las salas y equipos reservables de la clínica
+// @Tags Resources
+// @Accept json
+// @Produce json
+// @Param kind query string false "Tipo de recurso: room, equipment o kennel"
+// @Success 200 {array} models.Resource
+// @Failure 400 {object} map[string]interface{} "Tipo de recurso inválido"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/resources [get]
+func (h *Handler) GetResources(c *gin.Context) {
+    kind, ok := resourceKind(c)
+    if !ok {
+        return
+    }
+
+    resources, err := h.resources(c).GetAll(kind)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusOK, resources)
+}
+
+// GetResource obtiene un recurso por ID
+// @Summary Obtiene un recurso
+// @Tags Resources
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del recurso"
+// @Success 200 {object} models.Resource
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Recurso no encontrado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/resources/{id} [get]
+func (h *Handler) GetResource(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidResourceIDFormat})
+        return
+    }
+
+    resource, err := h.resources(c).GetByID(uint(id))
+    if err != nil {
+        resourceError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, resource)
+}
+
+// CreateResource da de alta una sala o un equipo
+// @Summary Crea un recurso
+// @Description Da de alta una sala (room) o un equipo (equipment) reservable en la clínica de la petición
+// @Tags Resources
+// @Accept json
+// @Produce json
+// @Param resource body models.Resource true "Datos del recurso"
+// @Success 201 {object} models.Resource
+// @Failure 400 {object} map[string]interface{} "Datos inválidos"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/resources [post]
+func (h *Handler) CreateResource(c *gin.Context) {
+    var resource models.Resource
+    if err := c.ShouldBindJSON(&resource); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    resource.ID = 0
+
+    if err := h.resources(c).Create(&resource); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusCreated, resource)
+}
+
+// UpdateResource actualiza una sala o un equipo
+// @Summary Actualiza un recurso
+// @Tags Resources
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del recurso"
+// @Param resource body models.Resource true "Datos del recurso"
+// @Success 200 {object} models.Resource
+// @Failure 400 {object} map[string]interface{} "Datos inválidos"
+// @Failure 404 {object} map[string]interface{} "Recurso no encontrado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/resources/{id} [put]
+func (h *Handler) UpdateResource(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidResourceIDFormat})
+        return
+    }
+
+    var resource models.Resource
+    if err := c.ShouldBindJSON(&resource); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    resource.ID = uint(id)
+
+    if err := h.resources(c).Update(&resource); err != nil {
+        resourceError(c, err)
+        return
+    }
+
+    h.GetResource(c)
+}
+
+// DeleteResource da de baja una sala o un equipo
+// @Summary Elimina un recurso
+// @Description Da de baja (borrado lógico) un recurso que no está reservado por citas futuras pendientes ni es una jaula ocupada
+// @Tags Resources
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del recurso"
+// @Success 200 {object} map[string]interface{} "Recurso eliminado"
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Recurso no encontrado"
+// @Failure 409 {object} map[string]interface{} "Reservado por citas futuras pendientes o jaula ocupada"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/resources/{id} [delete]
+func (h *Handler) DeleteResource(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidResourceIDFormat})
+        return
+    }
+
+    if err := h.resources(c).Delete(uint(id)); err != nil {
+        resourceError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Recurso eliminado correctamente"})
+}
+
+// GetResourceUtilization muestra la ocupación de los recursos en un día
+// @Summary Ocupación diaria de los recursos
+// @Description Devuelve, para cada sala y equipo, las citas que lo reservan durante el día y la fracción del horario (por defecto de 08:00 a 20:00) en que está ocupado
+// @Tags Resources
+// @Accept json
+// @Produce json
+// @Param date query string true "Día (AAAA-MM-DD)"
+// @Param kind query string false "Tipo de recurso: room, equipment o kennel"
+// @Param from query string false "Inicio del horario (HH:MM)"
+// @Param to query string false "Fin del horario (HH:MM)"
+// @Success 200 {object} ResourceDayView
+// @Failure 400 {object} map[string]interface{} "Parámetros inválidos"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/resources/utilization [get]
+func (h *Handler) GetResourceUtilization(c *gin.Context) {
+    kind, ok := resourceKind(c)
+    if !ok {
+        return
+    }
+
+    day, err := time.ParseInLocation(time.DateOnly, c.Query("date"), timezone.Location(c.Request.Context()))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidDayViewDate})
+        return
+    }
+
+    from, errFrom := dayTime(day, c.DefaultQuery("from", DayViewFrom))
+    to, errTo := dayTime(day, c.DefaultQuery("to", DayViewTo))
+    if errFrom != nil || errTo != nil || !to.After(from) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidDayViewHours})
+        return
+    }
+
+    usage, err := h.resources(c).Usage(from, to, kind)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusOK, ResourceDayView{
+        Date:      day.Format(time.DateOnly),
+        From:      from,
+        To:        to,
+        Resources: usage,
+    })
+}
+
+// resourceKind lee el filtro kind. Si no es válido responde 400 y devuelve
+// false.
+func resourceKind(c *gin.Context) (string, bool) {
+    kind := c.Query("kind")
+    if kind != "" && kind != models.ResourceRoom && kind != models.ResourceEquipment && kind != models.ResourceKennel {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidResourceKind})
+        return "", false
+    }
+    return kind, true
+}
+
+// dayTime devuelve la hora HH:MM del día indicado. "24:00" es el final del
+// día.
+func dayTime(day time.Time, clock string) (time.Time, error) {
+    if clock == "24:00" {
+        return day.AddDate(0, 0, 1), nil
+    }
+    t, err := time.Parse("15:04", clock)
+    if err != nil {
+        return time.Time{}, err
+    }
+    return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location()), nil
+}
+
+func resourceError(c *gin.Context, err error) {
+    statusCode := http.StatusInternalServerError
+    errorMsg := InternalServerErrMsg
+
+    switch {
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        statusCode = http.StatusNotFound
+        errorMsg = ResourceNotFoundMessage
+    case errors.Is(err, repositories.ErrHasFutureAppointments):
+        statusCode = http.StatusConflict
+        errorMsg = FutureAppointmentsMsg
+    case errors.Is(err, repositories.ErrKennelOccupied):
+        statusCode = http.StatusConflict
+        errorMsg = KennelOccupiedMessage
+    }
+
+    c.JSON(statusCode, gin.H{"error": errorMsg})
+}
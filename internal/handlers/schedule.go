@@ -0,0 +1,248 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "slices"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "github.com/javice/vet-clinic-api/internal/timezone"
+    "gorm.io/gorm"
+)
+
+// Vistas de la agenda
+const (
+    ScheduleViewDay  = "day"
+    ScheduleViewWeek = "week"
+)
+
+// InvalidScheduleView es el error de una vista desconocida
+const InvalidScheduleView = "Vista NO válida; use day o week"
+
+// ScheduleView es la agenda de uno o siete días, lista para mostrarla como
+// una rejilla de calendario.
+type ScheduleView struct {
+    View string        `json:"view"`
+    From time.Time     `json:"from"`
+    To   time.Time     `json:"to"`
+    Days []ScheduleDay `json:"days"`
+}
+
+// ScheduleDay es la agenda de un día. Open y Close delimitan el horario en
+// el que se buscan huecos libres.
+type ScheduleDay struct {
+    Date  string           `json:"date"`
+    Open  time.Time        `json:"open"`
+    Close time.Time        `json:"close"`
+    Vets  []ScheduleColumn `json:"vets"`
+    Rooms []ScheduleColumn `json:"rooms"`
+    // Unassigned son las citas sin veterinario
+    Unassigned []repositories.ScheduleEntry `json:"unassigned"`
+}
+
+// ScheduleColumn son las citas de un veterinario o de una sala durante un
+// día y los huecos que quedan libres en su horario.
+type ScheduleColumn struct {
+    ID           uint                         `json:"id"`
+    Name         string                       `json:"name"`
+    Appointments []repositories.ScheduleEntry `json:"appointments"`
+    Gaps         []ScheduleGap                `json:"gaps"`
+}
+
+// ScheduleGap es un hueco libre de la agenda.
+type ScheduleGap struct {
+    Start   time.Time `json:"start"`
+    End     time.Time `json:"end"`
+    Minutes int       `json:"minutes"`
+}
+
+// GetSchedule devuelve la agenda diaria o semanal
+// @Summary Agenda diaria o semanal
+// @Description Devuelve las citas de un día, o de la semana (de lunes a domingo) que lo contiene, agrupadas por veterinario y por sala, con el nombre y la especie de la mascota, el propietario y su teléfono, y los huecos libres dentro del horario. Las citas sin veterinario se devuelven aparte. Con vet_id solo se incluye ese veterinario; las salas muestran siempre toda su ocupación. Las fechas se interpretan en la zona horaria de la clínica.
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Param view query string false "Vista: day (por defecto) o week"
+// @Param date query string false "Día (AAAA-MM-DD); por defecto hoy"
+// @Param vet_id query int false "ID del veterinario"
+// @Param from query string false "Inicio del horario (HH:MM)"
+// @Param to query string false "Fin del horario (HH:MM)"
+// @Success 200 {object} ScheduleView
+// @Failure 400 {object} map[string]interface{} "Parámetros inválidos"
+// @Failure 404 {object} map[string]interface{} "Veterinario no encontrado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/schedule [get]
+func (h *Handler) GetSchedule(c *gin.Context) {
+    loc := timezone.Location(c.Request.Context())
+
+    view := c.DefaultQuery("view", ScheduleViewDay)
+    days := 1
+    switch view {
+    case ScheduleViewDay:
+    case ScheduleViewWeek:
+        days = 7
+    default:
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidScheduleView})
+        return
+    }
+
+    date := c.DefaultQuery("date", time.Now().In(loc).Format(time.DateOnly))
+    start, _, err := timezone.Day(date, loc)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidDayViewDate})
+        return
+    }
+    if view == ScheduleViewWeek {
+        start = start.AddDate(0, 0, -(int(start.Weekday())+6)%7)
+    }
+    end := start.AddDate(0, 0, days)
+
+    openAt, closeAt := c.DefaultQuery("from", DayViewFrom), c.DefaultQuery("to", DayViewTo)
+    from, errFrom := dayTime(start, openAt)
+    to, errTo := dayTime(start, closeAt)
+    if errFrom != nil || errTo != nil || !to.After(from) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidDayViewHours})
+        return
+    }
+
+    var vets []models.Vet
+    if vetID := c.Query("vet_id"); vetID != "" {
+        id, err := strconv.ParseUint(vetID, 10, 32)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": InvalidVetIDFormat})
+            return
+        }
+        vet, err := h.vets(c).GetByID(uint(id))
+        if err != nil {
+            if errors.Is(err, gorm.ErrRecordNotFound) {
+                c.JSON(http.StatusNotFound, gin.H{"error": VetNotFoundMessage})
+                return
+            }
+            c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+            return
+        }
+        vets = []models.Vet{vet}
+    } else if vets, err = h.vets(c).GetAll(); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    rooms, err := h.resources(c).GetAll(models.ResourceRoom)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    // Todas las citas del periodo en una sola consulta
+    entries, err := h.appointments(c).Schedule(start, end)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+    for i := range entries {
+        entries[i].Start = entries[i].Start.In(loc)
+        entries[i].End = entries[i].End.In(loc)
+    }
+
+    schedule := ScheduleView{View: view, From: start, To: end, Days: make([]ScheduleDay, 0, days)}
+    for i := 0; i < days; i++ {
+        day := start.AddDate(0, 0, i)
+        next := day.AddDate(0, 0, 1)
+        // Los horarios ya se han validado
+        dayOpen, _ := dayTime(day, openAt)
+        dayClose, _ := dayTime(day, closeAt)
+
+        var today []repositories.ScheduleEntry
+        for _, e := range entries {
+            if e.Start.Before(next) && e.End.After(day) {
+                today = append(today, e)
+            }
+        }
+
+        sd := ScheduleDay{
+            Date:       day.Format(time.DateOnly),
+            Open:       dayOpen,
+            Close:      dayClose,
+            Vets:       make([]ScheduleColumn, 0, len(vets)),
+            Rooms:      make([]ScheduleColumn, 0, len(rooms)),
+            Unassigned: []repositories.ScheduleEntry{},
+        }
+        for _, vet := range vets {
+            sd.Vets = append(sd.Vets, scheduleColumn(vet.ID, vet.Name, today, dayOpen, dayClose, func(e repositories.ScheduleEntry) bool {
+                return e.VetID != nil && *e.VetID == vet.ID
+            }))
+        }
+        for _, room := range rooms {
+            sd.Rooms = append(sd.Rooms, scheduleColumn(room.ID, room.Name, today, dayOpen, dayClose, func(e repositories.ScheduleEntry) bool {
+                return slices.Contains(e.ResourceIDs, room.ID)
+            }))
+        }
+        if c.Query("vet_id") == "" {
+            for _, e := range today {
+                if e.VetID == nil {
+                    sd.Unassigned = append(sd.Unassigned, e)
+                }
+            }
+        }
+        schedule.Days = append(schedule.Days, sd)
+    }
+
+    c.JSON(http.StatusOK, schedule)
+}
+
+// scheduleColumn reúne las citas del día que cumplen belongs y los huecos
+// que dejan libres entre open y close.
+func scheduleColumn(id uint, name string, entries []repositories.ScheduleEntry, open, close time.Time, belongs func(repositories.ScheduleEntry) bool) ScheduleColumn {
+    column := ScheduleColumn{ID: id, Name: name, Appointments: []repositories.ScheduleEntry{}}
+    for _, e := range entries {
+        if belongs(e) {
+            column.Appointments = append(column.Appointments, e)
+        }
+    }
+    column.Gaps = scheduleGaps(column.Appointments, open, close)
+    return column
+}
+
+// scheduleGaps devuelve los huecos entre open y close que no ocupa ninguna
+// cita programada, márgenes incluidos. Las ausencias no ocupan su hueco.
+func scheduleGaps(entries []repositories.ScheduleEntry, open, close time.Time) []ScheduleGap {
+    var busy []repositories.ScheduleEntry
+    for _, e := range entries {
+        if e.Status == models.AppointmentScheduled {
+            busy = append(busy, e)
+        }
+    }
+    slices.SortFunc(busy, func(a, b repositories.ScheduleEntry) int {
+        return a.BlockedFrom().Compare(b.BlockedFrom())
+    })
+
+    gaps := []ScheduleGap{}
+    add := func(start, end time.Time) {
+        if end.After(start) {
+            gaps = append(gaps, ScheduleGap{Start: start, End: end, Minutes: int(end.Sub(start).Minutes())})
+        }
+    }
+    cursor := open
+    for _, e := range busy {
+        if !cursor.Before(close) {
+            break
+        }
+        if from := e.BlockedFrom(); from.After(cursor) {
+            if from.After(close) {
+                from = close
+            }
+            add(cursor, from)
+        }
+        if until := e.BlockedUntil(); until.After(cursor) {
+            cursor = until
+        }
+    }
+    if cursor.Before(close) {
+        add(cursor, close)
+    }
+    return gaps
+}
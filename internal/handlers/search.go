@@ -0,0 +1,72 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+)
+
+const (
+    defaultSearchLimit = 20
+    maxSearchLimit     = 100
+)
+
+// Search busca en clientes, mascotas y citas
+// @Summary Búsqueda de texto completo
+// @Description Busca el texto en el nombre, email y teléfono de los clientes, el nombre, raza y descripción de las mascotas y el motivo y las notas de las citas. Admite fragmentos (p. ej. parte de un teléfono) y devuelve los resultados ordenados por relevancia con las coincidencias resaltadas con <mark>.
+// @Tags Search
+// @Accept json
+// @Produce json
+// @Param q query string true "Texto a buscar"
+// @Param type query string false "Tipos separados por comas (client, pet, appointment)"
+// @Param limit query int false "Máximo de resultados (por defecto 20, máximo 100)"
+// @Success 200 {array} repositories.SearchResult
+// @Failure 400 {object} map[string]interface{} "Parámetros inválidos"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/search [get]
+func (h *Handler) Search(c *gin.Context) {
+    query := repositories.SearchQuery{
+        Text:  strings.TrimSpace(c.Query("q")),
+        Limit: defaultSearchLimit,
+    }
+    if query.Text == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "El parámetro q es obligatorio"})
+        return
+    }
+
+    if types := c.Query("type"); types != "" {
+        for _, t := range strings.Split(types, ",") {
+            t = strings.TrimSpace(t)
+            switch t {
+            case repositories.SearchClient, repositories.SearchPet, repositories.SearchAppointment:
+                query.Types = append(query.Types, t)
+            default:
+                c.JSON(http.StatusBadRequest, gin.H{"error": "Tipo de búsqueda inválido: " + t})
+                return
+            }
+        }
+    }
+
+    if value := c.Query("limit"); value != "" {
+        n, err := strconv.Atoi(value)
+        if err != nil || n <= 0 {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Valor inválido en limit"})
+            return
+        }
+        query.Limit = n
+    }
+    if query.Limit > maxSearchLimit {
+        query.Limit = maxSearchLimit
+    }
+
+    results, err := h.SearchRepo.WithContext(c.Request.Context()).Search(query)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusOK, results)
+}
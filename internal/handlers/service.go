@@ -0,0 +1,163 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "gorm.io/gorm"
+)
+
+// Error messages
+const (
+    InvalidServiceIDFormat = "Formato de ID de servicio NO válido"
+    ServiceNotFoundMessage = "Servicio NO encontrado"
+)
+
+// GetServices lista los servicios facturables
+// @Summary Lista los servicios
+// @Description Devuelve el catálogo de servicios facturables, común a todas las clínicas, ordenado por código
+// @Tags Services
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.Service
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/services [get]
+func (h *Handler) GetServices(c *gin.Context) {
+    services, err := h.services(c).GetAll()
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusOK, services)
+}
+
+// GetService obtiene un servicio por ID
+// @Summary Obtiene un servicio
+// @Tags Services
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del servicio"
+// @Success 200 {object} models.Service
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Servicio no encontrado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/services/{id} [get]
+func (h *Handler) GetService(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidServiceIDFormat})
+        return
+    }
+
+    service, err := h.services(c).GetByID(uint(id))
+    if err != nil {
+        serviceError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, service)
+}
+
+// CreateService da de alta un servicio
+// @Summary Crea un servicio
+// @Description Da de alta un servicio facturable. El precio se indica en céntimos
+// @Tags Services
+// @Accept json
+// @Produce json
+// @Param service body models.Service true "Datos del servicio"
+// @Success 201 {object} models.Service
+// @Failure 400 {object} map[string]interface{} "Datos inválidos"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/services [post]
+func (h *Handler) CreateService(c *gin.Context) {
+    var service models.Service
+    if err := c.ShouldBindJSON(&service); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    service.ID = 0
+
+    if err := h.services(c).Create(&service); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusCreated, service)
+}
+
+// UpdateService actualiza un servicio
+// @Summary Actualiza un servicio
+// @Tags Services
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del servicio"
+// @Param service body models.Service true "Datos del servicio"
+// @Success 200 {object} models.Service
+// @Failure 400 {object} map[string]interface{} "Datos inválidos"
+// @Failure 404 {object} map[string]interface{} "Servicio no encontrado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/services/{id} [put]
+func (h *Handler) UpdateService(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidServiceIDFormat})
+        return
+    }
+
+    var service models.Service
+    if err := c.ShouldBindJSON(&service); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    service.ID = uint(id)
+
+    if err := h.services(c).Update(&service); err != nil {
+        serviceError(c, err)
+        return
+    }
+
+    h.GetService(c)
+}
+
+// DeleteService da de baja un servicio
+// @Summary Elimina un servicio
+// @Description Da de baja (borrado lógico) un servicio
+// @Tags Services
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del servicio"
+// @Success 200 {object} map[string]interface{} "Servicio eliminado"
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Servicio no encontrado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/services/{id} [delete]
+func (h *Handler) DeleteService(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidServiceIDFormat})
+        return
+    }
+
+    if err := h.services(c).Delete(uint(id)); err != nil {
+        serviceError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Servicio eliminado correctamente"})
+}
+
+func serviceError(c *gin.Context, err error) {
+    statusCode := http.StatusInternalServerError
+    errorMsg := InternalServerErrMsg
+
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        statusCode = http.StatusNotFound
+        errorMsg = ServiceNotFoundMessage
+    }
+
+    c.JSON(statusCode, gin.H{"error": errorMsg})
+}
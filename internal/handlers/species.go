@@ -0,0 +1,283 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "gorm.io/gorm"
+)
+
+// Error messages
+const (
+    InvalidSpeciesIDFormat = "Formato de ID de especie NO válido"
+    InvalidBreedIDFormat   = "Formato de ID de raza NO válido"
+    SpeciesNotFoundMessage = "Especie NO encontrada"
+    BreedNotFoundMessage   = "Raza NO encontrada"
+    UnknownSpeciesMessage  = "Especie desconocida; consulte el catálogo en /api/v1/species"
+    UnknownBreedMessage    = "Raza desconocida para esta especie; consulte /api/v1/species/{id}/breeds"
+)
+
+// GetSpecies lista las especies del catálogo
+// @Summary Lista las especies
+// @Description Devuelve el catálogo de especies, común a todas las clínicas, con sus nombres en cada idioma y sus alias
+// @Tags Species
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.Species
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/species [get]
+func (h *Handler) GetSpecies(c *gin.Context) {
+    species, err := h.species(c).GetAll()
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusOK, species)
+}
+
+// GetSpeciesByID obtiene una especie con sus razas
+// @Summary Obtiene una especie
+// @Tags Species
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la especie"
+// @Success 200 {object} models.Species
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Especie no encontrada"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/species/{id} [get]
+func (h *Handler) GetSpeciesByID(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidSpeciesIDFormat})
+        return
+    }
+
+    species, err := h.species(c).GetByID(uint(id))
+    if err != nil {
+        speciesError(c, err, SpeciesNotFoundMessage)
+        return
+    }
+
+    c.JSON(http.StatusOK, species)
+}
+
+// CreateSpecies da de alta una especie
+// @Summary Crea una especie
+// @Description Da de alta una especie, con sus razas si se indican. Ningún nombre ni alias puede identificar ya a otra especie
+// @Tags Species
+// @Accept json
+// @Produce json
+// @Param species body models.Species true "Datos de la especie"
+// @Success 201 {object} models.Species
+// @Failure 400 {object} map[string]interface{} "Datos inválidos"
+// @Failure 409 {object} map[string]interface{} "Nombre o alias en uso"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/species [post]
+func (h *Handler) CreateSpecies(c *gin.Context) {
+    var species models.Species
+    if err := c.ShouldBindJSON(&species); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    species.ID = 0
+    for i := range species.Breeds {
+        species.Breeds[i].ID = 0
+        species.Breeds[i].SpeciesID = 0
+    }
+
+    if err := h.species(c).Create(&species); err != nil {
+        speciesError(c, err, SpeciesNotFoundMessage)
+        return
+    }
+
+    c.JSON(http.StatusCreated, species)
+}
+
+// UpdateSpecies actualiza una especie
+// @Summary Actualiza una especie
+// @Description Modifica los nombres y alias de la especie; sus razas se gestionan aparte. Si cambia el nombre, las mascotas de la especie pasan a tener el nuevo
+// @Tags Species
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la especie"
+// @Param species body models.Species true "Datos de la especie"
+// @Success 200 {object} models.Species
+// @Failure 400 {object} map[string]interface{} "Datos inválidos"
+// @Failure 404 {object} map[string]interface{} "Especie no encontrada"
+// @Failure 409 {object} map[string]interface{} "Nombre o alias en uso"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/species/{id} [put]
+func (h *Handler) UpdateSpecies(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidSpeciesIDFormat})
+        return
+    }
+
+    var species models.Species
+    if err := c.ShouldBindJSON(&species); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    species.ID = uint(id)
+
+    if err := h.species(c).Update(&species); err != nil {
+        speciesError(c, err, SpeciesNotFoundMessage)
+        return
+    }
+
+    h.GetSpeciesByID(c)
+}
+
+// GetBreeds lista las razas de una especie
+// @Summary Lista las razas de una especie
+// @Description Devuelve las razas de la especie por orden de nombre. Con `q` solo las que tienen algún nombre o alias que lo contiene, sin distinguir mayúsculas ni tildes, para autocompletar
+// @Tags Species
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la especie"
+// @Param q query string false "Texto a buscar"
+// @Success 200 {array} models.Breed
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Especie no encontrada"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/species/{id}/breeds [get]
+func (h *Handler) GetBreeds(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidSpeciesIDFormat})
+        return
+    }
+
+    breeds, err := h.species(c).Breeds(uint(id), c.Query("q"))
+    if err != nil {
+        speciesError(c, err, SpeciesNotFoundMessage)
+        return
+    }
+
+    c.JSON(http.StatusOK, breeds)
+}
+
+// CreateBreed añade una raza a una especie
+// @Summary Crea una raza
+// @Description Añade una raza a la especie. Ningún nombre ni alias puede identificar ya a otra raza de la especie
+// @Tags Species
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la especie"
+// @Param breed body models.Breed true "Datos de la raza"
+// @Success 201 {object} models.Breed
+// @Failure 400 {object} map[string]interface{} "Datos inválidos"
+// @Failure 404 {object} map[string]interface{} "Especie no encontrada"
+// @Failure 409 {object} map[string]interface{} "Nombre o alias en uso"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/species/{id}/breeds [post]
+func (h *Handler) CreateBreed(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidSpeciesIDFormat})
+        return
+    }
+
+    var breed models.Breed
+    if err := c.ShouldBindJSON(&breed); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    breed.ID = 0
+    breed.SpeciesID = uint(id)
+
+    if err := h.species(c).CreateBreed(&breed); err != nil {
+        speciesError(c, err, SpeciesNotFoundMessage)
+        return
+    }
+
+    c.JSON(http.StatusCreated, breed)
+}
+
+// UpdateBreed actualiza una raza
+// @Summary Actualiza una raza
+// @Description Modifica los nombres y alias de la raza. Si cambia el nombre, las mascotas de la raza pasan a tener el nuevo
+// @Tags Species
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la especie"
+// @Param breed_id path int true "ID de la raza"
+// @Param breed body models.Breed true "Datos de la raza"
+// @Success 200 {object} models.Breed
+// @Failure 400 {object} map[string]interface{} "Datos inválidos"
+// @Failure 404 {object} map[string]interface{} "Especie o raza no encontrada"
+// @Failure 409 {object} map[string]interface{} "Nombre o alias en uso"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/species/{id}/breeds/{breed_id} [put]
+func (h *Handler) UpdateBreed(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidSpeciesIDFormat})
+        return
+    }
+    breedID, err := strconv.ParseUint(c.Param("breed_id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidBreedIDFormat})
+        return
+    }
+
+    var breed models.Breed
+    if err := c.ShouldBindJSON(&breed); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    breed.ID = uint(breedID)
+    breed.SpeciesID = uint(id)
+
+    if err := h.species(c).UpdateBreed(&breed); err != nil {
+        speciesError(c, err, BreedNotFoundMessage)
+        return
+    }
+
+    c.JSON(http.StatusOK, breed)
+}
+
+// resolveSpecies sustituye la especie y la raza de la mascota por sus
+// nombres canónicos del catálogo. Si no están en el catálogo responde con
+// status y devuelve false.
+func (h *Handler) resolveSpecies(c *gin.Context, pet *models.Pet, status int) bool {
+    species, breed, err := h.species(c).Resolve(pet.Species, pet.Breed)
+    switch {
+    case errors.Is(err, repositories.ErrUnknownSpecies):
+        c.JSON(status, gin.H{"error": UnknownSpeciesMessage, "field": "species"})
+        return false
+    case errors.Is(err, repositories.ErrUnknownBreed):
+        c.JSON(status, gin.H{"error": UnknownBreedMessage, "field": "breed"})
+        return false
+    case err != nil:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": ServerError})
+        return false
+    }
+
+    pet.Species = species
+    pet.Breed = breed
+    return true
+}
+
+func speciesError(c *gin.Context, err error, notFound string) {
+    statusCode := http.StatusInternalServerError
+    errorMsg := InternalServerErrMsg
+
+    switch {
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        statusCode = http.StatusNotFound
+        errorMsg = notFound
+    case errors.Is(err, repositories.ErrSpeciesTaken), errors.Is(err, repositories.ErrBreedTaken):
+        statusCode = http.StatusConflict
+        errorMsg = err.Error()
+    }
+
+    c.JSON(statusCode, gin.H{"error": errorMsg})
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "gorm.io/gorm"
+)
+
+// Error messages
+const (
+    InvalidVetIDFormat = "Formato de ID de veterinario NO válido"
+    VetNotFoundMessage = "Veterinario NO encontrado"
+)
+
+// GetVets lista los veterinarios de la clínica
+// @Summary Lista los veterinarios
+// @Description Devuelve los veterinarios de la clínica ordenados por nombre
+// @Tags Vets
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.Vet
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/vets [get]
+func (h *Handler) GetVets(c *gin.Context) {
+    vets, err := h.vets(c).GetAll()
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusOK, vets)
+}
+
+// GetVet obtiene un veterinario por ID
+// @Summary Obtiene un veterinario
+// @Tags Vets
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del veterinario"
+// @Success 200 {object} models.Vet
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Veterinario no encontrado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/vets/{id} [get]
+func (h *Handler) GetVet(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidVetIDFormat})
+        return
+    }
+
+    vet, err := h.vets(c).GetByID(uint(id))
+    if err != nil {
+        vetError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, vet)
+}
+
+// CreateVet da de alta un veterinario
+// @Summary Crea un veterinario
+// @Description Da de alta un veterinario en la clínica de la petición
+// @Tags Vets
+// @Accept json
+// @Produce json
+// @Param vet body models.Vet true "Datos del veterinario"
+// @Success 201 {object} models.Vet
+// @Failure 400 {object} map[string]interface{} "Datos inválidos"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/vets [post]
+func (h *Handler) CreateVet(c *gin.Context) {
+    var vet models.Vet
+    if err := c.ShouldBindJSON(&vet); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    vet.ID = 0
+
+    if err := h.vets(c).Create(&vet); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusCreated, vet)
+}
+
+// UpdateVet actualiza un veterinario
+// @Summary Actualiza un veterinario
+// @Tags Vets
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del veterinario"
+// @Param vet body models.Vet true "Datos del veterinario"
+// @Success 200 {object} models.Vet
+// @Failure 400 {object} map[string]interface{} "Datos inválidos"
+// @Failure 404 {object} map[string]interface{} "Veterinario no encontrado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/vets/{id} [put]
+func (h *Handler) UpdateVet(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidVetIDFormat})
+        return
+    }
+
+    var vet models.Vet
+    if err := c.ShouldBindJSON(&vet); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    vet.ID = uint(id)
+
+    if err := h.vets(c).Update(&vet); err != nil {
+        vetError(c, err)
+        return
+    }
+
+    h.GetVet(c)
+}
+
+// DeleteVet da de baja un veterinario
+// @Summary Elimina un veterinario
+// @Description Da de baja (borrado lógico) un veterinario sin citas futuras pendientes
+// @Tags Vets
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del veterinario"
+// @Success 200 {object} map[string]interface{} "Veterinario eliminado"
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Veterinario no encontrado"
+// @Failure 409 {object} map[string]interface{} "Tiene citas futuras pendientes"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/vets/{id} [delete]
+func (h *Handler) DeleteVet(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidVetIDFormat})
+        return
+    }
+
+    if err := h.vets(c).Delete(uint(id)); err != nil {
+        vetError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Veterinario eliminado correctamente"})
+}
+
+func vetError(c *gin.Context, err error) {
+    statusCode := http.StatusInternalServerError
+    errorMsg := InternalServerErrMsg
+
+    switch {
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        statusCode = http.StatusNotFound
+        errorMsg = VetNotFoundMessage
+    case errors.Is(err, repositories.ErrHasFutureAppointments):
+        statusCode = http.StatusConflict
+        errorMsg = FutureAppointmentsMsg
+    }
+
+    c.JSON(statusCode, gin.H{"error": errorMsg})
+}
@@ -0,0 +1,278 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "github.com/javice/vet-clinic-api/internal/tenant"
+    "gorm.io/gorm"
+)
+
+// Error messages
+const (
+    InvalidWaitlistIDFormat = "Formato de ID de la lista de espera NO válido"
+    WaitlistNotFoundMessage = "Entrada de la lista de espera NO encontrada"
+    WaitlistClosedMessage   = "La entrada ya no está en la lista de espera"
+    InvalidWaitlistStatus   = "Estado NO válido; use waiting, offered, booked o cancelled"
+    InvalidOfferIDFormat    = "Formato de ID de oferta NO válido"
+    OfferNotFoundMessage    = "Oferta NO encontrada"
+    OfferClosedMessage      = "La oferta ya no está pendiente"
+    OfferExpiredMessage     = "La oferta ha caducado y se ha ofrecido el hueco a otro cliente"
+    InvalidOfferStatus      = "Estado NO válido; use pending, accepted, declined o expired"
+)
+
+// GetWaitlist lista la lista de espera
+// @Summary Lista de espera
+// @Description Devuelve las entradas de la lista de espera por orden de prioridad: urgencia y antigüedad
+// @Tags Waitlist
+// @Accept json
+// @Produce json
+// @Param status query string false "Estado: waiting, offered, booked o cancelled"
+// @Success 200 {array} models.WaitlistEntry
+// @Failure 400 {object} map[string]interface{} "Estado inválido"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/waitlist [get]
+func (h *Handler) GetWaitlist(c *gin.Context) {
+    status := c.Query("status")
+    switch status {
+    case "", models.WaitlistWaiting, models.WaitlistOffered, models.WaitlistBooked, models.WaitlistCancelled:
+    default:
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidWaitlistStatus})
+        return
+    }
+
+    entries, err := h.waitlist(c).GetAll(status)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusOK, entries)
+}
+
+// GetWaitlistEntry obtiene una entrada de la lista de espera
+// @Summary Obtiene una entrada de la lista de espera
+// @Tags Waitlist
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la entrada"
+// @Success 200 {object} models.WaitlistEntry
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Entrada no encontrada"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/waitlist/{id} [get]
+func (h *Handler) GetWaitlistEntry(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidWaitlistIDFormat})
+        return
+    }
+
+    entry, err := h.waitlist(c).GetByID(uint(id))
+    if err != nil {
+        waitlistError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, entry)
+}
+
+// CreateWaitlistEntry apunta una mascota en la lista de espera
+// @Summary Apunta en la lista de espera
+// @Description Apunta una mascota en la lista de espera con el motivo, la duración necesaria, el veterinario preferido, la urgencia (low, normal o high) y los intervalos en los que puede acudir
+// @Tags Waitlist
+// @Accept json
+// @Produce json
+// @Param entry body models.WaitlistEntry true "Datos de la entrada"
+// @Success 201 {object} models.WaitlistEntry
+// @Failure 400 {object} map[string]interface{} "Datos inválidos"
+// @Failure 404 {object} map[string]interface{} "Mascota no encontrada"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/waitlist [post]
+func (h *Handler) CreateWaitlistEntry(c *gin.Context) {
+    var entry models.WaitlistEntry
+    if err := c.ShouldBindJSON(&entry); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    entry.ID = 0
+
+    exists, err := h.pets(c).Exists(entry.PetID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+    if !exists {
+        c.JSON(http.StatusNotFound, gin.H{"error": PetNotFound})
+        return
+    }
+
+    if err := h.waitlist(c).Create(&entry); err != nil {
+        if errors.Is(err, tenant.ErrNotInClinic) {
+            c.JSON(http.StatusNotFound, gin.H{"error": PetNotFound})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusCreated, entry)
+}
+
+// CancelWaitlistEntry saca una mascota de la lista de espera
+// @Summary Cancela una entrada de la lista de espera
+// @Description Saca la entrada de la lista de espera. Si tenía una oferta pendiente, el hueco se ofrece al siguiente candidato.
+// @Tags Waitlist
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la entrada"
+// @Success 200 {object} models.WaitlistEntry
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Entrada no encontrada"
+// @Failure 409 {object} map[string]interface{} "La entrada ya tiene cita o está cancelada"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/waitlist/{id} [delete]
+func (h *Handler) CancelWaitlistEntry(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidWaitlistIDFormat})
+        return
+    }
+
+    if err := h.Waitlist.Cancel(c.Request.Context(), uint(id)); err != nil {
+        waitlistError(c, err)
+        return
+    }
+
+    h.GetWaitlistEntry(c)
+}
+
+// GetWaitlistOffers lista las ofertas de huecos
+// @Summary Ofertas de la lista de espera
+// @Description Devuelve las ofertas de huecos hechas a la lista de espera, las más recientes primero
+// @Tags Waitlist
+// @Accept json
+// @Produce json
+// @Param status query string false "Estado: pending, accepted, declined o expired"
+// @Success 200 {array} models.WaitlistOffer
+// @Failure 400 {object} map[string]interface{} "Estado inválido"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/waitlist/offers [get]
+func (h *Handler) GetWaitlistOffers(c *gin.Context) {
+    status := c.Query("status")
+    switch status {
+    case "", models.OfferPending, models.OfferAccepted, models.OfferDeclined, models.OfferExpired:
+    default:
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidOfferStatus})
+        return
+    }
+
+    offers, err := h.waitlist(c).GetOffers(status)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": InternalServerErrMsg})
+        return
+    }
+
+    c.JSON(http.StatusOK, offers)
+}
+
+// AcceptWaitlistOffer acepta una oferta de hueco
+// @Summary Acepta una oferta
+// @Description Convierte la oferta en una cita si se acepta dentro de plazo
+// @Tags Waitlist
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la oferta"
+// @Success 201 {object} models.Appointment
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Oferta no encontrada"
+// @Failure 409 {object} map[string]interface{} "La oferta ya no está pendiente o el hueco se ha ocupado"
+// @Failure 410 {object} map[string]interface{} "La oferta ha caducado"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/waitlist/offers/{id}/accept [post]
+func (h *Handler) AcceptWaitlistOffer(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidOfferIDFormat})
+        return
+    }
+
+    appointment, err := h.Waitlist.Accept(c.Request.Context(), uint(id))
+    if err != nil {
+        if scheduleError(c, err) {
+            return
+        }
+        offerError(c, err)
+        return
+    }
+
+    setETag(c, appointment.Version)
+    c.JSON(http.StatusCreated, appointment)
+}
+
+// DeclineWaitlistOffer rechaza una oferta de hueco
+// @Summary Rechaza una oferta
+// @Description Rechaza la oferta; la entrada vuelve a la lista de espera y el hueco se ofrece al siguiente candidato
+// @Tags Waitlist
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la oferta"
+// @Success 200 {object} map[string]interface{} "Oferta rechazada"
+// @Failure 400 {object} map[string]interface{} "Formato de ID inválido"
+// @Failure 404 {object} map[string]interface{} "Oferta no encontrada"
+// @Failure 409 {object} map[string]interface{} "La oferta ya no está pendiente"
+// @Failure 500 {object} map[string]interface{} "Error interno del servidor"
+// @Router /api/v1/waitlist/offers/{id}/decline [post]
+func (h *Handler) DeclineWaitlistOffer(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": InvalidOfferIDFormat})
+        return
+    }
+
+    if err := h.Waitlist.Decline(c.Request.Context(), uint(id)); err != nil {
+        offerError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Oferta rechazada"})
+}
+
+func waitlistError(c *gin.Context, err error) {
+    statusCode := http.StatusInternalServerError
+    errorMsg := InternalServerErrMsg
+
+    switch {
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        statusCode = http.StatusNotFound
+        errorMsg = WaitlistNotFoundMessage
+    case errors.Is(err, repositories.ErrWaitlistClosed):
+        statusCode = http.StatusConflict
+        errorMsg = WaitlistClosedMessage
+    }
+
+    c.JSON(statusCode, gin.H{"error": errorMsg})
+}
+
+func offerError(c *gin.Context, err error) {
+    statusCode := http.StatusInternalServerError
+    errorMsg := InternalServerErrMsg
+
+    switch {
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        statusCode = http.StatusNotFound
+        errorMsg = OfferNotFoundMessage
+    case errors.Is(err, repositories.ErrOfferClosed):
+        statusCode = http.StatusConflict
+        errorMsg = OfferClosedMessage
+    case errors.Is(err, repositories.ErrOfferExpired):
+        statusCode = http.StatusGone
+        errorMsg = OfferExpiredMessage
+    }
+
+    c.JSON(statusCode, gin.H{"error": errorMsg})
+}
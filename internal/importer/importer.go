@@ -0,0 +1,542 @@
+// Package importer carga clientes y mascotas desde ficheros CSV o NDJSON
+// exportados por otros programas de gestión.
+package importer
+
+import (
+    "bufio"
+    "encoding/csv"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "reflect"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin/binding"
+    "github.com/go-playground/validator/v10"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/tenant"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// Formatos admitidos
+const (
+    FormatCSV    = "csv"
+    FormatNDJSON = "ndjson"
+)
+
+// Tipos de registro que se pueden importar
+const (
+    KindClients = "clients"
+    KindPets    = "pets"
+)
+
+// ClientEmailField es la columna que enlaza cada mascota con su cliente.
+const ClientEmailField = "client_email"
+
+// IgnoreColumn como destino de un mapeo descarta esa columna.
+const IgnoreColumn = "-"
+
+// batchSize es el número de registros por INSERT al confirmar la importación.
+const batchSize = 100
+
+var (
+    ErrUnknownFormat = errors.New("formato desconocido; use csv o ndjson")
+    ErrUnknownKind   = errors.New("tipo desconocido; use clients o pets")
+    ErrMalformed     = errors.New("fichero mal formado")
+    // ErrInvalidRows se devuelve al confirmar una importación con errores
+    ErrInvalidRows = errors.New("hay filas con errores; no se ha importado nada")
+)
+
+// Campos de destino de cada tipo
+var fields = map[string][]string{
+    KindClients: {"name", "email", "phone", "address"},
+    KindPets:    {"name", "species", "breed", "birth_date", "birth_date_precision", "weight", "microchip", "description", ClientEmailField},
+}
+
+// Formatos de fecha aceptados en birth_date
+var dateLayouts = []string{"2006-01-02", time.RFC3339, "02/01/2006", "2/1/2006"}
+
+// Options configura una importación.
+type Options struct {
+    Format string
+    Kind   string
+    // Mapping traduce columnas del fichero a campos de destino
+    // (p. ej. "Correo" -> "email"). Las columnas sin traducción se usan
+    // con su nombre.
+    Mapping map[string]string
+    // DryRun valida todas las filas sin guardar nada
+    DryRun bool
+}
+
+// RowError es un error de validación de una fila.
+type RowError struct {
+    Row     int    `json:"row"`
+    Field   string `json:"field,omitempty"`
+    Message string `json:"message"`
+}
+
+// Report resume el resultado de una importación.
+type Report struct {
+    Kind     string     `json:"kind"`
+    DryRun   bool       `json:"dry_run"`
+    Total    int        `json:"total"`
+    Valid    int        `json:"valid"`
+    Invalid  int        `json:"invalid"`
+    Imported int        `json:"imported"`
+    Errors   []RowError `json:"errors"`
+}
+
+// row es una fila ya traducida a campos de destino, con su número de línea.
+// invalid indica que la fila no se pudo leer.
+type row struct {
+    line    int
+    values  map[string]string
+    invalid string
+}
+
+// Run lee el fichero, valida cada fila con las mismas reglas que la API
+// (las etiquetas binding de los modelos) y, si no es una simulación y no hay
+// errores, importa todas las filas en una única transacción.
+//
+// Los errores de las filas se devuelven en el informe; si se intenta
+// confirmar una importación con errores no se guarda nada y se devuelve
+// ErrInvalidRows junto con el informe.
+func Run(db *gorm.DB, r io.Reader, opts Options) (*Report, error) {
+    targets, ok := fields[opts.Kind]
+    if !ok {
+        return nil, ErrUnknownKind
+    }
+
+    var rows []row
+    var err error
+    switch opts.Format {
+    case FormatCSV:
+        rows, err = readCSV(r, opts.Mapping)
+    case FormatNDJSON:
+        rows, err = readNDJSON(r, opts.Mapping)
+    default:
+        return nil, ErrUnknownFormat
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    report := &Report{Kind: opts.Kind, DryRun: opts.DryRun, Total: len(rows), Errors: []RowError{}}
+    report.Errors = append(report.Errors, unknownColumns(rows, targets)...)
+
+    var records interface{}
+    switch opts.Kind {
+    case KindClients:
+        var clients []models.Client
+        clients, err = buildClients(db, rows, report)
+        records = &clients
+    case KindPets:
+        var pets []models.Pet
+        pets, err = buildPets(db, rows, report)
+        records = &pets
+    }
+    if err != nil {
+        return nil, err
+    }
+    report.Valid = report.Total - report.Invalid
+
+    if opts.DryRun {
+        return report, nil
+    }
+    if len(report.Errors) > 0 {
+        return report, ErrInvalidRows
+    }
+    if report.Valid == 0 {
+        return report, nil
+    }
+
+    err = db.Transaction(func(tx *gorm.DB) error {
+        return tx.Omit(clause.Associations).CreateInBatches(records, batchSize).Error
+    })
+    if err != nil {
+        return nil, err
+    }
+    report.Imported = report.Valid
+
+    return report, nil
+}
+
+func readCSV(r io.Reader, mapping map[string]string) ([]row, error) {
+    reader := csv.NewReader(r)
+    reader.TrimLeadingSpace = true
+    reader.FieldsPerRecord = -1
+
+    header, err := reader.Read()
+    if err != nil {
+        return nil, fmt.Errorf("%w: no se puede leer la cabecera CSV: %v", ErrMalformed, err)
+    }
+    // Las hojas de cálculo suelen guardar el CSV con BOM
+    if len(header) > 0 {
+        header[0] = strings.TrimPrefix(header[0], "\ufeff")
+    }
+
+    var rows []row
+    for line := 2; ; line++ {
+        record, err := reader.Read()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+        }
+
+        values := make(map[string]string, len(header))
+        for i, column := range header {
+            if field := target(column, mapping); i < len(record) && field != IgnoreColumn {
+                values[field] = strings.TrimSpace(record[i])
+            }
+        }
+        rows = append(rows, row{line: line, values: values})
+    }
+    return rows, nil
+}
+
+func readNDJSON(r io.Reader, mapping map[string]string) ([]row, error) {
+    scanner := bufio.NewScanner(r)
+    scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+    var rows []row
+    for line := 1; scanner.Scan(); line++ {
+        text := strings.TrimSpace(scanner.Text())
+        if text == "" {
+            continue
+        }
+
+        var object map[string]interface{}
+        if err := json.Unmarshal([]byte(text), &object); err != nil {
+            // Una línea ilegible es un error de esa fila, no de todo el fichero
+            rows = append(rows, row{line: line, invalid: "JSON inválido: " + err.Error()})
+            continue
+        }
+
+        values := make(map[string]string, len(object))
+        for key, value := range object {
+            field := target(key, mapping)
+            if field == IgnoreColumn {
+                continue
+            }
+
+            switch v := value.(type) {
+            case nil:
+                values[field] = ""
+            case string:
+                values[field] = strings.TrimSpace(v)
+            default:
+                encoded, _ := json.Marshal(v)
+                values[field] = string(encoded)
+            }
+        }
+        rows = append(rows, row{line: line, values: values})
+    }
+    if err := scanner.Err(); err != nil {
+        return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+    }
+    return rows, nil
+}
+
+func target(column string, mapping map[string]string) string {
+    column = strings.TrimSpace(column)
+    if mapped, ok := mapping[column]; ok {
+        return mapped
+    }
+    return strings.ToLower(column)
+}
+
+// unknownColumns avisa de las columnas que no corresponden a ningún campo,
+// que suelen indicar un mapeo olvidado. Cada una se indica una vez, en la
+// primera fila en que aparece.
+func unknownColumns(rows []row, targets []string) []RowError {
+    known := make(map[string]bool, len(targets))
+    for _, t := range targets {
+        known[t] = true
+    }
+
+    var errs []RowError
+    for _, r := range rows {
+        for column := range r.values {
+            if !known[column] {
+                known[column] = true
+                errs = append(errs, RowError{Row: r.line, Field: column, Message: "columna desconocida; añada un mapeo o descártela con \"-\""})
+            }
+        }
+    }
+    sort.Slice(errs, func(i, j int) bool {
+        if errs[i].Row != errs[j].Row {
+            return errs[i].Row < errs[j].Row
+        }
+        return errs[i].Field < errs[j].Field
+    })
+    return errs
+}
+
+func buildClients(db *gorm.DB, rows []row, report *Report) ([]models.Client, error) {
+    existing, err := takenEmails(db)
+    if err != nil {
+        return nil, err
+    }
+
+    seen := make(map[string]int)
+    clients := make([]models.Client, 0, len(rows))
+    for _, r := range rows {
+        if failed(r, report) {
+            continue
+        }
+
+        client := models.Client{
+            Name:    r.values["name"],
+            Email:   r.values["email"],
+            Phone:   r.values["phone"],
+            Address: r.values["address"],
+            Version: 1,
+        }
+
+        errs := validate(r.line, &client)
+        email := strings.ToLower(client.Email)
+        if archived, ok := existing[email]; ok && email != "" {
+            message := "ya existe un cliente con este email"
+            if archived {
+                message = "ya existe un cliente archivado con este email"
+            }
+            errs = append(errs, RowError{Row: r.line, Field: "email", Message: message})
+        } else if first, ok := seen[email]; ok && email != "" {
+            errs = append(errs, RowError{Row: r.line, Field: "email", Message: fmt.Sprintf("email repetido en la fila %d", first)})
+        }
+        seen[email] = r.line
+
+        if record(errs, report) {
+            clients = append(clients, client)
+        }
+    }
+    return clients, nil
+}
+
+func buildPets(db *gorm.DB, rows []row, report *Report) ([]models.Pet, error) {
+    clients, err := clientsByEmail(db)
+    if err != nil {
+        return nil, err
+    }
+    var catalog []models.Species
+    if err := db.Preload("Breeds").Find(&catalog).Error; err != nil {
+        return nil, err
+    }
+    chips, err := existingMicrochips(db)
+    if err != nil {
+        return nil, err
+    }
+
+    seen := make(map[string]int)
+    pets := make([]models.Pet, 0, len(rows))
+    for _, r := range rows {
+        if failed(r, report) {
+            continue
+        }
+
+        var errs []RowError
+        pet := models.Pet{
+            Name:        r.values["name"],
+            Species:     r.values["species"],
+            Breed:       r.values["breed"],
+            BirthDatePrecision: r.values["birth_date_precision"],
+            Description: r.values["description"],
+            Version:     1,
+        }
+
+        // La especie y la raza se guardan con su nombre del catálogo
+        if species, ok := models.FindSpecies(catalog, pet.Species); ok {
+            pet.Species = species.Name
+            if breed, ok := species.FindBreed(pet.Breed); ok {
+                pet.Breed = breed.Name
+            } else if pet.Breed != "" {
+                errs = append(errs, RowError{Row: r.line, Field: "breed", Message: "raza desconocida para esta especie"})
+            }
+        } else if pet.Species != "" {
+            errs = append(errs, RowError{Row: r.line, Field: "species", Message: "especie desconocida"})
+        }
+
+        if value := r.values["birth_date"]; value != "" {
+            date, ok := parseDate(value)
+            if !ok {
+                errs = append(errs, RowError{Row: r.line, Field: "birth_date", Message: "fecha inválida; use AAAA-MM-DD o DD/MM/AAAA"})
+            }
+            pet.BirthDate = date
+        }
+
+        if value := r.values["weight"]; value != "" {
+            weight, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+            if err != nil || weight < 0 {
+                errs = append(errs, RowError{Row: r.line, Field: "weight", Message: "peso inválido"})
+            }
+            pet.Weight = weight
+        }
+
+        if value := r.values["microchip"]; value != "" {
+            chip, ok := models.NormalizeMicrochip(value)
+            if !ok {
+                errs = append(errs, RowError{Row: r.line, Field: "microchip", Message: "microchip inválido; debe tener 15 dígitos"})
+            } else if _, ok := chips[chip]; ok {
+                errs = append(errs, RowError{Row: r.line, Field: "microchip", Message: "ya existe una mascota con este microchip"})
+            } else if first, ok := seen[chip]; ok {
+                errs = append(errs, RowError{Row: r.line, Field: "microchip", Message: fmt.Sprintf("microchip repetido en la fila %d", first)})
+            } else {
+                seen[chip] = r.line
+            }
+            pet.Microchip = chip
+        }
+
+        email := strings.ToLower(r.values[ClientEmailField])
+        if email == "" {
+            errs = append(errs, RowError{Row: r.line, Field: ClientEmailField, Message: "es obligatorio"})
+        } else if id, ok := clients[email]; ok {
+            pet.ClientID = id
+        } else {
+            errs = append(errs, RowError{Row: r.line, Field: ClientEmailField, Message: "no existe ningún cliente con este email"})
+        }
+
+        if pet.ClientID != 0 {
+            errs = append(errs, validate(r.line, &pet)...)
+        } else {
+            // Sin cliente la validación repetiría el error de client_id
+            errs = append(errs, withoutField(validate(r.line, &pet), "client_id")...)
+        }
+
+        if record(errs, report) {
+            pet.NormalizeBirthDate()
+            pets = append(pets, pet)
+        }
+    }
+    return pets, nil
+}
+
+// clientsByEmail devuelve el ID de cada cliente activo de la clínica por su
+// email en minúsculas.
+func clientsByEmail(db *gorm.DB) (map[string]uint, error) {
+    var clients []models.Client
+    if err := db.Select("id", "email").Find(&clients).Error; err != nil {
+        return nil, err
+    }
+
+    emails := make(map[string]uint, len(clients))
+    for _, c := range clients {
+        emails[strings.ToLower(c.Email)] = c.ID
+    }
+    return emails, nil
+}
+
+// takenEmails devuelve los emails en minúsculas de todos los clientes, de
+// cualquier clínica y también de los archivados, que no se pueden repetir,
+// indicando si el cliente está archivado.
+func takenEmails(db *gorm.DB) (map[string]bool, error) {
+    var clients []models.Client
+    err := tenant.AllClinics(db, tenant.ReasonUniqueness).Unscoped().
+        Select("email", "deleted_at").Find(&clients).Error
+    if err != nil {
+        return nil, err
+    }
+
+    emails := make(map[string]bool, len(clients))
+    for _, c := range clients {
+        emails[strings.ToLower(c.Email)] = c.DeletedAt.Valid
+    }
+    return emails, nil
+}
+
+// existingMicrochips devuelve los microchips de todas las mascotas, de
+// cualquier clínica y también de las archivadas.
+func existingMicrochips(db *gorm.DB) (map[string]struct{}, error) {
+    var values []string
+    err := tenant.AllClinics(db, tenant.ReasonUniqueness).Unscoped().
+        Model(&models.Pet{}).Where("microchip <> ''").Pluck("microchip", &values).Error
+    if err != nil {
+        return nil, err
+    }
+
+    chips := make(map[string]struct{}, len(values))
+    for _, v := range values {
+        chips[v] = struct{}{}
+    }
+    return chips, nil
+}
+
+// failed registra las filas que no se pudieron leer.
+func failed(r row, report *Report) bool {
+    if r.invalid == "" {
+        return false
+    }
+    record([]RowError{{Row: r.line, Message: r.invalid}}, report)
+    return true
+}
+
+// record añade los errores al informe y devuelve true si la fila es válida.
+func record(errs []RowError, report *Report) bool {
+    if len(errs) == 0 {
+        return true
+    }
+    report.Invalid++
+    report.Errors = append(report.Errors, errs...)
+    return false
+}
+
+func parseDate(value string) (time.Time, bool) {
+    for _, layout := range dateLayouts {
+        if date, err := time.Parse(layout, value); err == nil {
+            return date, true
+        }
+    }
+    return time.Time{}, false
+}
+
+// validate aplica las reglas binding del modelo y devuelve un error por campo
+// con el nombre JSON del campo.
+func validate(line int, model interface{}) []RowError {
+    err := binding.Validator.ValidateStruct(model)
+    if err == nil {
+        return nil
+    }
+
+    var validationErrors validator.ValidationErrors
+    if !errors.As(err, &validationErrors) {
+        return []RowError{{Row: line, Message: err.Error()}}
+    }
+
+    modelType := reflect.TypeOf(model).Elem()
+    errs := make([]RowError, 0, len(validationErrors))
+    for _, fe := range validationErrors {
+        field := fe.Field()
+        if f, ok := modelType.FieldByName(fe.StructField()); ok {
+            field = strings.Split(f.Tag.Get("json"), ",")[0]
+        }
+        errs = append(errs, RowError{Row: line, Field: field, Message: ruleMessage(fe)})
+    }
+    return errs
+}
+
+func ruleMessage(fe validator.FieldError) string {
+    switch fe.Tag() {
+    case "required":
+        return "es obligatorio"
+    case "email":
+        return "no es un email válido"
+    default:
+        return "no cumple la regla " + fe.Tag()
+    }
+}
+
+func withoutField(errs []RowError, field string) []RowError {
+    kept := errs[:0]
+    for _, e := range errs {
+        if e.Field != field {
+            kept = append(kept, e)
+        }
+    }
+    return kept
+}
@@ -0,0 +1,108 @@
+package logging
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "log/slog"
+    "time"
+
+    gormlogger "gorm.io/gorm/logger"
+    "gorm.io/gorm"
+)
+
+// DefaultSlowQuery es la duración a partir de la cual una consulta se
+// registra como lenta
+const DefaultSlowQuery = 200 * time.Millisecond
+
+// GormLogger envía los logs de GORM a slog. Las consultas se registran en
+// nivel debug, las lentas en warn y los errores en error, siempre con los
+// emails y teléfonos de sus parámetros ocultos.
+type GormLogger struct {
+    Logger    *slog.Logger
+    SlowQuery time.Duration
+    level     gormlogger.LogLevel
+}
+
+func NewGormLogger(logger *slog.Logger) *GormLogger {
+    return &GormLogger{Logger: logger, SlowQuery: DefaultSlowQuery, level: gormlogger.Info}
+}
+
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+    clone := *l
+    clone.level = level
+    return &clone
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+    if l.level >= gormlogger.Info {
+        l.Logger.InfoContext(ctx, fmt.Sprintf(msg, args...))
+    }
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+    if l.level >= gormlogger.Warn {
+        l.Logger.WarnContext(ctx, fmt.Sprintf(msg, args...))
+    }
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+    if l.level >= gormlogger.Error {
+        l.Logger.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+    }
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+    if l.level <= gormlogger.Silent {
+        return
+    }
+
+    elapsed := time.Since(begin)
+    level := slog.LevelDebug
+    switch {
+    case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+        level = slog.LevelError
+    case l.SlowQuery > 0 && elapsed > l.SlowQuery && l.level >= gormlogger.Warn:
+        level = slog.LevelWarn
+    case l.level < gormlogger.Info:
+        return
+    }
+    if !l.Logger.Enabled(ctx, level) {
+        return
+    }
+
+    sql, rows := fc()
+    attrs := []slog.Attr{
+        slog.String("sql", sql),
+        slog.Int64("rows", rows),
+        slog.Duration("elapsed", elapsed),
+    }
+    message := "consulta SQL"
+    if level == slog.LevelError {
+        attrs = append(attrs, slog.String("error", Redact(err.Error())))
+        message = "error en consulta SQL"
+    } else if level == slog.LevelWarn {
+        message = "consulta SQL lenta"
+    }
+    l.Logger.LogAttrs(ctx, level, message, attrs...)
+}
+
+// ParamsFilter oculta los datos personales de los parámetros antes de que
+// GORM los interpole en la consulta que se registra.
+func (l *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+    filtered := make([]interface{}, len(params))
+    for i, param := range params {
+        switch v := param.(type) {
+        case string:
+            filtered[i] = Redact(v)
+        case json.RawMessage:
+            filtered[i] = Redact(string(v))
+        case []byte:
+            filtered[i] = Redact(string(v))
+        default:
+            filtered[i] = param
+        }
+    }
+    return sql, filtered
+}
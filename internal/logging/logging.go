@@ -0,0 +1,123 @@
+// Package logging configura los logs estructurados (log/slog) de la API:
+// JSON en producción, texto legible en desarrollo, el ID de la petición en
+// cada línea y datos personales ocultos.
+package logging
+
+import (
+    "context"
+    "io"
+    "log/slog"
+    "regexp"
+    "strings"
+)
+
+// RequestIDKey es el atributo con el ID de la petición en cada línea de log.
+const RequestIDKey = "request_id"
+
+// Redacted sustituye a los datos personales en los logs.
+const Redacted = "[REDACTED]"
+
+// Config configura el logger.
+type Config struct {
+    Level slog.Level
+    // Pretty escribe texto legible en lugar de JSON
+    Pretty bool
+}
+
+// New crea un logger que escribe en w y añade a cada línea el ID de la
+// petición si el contexto lo tiene.
+func New(w io.Writer, cfg Config) *slog.Logger {
+    opts := &slog.HandlerOptions{Level: cfg.Level}
+
+    var handler slog.Handler
+    if cfg.Pretty {
+        handler = slog.NewTextHandler(w, opts)
+    } else {
+        handler = slog.NewJSONHandler(w, opts)
+    }
+    return slog.New(contextHandler{handler})
+}
+
+type requestIDKey struct{}
+
+// WithRequestID devuelve un contexto con el ID de la petición.
+func WithRequestID(ctx context.Context, id string) context.Context {
+    return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext devuelve el ID de la petición o "" si no hay ninguno.
+func RequestIDFromContext(ctx context.Context) string {
+    if ctx != nil {
+        if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+            return id
+        }
+    }
+    return ""
+}
+
+// contextHandler añade el ID de la petición a los registros que se emiten
+// con un contexto (slog.InfoContext, etc.).
+type contextHandler struct {
+    slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+    if id := RequestIDFromContext(ctx); id != "" {
+        record.AddAttrs(slog.String(RequestIDKey, id))
+    }
+    return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+    return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+    return contextHandler{h.Handler.WithGroup(name)}
+}
+
+var (
+    emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+    phonePattern = regexp.MustCompile(`\+?\d[\d\s().-]{6,}\d`)
+    datePattern  = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}`)
+)
+
+// minPhoneDigits es el número mínimo de dígitos para considerar que un
+// número es un teléfono
+const minPhoneDigits = 9
+
+// Redact oculta los emails y teléfonos que aparezcan en el texto.
+func Redact(text string) string {
+    text = emailPattern.ReplaceAllString(text, Redacted)
+
+    matches := phonePattern.FindAllStringIndex(text, -1)
+    if matches == nil {
+        return text
+    }
+
+    var b strings.Builder
+    last := 0
+    for _, m := range matches {
+        candidate := text[m[0]:m[1]]
+        // Las fechas y horas también son secuencias de dígitos
+        if datePattern.MatchString(candidate) || (m[1] < len(text) && text[m[1]] == ':') ||
+            countDigits(candidate) < minPhoneDigits {
+            continue
+        }
+        b.WriteString(text[last:m[0]])
+        b.WriteString(Redacted)
+        last = m[1]
+    }
+    b.WriteString(text[last:])
+    return b.String()
+}
+
+func countDigits(text string) int {
+    n := 0
+    for _, r := range text {
+        if r >= '0' && r <= '9' {
+            n++
+        }
+    }
+    return n
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/prometheus/client_golang/prometheus"
+    "gorm.io/gorm"
+)
+
+// NoShowWindow es el periodo sobre el que se calcula la tasa de ausencias
+const NoShowWindow = 30 * 24 * time.Hour
+
+// businessCollector calcula los indicadores de negocio en cada consulta de
+// /metrics, de modo que siempre reflejan el estado de la base de datos.
+type businessCollector struct {
+    db  *gorm.DB
+    now func() time.Time
+
+    appointmentsToday *prometheus.Desc
+    noShowRatio       *prometheus.Desc
+    activeClients     *prometheus.Desc
+    errors            *prometheus.Desc
+}
+
+func newBusinessCollector(db *gorm.DB) *businessCollector {
+    return &businessCollector{
+        db:  db,
+        now: time.Now,
+        appointmentsToday: prometheus.NewDesc(prometheus.BuildFQName(Namespace, "", "appointments_today"),
+            "Citas activas programadas para hoy.", nil, nil),
+        noShowRatio: prometheus.NewDesc(prometheus.BuildFQName(Namespace, "", "appointments_no_show_ratio"),
+            "Proporción de citas no canceladas de los últimos 30 días que ya han pasado sin completarse.", nil, nil),
+        activeClients: prometheus.NewDesc(prometheus.BuildFQName(Namespace, "", "clients_active"),
+            "Clientes no archivados.", nil, nil),
+        errors: prometheus.NewDesc(prometheus.BuildFQName(Namespace, "", "business_metrics_errors"),
+            "1 si ha fallado el cálculo de algún indicador de negocio.", nil, nil),
+    }
+}
+
+func (b *businessCollector) Describe(ch chan<- *prometheus.Desc) {
+    ch <- b.appointmentsToday
+    ch <- b.noShowRatio
+    ch <- b.activeClients
+    ch <- b.errors
+}
+
+func (b *businessCollector) Collect(ch chan<- prometheus.Metric) {
+    now := b.now()
+    failed := 0.0
+
+    dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+    var today int64
+    if err := b.db.Model(&models.Appointment{}).
+        Where("date >= ? AND date < ?", dayStart, dayStart.AddDate(0, 0, 1)).
+        Count(&today).Error; err != nil {
+        failed = 1
+    } else {
+        ch <- prometheus.MustNewConstMetric(b.appointmentsToday, prometheus.GaugeValue, float64(today))
+    }
+
+    // Una cita cuenta como ausencia si su hora ya ha pasado y no se ha
+    // marcado como completada. Las canceladas no cuentan
+    var past, noShows int64
+    window := b.db.Model(&models.Appointment{}).
+        Where("date >= ? AND date < ? AND status <> ?", now.Add(-NoShowWindow), now, models.AppointmentCancelled)
+    if err := window.Session(&gorm.Session{}).Count(&past).Error; err != nil {
+        failed = 1
+    } else if err := window.Session(&gorm.Session{}).Where("completed = ?", false).Count(&noShows).Error; err != nil {
+        failed = 1
+    } else {
+        ratio := 0.0
+        if past > 0 {
+            ratio = float64(noShows) / float64(past)
+        }
+        ch <- prometheus.MustNewConstMetric(b.noShowRatio, prometheus.GaugeValue, ratio)
+    }
+
+    var clients int64
+    if err := b.db.Model(&models.Client{}).Count(&clients).Error; err != nil {
+        failed = 1
+    } else {
+        ch <- prometheus.MustNewConstMetric(b.activeClients, prometheus.GaugeValue, float64(clients))
+    }
+
+    ch <- prometheus.MustNewConstMetric(b.errors, prometheus.GaugeValue, failed)
+}
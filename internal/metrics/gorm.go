@@ -0,0 +1,62 @@
+package metrics
+
+import (
+    "errors"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "gorm.io/gorm"
+)
+
+// startKey guarda en la instancia de GORM el inicio de la consulta
+const startKey = "metrics:start"
+
+// gormPlugin mide la duración de cada operación de GORM.
+type gormPlugin struct {
+    duration *prometheus.HistogramVec
+}
+
+func (p *gormPlugin) Name() string {
+    return "metrics"
+}
+
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+    callbacks := db.Callback()
+    return errors.Join(
+        callbacks.Create().Before("gorm:create").Register("metrics:before_create", p.before),
+        callbacks.Create().After("gorm:create").Register("metrics:after_create", p.after("create")),
+        callbacks.Query().Before("gorm:query").Register("metrics:before_query", p.before),
+        callbacks.Query().After("gorm:query").Register("metrics:after_query", p.after("query")),
+        callbacks.Update().Before("gorm:update").Register("metrics:before_update", p.before),
+        callbacks.Update().After("gorm:update").Register("metrics:after_update", p.after("update")),
+        callbacks.Delete().Before("gorm:delete").Register("metrics:before_delete", p.before),
+        callbacks.Delete().After("gorm:delete").Register("metrics:after_delete", p.after("delete")),
+        callbacks.Row().Before("gorm:row").Register("metrics:before_row", p.before),
+        callbacks.Row().After("gorm:row").Register("metrics:after_row", p.after("row")),
+        callbacks.Raw().Before("gorm:raw").Register("metrics:before_raw", p.before),
+        callbacks.Raw().After("gorm:raw").Register("metrics:after_raw", p.after("raw")),
+    )
+}
+
+func (p *gormPlugin) before(db *gorm.DB) {
+    db.InstanceSet(startKey, time.Now())
+}
+
+func (p *gormPlugin) after(operation string) func(*gorm.DB) {
+    return func(db *gorm.DB) {
+        value, ok := db.InstanceGet(startKey)
+        if !ok {
+            return
+        }
+        start, ok := value.(time.Time)
+        if !ok {
+            return
+        }
+
+        table := db.Statement.Table
+        if table == "" {
+            table = "-"
+        }
+        p.duration.WithLabelValues(operation, table).Observe(time.Since(start).Seconds())
+    }
+}
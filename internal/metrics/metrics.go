@@ -0,0 +1,109 @@
+// Package metrics publica métricas de Prometheus de la API: peticiones
+// HTTP, consultas a la base de datos, el pool de conexiones e indicadores
+// de negocio de la clínica.
+package metrics
+
+import (
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/collectors"
+    "github.com/prometheus/client_golang/prometheus/promhttp"
+    "gorm.io/gorm"
+)
+
+// Namespace es el prefijo de las métricas propias de la API
+const Namespace = "vetclinic"
+
+// Path es la ruta en la que se publican las métricas
+const Path = "/metrics"
+
+// unmatchedRoute agrupa las peticiones que no corresponden a ninguna ruta,
+// para no crear una serie por cada URL desconocida
+const unmatchedRoute = "unmatched"
+
+// Metrics agrupa el registro de Prometheus y las métricas que se actualizan
+// en cada petición.
+type Metrics struct {
+    Registry *prometheus.Registry
+
+    requests *prometheus.CounterVec
+    latency  *prometheus.HistogramVec
+}
+
+// New registra las métricas de la API sobre db: duración de las consultas
+// (mediante callbacks de GORM), estadísticas del pool de conexiones e
+// indicadores de negocio, que se calculan al consultar /metrics.
+func New(db *gorm.DB) (*Metrics, error) {
+    m := &Metrics{
+        Registry: prometheus.NewRegistry(),
+        requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Namespace: Namespace,
+            Name:      "http_requests_total",
+            Help:      "Peticiones HTTP atendidas por método, ruta y estado.",
+        }, []string{"method", "route", "status"}),
+        latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+            Namespace: Namespace,
+            Name:      "http_request_duration_seconds",
+            Help:      "Duración de las peticiones HTTP por método, ruta y estado.",
+            Buckets:   prometheus.DefBuckets,
+        }, []string{"method", "route", "status"}),
+    }
+
+    sqlDB, err := db.DB()
+    if err != nil {
+        return nil, err
+    }
+
+    queries := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+        Namespace: Namespace,
+        Name:      "db_query_duration_seconds",
+        Help:      "Duración de las consultas a la base de datos por operación y tabla.",
+        Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+    }, []string{"operation", "table"})
+    if err := db.Use(&gormPlugin{duration: queries}); err != nil {
+        return nil, err
+    }
+
+    for _, collector := range []prometheus.Collector{
+        m.requests,
+        m.latency,
+        queries,
+        collectors.NewDBStatsCollector(sqlDB, db.Dialector.Name()),
+        newBusinessCollector(db),
+        collectors.NewGoCollector(),
+        collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+    } {
+        if err := m.Registry.Register(collector); err != nil {
+            return nil, err
+        }
+    }
+
+    return m, nil
+}
+
+// Middleware mide cada petición. La ruta es la plantilla de Gin
+// (/api/v1/clients/:id), no la URL concreta.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        start := time.Now()
+        c.Next()
+
+        route := c.FullPath()
+        if route == "" {
+            route = unmatchedRoute
+        }
+        status := strconv.Itoa(c.Writer.Status())
+
+        m.requests.WithLabelValues(c.Request.Method, route, status).Inc()
+        m.latency.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+    }
+}
+
+// Handler devuelve el manejador HTTP que publica las métricas.
+func (m *Metrics) Handler() http.Handler {
+    return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{Registry: m.Registry})
+}
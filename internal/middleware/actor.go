@@ -0,0 +1,26 @@
+// internal/middleware/actor.go
+package middleware
+
+import (
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/audit"
+    "github.com/javice/vet-clinic-api/internal/auth"
+)
+
+// AnonymousActor es el actor de las peticiones que no se identifican
+const AnonymousActor = "anonymous"
+
+// Actor guarda en el contexto de la petición quién la realiza, para que la
+// auditoría pueda atribuir los cambios. El actor es siempre el principal
+// autenticado; nada de lo que envíe el cliente lo cambia.
+func Actor() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        actor := AnonymousActor
+        if principal, ok := auth.PrincipalFromContext(c.Request.Context()); ok {
+            actor = principal.Actor()
+        }
+
+        c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), actor))
+        c.Next()
+    }
+}
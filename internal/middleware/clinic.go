@@ -0,0 +1,103 @@
+// internal/middleware/clinic.go
+package middleware
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/auth"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "github.com/javice/vet-clinic-api/internal/tenant"
+    "github.com/javice/vet-clinic-api/internal/timezone"
+    "gorm.io/gorm"
+)
+
+// ClinicHeader indica la clínica (sede) en la que se trabaja
+const ClinicHeader = "X-Clinic-ID"
+
+// Clinic resuelve la clínica de la petición y limita a ella todas las
+// consultas. La de una clave de API es siempre la suya; la cabecera
+// X-Clinic-ID solo se acepta si el principal puede usar esa clínica: los
+// administradores cualquiera y las claves, la suya. Las fechas se muestran en
+// la zona horaria de la clínica o, si no indica ninguna, en loc (la del
+// sistema si es nil). Sin clínica, RequireClinic decide si la petición
+// continúa.
+func Clinic(repo *repositories.ClinicRepository, loc *time.Location) gin.HandlerFunc {
+    if loc == nil {
+        loc = time.Local
+    }
+    return func(c *gin.Context) {
+        ctx := timezone.WithLocation(c.Request.Context(), loc)
+
+        var clinicID uint
+        if header := c.GetHeader(ClinicHeader); header != "" {
+            id, err := strconv.ParseUint(header, 10, 32)
+            if err != nil || id == 0 {
+                c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Formato de " + ClinicHeader + " NO válido"})
+                return
+            }
+            clinicID = uint(id)
+        }
+
+        principal, _ := auth.PrincipalFromContext(ctx)
+        if principal.Role != auth.RoleAdmin {
+            if clinicID != 0 && (principal.ClinicID == nil || clinicID != *principal.ClinicID) {
+                c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "No tiene acceso a esa clínica"})
+                return
+            }
+            if principal.ClinicID != nil {
+                clinicID = *principal.ClinicID
+            }
+        }
+
+        if clinicID == 0 {
+            c.Request = c.Request.WithContext(ctx)
+            c.Next()
+            return
+        }
+
+        clinic, err := repo.WithContext(ctx).GetByID(clinicID)
+        if err != nil {
+            if errors.Is(err, gorm.ErrRecordNotFound) {
+                c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "La clínica no existe"})
+            } else {
+                c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+            }
+            return
+        }
+
+        ctx = tenant.WithClinic(ctx, clinicID)
+        if clinic.TimeZone != "" {
+            if clinicLoc, err := timezone.Load(clinic.TimeZone); err == nil {
+                ctx = timezone.WithLocation(ctx, clinicLoc)
+            }
+        }
+        c.Request = c.Request.WithContext(ctx)
+        c.Next()
+    }
+}
+
+// RequireClinic protege los datos de las clínicas: rechaza las peticiones
+// sin clínica salvo las de los administradores si allowAll es true, que ven
+// las de todo el grupo.
+func RequireClinic(allowAll bool) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        ctx := c.Request.Context()
+        if _, ok := tenant.ClinicFromContext(ctx); !ok {
+            principal, _ := auth.PrincipalFromContext(ctx)
+            switch {
+            case principal.Role == auth.RoleAdmin && allowAll:
+            case principal.Type == auth.PrincipalAPIKey:
+                c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "La clave de API no está ligada a ninguna clínica"})
+                return
+            default:
+                c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Se requiere la cabecera " + ClinicHeader})
+                return
+            }
+        }
+        c.Next()
+    }
+}
@@ -0,0 +1,187 @@
+// internal/middleware/cors.go
+package middleware
+
+import (
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin"
+)
+
+// CORSPolicy define qué orígenes pueden llamar a la API desde el navegador y
+// con qué condiciones.
+type CORSPolicy struct {
+    // AllowedOrigins admite orígenes exactos (https://clinica.example.com),
+    // subdominios comodín (https://*.example.com) o "*" para cualquiera
+    AllowedOrigins []string
+    // AllowCredentials permite enviar cookies y cabeceras de autenticación.
+    // No puede combinarse con "*": el origen se devuelve siempre explícito
+    AllowCredentials bool
+    AllowedMethods   []string
+    AllowedHeaders   []string
+    ExposedHeaders   []string
+    // MaxAge es el tiempo que el navegador puede cachear la respuesta a una
+    // petición de comprobación previa (preflight)
+    MaxAge time.Duration
+}
+
+// DefaultCORSPolicy permite cualquier origen sin credenciales.
+func DefaultCORSPolicy() CORSPolicy {
+    return CORSPolicy{AllowedOrigins: []string{"*"}}
+}
+
+var (
+    defaultCORSMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+    defaultCORSHeaders = []string{"Content-Type", "Authorization", "If-Match", "If-None-Match", "X-API-Key", "X-Request-ID"}
+    // defaultCORSExposed son las cabeceras de respuesta que puede leer el
+    // navegador
+    defaultCORSExposed = []string{"ETag", "Link", "Location", "Content-Disposition", "X-Request-ID",
+        "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After"}
+)
+
+// CORS aplica a cada petición la política cuyo prefijo de ruta coincide de
+// forma más larga; la política con prefijo "" se aplica al resto. Las
+// peticiones de comprobación previa se responden directamente con 204.
+func CORS(policies map[string]CORSPolicy) gin.HandlerFunc {
+    prepared := make(map[string]corsPolicy, len(policies))
+    for prefix, policy := range policies {
+        prepared[prefix] = newCORSPolicy(policy)
+    }
+
+    return func(c *gin.Context) {
+        policy, ok := prepared[matchPrefix(prepared, c.Request.URL.Path)]
+        if !ok {
+            c.Next()
+            return
+        }
+
+        header := c.Writer.Header()
+        // La respuesta depende del origen aunque no se permita, para que
+        // las cachés no la compartan entre orígenes
+        header.Add("Vary", "Origin")
+
+        origin := c.GetHeader("Origin")
+        value, allowed := policy.allowOrigin(origin)
+        if allowed {
+            header.Set("Access-Control-Allow-Origin", value)
+            if policy.credentials {
+                header.Set("Access-Control-Allow-Credentials", "true")
+            }
+            header.Set("Access-Control-Expose-Headers", policy.exposed)
+        }
+
+        if c.Request.Method == http.MethodOptions {
+            if allowed {
+                header.Add("Vary", "Access-Control-Request-Method")
+                header.Add("Vary", "Access-Control-Request-Headers")
+                header.Set("Access-Control-Allow-Methods", policy.methods)
+                header.Set("Access-Control-Allow-Headers", policy.headers)
+                if policy.maxAge != "" {
+                    header.Set("Access-Control-Max-Age", policy.maxAge)
+                }
+            }
+            c.AbortWithStatus(http.StatusNoContent)
+            return
+        }
+
+        c.Next()
+    }
+}
+
+// corsPolicy es una CORSPolicy con las cabeceras ya calculadas.
+type corsPolicy struct {
+    any         bool
+    exact       map[string]bool
+    suffixes    []corsWildcard
+    credentials bool
+    methods     string
+    headers     string
+    exposed     string
+    maxAge      string
+}
+
+// corsWildcard es un origen con subdominio comodín, como https://*.example.com
+type corsWildcard struct {
+    scheme string
+    suffix string
+}
+
+func newCORSPolicy(policy CORSPolicy) corsPolicy {
+    p := corsPolicy{
+        exact:       map[string]bool{},
+        credentials: policy.AllowCredentials,
+        methods:     strings.Join(orDefault(policy.AllowedMethods, defaultCORSMethods), ", "),
+        headers:     strings.Join(orDefault(policy.AllowedHeaders, defaultCORSHeaders), ", "),
+        exposed:     strings.Join(orDefault(policy.ExposedHeaders, defaultCORSExposed), ", "),
+    }
+    if policy.MaxAge > 0 {
+        p.maxAge = strconv.Itoa(int(policy.MaxAge.Seconds()))
+    }
+
+    for _, origin := range policy.AllowedOrigins {
+        origin = strings.ToLower(strings.TrimRight(origin, "/"))
+        switch {
+        case origin == "*":
+            p.any = true
+        case strings.Contains(origin, "://*."):
+            scheme, host, _ := strings.Cut(origin, "://*")
+            p.suffixes = append(p.suffixes, corsWildcard{scheme: scheme + "://", suffix: host})
+        default:
+            p.exact[origin] = true
+        }
+    }
+    return p
+}
+
+// allowOrigin devuelve el valor de Access-Control-Allow-Origin para el
+// origen de la petición, o false si no está permitido.
+func (p corsPolicy) allowOrigin(origin string) (string, bool) {
+    if p.any && !p.credentials {
+        return "*", true
+    }
+    if origin == "" {
+        return "", false
+    }
+    if p.any {
+        return origin, true
+    }
+
+    normalized := strings.ToLower(origin)
+    if p.exact[normalized] {
+        return origin, true
+    }
+    for _, w := range p.suffixes {
+        host, ok := strings.CutPrefix(normalized, w.scheme)
+        // El comodín exige al menos un subdominio: *.example.com no
+        // incluye example.com
+        if ok && strings.HasSuffix(host, w.suffix) && len(host) > len(w.suffix) {
+            return origin, true
+        }
+    }
+    return "", false
+}
+
+// matchPrefix devuelve la clave de values con el prefijo de ruta más largo
+// que coincide con path. Un prefijo coincide con la ruta exacta o con sus
+// subrutas (/api/v1/admin no coincide con /api/v1/administrar).
+func matchPrefix[V any](values map[string]V, path string) string {
+    best := ""
+    for prefix := range values {
+        if len(prefix) <= len(best) || !strings.HasPrefix(path, prefix) {
+            continue
+        }
+        if len(path) == len(prefix) || path[len(prefix)] == '/' || strings.HasSuffix(prefix, "/") {
+            best = prefix
+        }
+    }
+    return best
+}
+
+func orDefault(values, defaults []string) []string {
+    if len(values) == 0 {
+        return defaults
+    }
+    return values
+}
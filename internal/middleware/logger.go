@@ -0,0 +1,45 @@
+// internal/middleware/logger.go
+package middleware
+
+import (
+    "log/slog"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/logging"
+)
+
+// Logger registra cada petición con slog: nivel error para las respuestas
+// 5xx, warn para las 4xx e info para el resto.
+func Logger(logger *slog.Logger) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        start := time.Now()
+        c.Next()
+
+        status := c.Writer.Status()
+        level := slog.LevelInfo
+        switch {
+        case status >= 500:
+            level = slog.LevelError
+        case status >= 400:
+            level = slog.LevelWarn
+        }
+
+        attrs := []slog.Attr{
+            slog.String("method", c.Request.Method),
+            slog.String("path", c.Request.URL.Path),
+            slog.Int("status", status),
+            slog.Duration("latency", time.Since(start)),
+            slog.String("client_ip", c.ClientIP()),
+            slog.Int("bytes", c.Writer.Size()),
+        }
+        if query := c.Request.URL.RawQuery; query != "" {
+            attrs = append(attrs, slog.String("query", logging.Redact(query)))
+        }
+        if errs := c.Errors.String(); errs != "" {
+            attrs = append(attrs, slog.String("error", logging.Redact(errs)))
+        }
+
+        logger.LogAttrs(c.Request.Context(), level, "petición HTTP", attrs...)
+    }
+}
@@ -0,0 +1,84 @@
+// internal/middleware/ratelimit.go
+package middleware
+
+import (
+    "log/slog"
+    "math"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/ratelimit"
+)
+
+// RateLimitKeyFunc identifica a quién se aplica el límite de una petición.
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// rateLimitSubjectKey guarda en el contexto de gin la identidad con la que
+// se limita la petición, si ya se conoce (por ejemplo una clave de API)
+const rateLimitSubjectKey = "ratelimit.subject"
+
+// SetRateLimitSubject hace que ClientKey limite la petición por subject en
+// lugar de por IP. Debe llamarse antes del middleware RateLimit.
+func SetRateLimitSubject(c *gin.Context, subject string) {
+    c.Set(rateLimitSubjectKey, subject)
+}
+
+// ClientKey limita por la identidad fijada con SetRateLimitSubject o, si no
+// hay ninguna, por la IP del cliente.
+func ClientKey(c *gin.Context) string {
+    if subject := c.GetString(rateLimitSubjectKey); subject != "" {
+        return subject
+    }
+    return "ip:" + c.ClientIP()
+}
+
+// RateLimit limita las peticiones de cada cliente con el límite del prefijo
+// de ruta más largo que coincide; el prefijo "" se aplica al resto. Cada
+// prefijo tiene sus propios cubos, de modo que agotar el límite de un grupo
+// no afecta a los demás. Añade las cabeceras RateLimit-* y responde 429 con
+// Retry-After cuando se supera el límite. Si el almacén falla la petición
+// se deja pasar.
+func RateLimit(store ratelimit.Store, limits map[string]ratelimit.Limit, key RateLimitKeyFunc, logger *slog.Logger) gin.HandlerFunc {
+    if key == nil {
+        key = ClientKey
+    }
+    if logger == nil {
+        logger = slog.Default()
+    }
+
+    return func(c *gin.Context) {
+        prefix := matchPrefix(limits, c.Request.URL.Path)
+        limit, ok := limits[prefix]
+        if !ok || !limit.Enabled() {
+            c.Next()
+            return
+        }
+
+        result, err := store.Take(c.Request.Context(), prefix+"|"+key(c), limit)
+        if err != nil {
+            logger.WarnContext(c.Request.Context(), "límite de peticiones no disponible", "error", err)
+            c.Next()
+            return
+        }
+
+        header := c.Writer.Header()
+        header.Set("RateLimit-Policy", limit.Policy())
+        header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+        header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+        header.Set("RateLimit-Reset", ceilSeconds(result.Reset))
+
+        if !result.Allowed {
+            header.Set("Retry-After", ceilSeconds(result.RetryAfter))
+            c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Demasiadas peticiones, inténtelo más tarde"})
+            return
+        }
+
+        c.Next()
+    }
+}
+
+func ceilSeconds(d time.Duration) string {
+    return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
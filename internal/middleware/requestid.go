@@ -0,0 +1,50 @@
+// internal/middleware/requestid.go
+package middleware
+
+import (
+    "crypto/rand"
+    "encoding/hex"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/logging"
+)
+
+// RequestIDHeader identifica cada petición en la respuesta y en los logs
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength limita el ID que puede enviar el cliente
+const maxRequestIDLength = 128
+
+// RequestID asigna un ID a cada petición, o conserva el que envía el
+// cliente, lo devuelve en la respuesta y lo guarda en el contexto para que
+// los handlers y repositorios lo incluyan en sus logs.
+func RequestID() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id := c.GetHeader(RequestIDHeader)
+        if !validRequestID(id) {
+            id = newRequestID()
+        }
+
+        c.Header(RequestIDHeader, id)
+        c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+        c.Next()
+    }
+}
+
+func validRequestID(id string) bool {
+    if id == "" || len(id) > maxRequestIDLength {
+        return false
+    }
+    for _, r := range id {
+        if r < 0x21 || r > 0x7e {
+            return false
+        }
+    }
+    return true
+}
+
+func newRequestID() string {
+    b := make([]byte, 16)
+    rand.Read(b)
+    return hex.EncodeToString(b)
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+    "time"
+)
+
+// Tipos de tarea de la hoja de tratamiento
+const (
+    TreatmentMedication  = "medication"
+    TreatmentObservation = "observation"
+)
+
+// Admission es el ingreso de una mascota en la hospitalización, por ejemplo
+// para pasar la noche tras una cirugía. Sigue ingresada mientras no tenga
+// fecha de alta.
+type Admission struct {
+    ID       uint `json:"id" gorm:"primaryKey"`
+    PetID    uint `json:"pet_id" gorm:"index" binding:"required"`
+    Pet      *Pet `json:"pet,omitempty" gorm:"foreignKey:PetID"`
+    ClinicID uint `json:"clinic_id" gorm:"index"`
+    // AppointmentID es la cita que originó el ingreso
+    AppointmentID *uint        `json:"appointment_id,omitempty" gorm:"index"`
+    Appointment   *Appointment `json:"appointment,omitempty" gorm:"foreignKey:AppointmentID"`
+    // KennelID es la jaula o box (un recurso de tipo kennel) que ocupa
+    KennelID *uint     `json:"kennel_id,omitempty" gorm:"index"`
+    Kennel   *Resource `json:"kennel,omitempty" gorm:"foreignKey:KennelID"`
+    Reason   string    `json:"reason" binding:"required"`
+    Notes    string    `json:"notes"`
+    // AdmittedAt es la hora de ingreso; por defecto, al darlo de alta
+    AdmittedAt     time.Time   `json:"admitted_at" gorm:"index"`
+    DischargedAt   *time.Time  `json:"discharged_at,omitempty" gorm:"index"`
+    DischargeNotes string      `json:"discharge_notes,omitempty"`
+    Treatments     []Treatment `json:"treatments,omitempty" gorm:"foreignKey:AdmissionID;constraint:OnDelete:CASCADE"`
+    CreatedAt      time.Time   `json:"created_at"`
+    UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+// Current indica si la mascota sigue ingresada.
+func (a Admission) Current() bool {
+    return a.DischargedAt == nil
+}
+
+// Treatment es una tarea de la hoja de tratamiento de un ingreso: una dosis
+// de medicación o una observación (constantes, ingesta...) programada para
+// una hora. El personal la marca como hecha con la hora y sus iniciales.
+type Treatment struct {
+    ID          uint   `json:"id" gorm:"primaryKey"`
+    AdmissionID uint   `json:"admission_id" gorm:"index"`
+    ClinicID    uint   `json:"clinic_id" gorm:"index"`
+    Kind        string `json:"kind" gorm:"not null" binding:"required,oneof=medication observation"`
+    Description string `json:"description" binding:"required"`
+    // Dose es la dosis y la vía de la medicación
+    Dose        string    `json:"dose,omitempty"`
+    ScheduledAt time.Time `json:"scheduled_at" gorm:"index" binding:"required"`
+    DoneAt      *time.Time `json:"done_at,omitempty"`
+    // DoneBy son las iniciales de quien la hizo
+    DoneBy    string    `json:"done_by,omitempty"`
+    Notes     string    `json:"notes,omitempty"`
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Overdue indica si la tarea sigue pendiente después de su hora.
+func (t Treatment) Overdue(now time.Time) bool {
+    return t.DoneAt == nil && t.ScheduledAt.Before(now)
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+    "database/sql/driver"
+    "fmt"
+    "strings"
+    "time"
+)
+
+// Scopes es la lista de permisos de una clave de API. Se guarda como texto
+// separado por comas.
+type Scopes []string
+
+func (s Scopes) Value() (driver.Value, error) {
+    return strings.Join(s, ","), nil
+}
+
+func (s *Scopes) Scan(value interface{}) error {
+    var text string
+    switch v := value.(type) {
+    case nil:
+    case string:
+        text = v
+    case []byte:
+        text = string(v)
+    default:
+        return fmt.Errorf("no se puede leer %T como permisos", value)
+    }
+
+    *s = Scopes{}
+    for _, scope := range strings.Split(text, ",") {
+        if scope != "" {
+            *s = append(*s, scope)
+        }
+    }
+    return nil
+}
+
+// APIKey es una clave para integraciones entre sistemas (laboratorio,
+// widget de reservas...). Solo se guarda el hash de la clave; Prefix permite
+// reconocerla sin conocerla entera.
+type APIKey struct {
+    ID         uint       `json:"id" gorm:"primaryKey"`
+    Name       string     `json:"name" gorm:"not null"`
+    Prefix     string     `json:"prefix" gorm:"not null"`
+    Hash       string     `json:"-" gorm:"not null;uniqueIndex"`
+    Scopes     Scopes     `json:"scopes" gorm:"type:text;not null" swaggertype:"array,string"`
+    // ClinicID limita la clave a una clínica; nil permite elegirla con la
+    // cabecera X-Clinic-ID
+    ClinicID   *uint      `json:"clinic_id"`
+    ExpiresAt  *time.Time `json:"expires_at"`
+    LastUsedAt *time.Time `json:"last_used_at"`
+    RevokedAt  *time.Time `json:"revoked_at"`
+    CreatedAt  time.Time  `json:"created_at"`
+    UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Active indica si la clave puede usarse en el instante now.
+func (k APIKey) Active(now time.Time) bool {
+    return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
@@ -2,16 +2,75 @@ package models
 
 import (
     "time"
+
+    "gorm.io/gorm"
+)
+
+// Estados de una cita. Las canceladas y las ausencias (el paciente no se
+// presentó) dejan libre su hueco en la agenda.
+const (
+    AppointmentScheduled = "scheduled"
+    AppointmentCancelled = "cancelled"
+    AppointmentNoShow    = "no_show"
 )
 
 type Appointment struct {
     ID          uint      `json:"id" gorm:"primaryKey"`
     PetID       uint      `json:"pet_id" binding:"required"`
+    Pet         *Pet      `json:"pet,omitempty" gorm:"foreignKey:PetID"`
+    // ClinicID es la clínica donde se atiende la cita
+    ClinicID    uint      `json:"clinic_id" gorm:"index"`
     Date        time.Time `json:"date" binding:"required"`
-    Reason      string    `json:"reason" binding:"required"`
+    // Reason y Duration se toman del tipo de cita si no se indican
+    Reason      string    `json:"reason"`
     Notes       string    `json:"notes"`
     Completed   bool      `json:"completed" default:"false"`
+    // Status solo cambia al cancelar la cita o marcarla como ausencia
+    Status      string    `json:"status" gorm:"not null;default:scheduled;index"`
+    // VetID es el veterinario que atiende la cita
+    VetID       *uint     `json:"vet_id,omitempty" gorm:"index"`
+    // ResourceIDs son las salas y equipos que la cita reserva durante su
+    // duración
+    ResourceIDs IDs       `json:"resource_ids,omitempty" gorm:"type:text" swaggertype:"array,integer"`
+    // TypeID es el tipo de cita del catálogo
+    TypeID      *uint     `json:"type_id,omitempty" gorm:"index"`
+    Type        *AppointmentType `json:"type,omitempty" gorm:"foreignKey:TypeID"`
+    // BufferBefore y BufferAfter son los minutos que el veterinario y los
+    // recursos quedan reservados antes y después de la cita. Sin indicar
+    // son los del tipo de cita; 0 es ninguno aunque el tipo tenga
+    BufferBefore *int     `json:"buffer_before" gorm:"not null;default:0" binding:"omitempty,min=0"`
+    BufferAfter  *int     `json:"buffer_after" gorm:"not null;default:0" binding:"omitempty,min=0"`
     CreatedAt   time.Time `json:"created_at"`
     UpdatedAt   time.Time `json:"updated_at"`
-	Duration   int       `json:"duration" binding:"required"` 
-}
\ No newline at end of file
+    DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
+    Version     uint      `json:"version" gorm:"not null;default:1"`
+	Duration   int       `json:"duration" binding:"omitempty,min=1"` 
+}
+
+// End es la hora a la que termina la cita según su duración en minutos.
+func (a Appointment) End() time.Time {
+    return a.Date.Add(time.Duration(a.Duration) * time.Minute)
+}
+
+// BlockedFrom y BlockedUntil delimitan el tiempo que la cita reserva al
+// veterinario y a los recursos, márgenes incluidos.
+func (a Appointment) BlockedFrom() time.Time {
+    return a.Date.Add(-minutes(a.BufferBefore))
+}
+
+func (a Appointment) BlockedUntil() time.Time {
+    return a.End().Add(minutes(a.BufferAfter))
+}
+
+// minutes convierte un margen en minutos; sin margen es cero.
+func minutes(buffer *int) time.Duration {
+    if buffer == nil {
+        return 0
+    }
+    return time.Duration(*buffer) * time.Minute
+}
+
+// Active indica si la cita sigue ocupando su hueco en la agenda.
+func (a Appointment) Active() bool {
+    return a.Status == "" || a.Status == AppointmentScheduled
+}
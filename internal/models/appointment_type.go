@@ -0,0 +1,49 @@
+package models
+
+import (
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// Service es un servicio facturable del catálogo, común a todas las
+// clínicas del grupo. Price se expresa en céntimos.
+type Service struct {
+    ID        uint           `json:"id" gorm:"primaryKey"`
+    Code      string         `json:"code" gorm:"not null;index" binding:"required"`
+    Name      string         `json:"name" gorm:"not null" binding:"required"`
+    Price     int64          `json:"price" binding:"min=0"`
+    CreatedAt time.Time      `json:"created_at"`
+    UpdatedAt time.Time      `json:"updated_at"`
+    DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
+}
+
+// AppointmentType es un tipo de cita del catálogo de la clínica (vacunación,
+// consulta, cirugía...). Al crear una cita de ese tipo se rellenan con él
+// la duración, el motivo, los márgenes y los recursos que necesita.
+type AppointmentType struct {
+    ID       uint   `json:"id" gorm:"primaryKey"`
+    Name     string `json:"name" gorm:"not null" binding:"required"`
+    ClinicID uint   `json:"clinic_id" gorm:"index"`
+    // Duration es la duración por defecto en minutos
+    Duration int `json:"duration" binding:"required,min=1"`
+    // BufferBefore y BufferAfter son los minutos que el veterinario y los
+    // recursos quedan reservados antes y después de la cita (preparación,
+    // limpieza...)
+    BufferBefore int `json:"buffer_before" binding:"min=0"`
+    BufferAfter  int `json:"buffer_after" binding:"min=0"`
+    // Specialty es la especialidad que debe tener el veterinario que
+    // atiende la cita; vacía si vale cualquiera
+    Specialty string `json:"specialty,omitempty"`
+    // ResourceIDs son las salas y equipos que la cita reserva siempre
+    ResourceIDs IDs `json:"resource_ids,omitempty" gorm:"type:text" swaggertype:"array,integer"`
+    // ServiceID es el servicio que se factura por la cita
+    ServiceID *uint    `json:"service_id,omitempty" gorm:"index"`
+    Service   *Service `json:"service,omitempty" gorm:"foreignKey:ServiceID"`
+    // Color es el color de las citas de este tipo en los calendarios
+    // (#rrggbb)
+    Color     string         `json:"color,omitempty" binding:"omitempty,hexcolor"`
+    CreatedAt time.Time      `json:"created_at"`
+    UpdatedAt time.Time      `json:"updated_at"`
+    DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
+}
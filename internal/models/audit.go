@@ -0,0 +1,72 @@
+package models
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "strconv"
+    "time"
+)
+
+// Acciones registradas en la auditoría
+const (
+    AuditCreate  = "create"
+    AuditUpdate  = "update"
+    AuditDelete  = "delete"
+    AuditRestore = "restore"
+    AuditPurge   = "purge"
+    AuditMerge   = "merge"
+    AuditLookup  = "lookup"
+    // AuditAllClinics es una operación de una clínica que abarcó todas
+    AuditAllClinics = "all_clinics"
+)
+
+// FieldChange es el valor de un campo antes y después de un cambio.
+type FieldChange struct {
+    Before interface{} `json:"before"`
+    After  interface{} `json:"after"`
+}
+
+// AuditLog registra un cambio sobre una entidad. Cada entrada incluye el
+// hash de la anterior, de modo que cualquier modificación posterior de la
+// tabla rompe la cadena. Ninguna entrada puede repetir PrevHash: la cadena no
+// se bifurca.
+type AuditLog struct {
+    ID        uint            `json:"id" gorm:"primaryKey"`
+    Actor     string          `json:"actor" gorm:"not null"`
+    Entity    string          `json:"entity" gorm:"not null;index:idx_audit_entity"`
+    EntityID  uint            `json:"entity_id" gorm:"index:idx_audit_entity"`
+    Action    string          `json:"action" gorm:"not null"`
+    Changes   json.RawMessage `json:"changes" gorm:"type:text" swaggertype:"object"`
+    CreatedAt time.Time       `json:"created_at"`
+    PrevHash  string          `json:"prev_hash" gorm:"uniqueIndex"`
+    Hash      string          `json:"hash" gorm:"uniqueIndex"`
+}
+
+// ComputeHash calcula el hash de la entrada encadenado con PrevHash.
+func (l *AuditLog) ComputeHash() string {
+    h := sha256.New()
+    for _, part := range []string{
+        l.PrevHash,
+        l.Actor,
+        l.Entity,
+        strconv.FormatUint(uint64(l.EntityID), 10),
+        l.Action,
+        string(l.Changes),
+        l.CreatedAt.UTC().Format(time.RFC3339Nano),
+    } {
+        h.Write([]byte(part))
+        h.Write([]byte{0})
+    }
+    return hex.EncodeToString(h.Sum(nil))
+}
+
+// AuditChainHead es la única fila con el ID y el hash de la última entrada
+// de la auditoría. Cada entrada la avanza en la misma transacción en la que
+// se escribe, lo que ordena las escrituras de todos los procesos, y permite
+// detectar que se han borrado las últimas entradas.
+type AuditChainHead struct {
+    ID          uint   `gorm:"primaryKey"`
+    Hash        string `gorm:"not null"`
+    LastEntryID uint   `gorm:"not null;default:0"`
+}
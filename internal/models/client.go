@@ -2,6 +2,8 @@ package models
 
 import (
     "time"
+
+    "gorm.io/gorm"
 )
 
 type Client struct {
@@ -12,5 +14,7 @@ type Client struct {
     Address   string    `json:"address"`
     CreatedAt time.Time `json:"created_at"`
     UpdatedAt time.Time `json:"updated_at"`
+    DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
+    Version   uint      `json:"version" gorm:"not null;default:1"`
     Pets      []Pet     `json:"pets,omitempty" gorm:"foreignKey:ClientID"`
 }
\ No newline at end of file
@@ -0,0 +1,28 @@
+package models
+
+import (
+    "time"
+)
+
+// Clinic es una de las clínicas (sedes) del grupo. Los clientes, mascotas y
+// citas de cada clínica solo son visibles desde ella.
+type Clinic struct {
+    ID        uint      `json:"id" gorm:"primaryKey"`
+    Name      string    `json:"name" gorm:"not null;unique" binding:"required"`
+    Address   string    `json:"address"`
+    Phone     string    `json:"phone"`
+    // TimeZone es la zona horaria IANA de la clínica (por ejemplo
+    // "Europe/Madrid"); vacía usa la de la instalación
+    TimeZone  string    `json:"time_zone,omitempty"`
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ClientClinic comparte un cliente con una clínica. Un cliente que acude a
+// varias sedes tiene una fila por cada una; sus mascotas son visibles en
+// todas ellas.
+type ClientClinic struct {
+    ClientID  uint      `json:"client_id" gorm:"primaryKey;autoIncrement:false"`
+    ClinicID  uint      `json:"clinic_id" gorm:"primaryKey;autoIncrement:false;index"`
+    CreatedAt time.Time `json:"created_at"`
+}
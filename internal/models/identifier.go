@@ -0,0 +1,60 @@
+package models
+
+import (
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Tipos de identificación de una mascota, además del microchip
+const (
+    IdentifierTattoo     = "tattoo"
+    IdentifierLicenseTag = "license_tag"
+)
+
+// PetIdentifier es otra identificación de la mascota, como un tatuaje o la
+// chapa de la licencia municipal. Cada valor identifica a una sola mascota
+// dentro de su tipo.
+type PetIdentifier struct {
+    ID        uint      `json:"id" gorm:"primaryKey"`
+    PetID     uint      `json:"pet_id" gorm:"not null;index"`
+    Kind      string    `json:"kind" gorm:"not null;uniqueIndex:idx_pet_identifiers_value" binding:"required,oneof=tattoo license_tag"`
+    Value     string    `json:"value" gorm:"not null;uniqueIndex:idx_pet_identifiers_value" binding:"required"`
+    // Issuer es quien lo emitió, por ejemplo el ayuntamiento de la licencia
+    Issuer    string    `json:"issuer"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// NormalizeIdentifier quita los espacios a los lados, pasa a mayúsculas y
+// deja un solo espacio entre palabras, para que "ab 12" y "AB  12" sean el
+// mismo tatuaje.
+func NormalizeIdentifier(value string) string {
+    return strings.ToUpper(strings.Join(strings.Fields(value), " "))
+}
+
+// NormalizeMicrochip quita los espacios, guiones y puntos con los que se
+// suele escribir un microchip y comprueba que es un código ISO 11784/11785
+// de 15 dígitos: los tres primeros son el código numérico ISO 3166 del país
+// (001-899) o el del fabricante (900-998).
+func NormalizeMicrochip(value string) (string, bool) {
+    chip := strings.Map(func(r rune) rune {
+        switch r {
+        case ' ', '-', '.':
+            return -1
+        }
+        return r
+    }, value)
+    if len(chip) != 15 {
+        return "", false
+    }
+    for _, r := range chip {
+        if r < '0' || r > '9' {
+            return "", false
+        }
+    }
+    code, _ := strconv.Atoi(chip[:3])
+    if code == 0 || code == 999 {
+        return "", false
+    }
+    return chip, true
+}
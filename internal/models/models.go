@@ -0,0 +1,7 @@
+package models
+
+// All devuelve los modelos que se migran al arrancar, en orden de
+// dependencia.
+func All() []interface{} {
+    return []interface{}{&Clinic{}, &Client{}, &ClientClinic{}, &Species{}, &Breed{}, &Pet{}, &PetIdentifier{}, &Vet{}, &Resource{}, &Service{}, &AppointmentType{}, &Appointment{}, &WaitlistEntry{}, &WaitlistWindow{}, &WaitlistOffer{}, &Admission{}, &Treatment{}, &Reminder{}, &AuditLog{}, &AuditChainHead{}, &APIKey{}}
+}
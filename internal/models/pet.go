@@ -1,7 +1,10 @@
 package models
 
 import (
+    "encoding/json"
     "time"
+
+    "gorm.io/gorm"
 )
 
 type Pet struct {
@@ -9,13 +12,179 @@ type Pet struct {
     Name        string    `json:"name" binding:"required"`
     Species     string    `json:"species" binding:"required"`
     Breed       string    `json:"breed"`
-    BirthDate   time.Time `json:"birth_date"`
+    BirthDate   time.Time `json:"birth_date" timezone:"date"`
+    // BirthDatePrecision indica qué parte de la fecha de nacimiento se
+    // conoce: el día, solo el mes, solo el año o una fecha estimada a partir
+    // de la edad aproximada
+    BirthDatePrecision string `json:"birth_date_precision" binding:"omitempty,oneof=day month year estimated"`
     Weight      float64   `json:"weight"`
+    // Microchip es el código ISO 11784/11785 de 15 dígitos; vacío si no tiene
+    Microchip   string    `json:"microchip" gorm:"index:idx_pets_microchip,unique,where:microchip <> ''"`
     ClientID    uint      `json:"client_id" binding:"required"`
+    Client      *Client   `json:"client,omitempty" gorm:"foreignKey:ClientID"`
 	Appointments      []Appointment     `json:"appointments,omitempty" gorm:"foreignKey:PetID"`
+    Identifiers []PetIdentifier `json:"identifiers,omitempty" gorm:"foreignKey:PetID"`
     Description string    `json:"description"`
     CreatedAt   time.Time `json:"created_at"`
     UpdatedAt   time.Time `json:"updated_at"`
+    DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
+    Version     uint      `json:"version" gorm:"not null;default:1"`
+}
+
+// Precisión de la fecha de nacimiento
+const (
+    BirthDateDay       = "day"
+    BirthDateMonth     = "month"
+    BirthDateYear      = "year"
+    BirthDateEstimated = "estimated"
+)
+
+// Etapas de vida de una mascota
+const (
+    LifeStagePuppy  = "puppy"
+    LifeStageAdult  = "adult"
+    LifeStageSenior = "senior"
+)
+
+// LifeStageAges son las edades, en meses, a las que una mascota de cada
+// especie pasa a ser adulta y senior.
+type LifeStageAges struct {
+    Adult  int
+    Senior int
+}
+
+// lifeStages son las edades de cada especie del catálogo por su nombre
+// canónico; las demás usan DefaultLifeStageAges.
+var lifeStages = map[string]LifeStageAges{
+    "Dog":        {Adult: 12, Senior: 84},
+    "Cat":        {Adult: 12, Senior: 132},
+    "Rabbit":     {Adult: 6, Senior: 60},
+    "Bird":       {Adult: 12, Senior: 120},
+    "Hamster":    {Adult: 3, Senior: 18},
+    "Guinea Pig": {Adult: 4, Senior: 60},
+    "Ferret":     {Adult: 12, Senior: 60},
+    "Reptile":    {Adult: 24, Senior: 180},
+}
+
+// DefaultLifeStageAges son las edades de las especies sin edades propias.
+var DefaultLifeStageAges = LifeStageAges{Adult: 12, Senior: 84}
+
+// LifeStageAgesFor devuelve las edades de las etapas de vida de la especie.
+func LifeStageAgesFor(species string) LifeStageAges {
+    if ages, ok := lifeStages[species]; ok {
+        return ages
+    }
+    return DefaultLifeStageAges
+}
+
+// LifeStageSpecies devuelve las especies con edades propias.
+func LifeStageSpecies() []string {
+    species := make([]string, 0, len(lifeStages))
+    for name := range lifeStages {
+        species = append(species, name)
+    }
+    return species
+}
+
+// PetAge es la edad de una mascota en años y meses cumplidos. Approximate
+// indica que la fecha de nacimiento no se conoce con exactitud.
+type PetAge struct {
+    Years       int  `json:"years"`
+    Months      int  `json:"months"`
+    Approximate bool `json:"approximate"`
+}
+
+// NormalizeBirthDate ajusta la fecha de nacimiento a su precisión: con el
+// mes o el año solo se guarda el primer día. Sin precisión se entiende que
+// se conoce el día, y sin fecha no hay precisión.
+func (p *Pet) NormalizeBirthDate() {
+    if p.BirthDate.IsZero() {
+        p.BirthDatePrecision = ""
+        return
+    }
+    year, month, day := p.BirthDate.Date()
+    switch p.BirthDatePrecision {
+    case "":
+        p.BirthDatePrecision = BirthDateDay
+    case BirthDateMonth:
+        day = 1
+    case BirthDateYear:
+        month, day = time.January, 1
+    }
+    p.BirthDate = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Age devuelve la edad de la mascota en la fecha now, o nil si no se conoce
+// su fecha de nacimiento.
+func (p Pet) Age(now time.Time) *PetAge {
+    if p.BirthDate.IsZero() {
+        return nil
+    }
+    months := monthsBetween(p.BirthDate, now)
+    if months < 0 {
+        months = 0
+    }
+    return &PetAge{
+        Years:       months / 12,
+        Months:      months % 12,
+        Approximate: p.BirthDatePrecision != "" && p.BirthDatePrecision != BirthDateDay,
+    }
+}
+
+// LifeStage devuelve la etapa de vida de la mascota en la fecha now según
+// su especie, o "" si no se conoce su fecha de nacimiento.
+func (p Pet) LifeStage(now time.Time) string {
+    if p.BirthDate.IsZero() {
+        return ""
+    }
+    ages := LifeStageAgesFor(p.Species)
+    switch months := monthsBetween(p.BirthDate, now); {
+    case months >= ages.Senior:
+        return LifeStageSenior
+    case months >= ages.Adult:
+        return LifeStageAdult
+    default:
+        return LifeStagePuppy
+    }
+}
+
+// MarshalJSON añade la edad y la etapa de vida calculadas y devuelve null
+// como fecha de nacimiento si no se conoce.
+func (p Pet) MarshalJSON() ([]byte, error) {
+    type pet Pet
+    now := time.Now()
+    var birthDate *time.Time
+    if !p.BirthDate.IsZero() {
+        birthDate = &p.BirthDate
+    }
+    return json.Marshal(struct {
+        pet
+        BirthDate *time.Time `json:"birth_date"`
+        Age       *PetAge    `json:"age"`
+        LifeStage string     `json:"life_stage,omitempty"`
+    }{pet(p), birthDate, p.Age(now), p.LifeStage(now)})
+}
+
+// monthsBetween devuelve los meses cumplidos entre la fecha de nacimiento
+// (un día de calendario) y el día de now.
+func monthsBetween(birth, now time.Time) int {
+    year, month, day := now.Date()
+    months := (year-birth.Year())*12 + int(month) - int(birth.Month())
+    if day < birth.Day() {
+        months--
+    }
+    return months
+}
+
+// BornBy devuelve la última fecha de nacimiento con la que una mascota tiene
+// al menos months meses cumplidos el día de now.
+func BornBy(now time.Time, months int) time.Time {
+    year, month, day := now.Date()
+    first := time.Date(year, month-time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+    if last := first.AddDate(0, 1, -1).Day(); day > last {
+        day = last
+    }
+    return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, time.UTC)
 }
 
 /* type Appointment struct {
@@ -0,0 +1,29 @@
+package models
+
+import (
+    "time"
+)
+
+// Estados de un recordatorio
+const (
+    ReminderPending = "pending"
+    ReminderSent    = "sent"
+    ReminderFailed  = "failed"
+)
+
+// Reminder registra cada recordatorio enviado (o intentado) para una cita.
+// El índice único sobre (cita, antelación, canal) garantiza que cada
+// recordatorio se envía una sola vez.
+type Reminder struct {
+    ID            uint       `json:"id" gorm:"primaryKey"`
+    AppointmentID uint       `json:"appointment_id" gorm:"not null;uniqueIndex:idx_reminder_once"`
+    LeadTime      string     `json:"lead_time" gorm:"not null;uniqueIndex:idx_reminder_once"`
+    Channel       string     `json:"channel" gorm:"not null;uniqueIndex:idx_reminder_once"`
+    Recipient     string     `json:"recipient"`
+    Status        string     `json:"status" gorm:"not null;default:pending"`
+    Attempts      int        `json:"attempts"`
+    LastError     string     `json:"last_error,omitempty"`
+    SentAt        *time.Time `json:"sent_at,omitempty"`
+    CreatedAt     time.Time  `json:"created_at"`
+    UpdatedAt     time.Time  `json:"updated_at"`
+}
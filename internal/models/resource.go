@@ -0,0 +1,71 @@
+package models
+
+import (
+    "database/sql/driver"
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// Tipos de recurso reservable
+const (
+    ResourceRoom      = "room"
+    ResourceEquipment = "equipment"
+    // ResourceKennel es una jaula o box de hospitalización
+    ResourceKennel = "kennel"
+)
+
+// Resource es una sala (quirófano, sala de rayos X...) o un equipo (máquina
+// de anestesia, ecógrafo...) de la clínica que las citas reservan durante
+// su duración, o una jaula en la que se ingresa a las mascotas.
+type Resource struct {
+    ID          uint           `json:"id" gorm:"primaryKey"`
+    Name        string         `json:"name" gorm:"not null" binding:"required"`
+    Kind        string         `json:"kind" gorm:"not null;index" binding:"required,oneof=room equipment kennel"`
+    Description string         `json:"description"`
+    ClinicID    uint           `json:"clinic_id" gorm:"index"`
+    CreatedAt   time.Time      `json:"created_at"`
+    UpdatedAt   time.Time      `json:"updated_at"`
+    DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
+}
+
+// IDs es una lista de identificadores, como los recursos que reserva una
+// cita. Se guarda como texto separado por comas.
+type IDs []uint
+
+func (ids IDs) Value() (driver.Value, error) {
+    parts := make([]string, len(ids))
+    for i, id := range ids {
+        parts[i] = strconv.FormatUint(uint64(id), 10)
+    }
+    return strings.Join(parts, ","), nil
+}
+
+func (ids *IDs) Scan(value interface{}) error {
+    var text string
+    switch v := value.(type) {
+    case nil:
+    case string:
+        text = v
+    case []byte:
+        text = string(v)
+    default:
+        return fmt.Errorf("no se puede leer %T como lista de IDs", value)
+    }
+
+    *ids = nil
+    for _, part := range strings.Split(text, ",") {
+        if part == "" {
+            continue
+        }
+        id, err := strconv.ParseUint(part, 10, 32)
+        if err != nil {
+            return fmt.Errorf("ID no válido en la lista: %q", part)
+        }
+        *ids = append(*ids, uint(id))
+    }
+    return nil
+}
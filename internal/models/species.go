@@ -0,0 +1,146 @@
+package models
+
+import (
+    "database/sql/driver"
+    "encoding/json"
+    "fmt"
+    "strings"
+    "time"
+)
+
+// Species es una especie del catálogo. Name es el nombre canónico que se
+// guarda en las mascotas; Names son los nombres en cada idioma y Aliases
+// otras formas de escribirla que se aceptan al dar de alta una mascota.
+type Species struct {
+    ID        uint      `json:"id" gorm:"primaryKey"`
+    Name      string    `json:"name" gorm:"not null;uniqueIndex" binding:"required"`
+    Names     Localized `json:"names" gorm:"type:text" swaggertype:"object,string"`
+    Aliases   Terms     `json:"aliases" gorm:"type:text" swaggertype:"array,string"`
+    Breeds    []Breed   `json:"breeds,omitempty" gorm:"foreignKey:SpeciesID" binding:"omitempty,dive"`
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Breed es una raza de una especie del catálogo, con el mismo esquema de
+// nombres que la especie.
+type Breed struct {
+    ID        uint      `json:"id" gorm:"primaryKey"`
+    SpeciesID uint      `json:"species_id" gorm:"not null;index"`
+    Name      string    `json:"name" gorm:"not null" binding:"required"`
+    Names     Localized `json:"names" gorm:"type:text" swaggertype:"object,string"`
+    Aliases   Terms     `json:"aliases" gorm:"type:text" swaggertype:"array,string"`
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Matches indica si value es el nombre de la especie, su nombre en algún
+// idioma o uno de sus alias, sin distinguir mayúsculas ni tildes.
+func (s Species) Matches(value string) bool {
+    return matchesTerm(value, s.Name, s.Names, s.Aliases)
+}
+
+// FindBreed busca la raza de la especie que corresponde a value.
+func (s Species) FindBreed(value string) (Breed, bool) {
+    for _, b := range s.Breeds {
+        if b.Matches(value) {
+            return b, true
+        }
+    }
+    return Breed{}, false
+}
+
+// Matches indica si value es el nombre de la raza, su nombre en algún
+// idioma o uno de sus alias, sin distinguir mayúsculas ni tildes.
+func (b Breed) Matches(value string) bool {
+    return matchesTerm(value, b.Name, b.Names, b.Aliases)
+}
+
+// FindSpecies busca en el catálogo la especie que corresponde a value.
+func FindSpecies(catalog []Species, value string) (Species, bool) {
+    for _, s := range catalog {
+        if s.Matches(value) {
+            return s, true
+        }
+    }
+    return Species{}, false
+}
+
+// foldAccents quita las tildes y la diéresis al comparar nombres
+var foldAccents = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u")
+
+// FoldTerm normaliza un nombre para compararlo: sin espacios a los lados,
+// en minúsculas y sin tildes.
+func FoldTerm(value string) string {
+    return foldAccents.Replace(strings.ToLower(strings.TrimSpace(value)))
+}
+
+func matchesTerm(value, name string, names Localized, aliases Terms) bool {
+    key := FoldTerm(value)
+    if key == "" {
+        return false
+    }
+    if key == FoldTerm(name) {
+        return true
+    }
+    for _, n := range names {
+        if key == FoldTerm(n) {
+            return true
+        }
+    }
+    for _, a := range aliases {
+        if key == FoldTerm(a) {
+            return true
+        }
+    }
+    return false
+}
+
+// Localized son los nombres de un término por código de idioma ("es",
+// "en"...). Se guarda como JSON.
+type Localized map[string]string
+
+func (l Localized) Value() (driver.Value, error) {
+    if l == nil {
+        return "{}", nil
+    }
+    data, err := json.Marshal(l)
+    return string(data), err
+}
+
+func (l *Localized) Scan(value interface{}) error {
+    return scanJSON(value, l)
+}
+
+// Terms es una lista de términos, como los alias de una especie. Se guarda
+// como JSON.
+type Terms []string
+
+func (t Terms) Value() (driver.Value, error) {
+    if t == nil {
+        return "[]", nil
+    }
+    data, err := json.Marshal(t)
+    return string(data), err
+}
+
+func (t *Terms) Scan(value interface{}) error {
+    return scanJSON(value, t)
+}
+
+func scanJSON(value interface{}, target interface{}) error {
+    var data []byte
+    switch v := value.(type) {
+    case nil:
+        return nil
+    case string:
+        data = []byte(v)
+    case []byte:
+        data = v
+    default:
+        return fmt.Errorf("no se puede leer %T como JSON", value)
+    }
+    if len(data) == 0 {
+        return nil
+    }
+    return json.Unmarshal(data, target)
+}
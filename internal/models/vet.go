@@ -0,0 +1,60 @@
+package models
+
+import (
+    "database/sql/driver"
+    "fmt"
+    "slices"
+    "strings"
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// Specialties son las especialidades de un veterinario (cirugía,
+// dermatología, exóticos...). Se guardan como texto separado por comas.
+type Specialties []string
+
+func (s Specialties) Value() (driver.Value, error) {
+    return strings.Join(s, ","), nil
+}
+
+func (s *Specialties) Scan(value interface{}) error {
+    var text string
+    switch v := value.(type) {
+    case nil:
+    case string:
+        text = v
+    case []byte:
+        text = string(v)
+    default:
+        return fmt.Errorf("no se puede leer %T como especialidades", value)
+    }
+
+    *s = Specialties{}
+    for _, specialty := range strings.Split(text, ",") {
+        if specialty != "" {
+            *s = append(*s, specialty)
+        }
+    }
+    return nil
+}
+
+// Vet es un veterinario de la clínica. No puede tener dos citas a la vez.
+type Vet struct {
+    ID          uint           `json:"id" gorm:"primaryKey"`
+    Name        string         `json:"name" gorm:"not null" binding:"required"`
+    Email       string         `json:"email" binding:"omitempty,email"`
+    Specialties Specialties    `json:"specialties,omitempty" gorm:"type:text" swaggertype:"array,string"`
+    ClinicID    uint           `json:"clinic_id" gorm:"index"`
+    CreatedAt   time.Time      `json:"created_at"`
+    UpdatedAt   time.Time      `json:"updated_at"`
+    DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string"`
+}
+
+// HasSpecialty indica si el veterinario tiene la especialidad, sin
+// distinguir mayúsculas.
+func (v Vet) HasSpecialty(specialty string) bool {
+    return slices.ContainsFunc(v.Specialties, func(s string) bool {
+        return strings.EqualFold(s, specialty)
+    })
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+    "time"
+)
+
+// Estados de una entrada de la lista de espera
+const (
+    WaitlistWaiting   = "waiting"
+    WaitlistOffered   = "offered"
+    WaitlistBooked    = "booked"
+    WaitlistCancelled = "cancelled"
+)
+
+// Urgencia de una entrada de la lista de espera. Las más urgentes reciben
+// antes las ofertas.
+const (
+    UrgencyLow    = "low"
+    UrgencyNormal = "normal"
+    UrgencyHigh   = "high"
+)
+
+// Estados de una oferta de hueco
+const (
+    OfferPending  = "pending"
+    OfferAccepted = "accepted"
+    OfferDeclined = "declined"
+    OfferExpired  = "expired"
+)
+
+// WaitlistEntry es una mascota que espera un hueco en la agenda cuando no
+// hay citas libres.
+type WaitlistEntry struct {
+    ID       uint   `json:"id" gorm:"primaryKey"`
+    PetID    uint   `json:"pet_id" gorm:"index" binding:"required"`
+    Pet      *Pet   `json:"pet,omitempty" gorm:"foreignKey:PetID"`
+    ClinicID uint   `json:"clinic_id" gorm:"index"`
+    Reason   string `json:"reason" binding:"required"`
+    // Duration son los minutos que necesita la cita
+    Duration int `json:"duration" binding:"required,min=1"`
+    // VetID es el veterinario preferido; solo se ofrecen sus huecos o los
+    // que no tienen veterinario
+    VetID   *uint  `json:"vet_id,omitempty"`
+    Urgency string `json:"urgency" gorm:"not null;default:normal" binding:"omitempty,oneof=low normal high"`
+    // Windows son los intervalos en los que el cliente puede acudir
+    Windows []WaitlistWindow `json:"windows" gorm:"foreignKey:EntryID;constraint:OnDelete:CASCADE" binding:"required,min=1,dive"`
+    Status  string           `json:"status" gorm:"not null;default:waiting;index"`
+    // AppointmentID es la cita creada al aceptar una oferta
+    AppointmentID *uint     `json:"appointment_id,omitempty"`
+    CreatedAt     time.Time `json:"created_at"`
+    UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// WaitlistWindow es un intervalo en el que el cliente puede acudir.
+type WaitlistWindow struct {
+    ID      uint      `json:"-" gorm:"primaryKey"`
+    EntryID uint      `json:"-" gorm:"index"`
+    Start   time.Time `json:"start" binding:"required"`
+    End     time.Time `json:"end" binding:"required,gtfield=Start"`
+}
+
+// Fits indica si la entrada puede ocupar el hueco que empieza en start: cabe
+// entera en alguna de sus ventanas.
+func (e WaitlistEntry) Fits(start time.Time) bool {
+    end := start.Add(time.Duration(e.Duration) * time.Minute)
+    for _, w := range e.Windows {
+        if !start.Before(w.Start) && !end.After(w.End) {
+            return true
+        }
+    }
+    return false
+}
+
+// WaitlistOffer ofrece a una entrada de la lista de espera el hueco que ha
+// dejado una cita cancelada o a la que no se presentaron. Si no se acepta
+// antes de ExpiresAt se ofrece al siguiente candidato.
+type WaitlistOffer struct {
+    ID       uint           `json:"id" gorm:"primaryKey"`
+    EntryID  uint           `json:"entry_id" gorm:"index"`
+    Entry    *WaitlistEntry `json:"entry,omitempty" gorm:"foreignKey:EntryID"`
+    ClinicID uint           `json:"clinic_id" gorm:"index"`
+    // FreedAppointmentID es la cita que dejó libre el hueco
+    FreedAppointmentID uint      `json:"freed_appointment_id" gorm:"index"`
+    Date               time.Time `json:"date"`
+    Duration           int       `json:"duration"`
+    VetID              *uint     `json:"vet_id,omitempty"`
+    Status             string    `json:"status" gorm:"not null;default:pending;index"`
+    ExpiresAt          time.Time `json:"expires_at" gorm:"index"`
+    // AppointmentID es la cita creada al aceptar la oferta
+    AppointmentID *uint     `json:"appointment_id,omitempty"`
+    CreatedAt     time.Time `json:"created_at"`
+    UpdatedAt     time.Time `json:"updated_at"`
+}
@@ -0,0 +1,133 @@
+// Package ratelimit limita el número de peticiones con cubos de fichas
+// (token bucket): cada clave dispone de Limit.Requests fichas que se
+// recargan de forma continua a lo largo de Limit.Period.
+package ratelimit
+
+import (
+    "context"
+    "fmt"
+    "math"
+    "sync"
+    "time"
+)
+
+// Limit es el número de peticiones permitidas por periodo. Requests es
+// también la ráfaga máxima.
+type Limit struct {
+    Requests int
+    Period   time.Duration
+}
+
+// Enabled indica si el límite tiene efecto.
+func (l Limit) Enabled() bool {
+    return l.Requests > 0 && l.Period > 0
+}
+
+// Policy describe el límite para la cabecera RateLimit-Policy (100;w=60).
+func (l Limit) Policy() string {
+    return fmt.Sprintf("%d;w=%d", l.Requests, int(math.Ceil(l.Period.Seconds())))
+}
+
+// rate es el número de fichas que se recargan por segundo.
+func (l Limit) rate() float64 {
+    return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Result es el resultado de consumir una ficha.
+type Result struct {
+    Allowed   bool
+    Limit     int
+    Remaining int
+    // Reset es el tiempo hasta que el cubo vuelve a estar lleno
+    Reset time.Duration
+    // RetryAfter es el tiempo hasta la próxima ficha si no se permitió la
+    // petición
+    RetryAfter time.Duration
+}
+
+// Store guarda los cubos de fichas. MemoryStore sirve para una sola
+// instancia; con varias réplicas se necesita una implementación compartida
+// (por ejemplo sobre Redis) que haga Take de forma atómica.
+type Store interface {
+    // Take consume una ficha del cubo de key con el límite indicado.
+    Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// MemoryStore guarda los cubos en memoria.
+type MemoryStore struct {
+    // Now permite fijar la hora en las pruebas
+    Now func() time.Time
+
+    mu        sync.Mutex
+    buckets   map[string]*bucket
+    lastSweep time.Time
+}
+
+type bucket struct {
+    tokens  float64
+    updated time.Time
+    // full es cuándo se habrá recargado por completo
+    full time.Time
+}
+
+// sweepInterval es cada cuánto se eliminan los cubos llenos, que equivalen
+// a no tener cubo
+const sweepInterval = time.Minute
+
+func NewMemoryStore() *MemoryStore {
+    return &MemoryStore{
+        Now:     time.Now,
+        buckets: make(map[string]*bucket),
+    }
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    now := s.Now()
+    s.sweep(now)
+
+    capacity := float64(limit.Requests)
+    rate := limit.rate()
+
+    b, ok := s.buckets[key]
+    if !ok {
+        b = &bucket{tokens: capacity, updated: now}
+        s.buckets[key] = b
+    }
+    if elapsed := now.Sub(b.updated); elapsed > 0 {
+        b.tokens = math.Min(capacity, b.tokens+elapsed.Seconds()*rate)
+        b.updated = now
+    }
+
+    result := Result{Limit: limit.Requests}
+    if b.tokens >= 1 {
+        b.tokens--
+        result.Allowed = true
+    } else {
+        result.RetryAfter = seconds((1 - b.tokens) / rate)
+    }
+    result.Remaining = int(b.tokens)
+    result.Reset = seconds((capacity - b.tokens) / rate)
+    b.full = now.Add(result.Reset)
+    return result, nil
+}
+
+// sweep elimina los cubos que ya se han recargado. Debe llamarse con mu
+// bloqueado.
+func (s *MemoryStore) sweep(now time.Time) {
+    if now.Sub(s.lastSweep) < sweepInterval {
+        return
+    }
+    s.lastSweep = now
+    for key, b := range s.buckets {
+        if !now.Before(b.full) {
+            delete(s.buckets, key)
+        }
+    }
+}
+
+func seconds(s float64) time.Duration {
+    return time.Duration(s * float64(time.Second))
+}
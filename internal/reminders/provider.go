@@ -0,0 +1,220 @@
+// internal/reminders/provider.go
+package reminders
+
+import (
+    "bytes"
+    "context"
+    "crypto/tls"
+    "encoding/json"
+    "fmt"
+    "io"
+    "mime"
+    "net"
+    "net/http"
+    "net/smtp"
+    "os"
+    "strings"
+    "sync"
+    "time"
+)
+
+// Canales de envío soportados
+const (
+    ChannelEmail = "email"
+    ChannelSMS   = "sms"
+)
+
+// Message es un recordatorio ya renderizado, listo para enviar.
+type Message struct {
+    Channel string `json:"channel"`
+    To      string `json:"to"`
+    Subject string `json:"subject,omitempty"`
+    Body    string `json:"body"`
+}
+
+// Provider envía mensajes por un canal concreto (SMTP, pasarela SMS, log...).
+type Provider interface {
+    Send(ctx context.Context, msg Message) error
+}
+
+// SMTPProvider envía los recordatorios por correo electrónico.
+type SMTPProvider struct {
+    Host     string
+    Port     string
+    Username string
+    Password string
+    From     string
+}
+
+// smtpTimeout limita una conversación SMTP cuando el contexto no tiene plazo
+const smtpTimeout = 30 * time.Second
+
+// Send entrega el mensaje respetando el plazo y la cancelación de ctx, para
+// que el apagado no espere a un servidor SMTP que no responde.
+func (p *SMTPProvider) Send(ctx context.Context, msg Message) error {
+    from, err := headerValue(p.From)
+    if err != nil {
+        return err
+    }
+    to, err := headerValue(msg.To)
+    if err != nil {
+        return err
+    }
+
+    var dialer net.Dialer
+    conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(p.Host, p.Port))
+    if err != nil {
+        return err
+    }
+    defer conn.Close()
+    deadline, ok := ctx.Deadline()
+    if !ok {
+        deadline = time.Now().Add(smtpTimeout)
+    }
+    if err := conn.SetDeadline(deadline); err != nil {
+        return err
+    }
+    // Al cancelar el contexto se corta la conexión y falla la operación en curso
+    stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+    defer stop()
+
+    client, err := smtp.NewClient(conn, p.Host)
+    if err != nil {
+        return contextError(ctx, err)
+    }
+    defer client.Close()
+
+    if ok, _ := client.Extension("STARTTLS"); ok {
+        if err := client.StartTLS(&tls.Config{ServerName: p.Host}); err != nil {
+            return contextError(ctx, err)
+        }
+    }
+    if p.Username != "" {
+        if err := client.Auth(smtp.PlainAuth("", p.Username, p.Password, p.Host)); err != nil {
+            return contextError(ctx, err)
+        }
+    }
+    if err := client.Mail(from); err != nil {
+        return contextError(ctx, err)
+    }
+    if err := client.Rcpt(to); err != nil {
+        return contextError(ctx, err)
+    }
+
+    w, err := client.Data()
+    if err != nil {
+        return contextError(ctx, err)
+    }
+    var body bytes.Buffer
+    fmt.Fprintf(&body, "From: %s\r\n", from)
+    fmt.Fprintf(&body, "To: %s\r\n", to)
+    // El asunto incluye datos del cliente, como el nombre de la mascota: los
+    // saltos de línea no pueden añadir cabeceras
+    subject := strings.Join(strings.Fields(msg.Subject), " ")
+    fmt.Fprintf(&body, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", subject))
+    body.WriteString("MIME-Version: 1.0\r\n")
+    body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+    body.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+    if _, err := w.Write(body.Bytes()); err != nil {
+        return contextError(ctx, err)
+    }
+    if err := w.Close(); err != nil {
+        return contextError(ctx, err)
+    }
+    return contextError(ctx, client.Quit())
+}
+
+// headerValue rechaza las direcciones con saltos de línea, que permitirían
+// añadir cabeceras o destinatarios al mensaje.
+func headerValue(value string) (string, error) {
+    if strings.ContainsAny(value, "\r\n") {
+        return "", fmt.Errorf("dirección de correo inválida: %q", value)
+    }
+    return value, nil
+}
+
+// contextError devuelve el error del contexto si la operación falló porque
+// se canceló o venció su plazo.
+func contextError(ctx context.Context, err error) error {
+    if err != nil && ctx.Err() != nil {
+        return ctx.Err()
+    }
+    return err
+}
+
+// HTTPSMSProvider envía SMS a través de una pasarela HTTP genérica que acepta
+// un POST JSON con los campos to, from y message.
+type HTTPSMSProvider struct {
+    URL    string
+    Token  string
+    From   string
+    Client *http.Client
+}
+
+func (p *HTTPSMSProvider) Send(ctx context.Context, msg Message) error {
+    payload, err := json.Marshal(map[string]string{
+        "to":      msg.To,
+        "from":    p.From,
+        "message": msg.Body,
+    })
+    if err != nil {
+        return err
+    }
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(payload))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    if p.Token != "" {
+        req.Header.Set("Authorization", "Bearer "+p.Token)
+    }
+
+    client := p.Client
+    if client == nil {
+        client = &http.Client{Timeout: 10 * time.Second}
+    }
+
+    resp, err := client.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+        return fmt.Errorf("pasarela SMS respondió %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+    }
+    return nil
+}
+
+// LogProvider escribe cada mensaje como una línea JSON. Útil en desarrollo y tests.
+type LogProvider struct {
+    mu sync.Mutex
+    w  io.Writer
+}
+
+func NewLogProvider(w io.Writer) *LogProvider {
+    return &LogProvider{w: w}
+}
+
+// NewFileProvider crea un LogProvider que añade los mensajes al fichero indicado.
+func NewFileProvider(path string) (*LogProvider, error) {
+    f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+    if err != nil {
+        return nil, err
+    }
+    return NewLogProvider(f), nil
+}
+
+func (p *LogProvider) Send(ctx context.Context, msg Message) error {
+    line, err := json.Marshal(msg)
+    if err != nil {
+        return err
+    }
+
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    _, err = p.w.Write(append(line, '\n'))
+    return err
+}
@@ -0,0 +1,176 @@
+// internal/reminders/scheduler.go
+package reminders
+
+import (
+    "context"
+    "log/slog"
+    "sort"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "github.com/javice/vet-clinic-api/internal/timezone"
+)
+
+// DefaultClaimTimeout es el tiempo por defecto tras el que se reintenta un
+// envío reservado que no ha terminado
+const DefaultClaimTimeout = 10 * time.Minute
+
+// Config define cuándo y cómo se envían los recordatorios.
+type Config struct {
+    // LeadTimes son las antelaciones con las que se avisa (p. ej. 24h y 2h)
+    LeadTimes []time.Duration
+    // Interval es la frecuencia con la que se buscan citas próximas
+    Interval time.Duration
+    // MaxAttempts limita los reintentos de un envío fallido
+    MaxAttempts int
+    // ClaimTimeout es el tiempo tras el que un envío reservado que no ha
+    // terminado se da por perdido y se puede reintentar
+    ClaimTimeout time.Duration
+    Templates   Templates
+    // TimeZone es la zona horaria de las clínicas que no indican otra
+    TimeZone *time.Location
+    // Now permite fijar el reloj en los tests
+    Now func() time.Time
+}
+
+// Scheduler busca periódicamente citas próximas y envía los recordatorios
+// por los canales que tengan proveedor configurado.
+type Scheduler struct {
+    repo      *repositories.ReminderRepository
+    providers map[string]Provider
+    cfg       Config
+}
+
+func NewScheduler(repo *repositories.ReminderRepository, providers map[string]Provider, cfg Config) *Scheduler {
+    if len(cfg.LeadTimes) == 0 {
+        cfg.LeadTimes = []time.Duration{24 * time.Hour, 2 * time.Hour}
+    }
+    if cfg.Interval <= 0 {
+        cfg.Interval = time.Minute
+    }
+    if cfg.MaxAttempts <= 0 {
+        cfg.MaxAttempts = 3
+    }
+    if cfg.ClaimTimeout <= 0 {
+        cfg.ClaimTimeout = DefaultClaimTimeout
+    }
+    if cfg.Templates.EmailBody == nil {
+        cfg.Templates = DefaultTemplates()
+    }
+    if cfg.Now == nil {
+        cfg.Now = time.Now
+    }
+    if cfg.TimeZone == nil {
+        cfg.TimeZone = time.Local
+    }
+
+    // Ordenar de menor a mayor antelación
+    leadTimes := append([]time.Duration(nil), cfg.LeadTimes...)
+    sort.Slice(leadTimes, func(i, j int) bool { return leadTimes[i] < leadTimes[j] })
+    cfg.LeadTimes = leadTimes
+
+    return &Scheduler{repo: repo, providers: providers, cfg: cfg}
+}
+
+// Start ejecuta el planificador hasta que se cancele el contexto.
+func (s *Scheduler) Start(ctx context.Context) {
+    ticker := time.NewTicker(s.cfg.Interval)
+    defer ticker.Stop()
+
+    for {
+        if err := s.RunOnce(ctx); err != nil {
+            slog.ErrorContext(ctx, "Error enviando recordatorios", "error", err)
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// RunOnce envía los recordatorios pendientes en este momento. Para cada cita
+// se usa la menor antelación que ya se ha alcanzado, de modo que una cita
+// creada con poca antelación no recibe también los avisos anteriores.
+func (s *Scheduler) RunOnce(ctx context.Context) error {
+    if len(s.providers) == 0 {
+        return nil
+    }
+
+    now := s.cfg.Now()
+    maxLead := s.cfg.LeadTimes[len(s.cfg.LeadTimes)-1]
+
+    targets, err := s.repo.GetUpcoming(now, now.Add(maxLead))
+    if err != nil {
+        return err
+    }
+
+    for _, target := range targets {
+        if ctx.Err() != nil {
+            return ctx.Err()
+        }
+
+        lead := s.leadTimeFor(target.Date.Sub(now))
+        for channel, provider := range s.providers {
+            if err := s.send(ctx, channel, provider, target, lead); err != nil {
+                slog.ErrorContext(ctx, "Error enviando recordatorio", "channel", channel, "appointment_id", target.AppointmentID, "error", err)
+            }
+        }
+    }
+
+    return nil
+}
+
+func (s *Scheduler) leadTimeFor(until time.Duration) time.Duration {
+    for _, lead := range s.cfg.LeadTimes {
+        if until <= lead {
+            return lead
+        }
+    }
+    return s.cfg.LeadTimes[len(s.cfg.LeadTimes)-1]
+}
+
+func (s *Scheduler) send(ctx context.Context, channel string, provider Provider, target repositories.ReminderTarget, lead time.Duration) error {
+    recipient := target.Email
+    if channel == ChannelSMS {
+        recipient = target.Phone
+    }
+    if recipient == "" {
+        return nil
+    }
+
+    reminder := models.Reminder{
+        AppointmentID: target.AppointmentID,
+        LeadTime:      lead.String(),
+        Channel:       channel,
+        Recipient:     recipient,
+    }
+    claimed, err := s.repo.Claim(&reminder, s.cfg.MaxAttempts, s.cfg.Now().Add(-s.cfg.ClaimTimeout))
+    if err != nil || !claimed {
+        return err
+    }
+
+    msg, err := s.cfg.Templates.Render(channel, TemplateData{
+        ClientName: target.ClientName,
+        PetName:    target.PetName,
+        Date:       timezone.In(target.Date, target.TimeZone, s.cfg.TimeZone),
+        Reason:     target.Reason,
+        Duration:   target.Duration,
+        LeadTime:   lead,
+    })
+    if err == nil {
+        msg.To = recipient
+        err = provider.Send(ctx, msg)
+    }
+
+    if err != nil {
+        if markErr := s.repo.MarkFailed(&reminder, err); markErr != nil {
+            return markErr
+        }
+        return err
+    }
+
+    return s.repo.MarkSent(&reminder, s.cfg.Now())
+}
@@ -0,0 +1,111 @@
+// internal/reminders/template.go
+package reminders
+
+import (
+    "bytes"
+    "errors"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "text/template"
+    "time"
+)
+
+// Plantillas por defecto
+const (
+    DefaultEmailSubject = `Recordatorio: cita de {{.PetName}} el {{.Date.Format "02/01/2006"}}`
+    DefaultEmailBody    = `Hola {{.ClientName}},
+
+Le recordamos que {{.PetName}} tiene una cita el {{.Date.Format "02/01/2006"}} a las {{.Date.Format "15:04"}}.
+Motivo: {{.Reason}}
+
+Si no puede acudir, por favor avísenos con antelación.
+`
+    DefaultSMSBody = `Recordatorio: cita de {{.PetName}} el {{.Date.Format "02/01"}} a las {{.Date.Format "15:04"}}. Motivo: {{.Reason}}`
+)
+
+// TemplateData son los datos disponibles en las plantillas.
+type TemplateData struct {
+    ClientName string
+    PetName    string
+    Date       time.Time
+    Reason     string
+    Duration   int
+    LeadTime   time.Duration
+}
+
+// Templates agrupa las plantillas de los distintos canales.
+type Templates struct {
+    EmailSubject *template.Template
+    EmailBody    *template.Template
+    SMSBody      *template.Template
+}
+
+// DefaultTemplates devuelve las plantillas por defecto.
+func DefaultTemplates() Templates {
+    return Templates{
+        EmailSubject: template.Must(template.New("email_subject").Parse(DefaultEmailSubject)),
+        EmailBody:    template.Must(template.New("email_body").Parse(DefaultEmailBody)),
+        SMSBody:      template.Must(template.New("sms_body").Parse(DefaultSMSBody)),
+    }
+}
+
+// LoadTemplates carga las plantillas email_subject.tmpl, email_body.tmpl y
+// sms_body.tmpl del directorio indicado. Las que no existan usan el valor por defecto.
+func LoadTemplates(dir string) (Templates, error) {
+    templates := DefaultTemplates()
+    if dir == "" {
+        return templates, nil
+    }
+
+    files := map[string]**template.Template{
+        "email_subject.tmpl": &templates.EmailSubject,
+        "email_body.tmpl":    &templates.EmailBody,
+        "sms_body.tmpl":      &templates.SMSBody,
+    }
+    for name, tmpl := range files {
+        content, err := os.ReadFile(filepath.Join(dir, name))
+        if errors.Is(err, fs.ErrNotExist) {
+            continue
+        }
+        if err != nil {
+            return templates, err
+        }
+
+        parsed, err := template.New(name).Parse(string(content))
+        if err != nil {
+            return templates, err
+        }
+        *tmpl = parsed
+    }
+
+    return templates, nil
+}
+
+// Render genera el mensaje para el canal indicado.
+func (t Templates) Render(channel string, data TemplateData) (Message, error) {
+    msg := Message{Channel: channel}
+
+    var err error
+    switch channel {
+    case ChannelEmail:
+        if msg.Subject, err = execute(t.EmailSubject, data); err != nil {
+            return msg, err
+        }
+        msg.Body, err = execute(t.EmailBody, data)
+    case ChannelSMS:
+        msg.Body, err = execute(t.SMSBody, data)
+    default:
+        err = errors.New("canal de recordatorio desconocido: " + channel)
+    }
+
+    return msg, err
+}
+
+func execute(tmpl *template.Template, data TemplateData) (string, error) {
+    var buf bytes.Buffer
+    if err := tmpl.Execute(&buf, data); err != nil {
+        return "", err
+    }
+    return buf.String(), nil
+}
@@ -0,0 +1,232 @@
+// internal/repositories/admission.go
+package repositories
+
+import (
+    "context"
+    "errors"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "gorm.io/gorm"
+)
+
+type AdmissionRepository struct {
+    DB *gorm.DB
+}
+
+func NewAdmissionRepository(db *gorm.DB) *AdmissionRepository {
+    return &AdmissionRepository{DB: db}
+}
+
+// WithContext devuelve una copia del repositorio que propaga el contexto
+// (actor, clínica, cancelación...) a las consultas.
+func (r *AdmissionRepository) WithContext(ctx context.Context) *AdmissionRepository {
+    return &AdmissionRepository{DB: r.DB.WithContext(ctx)}
+}
+
+// GetAll devuelve los ingresos, los más recientes primero, con su jaula.
+// Con current solo devuelve las mascotas que siguen ingresadas y con petID
+// solo los de esa mascota.
+func (r *AdmissionRepository) GetAll(current bool, petID uint, preloads ...string) ([]models.Admission, error) {
+    var admissions []models.Admission
+    query := preload(r.DB.Preload("Kennel"), preloads).Order("admitted_at DESC").Order("id DESC")
+    if current {
+        query = query.Where("discharged_at IS NULL")
+    }
+    if petID != 0 {
+        query = query.Where("pet_id = ?", petID)
+    }
+    result := query.Find(&admissions)
+    return admissions, result.Error
+}
+
+// GetByID devuelve el ingreso con su jaula y su hoja de tratamiento por
+// orden de hora.
+func (r *AdmissionRepository) GetByID(id uint, preloads ...string) (models.Admission, error) {
+    var admission models.Admission
+    result := preload(r.DB, preloads).
+        Preload("Kennel").
+        Preload("Treatments", func(db *gorm.DB) *gorm.DB { return db.Order("scheduled_at").Order("id") }).
+        First(&admission, id)
+    return admission, result.Error
+}
+
+// Admit ingresa a la mascota. Comprueba que no está ya ingresada, que la
+// cita de origen es suya y que la jaula existe y está libre.
+func (r *AdmissionRepository) Admit(admission *models.Admission) error {
+    admission.DischargedAt = nil
+    admission.DischargeNotes = ""
+    admission.Treatments = nil
+    if admission.AdmittedAt.IsZero() {
+        admission.AdmittedAt = time.Now()
+    }
+
+    return r.DB.Transaction(func(tx *gorm.DB) error {
+        var count int64
+        err := tx.Model(&models.Admission{}).
+            Where("pet_id = ? AND discharged_at IS NULL", admission.PetID).
+            Count(&count).Error
+        if err != nil {
+            return err
+        }
+        if count > 0 {
+            return ErrAlreadyAdmitted
+        }
+
+        if admission.AppointmentID != nil {
+            var appointment models.Appointment
+            err := tx.Select("id", "pet_id").First(&appointment, *admission.AppointmentID).Error
+            if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && appointment.PetID != admission.PetID) {
+                return ErrAdmissionAppointment
+            }
+            if err != nil {
+                return err
+            }
+        }
+
+        if admission.KennelID != nil {
+            if err := checkKennel(tx, *admission.KennelID, 0); err != nil {
+                return err
+            }
+        }
+        return tx.Omit("Pet", "Appointment", "Kennel", "Treatments").Create(admission).Error
+    })
+}
+
+// MoveKennel cambia de jaula a una mascota ingresada, o la deja sin jaula
+// si kennelID es nil.
+func (r *AdmissionRepository) MoveKennel(id uint, kennelID *uint) error {
+    return r.DB.Transaction(func(tx *gorm.DB) error {
+        admission, err := currentAdmission(tx, id)
+        if err != nil {
+            return err
+        }
+        if kennelID != nil {
+            if err := checkKennel(tx, *kennelID, admission.ID); err != nil {
+                return err
+            }
+        }
+        return tx.Model(&admission).Update("kennel_id", kennelID).Error
+    })
+}
+
+// Discharge da el alta a la mascota a la hora indicada, que no puede ser
+// anterior al ingreso. Devuelve ErrAlreadyDischarged si ya tenía el alta.
+func (r *AdmissionRepository) Discharge(id uint, at time.Time, notes string) error {
+    return r.DB.Transaction(func(tx *gorm.DB) error {
+        admission, err := currentAdmission(tx, id)
+        if err != nil {
+            return err
+        }
+        if at.Before(admission.AdmittedAt) {
+            return ErrDischargeBeforeAdmission
+        }
+        return tx.Model(&admission).Updates(map[string]interface{}{
+            "discharged_at":   at,
+            "discharge_notes": notes,
+        }).Error
+    })
+}
+
+// AddTreatments añade tareas a la hoja de tratamiento de una mascota
+// ingresada.
+func (r *AdmissionRepository) AddTreatments(id uint, treatments []models.Treatment) error {
+    return r.DB.Transaction(func(tx *gorm.DB) error {
+        admission, err := currentAdmission(tx, id)
+        if err != nil {
+            return err
+        }
+        for i := range treatments {
+            treatments[i].ID = 0
+            treatments[i].AdmissionID = admission.ID
+            treatments[i].ClinicID = admission.ClinicID
+            treatments[i].DoneAt = nil
+            treatments[i].DoneBy = ""
+        }
+        return tx.Create(&treatments).Error
+    })
+}
+
+// CompleteTreatment marca como hecha una tarea del ingreso con la hora y
+// las iniciales de quien la hizo. Devuelve ErrTreatmentDone si ya estaba
+// hecha y ErrAlreadyDischarged si la mascota ya tiene el alta.
+func (r *AdmissionRepository) CompleteTreatment(admissionID, treatmentID uint, at time.Time, initials, notes string) (models.Treatment, error) {
+    var treatment models.Treatment
+    err := r.DB.Transaction(func(tx *gorm.DB) error {
+        if _, err := currentAdmission(tx, admissionID); err != nil {
+            return err
+        }
+        if err := tx.Where("admission_id = ?", admissionID).First(&treatment, treatmentID).Error; err != nil {
+            return err
+        }
+        updates := map[string]interface{}{"done_at": at, "done_by": initials}
+        if notes != "" {
+            updates["notes"] = notes
+        }
+        result := tx.Model(&treatment).Where("done_at IS NULL").Updates(updates)
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return ErrTreatmentDone
+        }
+        return tx.First(&treatment, treatmentID).Error
+    })
+    return treatment, err
+}
+
+// Ward devuelve las mascotas ingresadas con su mascota, su jaula y las
+// tareas pendientes de su hoja de tratamiento por orden de hora.
+func (r *AdmissionRepository) Ward() ([]models.Admission, error) {
+    var admissions []models.Admission
+    result := r.DB.
+        Preload("Pet").
+        Preload("Kennel").
+        Preload("Treatments", func(db *gorm.DB) *gorm.DB {
+            return db.Where("done_at IS NULL").Order("scheduled_at").Order("id")
+        }).
+        Where("discharged_at IS NULL").
+        Order("admitted_at").
+        Find(&admissions)
+    return admissions, result.Error
+}
+
+// currentAdmission devuelve el ingreso si la mascota sigue ingresada o
+// ErrAlreadyDischarged si ya tiene el alta.
+func currentAdmission(tx *gorm.DB, id uint) (models.Admission, error) {
+    var admission models.Admission
+    if err := tx.First(&admission, id).Error; err != nil {
+        return admission, err
+    }
+    if !admission.Current() {
+        return admission, ErrAlreadyDischarged
+    }
+    return admission, nil
+}
+
+// checkKennel comprueba que la jaula existe y que no la ocupa otra mascota
+// ingresada, salvo la del ingreso exclude.
+func checkKennel(tx *gorm.DB, kennelID, exclude uint) error {
+    var count int64
+    err := tx.Model(&models.Resource{}).
+        Where("id = ? AND kind = ?", kennelID, models.ResourceKennel).
+        Count(&count).Error
+    if err != nil {
+        return err
+    }
+    if count == 0 {
+        return ErrKennelNotFound
+    }
+
+    query := tx.Model(&models.Admission{}).Where("kennel_id = ? AND discharged_at IS NULL", kennelID)
+    if exclude != 0 {
+        query = query.Where("id <> ?", exclude)
+    }
+    if err := query.Count(&count).Error; err != nil {
+        return err
+    }
+    if count > 0 {
+        return ErrKennelOccupied
+    }
+    return nil
+}
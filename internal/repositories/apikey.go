@@ -0,0 +1,84 @@
+// internal/repositories/apikey.go
+package repositories
+
+import (
+    "context"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "gorm.io/gorm"
+)
+
+type APIKeyRepository struct {
+    DB *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+    return &APIKeyRepository{DB: db}
+}
+
+// WithContext devuelve una copia del repositorio que propaga el contexto
+// (actor, cancelación...) a las consultas.
+func (r *APIKeyRepository) WithContext(ctx context.Context) *APIKeyRepository {
+    return &APIKeyRepository{DB: r.DB.WithContext(ctx)}
+}
+
+func (r *APIKeyRepository) Create(key *models.APIKey) error {
+    return r.DB.Create(key).Error
+}
+
+func (r *APIKeyRepository) GetAll() ([]models.APIKey, error) {
+    var keys []models.APIKey
+    result := r.DB.Order("id").Find(&keys)
+    return keys, result.Error
+}
+
+func (r *APIKeyRepository) GetByID(id uint) (models.APIKey, error) {
+    var key models.APIKey
+    result := r.DB.First(&key, id)
+    return key, result.Error
+}
+
+// GetByHash busca una clave por su hash, esté activa o no.
+func (r *APIKeyRepository) GetByHash(hash string) (models.APIKey, error) {
+    var key models.APIKey
+    result := r.DB.Where("hash = ?", hash).First(&key)
+    return key, result.Error
+}
+
+// Revoke anula la clave. Devuelve ErrAPIKeyRevoked si ya estaba anulada.
+func (r *APIKeyRepository) Revoke(id uint, now time.Time) (models.APIKey, error) {
+    key, err := r.GetByID(id)
+    if err != nil {
+        return key, err
+    }
+    if key.RevokedAt != nil {
+        return key, ErrAPIKeyRevoked
+    }
+
+    key.RevokedAt = &now
+    err = r.DB.Model(&key).Update("revoked_at", now).Error
+    return key, err
+}
+
+// Rotate sustituye el secreto de la clave conservando su ID, nombre,
+// permisos y caducidad. La clave anterior deja de funcionar en el acto.
+func (r *APIKeyRepository) Rotate(id uint, prefix, hash string) (models.APIKey, error) {
+    key, err := r.GetByID(id)
+    if err != nil {
+        return key, err
+    }
+    if key.RevokedAt != nil {
+        return key, ErrAPIKeyRevoked
+    }
+
+    key.Prefix = prefix
+    key.Hash = hash
+    err = r.DB.Model(&key).Updates(map[string]interface{}{"prefix": prefix, "hash": hash}).Error
+    return key, err
+}
+
+// Touch registra el último uso de la clave.
+func (r *APIKeyRepository) Touch(id uint, usedAt time.Time) error {
+    return r.DB.Model(&models.APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", usedAt).Error
+}
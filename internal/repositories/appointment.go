@@ -2,8 +2,12 @@
 package repositories
 
 import (
+    "context"
+    "time"
+
     "github.com/javice/vet-clinic-api/internal/models"
     "gorm.io/gorm"
+    "gorm.io/gorm/clause"
 )
 
 type AppointmentRepository struct {
@@ -14,32 +18,204 @@ func NewAppointmentRepository(db *gorm.DB) *AppointmentRepository {
     return &AppointmentRepository{DB: db}
 }
 
-func (r *AppointmentRepository) GetAll() ([]models.Appointment, error) {
+// WithContext devuelve una copia del repositorio que propaga el contexto
+// (actor, cancelación...) a las consultas.
+func (r *AppointmentRepository) WithContext(ctx context.Context) *AppointmentRepository {
+    return &AppointmentRepository{DB: r.DB.WithContext(ctx)}
+}
+
+func (r *AppointmentRepository) GetAll(preloads ...string) ([]models.Appointment, error) {
+    var appointments []models.Appointment
+    result := preload(r.DB, preloads).Find(&appointments)
+    return appointments, result.Error
+}
+
+// AppointmentFilter limita los listados de citas. Los campos vacíos no
+// filtran.
+type AppointmentFilter struct {
+    PetID uint
+    // From y To limitan la fecha de la cita al intervalo [From, To)
+    From time.Time
+    To   time.Time
+}
+
+// Apply añade a la consulta las condiciones del filtro. La usan también las
+// exportaciones, para filtrar igual que el listado.
+func (f AppointmentFilter) Apply(query *gorm.DB) *gorm.DB {
+    if f.PetID != 0 {
+        query = query.Where("pet_id = ?", f.PetID)
+    }
+    if !f.From.IsZero() {
+        query = query.Where("date >= ?", f.From)
+    }
+    if !f.To.IsZero() {
+        query = query.Where("date < ?", f.To)
+    }
+    return query
+}
+
+// Find devuelve las citas que cumplen el filtro, ordenadas por fecha.
+func (r *AppointmentRepository) Find(filter AppointmentFilter, preloads ...string) ([]models.Appointment, error) {
     var appointments []models.Appointment
-    result := r.DB.Find(&appointments)
+    result := filter.Apply(preload(r.DB, preloads)).Order("date").Find(&appointments)
     return appointments, result.Error
 }
 
-func (r *AppointmentRepository) GetByID(id uint) (models.Appointment, error) {
+func (r *AppointmentRepository) GetByID(id uint, preloads ...string) (models.Appointment, error) {
     var appointment models.Appointment
-    result := r.DB.First(&appointment, id)
+    result := preload(r.DB, preloads).First(&appointment, id)
     return appointment, result.Error
 }
 
-func (r *AppointmentRepository) GetByPetID(petID uint) ([]models.Appointment, error) {
+func (r *AppointmentRepository) GetByPetID(petID uint, preloads ...string) ([]models.Appointment, error) {
     var appointments []models.Appointment
-    result := r.DB.Where("pet_id = ?", petID).Find(&appointments)
+    result := preload(r.DB, preloads).Where("pet_id = ?", petID).Find(&appointments)
     return appointments, result.Error
 }
 
+// Create da de alta la cita con los valores por defecto de su tipo. Si el
+// veterinario o algún recurso ya está ocupado durante su duración devuelve
+// un *ScheduleConflictError.
 func (r *AppointmentRepository) Create(appointment *models.Appointment) error {
-    return r.DB.Create(appointment).Error
+    appointment.Version = 1
+    appointment.Status = models.AppointmentScheduled
+    return r.DB.Transaction(func(tx *gorm.DB) error {
+        if err := applyAppointmentType(tx, appointment); err != nil {
+            return err
+        }
+        if err := checkSchedule(tx, appointment); err != nil {
+            return err
+        }
+        return tx.Omit(clause.Associations).Create(appointment).Error
+    })
+}
+
+// Update guarda la cita solo si su versión sigue siendo expectedVersion, e
+// incrementa la versión. Aplica el tipo de cita y comprueba los
+// solapamientos igual que Create. Si cambia la fecha, los recordatorios se
+// vuelven a enviar.
+func (r *AppointmentRepository) Update(appointment *models.Appointment, expectedVersion uint) error {
+    err := r.DB.Transaction(func(tx *gorm.DB) error {
+        // El estado no se modifica aquí, pero las citas canceladas no
+        // ocupan la agenda
+        var current models.Appointment
+        err := tx.Select("status", "date").Where("id = ?", appointment.ID).Limit(1).Find(&current).Error
+        if err != nil {
+            return err
+        }
+        appointment.Status = current.Status
+        if err := applyAppointmentType(tx, appointment); err != nil {
+            return err
+        }
+        if err := checkSchedule(tx, appointment); err != nil {
+            return err
+        }
+
+        appointment.Version = expectedVersion + 1
+        result := tx.Model(appointment).
+            Where("version = ?", expectedVersion).
+            Select("*").
+            Omit("id", "clinic_id", "status", "created_at", "deleted_at", clause.Associations).
+            Updates(appointment)
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return ErrVersionConflict
+        }
+
+        // Los recordatorios ya enviados avisaban de la fecha anterior
+        if !current.Date.Equal(appointment.Date) {
+            return tx.Where("appointment_id = ?", appointment.ID).Delete(&models.Reminder{}).Error
+        }
+        return nil
+    })
+    if err != nil {
+        appointment.Version = expectedVersion
+    }
+    return err
+}
+
+// SetStatus cancela una cita programada o la marca como ausencia, e
+// incrementa su versión. Devuelve ErrNotScheduled si la cita ya está
+// completada, cancelada o marcada como ausencia.
+func (r *AppointmentRepository) SetStatus(id uint, status string) (models.Appointment, error) {
+    result := r.DB.Model(&models.Appointment{}).
+        Where("id = ? AND status = ? AND completed = ?", id, models.AppointmentScheduled, false).
+        Updates(map[string]interface{}{"status": status, "version": gorm.Expr("version + 1")})
+    if result.Error != nil {
+        return models.Appointment{}, result.Error
+    }
+    if result.RowsAffected == 0 {
+        if _, err := r.GetByID(id); err != nil {
+            return models.Appointment{}, err
+        }
+        return models.Appointment{}, ErrNotScheduled
+    }
+    return r.GetByID(id)
+}
+
+// Delete archiva (borrado lógico) una cita si su versión sigue siendo
+// expectedVersion.
+func (r *AppointmentRepository) Delete(id uint, expectedVersion uint) error {
+    result := r.DB.Model(&models.Appointment{}).
+        Where("id = ? AND version = ?", id, expectedVersion).
+        Updates(archiveColumns(time.Now()))
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return ErrVersionConflict
+    }
+    return nil
 }
 
-func (r *AppointmentRepository) Update(appointment *models.Appointment) error {
-    return r.DB.Save(appointment).Error
+// Restore recupera una cita archivada. La mascota debe estar activa y el
+// veterinario y los recursos, libres.
+func (r *AppointmentRepository) Restore(id uint) (models.Appointment, error) {
+    var appointment models.Appointment
+    err := r.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Unscoped().First(&appointment, id).Error; err != nil {
+            return err
+        }
+        if !appointment.DeletedAt.Valid {
+            return ErrNotDeleted
+        }
+
+        var pets int64
+        if err := tx.Model(&models.Pet{}).Where("id = ?", appointment.PetID).Count(&pets).Error; err != nil {
+            return err
+        }
+        if pets == 0 {
+            return ErrParentDeleted
+        }
+        if err := checkSchedule(tx, &appointment); err != nil {
+            return err
+        }
+
+        return tx.Unscoped().Model(&appointment).Updates(archiveColumns(nil)).Error
+    })
+    if err != nil {
+        return appointment, err
+    }
+
+    return r.GetByID(id)
 }
 
-func (r *AppointmentRepository) Delete(id uint) error {
-    return r.DB.Delete(&models.Appointment{}, id).Error
+// Purge elimina definitivamente las citas archivadas antes de la fecha
+// indicada, junto con sus recordatorios.
+func (r *AppointmentRepository) Purge(before time.Time) (int64, error) {
+    var purged int64
+    err := r.DB.Transaction(func(tx *gorm.DB) error {
+        ids := tx.Unscoped().Model(&models.Appointment{}).Select("id").
+            Where("deleted_at IS NOT NULL AND deleted_at < ?", before)
+        if err := tx.Where("appointment_id IN (?)", ids).Delete(&models.Reminder{}).Error; err != nil {
+            return err
+        }
+
+        result := tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Delete(&models.Appointment{})
+        purged = result.RowsAffected
+        return result.Error
+    })
+    return purged, err
 }
\ No newline at end of file
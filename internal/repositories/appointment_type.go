@@ -0,0 +1,171 @@
+// internal/repositories/appointment_type.go
+package repositories
+
+import (
+    "context"
+    "errors"
+    "slices"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+type AppointmentTypeRepository struct {
+    DB *gorm.DB
+}
+
+func NewAppointmentTypeRepository(db *gorm.DB) *AppointmentTypeRepository {
+    return &AppointmentTypeRepository{DB: db}
+}
+
+// WithContext devuelve una copia del repositorio que propaga el contexto
+// (actor, clínica, cancelación...) a las consultas.
+func (r *AppointmentTypeRepository) WithContext(ctx context.Context) *AppointmentTypeRepository {
+    return &AppointmentTypeRepository{DB: r.DB.WithContext(ctx)}
+}
+
+func (r *AppointmentTypeRepository) GetAll() ([]models.AppointmentType, error) {
+    var types []models.AppointmentType
+    result := r.DB.Preload("Service").Order("name").Find(&types)
+    return types, result.Error
+}
+
+func (r *AppointmentTypeRepository) GetByID(id uint) (models.AppointmentType, error) {
+    var appointmentType models.AppointmentType
+    result := r.DB.Preload("Service").First(&appointmentType, id)
+    return appointmentType, result.Error
+}
+
+// Create da de alta el tipo de cita. El servicio y los recursos deben
+// existir.
+func (r *AppointmentTypeRepository) Create(appointmentType *models.AppointmentType) error {
+    return r.DB.Transaction(func(tx *gorm.DB) error {
+        if err := checkAppointmentType(tx, appointmentType); err != nil {
+            return err
+        }
+        return tx.Omit(clause.Associations).Create(appointmentType).Error
+    })
+}
+
+func (r *AppointmentTypeRepository) Update(appointmentType *models.AppointmentType) error {
+    return r.DB.Transaction(func(tx *gorm.DB) error {
+        if err := checkAppointmentType(tx, appointmentType); err != nil {
+            return err
+        }
+        result := tx.Model(appointmentType).
+            Select("name", "duration", "buffer_before", "buffer_after", "specialty", "resource_ids", "service_id", "color").
+            Updates(appointmentType)
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return gorm.ErrRecordNotFound
+        }
+        return nil
+    })
+}
+
+// Delete da de baja el tipo de cita. Las citas que ya lo usan lo
+// conservan.
+func (r *AppointmentTypeRepository) Delete(id uint) error {
+    result := r.DB.Delete(&models.AppointmentType{}, id)
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return gorm.ErrRecordNotFound
+    }
+    return nil
+}
+
+// checkAppointmentType comprueba que el servicio y los recursos del tipo de
+// cita existen. Elimina los recursos repetidos.
+func checkAppointmentType(tx *gorm.DB, appointmentType *models.AppointmentType) error {
+    if appointmentType.ServiceID != nil {
+        var count int64
+        if err := tx.Model(&models.Service{}).Where("id = ?", *appointmentType.ServiceID).Count(&count).Error; err != nil {
+            return err
+        }
+        if count == 0 {
+            return ErrServiceNotFound
+        }
+    }
+
+    if len(appointmentType.ResourceIDs) > 0 {
+        ids := slices.Clone(appointmentType.ResourceIDs)
+        slices.Sort(ids)
+        appointmentType.ResourceIDs = slices.Compact(ids)
+
+        var count int64
+        if err := tx.Model(&models.Resource{}).Where("id IN ?", []uint(appointmentType.ResourceIDs)).Count(&count).Error; err != nil {
+            return err
+        }
+        if count != int64(len(appointmentType.ResourceIDs)) {
+            return ErrResourceNotFound
+        }
+    }
+    return nil
+}
+
+// applyAppointmentType completa la cita con los valores de su tipo: la
+// duración, el motivo y los márgenes si no los indica, y los recursos que
+// el tipo reserva siempre. Un margen indicado, aunque sea 0, se respeta; sin
+// tipo, los que no se indican son 0. Si el tipo requiere una especialidad,
+// la cita debe tener un veterinario con ella.
+func applyAppointmentType(tx *gorm.DB, appointment *models.Appointment) error {
+    var appointmentType models.AppointmentType
+    if appointment.TypeID != nil {
+        if err := tx.First(&appointmentType, *appointment.TypeID).Error; err != nil {
+            if errors.Is(err, gorm.ErrRecordNotFound) {
+                return ErrAppointmentTypeNotFound
+            }
+            return err
+        }
+    }
+
+    if appointment.BufferBefore == nil {
+        before := appointmentType.BufferBefore
+        appointment.BufferBefore = &before
+    }
+    if appointment.BufferAfter == nil {
+        after := appointmentType.BufferAfter
+        appointment.BufferAfter = &after
+    }
+    if appointment.TypeID == nil {
+        return nil
+    }
+
+    if appointment.Duration == 0 {
+        appointment.Duration = appointmentType.Duration
+    }
+    if appointment.Reason == "" {
+        appointment.Reason = appointmentType.Name
+    }
+
+    resources := slices.Clone(appointment.ResourceIDs)
+    for _, id := range appointmentType.ResourceIDs {
+        if !slices.Contains(resources, id) {
+            resources = append(resources, id)
+        }
+    }
+    appointment.ResourceIDs = resources
+
+    if appointmentType.Specialty == "" {
+        return nil
+    }
+    if appointment.VetID == nil {
+        return ErrVetSpecialty
+    }
+    var vet models.Vet
+    if err := tx.First(&vet, *appointment.VetID).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return ErrVetNotFound
+        }
+        return err
+    }
+    if !vet.HasSpecialty(appointmentType.Specialty) {
+        return ErrVetSpecialty
+    }
+    return nil
+}
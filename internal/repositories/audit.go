@@ -0,0 +1,115 @@
+// internal/repositories/audit.go
+package repositories
+
+import (
+    "context"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "gorm.io/gorm"
+)
+
+// AuditFilter restringe la consulta del registro de auditoría.
+type AuditFilter struct {
+    Entity   string
+    EntityID uint
+    Actor    string
+    Action   string
+    From     time.Time
+    To       time.Time
+    Limit    int
+    Offset   int
+}
+
+// AuditVerification es el resultado de comprobar la cadena de hashes.
+type AuditVerification struct {
+    Valid    bool `json:"valid"`
+    Checked  int  `json:"checked"`
+    BrokenAt uint `json:"broken_at,omitempty"`
+}
+
+type AuditRepository struct {
+    DB *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+    return &AuditRepository{DB: db}
+}
+
+// WithContext devuelve una copia del repositorio que propaga el contexto
+// (actor, cancelación...) a las consultas.
+func (r *AuditRepository) WithContext(ctx context.Context) *AuditRepository {
+    return &AuditRepository{DB: r.DB.WithContext(ctx)}
+}
+
+func (r *AuditRepository) List(filter AuditFilter) ([]models.AuditLog, error) {
+    query := r.DB.Model(&models.AuditLog{})
+    if filter.Entity != "" {
+        query = query.Where("entity = ?", filter.Entity)
+    }
+    if filter.EntityID != 0 {
+        query = query.Where("entity_id = ?", filter.EntityID)
+    }
+    if filter.Actor != "" {
+        query = query.Where("actor = ?", filter.Actor)
+    }
+    if filter.Action != "" {
+        query = query.Where("action = ?", filter.Action)
+    }
+    if !filter.From.IsZero() {
+        query = query.Where("created_at >= ?", filter.From.UTC())
+    }
+    if !filter.To.IsZero() {
+        query = query.Where("created_at < ?", filter.To.UTC())
+    }
+    if filter.Limit > 0 {
+        query = query.Limit(filter.Limit)
+    }
+
+    var logs []models.AuditLog
+    result := query.Offset(filter.Offset).Order("id DESC").Find(&logs)
+    return logs, result.Error
+}
+
+// Verify recorre la cadena completa y comprueba que ninguna entrada ha sido
+// modificada, eliminada o reordenada, y que la última es la que indica la
+// cabeza de la cadena: si se borran las más recientes, BrokenAt es la última
+// que falta.
+func (r *AuditRepository) Verify() (AuditVerification, error) {
+    verification := AuditVerification{Valid: true}
+    prev := ""
+    var lastID uint
+
+    var batch []models.AuditLog
+    result := r.DB.Order("id").FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+        for _, entry := range batch {
+            verification.Checked++
+            if entry.PrevHash != prev || entry.ComputeHash() != entry.Hash {
+                verification.Valid = false
+                verification.BrokenAt = entry.ID
+                return errStopIteration
+            }
+            prev = entry.Hash
+            lastID = entry.ID
+        }
+        return nil
+    })
+    if result.Error != nil && result.Error != errStopIteration {
+        return verification, result.Error
+    }
+    if !verification.Valid {
+        return verification, nil
+    }
+
+    // Las bases de datos anteriores a la cabeza no la tienen hasta la
+    // siguiente entrada
+    var head models.AuditChainHead
+    if err := r.DB.Limit(1).Find(&head).Error; err != nil {
+        return verification, err
+    }
+    if head.ID != 0 && (head.Hash != prev || head.LastEntryID != lastID) {
+        verification.Valid = false
+        verification.BrokenAt = max(head.LastEntryID, lastID)
+    }
+    return verification, nil
+}
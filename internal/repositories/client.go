@@ -2,8 +2,15 @@
 package repositories
 
 import (
+    "context"
+    "encoding/json"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/audit"
     "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/tenant"
     "gorm.io/gorm"
+    "gorm.io/gorm/clause"
 )
 
 type ClientRepository struct {
@@ -14,27 +21,230 @@ func NewClientRepository(db *gorm.DB) *ClientRepository {
     return &ClientRepository{DB: db}
 }
 
-func (r *ClientRepository) GetAll() ([]models.Client, error) {
+// WithContext devuelve una copia del repositorio que propaga el contexto
+// (actor, cancelación...) a las consultas.
+func (r *ClientRepository) WithContext(ctx context.Context) *ClientRepository {
+    return &ClientRepository{DB: r.DB.WithContext(ctx)}
+}
+
+func (r *ClientRepository) GetAll(preloads ...string) ([]models.Client, error) {
     var clients []models.Client
-    result := r.DB.Find(&clients)
+    result := preload(r.DB, preloads).Find(&clients)
     return clients, result.Error
 }
 
-func (r *ClientRepository) GetByID(id uint) (models.Client, error) {
+func (r *ClientRepository) GetByID(id uint, preloads ...string) (models.Client, error) {
     var client models.Client
-    result := r.DB.Preload("Pets").First(&client, id)
+    result := preload(r.DB, preloads).First(&client, id)
     return client, result.Error
 }
 
+// Exists indica si existe un cliente activo con ese ID.
+func (r *ClientRepository) Exists(id uint) (bool, error) {
+    var count int64
+    result := r.DB.Model(&models.Client{}).Where("id = ?", id).Count(&count)
+    return count > 0, result.Error
+}
+
 func (r *ClientRepository) Create(client *models.Client) error {
-    return r.DB.Create(client).Error
+    client.Version = 1
+    return r.DB.Omit(clause.Associations).Create(client).Error
+}
+
+// Update guarda el cliente solo si su versión sigue siendo expectedVersion,
+// e incrementa la versión. Devuelve ErrVersionConflict si otra petición lo
+// modificó antes.
+func (r *ClientRepository) Update(client *models.Client, expectedVersion uint) error {
+    client.Version = expectedVersion + 1
+    result := r.DB.Model(client).
+        Where("version = ?", expectedVersion).
+        Select("*").
+        Omit("id", "created_at", "deleted_at", clause.Associations).
+        Updates(client)
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        client.Version = expectedVersion
+        return ErrVersionConflict
+    }
+    return nil
+}
+
+// Delete archiva (borrado lógico) un cliente si su versión sigue siendo
+// expectedVersion. Se bloquea si alguna de sus
+// mascotas tiene citas futuras. Si cascade es false y el cliente tiene
+// mascotas activas también se bloquea; si es true las mascotas y sus citas
+// se archivan junto al cliente con la misma marca de tiempo.
+func (r *ClientRepository) Delete(id uint, expectedVersion uint, cascade bool) error {
+    return r.DB.Transaction(func(tx *gorm.DB) error {
+        var client models.Client
+        if err := tx.First(&client, id).Error; err != nil {
+            return err
+        }
+        if client.Version != expectedVersion {
+            return ErrVersionConflict
+        }
+
+        petIDs := tx.Model(&models.Pet{}).Select("id").Where("client_id = ?", id)
+        // El cliente puede estar compartido: sus mascotas pueden tener citas
+        // en otras clínicas, que también bloquean o se archivan
+        all := tenant.AllClinics(tx, tenant.ReasonArchive)
+
+        var future int64
+        err := all.Model(&models.Appointment{}).
+            Where("pet_id IN (?) AND completed = ? AND status = ? AND date > ?", petIDs, false, models.AppointmentScheduled, time.Now()).
+            Count(&future).Error
+        if err != nil {
+            return err
+        }
+        if future > 0 {
+            return ErrHasFutureAppointments
+        }
+
+        if !cascade {
+            var pets int64
+            if err := tx.Model(&models.Pet{}).Where("client_id = ?", id).Count(&pets).Error; err != nil {
+                return err
+            }
+            if pets > 0 {
+                return ErrHasActivePets
+            }
+        }
+
+        now := time.Now()
+        if err := all.Model(&models.Appointment{}).Where("pet_id IN (?)", petIDs).Updates(archiveColumns(now)).Error; err != nil {
+            return err
+        }
+        if err := tx.Model(&models.Pet{}).Where("client_id = ?", id).Updates(archiveColumns(now)).Error; err != nil {
+            return err
+        }
+        return tx.Model(&client).Updates(archiveColumns(now)).Error
+    })
 }
 
-func (r *ClientRepository) Update(client *models.Client) error {
-    return r.DB.Save(client).Error
+// Restore recupera un cliente archivado junto con las mascotas y citas que
+// se archivaron con él.
+func (r *ClientRepository) Restore(id uint) (models.Client, error) {
+    var client models.Client
+    err := r.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Unscoped().First(&client, id).Error; err != nil {
+            return err
+        }
+        if !client.DeletedAt.Valid {
+            return ErrNotDeleted
+        }
+
+        deletedAt := client.DeletedAt.Time
+        petIDs := tx.Unscoped().Model(&models.Pet{}).Select("id").Where("client_id = ? AND deleted_at = ?", id, deletedAt)
+
+        if err := tx.Unscoped().Model(&models.Appointment{}).
+            Where("pet_id IN (?) AND deleted_at = ?", petIDs, deletedAt).
+            Updates(archiveColumns(nil)).Error; err != nil {
+            return err
+        }
+        if err := tx.Unscoped().Model(&models.Pet{}).
+            Where("client_id = ? AND deleted_at = ?", id, deletedAt).
+            Updates(archiveColumns(nil)).Error; err != nil {
+            return err
+        }
+        return tx.Unscoped().Model(&client).Updates(archiveColumns(nil)).Error
+    })
+    if err != nil {
+        return client, err
+    }
+
+    return r.GetByID(id)
 }
 
-func (r *ClientRepository) Delete(id uint) error {
-    return r.DB.Delete(&models.Client{}, id).Error
+// Purge elimina definitivamente los clientes archivados antes de la fecha
+// indicada que ya no tienen mascotas asociadas.
+func (r *ClientRepository) Purge(before time.Time) (int64, error) {
+    result := r.DB.Unscoped().
+        Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+        Where("NOT EXISTS (SELECT 1 FROM pets WHERE pets.client_id = clients.id)").
+        Delete(&models.Client{})
+    if result.Error != nil {
+        return 0, result.Error
+    }
+
+    // Las clínicas de los clientes purgados
+    err := r.DB.Where("client_id NOT IN (SELECT id FROM clients)").Delete(&models.ClientClinic{}).Error
+    return result.RowsAffected, err
 }
 
+// Merge fusiona el cliente duplicado en el superviviente en una sola
+// transacción: le pasa todas sus mascotas (y con ellas sus citas), completa
+// la dirección si no la tenía y archiva el duplicado. La fusión queda
+// registrada en la auditoría del superviviente.
+func (r *ClientRepository) Merge(survivorID, duplicateID uint) (models.Client, error) {
+    err := r.DB.Transaction(func(tx *gorm.DB) error {
+        var survivor, duplicate models.Client
+        if err := tx.First(&survivor, survivorID).Error; err != nil {
+            return err
+        }
+        if err := tx.First(&duplicate, duplicateID).Error; err != nil {
+            return err
+        }
+
+        // También las mascotas archivadas, para no separar el historial
+        var petIDs []uint
+        if err := tx.Unscoped().Model(&models.Pet{}).Where("client_id = ?", duplicateID).Pluck("id", &petIDs).Error; err != nil {
+            return err
+        }
+        if len(petIDs) > 0 {
+            err := tx.Unscoped().Model(&models.Pet{}).Where("id IN ?", petIDs).Updates(map[string]interface{}{
+                "client_id": survivorID,
+                "version":   gorm.Expr("version + 1"),
+            }).Error
+            if err != nil {
+                return err
+            }
+        }
+
+        // El superviviente pasa a estar en todas las clínicas del duplicado
+        var clinicIDs []uint
+        if err := tx.Model(&models.ClientClinic{}).Where("client_id = ?", duplicateID).Pluck("clinic_id", &clinicIDs).Error; err != nil {
+            return err
+        }
+        links := make([]models.ClientClinic, 0, len(clinicIDs))
+        for _, clinicID := range clinicIDs {
+            links = append(links, models.ClientClinic{ClientID: survivorID, ClinicID: clinicID, CreatedAt: time.Now()})
+        }
+        if len(links) > 0 {
+            if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error; err != nil {
+                return err
+            }
+        }
+
+        updates := map[string]interface{}{"version": gorm.Expr("version + 1")}
+        if survivor.Address == "" && duplicate.Address != "" {
+            updates["address"] = duplicate.Address
+        }
+        if err := tx.Model(&survivor).Updates(updates).Error; err != nil {
+            return err
+        }
+        if err := tx.Model(&duplicate).Updates(archiveColumns(time.Now())).Error; err != nil {
+            return err
+        }
+
+        changes, err := json.Marshal(map[string]models.FieldChange{
+            "merged_client_id": {After: duplicateID},
+            "pet_ids":          {After: petIDs},
+        })
+        if err != nil {
+            return err
+        }
+        return audit.Record(tx, &models.AuditLog{
+            Entity:   "client",
+            EntityID: survivorID,
+            Action:   models.AuditMerge,
+            Changes:  changes,
+        })
+    })
+    if err != nil {
+        return models.Client{}, err
+    }
+
+    return r.GetByID(survivorID, "Pets")
+}
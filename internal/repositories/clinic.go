@@ -0,0 +1,92 @@
+// internal/repositories/clinic.go
+package repositories
+
+import (
+    "context"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+type ClinicRepository struct {
+    DB *gorm.DB
+}
+
+func NewClinicRepository(db *gorm.DB) *ClinicRepository {
+    return &ClinicRepository{DB: db}
+}
+
+// WithContext devuelve una copia del repositorio que propaga el contexto
+// (actor, clínica, cancelación...) a las consultas.
+func (r *ClinicRepository) WithContext(ctx context.Context) *ClinicRepository {
+    return &ClinicRepository{DB: r.DB.WithContext(ctx)}
+}
+
+// Create da de alta la clínica. Devuelve ErrClinicNameTaken si ya hay otra
+// con el mismo nombre.
+func (r *ClinicRepository) Create(clinic *models.Clinic) error {
+    return r.DB.Transaction(func(tx *gorm.DB) error {
+        var count int64
+        if err := tx.Model(&models.Clinic{}).Where("LOWER(name) = LOWER(?)", clinic.Name).Count(&count).Error; err != nil {
+            return err
+        }
+        if count > 0 {
+            return ErrClinicNameTaken
+        }
+        return tx.Create(clinic).Error
+    })
+}
+
+func (r *ClinicRepository) GetAll() ([]models.Clinic, error) {
+    var clinics []models.Clinic
+    result := r.DB.Order("id").Find(&clinics)
+    return clinics, result.Error
+}
+
+func (r *ClinicRepository) GetByID(id uint) (models.Clinic, error) {
+    var clinic models.Clinic
+    result := r.DB.First(&clinic, id)
+    return clinic, result.Error
+}
+
+// GetByClient devuelve las clínicas con las que está compartido el cliente.
+func (r *ClinicRepository) GetByClient(clientID uint) ([]models.Clinic, error) {
+    var clinics []models.Clinic
+    result := r.DB.Joins("JOIN client_clinics ON client_clinics.clinic_id = clinics.id").
+        Where("client_clinics.client_id = ?", clientID).
+        Order("clinics.id").
+        Find(&clinics)
+    return clinics, result.Error
+}
+
+// Share comparte el cliente con la clínica. Compartirlo de nuevo no es un
+// error.
+func (r *ClinicRepository) Share(clientID, clinicID uint) error {
+    return r.DB.Clauses(clause.OnConflict{DoNothing: true}).
+        Create(&models.ClientClinic{ClientID: clientID, ClinicID: clinicID, CreatedAt: time.Now()}).Error
+}
+
+// Unshare deja de compartir el cliente con la clínica. Un cliente tiene que
+// estar siempre en al menos una clínica.
+func (r *ClinicRepository) Unshare(clientID, clinicID uint) error {
+    return r.DB.Transaction(func(tx *gorm.DB) error {
+        var count int64
+        if err := tx.Model(&models.ClientClinic{}).Where("client_id = ?", clientID).Count(&count).Error; err != nil {
+            return err
+        }
+
+        result := tx.Where("client_id = ? AND clinic_id = ?", clientID, clinicID).Delete(&models.ClientClinic{})
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return gorm.ErrRecordNotFound
+        }
+        if count <= 1 {
+            return ErrLastClinic
+        }
+        return nil
+    })
+}
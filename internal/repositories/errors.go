@@ -0,0 +1,79 @@
+// internal/repositories/errors.go
+package repositories
+
+import "errors"
+
+var (
+    // ErrHasFutureAppointments se devuelve al eliminar un registro con citas pendientes
+    ErrHasFutureAppointments = errors.New("tiene citas futuras pendientes")
+    // ErrHasActivePets se devuelve al eliminar sin cascada un cliente con mascotas activas
+    ErrHasActivePets = errors.New("el cliente tiene mascotas activas")
+    // ErrNotDeleted se devuelve al restaurar un registro que no está eliminado
+    ErrNotDeleted = errors.New("el registro no está eliminado")
+    // ErrParentDeleted se devuelve al restaurar un registro cuyo padre sigue eliminado
+    ErrParentDeleted = errors.New("el registro padre está eliminado")
+    // ErrVersionConflict se devuelve cuando el registro cambió desde que se leyó
+    ErrVersionConflict = errors.New("el registro ha sido modificado por otra petición")
+    // ErrAPIKeyRevoked se devuelve al modificar una clave de API anulada
+    ErrAPIKeyRevoked = errors.New("la clave de API está anulada")
+    // ErrLastClinic se devuelve al dejar a un cliente sin ninguna clínica
+    ErrLastClinic = errors.New("el cliente tiene que estar en al menos una clínica")
+    // ErrClinicNameTaken se devuelve al crear una clínica con un nombre en uso
+    ErrClinicNameTaken = errors.New("ya existe una clínica con ese nombre")
+    // ErrNotScheduled se devuelve al cancelar una cita que ya no está programada
+    ErrNotScheduled = errors.New("la cita ya está completada, cancelada o marcada como ausencia")
+    // ErrWaitlistClosed se devuelve al cancelar u ofrecer un hueco a una
+    // entrada de la lista de espera que ya no está en espera
+    ErrWaitlistClosed = errors.New("la entrada ya no está en la lista de espera")
+    // ErrOfferClosed se devuelve al aceptar una oferta que ya no está pendiente
+    ErrOfferClosed = errors.New("la oferta ya no está pendiente")
+    // ErrOfferExpired se devuelve al aceptar una oferta fuera de plazo
+    ErrOfferExpired = errors.New("la oferta ha caducado")
+    // ErrVetNotFound se devuelve al asignar a una cita un veterinario que no existe
+    ErrVetNotFound = errors.New("veterinario no encontrado")
+    // ErrResourceNotFound se devuelve al reservar un recurso que no existe
+    ErrResourceNotFound = errors.New("recurso no encontrado")
+    // ErrAppointmentTypeNotFound se devuelve al crear una cita de un tipo que no existe
+    ErrAppointmentTypeNotFound = errors.New("tipo de cita no encontrado")
+    // ErrServiceNotFound se devuelve al asociar a un tipo de cita un servicio que no existe
+    ErrServiceNotFound = errors.New("servicio no encontrado")
+    // ErrVetSpecialty se devuelve cuando el tipo de cita requiere una
+    // especialidad que el veterinario de la cita no tiene
+    ErrVetSpecialty = errors.New("el tipo de cita requiere un veterinario con otra especialidad")
+    // ErrAlreadyAdmitted se devuelve al ingresar una mascota que ya está ingresada
+    ErrAlreadyAdmitted = errors.New("la mascota ya está ingresada")
+    // ErrAlreadyDischarged se devuelve al modificar un ingreso que ya tiene el alta
+    ErrAlreadyDischarged = errors.New("la mascota ya tiene el alta")
+    // ErrDischargeBeforeAdmission se devuelve al dar el alta antes del ingreso
+    ErrDischargeBeforeAdmission = errors.New("el alta no puede ser anterior al ingreso")
+    // ErrAdmissionAppointment se devuelve cuando la cita de origen de un
+    // ingreso no existe o es de otra mascota
+    ErrAdmissionAppointment = errors.New("la cita no existe o es de otra mascota")
+    // ErrKennelNotFound se devuelve al asignar una jaula que no existe
+    ErrKennelNotFound = errors.New("jaula no encontrada")
+    // ErrKennelOccupied se devuelve al asignar una jaula que ocupa otra mascota ingresada
+    ErrKennelOccupied = errors.New("la jaula está ocupada")
+    // ErrTreatmentDone se devuelve al marcar como hecha una tarea que ya lo estaba
+    ErrTreatmentDone = errors.New("la tarea ya está hecha")
+    // ErrUnknownSpecies se devuelve cuando la especie de una mascota no está en el catálogo
+    ErrUnknownSpecies = errors.New("especie desconocida")
+    // ErrUnknownBreed se devuelve cuando la raza de una mascota no es de su especie
+    ErrUnknownBreed = errors.New("raza desconocida para esta especie")
+    // ErrSpeciesTaken se devuelve cuando un nombre o alias de una especie ya
+    // identifica a otra
+    ErrSpeciesTaken = errors.New("ya existe una especie con ese nombre o alias")
+    // ErrBreedTaken se devuelve cuando un nombre o alias de una raza ya
+    // identifica a otra de la misma especie
+    ErrBreedTaken = errors.New("ya existe una raza de la especie con ese nombre o alias")
+    // ErrMicrochipTaken se devuelve al asignar un microchip que ya es de otra mascota
+    ErrMicrochipTaken = errors.New("el microchip ya está registrado en otra mascota")
+    // ErrIdentifierTaken se devuelve al añadir una identificación que ya es de otra mascota
+    ErrIdentifierTaken = errors.New("la identificación ya está registrada en otra mascota")
+    // ErrScheduleConflict se devuelve cuando el veterinario o algún recurso
+    // de la cita ya está ocupado a esa hora; el error concreto es un
+    // *ScheduleConflictError con las citas que se solapan
+    ErrScheduleConflict = errors.New("el veterinario o algún recurso ya está ocupado a esa hora")
+)
+
+// errStopIteration corta un recorrido por lotes sin que sea un error
+var errStopIteration = errors.New("stop iteration")
@@ -0,0 +1,141 @@
+// internal/repositories/identifier.go
+package repositories
+
+import (
+    "encoding/json"
+
+    "github.com/javice/vet-clinic-api/internal/audit"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/tenant"
+    "gorm.io/gorm"
+)
+
+// Identificaciones por las que se puede buscar una mascota
+const (
+    LookupMicrochip  = "microchip"
+    LookupTattoo     = models.IdentifierTattoo
+    LookupLicenseTag = models.IdentifierLicenseTag
+)
+
+// PetLookup es lo mínimo para devolver un animal encontrado a su dueño: la
+// mascota y el nombre y los teléfonos de contacto del propietario.
+type PetLookup struct {
+    PetID     uint   `json:"pet_id"`
+    Name      string `json:"name"`
+    Species   string `json:"species"`
+    Breed     string `json:"breed"`
+    OwnerName string `json:"owner_name"`
+    Phone     string `json:"phone"`
+    Email     string `json:"email"`
+}
+
+// Lookup busca en todas las clínicas la mascota activa con esa
+// identificación y devuelve el contacto de su dueño. Cada búsqueda, con o
+// sin resultado, queda registrada en la auditoría.
+func (r *PetRepository) Lookup(kind, value string) (PetLookup, error) {
+    query := tenant.AllClinics(r.DB, tenant.ReasonLookup).Table("pets").
+        Select("pets.id AS pet_id, pets.name, pets.species, pets.breed, clients.name AS owner_name, clients.phone, clients.email").
+        Joins("JOIN clients ON clients.id = pets.client_id").
+        Where("pets.deleted_at IS NULL")
+    if kind == LookupMicrochip {
+        query = query.Where("pets.microchip = ?", value)
+    } else {
+        query = query.
+            Joins("JOIN pet_identifiers ON pet_identifiers.pet_id = pets.id").
+            Where("pet_identifiers.kind = ? AND pet_identifiers.value = ?", kind, value)
+    }
+
+    var found []PetLookup
+    if err := query.Limit(1).Scan(&found).Error; err != nil {
+        return PetLookup{}, err
+    }
+
+    var result PetLookup
+    if len(found) > 0 {
+        result = found[0]
+    }
+    changes, err := json.Marshal(map[string]models.FieldChange{
+        kind:    {After: value},
+        "found": {After: len(found) > 0},
+    })
+    if err != nil {
+        return PetLookup{}, err
+    }
+    err = audit.Record(r.DB, &models.AuditLog{
+        Entity:   "pet",
+        EntityID: result.PetID,
+        Action:   models.AuditLookup,
+        Changes:  changes,
+    })
+    if err != nil {
+        return PetLookup{}, err
+    }
+
+    if len(found) == 0 {
+        return PetLookup{}, gorm.ErrRecordNotFound
+    }
+    return result, nil
+}
+
+// GetIdentifiers devuelve las demás identificaciones de la mascota.
+func (r *PetRepository) GetIdentifiers(petID uint) ([]models.PetIdentifier, error) {
+    if _, err := r.GetByID(petID); err != nil {
+        return nil, err
+    }
+    identifiers := []models.PetIdentifier{}
+    result := r.DB.Where("pet_id = ?", petID).Order("kind").Order("id").Find(&identifiers)
+    return identifiers, result.Error
+}
+
+// AddIdentifier añade una identificación a la mascota. Devuelve
+// ErrIdentifierTaken si ya identifica a otra mascota.
+func (r *PetRepository) AddIdentifier(identifier *models.PetIdentifier) error {
+    identifier.Value = models.NormalizeIdentifier(identifier.Value)
+    return r.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Select("id").First(&models.Pet{}, identifier.PetID).Error; err != nil {
+            return err
+        }
+        var count int64
+        err := tenant.AllClinics(tx, tenant.ReasonUniqueness).Model(&models.PetIdentifier{}).
+            Where("kind = ? AND value = ?", identifier.Kind, identifier.Value).
+            Count(&count).Error
+        if err != nil {
+            return err
+        }
+        if count > 0 {
+            return ErrIdentifierTaken
+        }
+        return tx.Create(identifier).Error
+    })
+}
+
+// DeleteIdentifier quita una identificación de la mascota.
+func (r *PetRepository) DeleteIdentifier(petID, id uint) error {
+    result := r.DB.Where("pet_id = ?", petID).Delete(&models.PetIdentifier{}, id)
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return gorm.ErrRecordNotFound
+    }
+    return nil
+}
+
+// checkMicrochip comprueba en todas las clínicas, también entre las
+// mascotas archivadas, que el microchip de la mascota no es de otra.
+func checkMicrochip(tx *gorm.DB, pet *models.Pet) error {
+    if pet.Microchip == "" {
+        return nil
+    }
+    var count int64
+    err := tenant.AllClinics(tx, tenant.ReasonUniqueness).Unscoped().Model(&models.Pet{}).
+        Where("microchip = ? AND id <> ?", pet.Microchip, pet.ID).
+        Count(&count).Error
+    if err != nil {
+        return err
+    }
+    if count > 0 {
+        return ErrMicrochipTaken
+    }
+    return nil
+}
@@ -2,8 +2,15 @@
 package repositories
 
 import (
+    "context"
+    "sort"
+    "strings"
+    "time"
+
     "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/tenant"
     "gorm.io/gorm"
+    "gorm.io/gorm/clause"
 )
 
 type PetRepository struct {
@@ -14,32 +21,225 @@ func NewPetRepository(db *gorm.DB) *PetRepository {
     return &PetRepository{DB: db}
 }
 
-func (r *PetRepository) GetAll() ([]models.Pet, error) {
+// WithContext devuelve una copia del repositorio que propaga el contexto
+// (actor, cancelación...) a las consultas.
+func (r *PetRepository) WithContext(ctx context.Context) *PetRepository {
+    return &PetRepository{DB: r.DB.WithContext(ctx)}
+}
+
+func (r *PetRepository) GetAll(preloads ...string) ([]models.Pet, error) {
     var pets []models.Pet
-    result := r.DB.Find(&pets)
+    result := preload(r.DB, preloads).Find(&pets)
     return pets, result.Error
 }
 
-func (r *PetRepository) GetByID(id uint) (models.Pet, error) {
+// PetFilter limita los listados de mascotas. Los campos vacíos no filtran.
+type PetFilter struct {
+    ClientID uint
+    // LifeStage limita a las mascotas en esa etapa de vida el día de Now
+    // (hoy si no se indica); las que no tienen fecha de nacimiento no están
+    // en ninguna
+    LifeStage string
+    Now       time.Time
+}
+
+// Apply añade a la consulta las condiciones del filtro. La usan también las
+// exportaciones, para filtrar igual que el listado.
+func (f PetFilter) Apply(query *gorm.DB) *gorm.DB {
+    if f.ClientID != 0 {
+        query = query.Where("client_id = ?", f.ClientID)
+    }
+    if f.LifeStage != "" {
+        now := f.Now
+        if now.IsZero() {
+            now = time.Now()
+        }
+        sql, vars := lifeStageCondition(f.LifeStage, now)
+        query = query.Where("birth_date > ?", time.Time{}).Where(sql, vars...)
+    }
+    return query
+}
+
+// Find devuelve las mascotas que cumplen el filtro.
+func (r *PetRepository) Find(filter PetFilter, preloads ...string) ([]models.Pet, error) {
+    var pets []models.Pet
+    result := filter.Apply(preload(r.DB, preloads)).Find(&pets)
+    return pets, result.Error
+}
+
+func (r *PetRepository) GetByID(id uint, preloads ...string) (models.Pet, error) {
     var pet models.Pet
-    result := r.DB.First(&pet, id)
+    result := preload(r.DB, preloads).First(&pet, id)
     return pet, result.Error
 }
 
-func (r *PetRepository) GetByClientID(clientID uint) ([]models.Pet, error) {
+// Exists indica si existe una mascota activa con ese ID.
+func (r *PetRepository) Exists(id uint) (bool, error) {
+    var count int64
+    result := r.DB.Model(&models.Pet{}).Where("id = ?", id).Count(&count)
+    return count > 0, result.Error
+}
+
+func (r *PetRepository) GetByClientID(clientID uint, preloads ...string) ([]models.Pet, error) {
     var pets []models.Pet
-    result := r.DB.Where("client_id = ?", clientID).Find(&pets)
+    result := preload(r.DB, preloads).Where("client_id = ?", clientID).Find(&pets)
     return pets, result.Error
 }
 
+// Create da de alta la mascota. Devuelve ErrMicrochipTaken si su microchip
+// ya es de otra mascota.
 func (r *PetRepository) Create(pet *models.Pet) error {
-    return r.DB.Create(pet).Error
+    pet.Version = 1
+    pet.NormalizeBirthDate()
+    return r.DB.Transaction(func(tx *gorm.DB) error {
+        if err := checkMicrochip(tx, pet); err != nil {
+            return err
+        }
+        return tx.Omit(clause.Associations).Create(pet).Error
+    })
 }
 
-func (r *PetRepository) Update(pet *models.Pet) error {
-    return r.DB.Save(pet).Error
+// Update guarda la mascota solo si su versión sigue siendo expectedVersion,
+// e incrementa la versión. Devuelve ErrMicrochipTaken si su microchip ya es
+// de otra mascota.
+func (r *PetRepository) Update(pet *models.Pet, expectedVersion uint) error {
+    pet.Version = expectedVersion + 1
+    pet.NormalizeBirthDate()
+    err := r.DB.Transaction(func(tx *gorm.DB) error {
+        if err := checkMicrochip(tx, pet); err != nil {
+            return err
+        }
+        result := tx.Model(pet).
+            Where("version = ?", expectedVersion).
+            Select("*").
+            Omit("id", "created_at", "deleted_at", clause.Associations).
+            Updates(pet)
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return ErrVersionConflict
+        }
+        return nil
+    })
+    if err != nil {
+        pet.Version = expectedVersion
+    }
+    return err
 }
 
-func (r *PetRepository) Delete(id uint) error {
-    return r.DB.Delete(&models.Pet{}, id).Error
-}
\ No newline at end of file
+// Delete archiva (borrado lógico) una mascota junto con sus citas si su
+// versión sigue siendo expectedVersion. Se bloquea si la mascota tiene citas
+// futuras.
+func (r *PetRepository) Delete(id uint, expectedVersion uint) error {
+    return r.DB.Transaction(func(tx *gorm.DB) error {
+        var pet models.Pet
+        if err := tx.First(&pet, id).Error; err != nil {
+            return err
+        }
+        if pet.Version != expectedVersion {
+            return ErrVersionConflict
+        }
+
+        // La mascota de un cliente compartido puede tener citas en otras
+        // clínicas
+        all := tenant.AllClinics(tx, tenant.ReasonArchive)
+
+        var future int64
+        err := all.Model(&models.Appointment{}).
+            Where("pet_id = ? AND completed = ? AND status = ? AND date > ?", id, false, models.AppointmentScheduled, time.Now()).
+            Count(&future).Error
+        if err != nil {
+            return err
+        }
+        if future > 0 {
+            return ErrHasFutureAppointments
+        }
+
+        now := time.Now()
+        if err := all.Model(&models.Appointment{}).Where("pet_id = ?", id).Updates(archiveColumns(now)).Error; err != nil {
+            return err
+        }
+        return tx.Model(&pet).Updates(archiveColumns(now)).Error
+    })
+}
+
+// Restore recupera una mascota archivada y las citas que se archivaron con
+// ella. El cliente propietario debe estar activo.
+func (r *PetRepository) Restore(id uint) (models.Pet, error) {
+    var pet models.Pet
+    err := r.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Unscoped().First(&pet, id).Error; err != nil {
+            return err
+        }
+        if !pet.DeletedAt.Valid {
+            return ErrNotDeleted
+        }
+
+        var clients int64
+        if err := tx.Model(&models.Client{}).Where("id = ?", pet.ClientID).Count(&clients).Error; err != nil {
+            return err
+        }
+        if clients == 0 {
+            return ErrParentDeleted
+        }
+
+        if err := tx.Unscoped().Model(&models.Appointment{}).
+            Where("pet_id = ? AND deleted_at = ?", id, pet.DeletedAt.Time).
+            Updates(archiveColumns(nil)).Error; err != nil {
+            return err
+        }
+        return tx.Unscoped().Model(&pet).Updates(archiveColumns(nil)).Error
+    })
+    if err != nil {
+        return pet, err
+    }
+
+    return r.GetByID(id)
+}
+
+// Purge elimina definitivamente las mascotas archivadas antes de la fecha
+// indicada que ya no tienen citas asociadas.
+func (r *PetRepository) Purge(before time.Time) (int64, error) {
+    result := r.DB.Unscoped().
+        Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+        Where("NOT EXISTS (SELECT 1 FROM appointments WHERE appointments.pet_id = pets.id)").
+        Delete(&models.Pet{})
+    if result.Error != nil {
+        return 0, result.Error
+    }
+
+    // Las identificaciones de las mascotas purgadas
+    err := r.DB.Where("pet_id NOT IN (SELECT id FROM pets)").Delete(&models.PetIdentifier{}).Error
+    return result.RowsAffected, err
+}
+
+// lifeStageCondition devuelve la condición de las mascotas que están en la
+// etapa de vida el día de now, con las edades de cada especie.
+func lifeStageCondition(stage string, now time.Time) (string, []interface{}) {
+    between := func(ages models.LifeStageAges) (string, []interface{}) {
+        adult, senior := models.BornBy(now, ages.Adult), models.BornBy(now, ages.Senior)
+        switch stage {
+        case models.LifeStagePuppy:
+            return "birth_date > ?", []interface{}{adult}
+        case models.LifeStageAdult:
+            return "birth_date <= ? AND birth_date > ?", []interface{}{adult, senior}
+        default:
+            return "birth_date <= ?", []interface{}{senior}
+        }
+    }
+
+    species := models.LifeStageSpecies()
+    sort.Strings(species)
+    conditions := make([]string, 0, len(species)+1)
+    var vars []interface{}
+    for _, name := range species {
+        sql, args := between(models.LifeStageAgesFor(name))
+        conditions = append(conditions, "(species = ? AND "+sql+")")
+        vars = append(append(vars, name), args...)
+    }
+    sql, args := between(models.DefaultLifeStageAges)
+    conditions = append(conditions, "(species NOT IN ? AND "+sql+")")
+    vars = append(append(vars, species), args...)
+    return "(" + strings.Join(conditions, " OR ") + ")", vars
+}
@@ -0,0 +1,15 @@
+// internal/repositories/preload.go
+package repositories
+
+import (
+    "gorm.io/gorm"
+)
+
+// preload añade a la consulta las asociaciones indicadas, con la notación de
+// GORM (por ejemplo "Pets.Appointments").
+func preload(db *gorm.DB, associations []string) *gorm.DB {
+    for _, association := range associations {
+        db = db.Preload(association)
+    }
+    return db
+}
@@ -0,0 +1,37 @@
+// internal/repositories/purge.go
+package repositories
+
+import (
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// PurgeResult cuenta los registros eliminados definitivamente de cada tabla.
+type PurgeResult struct {
+    Appointments int64 `json:"appointments"`
+    Pets         int64 `json:"pets"`
+    Clients      int64 `json:"clients"`
+}
+
+// PurgeDeleted elimina en una sola transacción las citas, mascotas y
+// clientes archivados antes de before. Se purgan primero los hijos para no
+// dejar registros huérfanos; si algo falla no se elimina nada.
+func PurgeDeleted(db *gorm.DB, before time.Time) (PurgeResult, error) {
+    var result PurgeResult
+    err := db.Transaction(func(tx *gorm.DB) error {
+        var err error
+        if result.Appointments, err = NewAppointmentRepository(tx).Purge(before); err != nil {
+            return err
+        }
+        if result.Pets, err = NewPetRepository(tx).Purge(before); err != nil {
+            return err
+        }
+        result.Clients, err = NewClientRepository(tx).Purge(before)
+        return err
+    })
+    if err != nil {
+        return PurgeResult{}, err
+    }
+    return result, nil
+}
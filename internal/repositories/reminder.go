@@ -0,0 +1,116 @@
+// internal/repositories/reminder.go
+package repositories
+
+import (
+    "context"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// ReminderTarget reúne los datos de una cita y de su propietario necesarios
+// para enviar un recordatorio.
+type ReminderTarget struct {
+    AppointmentID uint
+    Date          time.Time
+    Reason        string
+    Duration      int
+    PetName       string
+    ClientName    string
+    Email         string
+    Phone         string
+    // TimeZone es la zona horaria de la clínica de la cita (vacía si es la
+    // de la instalación)
+    TimeZone string
+}
+
+type ReminderRepository struct {
+    DB *gorm.DB
+}
+
+func NewReminderRepository(db *gorm.DB) *ReminderRepository {
+    return &ReminderRepository{DB: db}
+}
+
+// WithContext devuelve una copia del repositorio que propaga el contexto
+// (actor, cancelación...) a las consultas.
+func (r *ReminderRepository) WithContext(ctx context.Context) *ReminderRepository {
+    return &ReminderRepository{DB: r.DB.WithContext(ctx)}
+}
+
+// GetUpcoming devuelve las citas programadas y no completadas con fecha en
+// el intervalo (from, to]. Las citas archivadas, o de mascotas o clientes
+// archivados, no reciben recordatorios.
+func (r *ReminderRepository) GetUpcoming(from, to time.Time) ([]ReminderTarget, error) {
+    var targets []ReminderTarget
+    result := r.DB.Table("appointments").
+        Select("appointments.id AS appointment_id, appointments.date, appointments.reason, appointments.duration, " +
+            "pets.name AS pet_name, clients.name AS client_name, clients.email, clients.phone, " +
+            "COALESCE(clinics.time_zone, '') AS time_zone").
+        Joins("JOIN pets ON pets.id = appointments.pet_id").
+        Joins("JOIN clients ON clients.id = pets.client_id").
+        Joins("LEFT JOIN clinics ON clinics.id = appointments.clinic_id").
+        Where("appointments.completed = ? AND appointments.status = ? AND appointments.date > ? AND appointments.date <= ?",
+            false, models.AppointmentScheduled, from, to).
+        Where("appointments.deleted_at IS NULL AND pets.deleted_at IS NULL AND clients.deleted_at IS NULL").
+        Order("appointments.date").
+        Scan(&targets)
+    return targets, result.Error
+}
+
+// Claim reserva el envío de un recordatorio. Devuelve false si ya se envió,
+// si otro proceso lo tiene reservado o si se agotaron los reintentos. Una
+// reserva anterior a staleBefore se da por perdida (el proceso se detuvo
+// durante el envío) y cuenta como un intento.
+func (r *ReminderRepository) Claim(reminder *models.Reminder, maxAttempts int, staleBefore time.Time) (bool, error) {
+    reminder.Status = models.ReminderPending
+    result := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(reminder)
+    if result.Error != nil {
+        return false, result.Error
+    }
+    if result.RowsAffected == 1 {
+        return true, nil
+    }
+
+    // Ya existe: solo se reintenta si el envío anterior falló o se perdió
+    result = r.DB.Model(&models.Reminder{}).
+        Where("appointment_id = ? AND lead_time = ? AND channel = ? AND attempts < ?",
+            reminder.AppointmentID, reminder.LeadTime, reminder.Channel, maxAttempts).
+        Where("status = ? OR (status = ? AND updated_at < ?)", models.ReminderFailed, models.ReminderPending, staleBefore).
+        Updates(map[string]interface{}{
+            "status":   models.ReminderPending,
+            "attempts": gorm.Expr("CASE WHEN status = ? THEN attempts + 1 ELSE attempts END", models.ReminderPending),
+        })
+    if result.Error != nil || result.RowsAffected == 0 {
+        return false, result.Error
+    }
+
+    err := r.DB.Where("appointment_id = ? AND lead_time = ? AND channel = ?",
+        reminder.AppointmentID, reminder.LeadTime, reminder.Channel).First(reminder).Error
+    return err == nil, err
+}
+
+func (r *ReminderRepository) MarkSent(reminder *models.Reminder, sentAt time.Time) error {
+    return r.DB.Model(reminder).Updates(map[string]interface{}{
+        "status":     models.ReminderSent,
+        "attempts":   gorm.Expr("attempts + 1"),
+        "last_error": "",
+        "sent_at":    sentAt,
+    }).Error
+}
+
+func (r *ReminderRepository) MarkFailed(reminder *models.Reminder, sendErr error) error {
+    return r.DB.Model(reminder).Updates(map[string]interface{}{
+        "status":     models.ReminderFailed,
+        "attempts":   gorm.Expr("attempts + 1"),
+        "last_error": sendErr.Error(),
+    }).Error
+}
+
+func (r *ReminderRepository) GetByAppointmentID(appointmentID uint) ([]models.Reminder, error) {
+    var reminders []models.Reminder
+    result := r.DB.Where("appointment_id = ?", appointmentID).Order("created_at").Find(&reminders)
+    return reminders, result.Error
+}
@@ -0,0 +1,185 @@
+// internal/repositories/resource.go
+package repositories
+
+import (
+    "context"
+    "math"
+    "slices"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "gorm.io/gorm"
+)
+
+// Booking es una cita que reserva un recurso.
+type Booking struct {
+    AppointmentID uint      `json:"appointment_id"`
+    PetID         uint      `json:"pet_id"`
+    VetID         *uint     `json:"vet_id,omitempty"`
+    Reason        string    `json:"reason"`
+    Start         time.Time `json:"start"`
+    End           time.Time `json:"end"`
+}
+
+// ResourceUsage es la ocupación de un recurso en un intervalo.
+type ResourceUsage struct {
+    Resource models.Resource `json:"resource"`
+    // BookedMinutes son los minutos del intervalo en que el recurso está
+    // reservado
+    BookedMinutes int `json:"booked_minutes"`
+    // Utilization es la fracción del intervalo (de 0 a 1) en que el recurso
+    // está reservado
+    Utilization float64   `json:"utilization"`
+    Bookings    []Booking `json:"bookings"`
+}
+
+type ResourceRepository struct {
+    DB *gorm.DB
+}
+
+func NewResourceRepository(db *gorm.DB) *ResourceRepository {
+    return &ResourceRepository{DB: db}
+}
+
+// WithContext devuelve una copia del repositorio que propaga el contexto
+// (actor, clínica, cancelación...) a las consultas.
+func (r *ResourceRepository) WithContext(ctx context.Context) *ResourceRepository {
+    return &ResourceRepository{DB: r.DB.WithContext(ctx)}
+}
+
+// GetAll devuelve los recursos, solo los del tipo indicado si kind no está
+// vacío.
+func (r *ResourceRepository) GetAll(kind string) ([]models.Resource, error) {
+    var resources []models.Resource
+    query := r.DB.Order("kind").Order("name")
+    if kind != "" {
+        query = query.Where("kind = ?", kind)
+    }
+    result := query.Find(&resources)
+    return resources, result.Error
+}
+
+func (r *ResourceRepository) GetByID(id uint) (models.Resource, error) {
+    var resource models.Resource
+    result := r.DB.First(&resource, id)
+    return resource, result.Error
+}
+
+func (r *ResourceRepository) Create(resource *models.Resource) error {
+    return r.DB.Create(resource).Error
+}
+
+func (r *ResourceRepository) Update(resource *models.Resource) error {
+    result := r.DB.Model(resource).Select("name", "kind", "description").Updates(resource)
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return gorm.ErrRecordNotFound
+    }
+    return nil
+}
+
+// Delete da de baja el recurso. Si alguna cita futura pendiente lo tiene
+// reservado devuelve ErrHasFutureAppointments, y si es una jaula ocupada
+// por una mascota ingresada, ErrKennelOccupied.
+func (r *ResourceRepository) Delete(id uint) error {
+    return r.DB.Transaction(func(tx *gorm.DB) error {
+        var inpatients int64
+        err := tx.Model(&models.Admission{}).Where("kennel_id = ? AND discharged_at IS NULL", id).Count(&inpatients).Error
+        if err != nil {
+            return err
+        }
+        if inpatients > 0 {
+            return ErrKennelOccupied
+        }
+
+        var upcoming []models.Appointment
+        err = tx.Select("id", "resource_ids").
+            Where("date > ? AND completed = ? AND status = ?", time.Now(), false, models.AppointmentScheduled).
+            Find(&upcoming).Error
+        if err != nil {
+            return err
+        }
+        for _, a := range upcoming {
+            if slices.Contains(a.ResourceIDs, id) {
+                return ErrHasFutureAppointments
+            }
+        }
+
+        result := tx.Delete(&models.Resource{}, id)
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return gorm.ErrRecordNotFound
+        }
+        return nil
+    })
+}
+
+// Usage devuelve la ocupación de los recursos (solo los del tipo kind si no
+// está vacío) entre from y to, con las citas que los reservan.
+func (r *ResourceRepository) Usage(from, to time.Time, kind string) ([]ResourceUsage, error) {
+    resources, err := r.GetAll(kind)
+    if err != nil {
+        return nil, err
+    }
+    appointments, err := overlapping(r.DB, from, to, 0)
+    if err != nil {
+        return nil, err
+    }
+
+    minutes := to.Sub(from).Minutes()
+    usage := make([]ResourceUsage, 0, len(resources))
+    for _, resource := range resources {
+        u := ResourceUsage{Resource: resource, Bookings: []Booking{}}
+        for _, a := range appointments {
+            // Los márgenes antes y después de la cita no cuentan como uso
+            if !a.Date.Before(to) || !a.End().After(from) {
+                continue
+            }
+            if slices.Contains(a.ResourceIDs, resource.ID) {
+                u.Bookings = append(u.Bookings, Booking{
+                    AppointmentID: a.ID,
+                    PetID:         a.PetID,
+                    VetID:         a.VetID,
+                    Reason:        a.Reason,
+                    Start:         a.Date,
+                    End:           a.End(),
+                })
+            }
+        }
+        u.BookedMinutes = bookedMinutes(u.Bookings, from, to)
+        if minutes > 0 {
+            u.Utilization = math.Round(float64(u.BookedMinutes)/minutes*100) / 100
+        }
+        usage = append(usage, u)
+    }
+    return usage, nil
+}
+
+// bookedMinutes suma los minutos entre from y to cubiertos por alguna
+// reserva, sin contar dos veces los solapamientos. Las reservas deben estar
+// ordenadas por inicio.
+func bookedMinutes(bookings []Booking, from, to time.Time) int {
+    var total time.Duration
+    var covered time.Time
+    for _, b := range bookings {
+        start, end := b.Start, b.End
+        if start.Before(from) {
+            start = from
+        }
+        if end.After(to) {
+            end = to
+        }
+        if start.Before(covered) {
+            start = covered
+        }
+        if end.After(start) {
+            total += end.Sub(start)
+            covered = end
+        }
+    }
+    return int(total.Minutes())
+}
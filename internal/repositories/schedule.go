@@ -0,0 +1,184 @@
+// internal/repositories/schedule.go
+package repositories
+
+import (
+    "slices"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "gorm.io/gorm"
+)
+
+// MaxAppointmentDuration es la duración máxima de una cita, y de cada uno de
+// sus márgenes, que se tiene en cuenta al buscar solapamientos: una cita más
+// larga que empiece antes de ese margen no se detecta.
+const MaxAppointmentDuration = 24 * time.Hour
+
+// ScheduleConflict es una cita que ocupa el mismo veterinario o alguno de
+// los mismos recursos a la misma hora.
+type ScheduleConflict struct {
+    AppointmentID uint      `json:"appointment_id"`
+    Start         time.Time `json:"start"`
+    End           time.Time `json:"end"`
+    // VetID es el veterinario compartido, si lo hay
+    VetID *uint `json:"vet_id,omitempty"`
+    // ResourceIDs son los recursos compartidos
+    ResourceIDs []uint `json:"resource_ids,omitempty"`
+}
+
+// ScheduleConflictError detalla un ErrScheduleConflict.
+type ScheduleConflictError struct {
+    Conflicts []ScheduleConflict
+}
+
+func (e *ScheduleConflictError) Error() string {
+    return ErrScheduleConflict.Error()
+}
+
+func (e *ScheduleConflictError) Unwrap() error {
+    return ErrScheduleConflict
+}
+
+// overlapping devuelve las citas programadas (ni canceladas ni ausencias)
+// que, con sus márgenes, se solapan con el intervalo [start, end), salvo la
+// cita exclude, ordenadas por fecha.
+func overlapping(tx *gorm.DB, start, end time.Time, exclude uint) ([]models.Appointment, error) {
+    var candidates []models.Appointment
+    query := tx.Where("date < ? AND date > ? AND status = ?", end.Add(MaxAppointmentDuration), start.Add(-MaxAppointmentDuration), models.AppointmentScheduled)
+    if exclude != 0 {
+        query = query.Where("id <> ?", exclude)
+    }
+    if err := query.Order("date").Find(&candidates).Error; err != nil {
+        return nil, err
+    }
+
+    appointments := candidates[:0]
+    for _, a := range candidates {
+        if a.BlockedFrom().Before(end) && a.BlockedUntil().After(start) {
+            appointments = append(appointments, a)
+        }
+    }
+    return appointments, nil
+}
+
+// checkSchedule comprueba que el veterinario y los recursos de la cita
+// existen y están libres durante toda su duración, márgenes incluidos.
+// Elimina los recursos repetidos. Las citas canceladas o ausencias no se comprueban.
+func checkSchedule(tx *gorm.DB, appointment *models.Appointment) error {
+    if !appointment.Active() || (appointment.VetID == nil && len(appointment.ResourceIDs) == 0) {
+        return nil
+    }
+
+    if appointment.VetID != nil {
+        var count int64
+        if err := tx.Model(&models.Vet{}).Where("id = ?", *appointment.VetID).Count(&count).Error; err != nil {
+            return err
+        }
+        if count == 0 {
+            return ErrVetNotFound
+        }
+    }
+
+    if len(appointment.ResourceIDs) > 0 {
+        ids := slices.Clone(appointment.ResourceIDs)
+        slices.Sort(ids)
+        appointment.ResourceIDs = slices.Compact(ids)
+
+        var count int64
+        if err := tx.Model(&models.Resource{}).Where("id IN ?", []uint(appointment.ResourceIDs)).Count(&count).Error; err != nil {
+            return err
+        }
+        if count != int64(len(appointment.ResourceIDs)) {
+            return ErrResourceNotFound
+        }
+    }
+
+    others, err := overlapping(tx, appointment.BlockedFrom(), appointment.BlockedUntil(), appointment.ID)
+    if err != nil {
+        return err
+    }
+
+    var conflicts []ScheduleConflict
+    for _, other := range others {
+        conflict := ScheduleConflict{AppointmentID: other.ID, Start: other.Date, End: other.End()}
+        if appointment.VetID != nil && other.VetID != nil && *appointment.VetID == *other.VetID {
+            conflict.VetID = other.VetID
+        }
+        for _, id := range appointment.ResourceIDs {
+            if slices.Contains(other.ResourceIDs, id) {
+                conflict.ResourceIDs = append(conflict.ResourceIDs, id)
+            }
+        }
+        if conflict.VetID != nil || len(conflict.ResourceIDs) > 0 {
+            conflicts = append(conflicts, conflict)
+        }
+    }
+    if len(conflicts) > 0 {
+        return &ScheduleConflictError{Conflicts: conflicts}
+    }
+    return nil
+}
+
+// ScheduleEntry es una cita de la agenda con los datos de la mascota y del
+// propietario que necesita la recepción.
+type ScheduleEntry struct {
+    AppointmentID uint      `json:"appointment_id"`
+    Start         time.Time `json:"start"`
+    End           time.Time `json:"end" gorm:"-"`
+    Duration      int       `json:"duration"`
+    BufferBefore  int       `json:"buffer_before"`
+    BufferAfter   int       `json:"buffer_after"`
+    Reason        string    `json:"reason"`
+    Status        string    `json:"status"`
+    Completed     bool      `json:"completed"`
+    TypeID        *uint     `json:"type_id,omitempty"`
+    VetID         *uint     `json:"vet_id,omitempty"`
+    ResourceIDs   models.IDs `json:"resource_ids,omitempty" swaggertype:"array,integer"`
+    PetID         uint      `json:"pet_id"`
+    PetName       string    `json:"pet_name"`
+    Species       string    `json:"species"`
+    ClientID      uint      `json:"client_id"`
+    ClientName    string    `json:"client_name"`
+    Phone         string    `json:"phone"`
+}
+
+// BlockedFrom y BlockedUntil delimitan el tiempo que la cita ocupa en la
+// agenda, márgenes incluidos.
+func (e ScheduleEntry) BlockedFrom() time.Time {
+    return e.Start.Add(-time.Duration(e.BufferBefore) * time.Minute)
+}
+
+func (e ScheduleEntry) BlockedUntil() time.Time {
+    return e.End.Add(time.Duration(e.BufferAfter) * time.Minute)
+}
+
+// Schedule devuelve en una sola consulta las citas no canceladas que se
+// solapan con el intervalo [from, to), con la mascota y su propietario,
+// ordenadas por fecha.
+func (r *AppointmentRepository) Schedule(from, to time.Time) ([]ScheduleEntry, error) {
+    var candidates []ScheduleEntry
+    err := r.DB.Model(&models.Appointment{}).
+        Select("appointments.id AS appointment_id, appointments.date AS start, appointments.duration, "+
+            "appointments.buffer_before, appointments.buffer_after, appointments.reason, appointments.status, "+
+            "appointments.completed, appointments.type_id, appointments.vet_id, appointments.resource_ids, "+
+            "appointments.pet_id, pets.name AS pet_name, pets.species, pets.client_id, "+
+            "clients.name AS client_name, clients.phone").
+        Joins("JOIN pets ON pets.id = appointments.pet_id").
+        Joins("JOIN clients ON clients.id = pets.client_id").
+        Where("appointments.date < ? AND appointments.date > ? AND appointments.status <> ?",
+            to, from.Add(-MaxAppointmentDuration), models.AppointmentCancelled).
+        Order("appointments.date").
+        Scan(&candidates).Error
+    if err != nil {
+        return nil, err
+    }
+
+    entries := candidates[:0]
+    for _, e := range candidates {
+        e.End = e.Start.Add(time.Duration(e.Duration) * time.Minute)
+        if e.End.After(from) {
+            entries = append(entries, e)
+        }
+    }
+    return entries, nil
+}
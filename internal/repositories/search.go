@@ -0,0 +1,437 @@
+// internal/repositories/search.go
+package repositories
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+    "unicode"
+
+    "gorm.io/gorm"
+)
+
+// Tipos de resultado de la búsqueda
+const (
+    SearchClient      = "client"
+    SearchPet         = "pet"
+    SearchAppointment = "appointment"
+)
+
+// Marcas con las que se resaltan las coincidencias en los fragmentos
+const (
+    HighlightStart = "<mark>"
+    HighlightEnd   = "</mark>"
+)
+
+// searchIndexTable es la tabla FTS5 que indexa clientes, mascotas y citas.
+// El rowid de cada fila es id*3 + el desplazamiento de su tipo.
+const searchIndexTable = "search_index"
+
+// SearchResult es una coincidencia de la búsqueda.
+type SearchResult struct {
+    Type    string  `json:"type"`
+    ID      uint    `json:"id"`
+    Title   string  `json:"title"`
+    Snippet string  `json:"snippet"`
+    Score   float64 `json:"score"`
+}
+
+// SearchQuery son los parámetros de una búsqueda.
+type SearchQuery struct {
+    Text  string
+    Types []string
+    Limit int
+}
+
+type SearchRepository struct {
+    DB *gorm.DB
+    // fts indica si la base de datos tiene el índice FTS5
+    fts bool
+}
+
+// NewSearchRepository usa el índice FTS5 si SetupSearchIndex lo ha creado y,
+// si no, busca directamente en las tablas con LIKE, lo que funciona en
+// cualquier base de datos.
+func NewSearchRepository(db *gorm.DB) *SearchRepository {
+    return &SearchRepository{DB: db, fts: db.Migrator().HasTable(searchIndexTable)}
+}
+
+// WithContext devuelve una copia del repositorio que propaga el contexto
+// (actor, cancelación...) a las consultas.
+func (r *SearchRepository) WithContext(ctx context.Context) *SearchRepository {
+    return &SearchRepository{DB: r.DB.WithContext(ctx), fts: r.fts}
+}
+
+// searchSource describe cómo se indexa cada tipo: la columna del título, las
+// del texto y, si la hay, la del teléfono, que se indexa también sin
+// separadores para encontrarlo escriba como se escriba.
+type searchSource struct {
+    Type   string
+    Offset int
+    Table  string
+    Title  string
+    Body   []string
+    Phone  string
+}
+
+var searchSources = []searchSource{
+    {Type: SearchClient, Offset: 0, Table: "clients", Title: "name", Body: []string{"email", "phone"}, Phone: "phone"},
+    {Type: SearchPet, Offset: 1, Table: "pets", Title: "name", Body: []string{"breed", "description"}},
+    {Type: SearchAppointment, Offset: 2, Table: "appointments", Title: "reason", Body: []string{"notes"}},
+}
+
+// digitsExpr devuelve una expresión SQL con la columna sin los separadores
+// habituales de los teléfonos.
+func digitsExpr(column string) string {
+    expr := column
+    for _, sep := range []string{" ", "-", ".", "(", ")", "+", "/"} {
+        expr = fmt.Sprintf("REPLACE(%s, '%s', '')", expr, sep)
+    }
+    return expr
+}
+
+// bodyExpr devuelve la expresión SQL del texto indexado. prefix permite usar
+// las columnas de new. en los triggers.
+func (s searchSource) bodyExpr(prefix string) string {
+    var parts []string
+    for _, column := range s.Body {
+        parts = append(parts, "COALESCE("+prefix+column+", '')")
+    }
+    if s.Phone != "" {
+        parts = append(parts, "COALESCE("+digitsExpr(prefix+s.Phone)+", '')")
+    }
+    return strings.Join(parts, " || ' ' || ")
+}
+
+// SetupSearchIndex crea, si SQLite tiene FTS5, la tabla del índice y los
+// triggers que la mantienen sincronizada en cada alta, modificación, borrado
+// lógico, restauración o purga. En el resto de casos no hace nada y las
+// búsquedas se resuelven con LIKE.
+func SetupSearchIndex(db *gorm.DB) error {
+    if db.Dialector.Name() != "sqlite" {
+        return nil
+    }
+
+    var fts5 bool
+    if err := db.Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&fts5).Error; err != nil || !fts5 {
+        return nil
+    }
+
+    if db.Migrator().HasTable(searchIndexTable) {
+        return nil
+    }
+
+    return db.Transaction(func(tx *gorm.DB) error {
+        statements := []string{
+            "CREATE VIRTUAL TABLE " + searchIndexTable + " USING fts5(entity UNINDEXED, entity_id UNINDEXED, title, body, tokenize = 'trigram')",
+        }
+
+        for _, s := range searchSources {
+            insert := func(prefix string) string {
+                return fmt.Sprintf("INSERT INTO %[1]s(rowid, entity, entity_id, title, body) SELECT %[2]sid * 3 + %[3]d, '%[4]s', %[2]sid, COALESCE(%[2]s%[5]s, ''), %[6]s",
+                    searchIndexTable, prefix, s.Offset, s.Type, s.Title, s.bodyExpr(prefix))
+            }
+            remove := fmt.Sprintf("DELETE FROM %s WHERE rowid = old.id * 3 + %d;", searchIndexTable, s.Offset)
+
+            statements = append(statements,
+                fmt.Sprintf("CREATE TRIGGER search_%s_ai AFTER INSERT ON %s WHEN new.deleted_at IS NULL BEGIN %s; END",
+                    s.Table, s.Table, insert("new.")),
+                fmt.Sprintf("CREATE TRIGGER search_%s_au AFTER UPDATE ON %s BEGIN %s %s WHERE new.deleted_at IS NULL; END",
+                    s.Table, s.Table, remove, insert("new.")),
+                fmt.Sprintf("CREATE TRIGGER search_%s_ad AFTER DELETE ON %s BEGIN %s END",
+                    s.Table, s.Table, remove),
+                // Indexar los registros que ya existían
+                fmt.Sprintf("%s FROM %s WHERE deleted_at IS NULL", insert(""), s.Table),
+            )
+        }
+
+        for _, statement := range statements {
+            if err := tx.Exec(statement).Error; err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+}
+
+// Search busca el texto en clientes, mascotas y citas activos y devuelve
+// los resultados ordenados por relevancia.
+func (r *SearchRepository) Search(query SearchQuery) ([]SearchResult, error) {
+    terms := searchTerms(query.Text)
+    if len(terms) == 0 {
+        return []SearchResult{}, nil
+    }
+
+    sources := searchSources
+    if len(query.Types) > 0 {
+        sources = nil
+        for _, s := range searchSources {
+            for _, t := range query.Types {
+                if s.Type == t {
+                    sources = append(sources, s)
+                }
+            }
+        }
+    }
+
+    // El tokenizador trigram no encuentra términos de menos de tres letras
+    useFTS := r.fts
+    for _, term := range terms {
+        if len([]rune(term)) < 3 {
+            useFTS = false
+        }
+    }
+
+    if useFTS {
+        return r.searchFTS(terms, sources, query.Limit)
+    }
+    return r.searchLike(terms, sources, query.Limit)
+}
+
+func (r *SearchRepository) searchFTS(terms []string, sources []searchSource, limit int) ([]SearchResult, error) {
+    if len(sources) == 0 {
+        return []SearchResult{}, nil
+    }
+
+    types := make([]string, len(sources))
+    for i, s := range sources {
+        types[i] = s.Type
+    }
+
+    if limit <= 0 {
+        limit = -1
+    }
+
+    results := []SearchResult{}
+    err := r.DB.Table(searchIndexTable).
+        Select("entity AS type, entity_id AS id, title, "+
+            "snippet("+searchIndexTable+", -1, ?, ?, '…', 12) AS snippet, "+
+            "-bm25("+searchIndexTable+", 0, 0, 10.0, 1.0) AS score", HighlightStart, HighlightEnd).
+        Where(searchIndexTable+" MATCH ?", ftsQuery(terms)).
+        Where("entity IN ?", types).
+        Order("score DESC").
+        Limit(limit).
+        Scan(&results).Error
+    return results, err
+}
+
+// ftsQuery construye la consulta FTS5: todos los términos deben aparecer y
+// los que contienen dígitos también se buscan sin separadores.
+func ftsQuery(terms []string) string {
+    quote := func(s string) string {
+        return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+    }
+
+    parts := make([]string, len(terms))
+    for i, term := range terms {
+        parts[i] = quote(term)
+        if digits := onlyDigits(term); digits != term && len(digits) >= 3 {
+            parts[i] = "(" + parts[i] + " OR " + quote(digits) + ")"
+        }
+    }
+    return strings.Join(parts, " AND ")
+}
+
+func (r *SearchRepository) searchLike(terms []string, sources []searchSource, limit int) ([]SearchResult, error) {
+    results := []SearchResult{}
+
+    for _, s := range sources {
+        var rows []struct {
+            ID    uint
+            Title string
+            Body  string
+        }
+
+        db := r.DB.Table(s.Table).
+            Select(fmt.Sprintf("id, COALESCE(%s, '') AS title, %s AS body", s.Title, s.bodyExpr(""))).
+            Where("deleted_at IS NULL")
+        for _, term := range terms {
+            pattern := "%" + escapeLike(strings.ToLower(term)) + "%"
+            condition := fmt.Sprintf("LOWER(COALESCE(%s, '')) LIKE ? ESCAPE '\\'", s.Title)
+            args := []interface{}{pattern}
+            for _, column := range s.Body {
+                condition += fmt.Sprintf(" OR LOWER(COALESCE(%s, '')) LIKE ? ESCAPE '\\'", column)
+                args = append(args, pattern)
+            }
+            if digits := onlyDigits(term); len(digits) >= 3 && s.Phone != "" {
+                condition += " OR " + digitsExpr(s.Phone) + " LIKE ?"
+                args = append(args, "%"+digits+"%")
+            }
+            db = db.Where("("+condition+")", args...)
+        }
+
+        if err := db.Scan(&rows).Error; err != nil {
+            return nil, err
+        }
+
+        for _, row := range rows {
+            results = append(results, SearchResult{
+                Type:    s.Type,
+                ID:      row.ID,
+                Title:   row.Title,
+                Snippet: snippet(row.Title, row.Body, terms),
+                Score:   score(row.Title, row.Body, terms),
+            })
+        }
+    }
+
+    sort.SliceStable(results, func(i, j int) bool {
+        return results[i].Score > results[j].Score
+    })
+    if limit > 0 && len(results) > limit {
+        results = results[:limit]
+    }
+    return results, nil
+}
+
+// Ayudas de texto
+
+func searchTerms(text string) []string {
+    return strings.Fields(text)
+}
+
+func onlyDigits(s string) string {
+    return strings.Map(func(r rune) rune {
+        if unicode.IsDigit(r) {
+            return r
+        }
+        return -1
+    }, s)
+}
+
+func escapeLike(s string) string {
+    return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// score puntúa una coincidencia: pesa más el título que el resto del texto
+// y una coincidencia al principio de palabra más que en medio.
+func score(title, body string, terms []string) float64 {
+    total := 0.0
+    lowerTitle, lowerBody := strings.ToLower(title), strings.ToLower(body)
+    for _, term := range terms {
+        term = strings.ToLower(term)
+        switch {
+        case lowerTitle == term:
+            total += 10
+        case hasWordPrefix(lowerTitle, term):
+            total += 6
+        case strings.Contains(lowerTitle, term):
+            total += 4
+        case hasWordPrefix(lowerBody, term):
+            total += 3
+        default:
+            total += 1
+        }
+    }
+    return total
+}
+
+func hasWordPrefix(text, term string) bool {
+    for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+    }) {
+        if strings.HasPrefix(word, term) {
+            return true
+        }
+    }
+    return strings.HasPrefix(text, term)
+}
+
+// snippet devuelve un fragmento del título o del texto alrededor de la
+// primera coincidencia, con los términos resaltados.
+func snippet(title, body string, terms []string) string {
+    const context = 40
+
+    text := []rune(title)
+    start := matchIndex(text, terms)
+    if start < 0 {
+        text = []rune(body)
+        start = matchIndex(text, terms)
+    }
+    if start < 0 {
+        // Solo coincide el teléfono normalizado
+        return title
+    }
+
+    from, to := start-context, start+context
+    prefix, suffix := "…", "…"
+    if from <= 0 {
+        from, prefix = 0, ""
+    }
+    if to >= len(text) {
+        to, suffix = len(text), ""
+    }
+
+    return prefix + highlight(text[from:to], terms) + suffix
+}
+
+// matchIndex devuelve la posición (en runas) de la primera coincidencia de
+// cualquiera de los términos, sin distinguir mayúsculas.
+func matchIndex(text []rune, terms []string) int {
+    first := -1
+    lower := toLowerRunes(text)
+    for _, term := range terms {
+        if i := runeIndex(lower, toLowerRunes([]rune(term))); i >= 0 && (first < 0 || i < first) {
+            first = i
+        }
+    }
+    return first
+}
+
+func highlight(text []rune, terms []string) string {
+    lower := toLowerRunes(text)
+    marked := make([]bool, len(text))
+    for _, term := range terms {
+        needle := toLowerRunes([]rune(term))
+        for offset := 0; offset <= len(lower)-len(needle); {
+            i := runeIndex(lower[offset:], needle)
+            if i < 0 {
+                break
+            }
+            for j := offset + i; j < offset+i+len(needle); j++ {
+                marked[j] = true
+            }
+            offset += i + len(needle)
+        }
+    }
+
+    var b strings.Builder
+    for i, r := range text {
+        if marked[i] && (i == 0 || !marked[i-1]) {
+            b.WriteString(HighlightStart)
+        }
+        b.WriteRune(r)
+        if marked[i] && (i == len(text)-1 || !marked[i+1]) {
+            b.WriteString(HighlightEnd)
+        }
+    }
+    return b.String()
+}
+
+func toLowerRunes(text []rune) []rune {
+    lower := make([]rune, len(text))
+    for i, r := range text {
+        lower[i] = unicode.ToLower(r)
+    }
+    return lower
+}
+
+func runeIndex(text, needle []rune) int {
+    if len(needle) == 0 {
+        return -1
+    }
+    for i := 0; i+len(needle) <= len(text); i++ {
+        match := true
+        for j := range needle {
+            if text[i+j] != needle[j] {
+                match = false
+                break
+            }
+        }
+        if match {
+            return i
+        }
+    }
+    return -1
+}
@@ -0,0 +1,61 @@
+// internal/repositories/service.go
+package repositories
+
+import (
+    "context"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "gorm.io/gorm"
+)
+
+type ServiceRepository struct {
+    DB *gorm.DB
+}
+
+func NewServiceRepository(db *gorm.DB) *ServiceRepository {
+    return &ServiceRepository{DB: db}
+}
+
+// WithContext devuelve una copia del repositorio que propaga el contexto
+// (actor, cancelación...) a las consultas.
+func (r *ServiceRepository) WithContext(ctx context.Context) *ServiceRepository {
+    return &ServiceRepository{DB: r.DB.WithContext(ctx)}
+}
+
+func (r *ServiceRepository) GetAll() ([]models.Service, error) {
+    var services []models.Service
+    result := r.DB.Order("code").Find(&services)
+    return services, result.Error
+}
+
+func (r *ServiceRepository) GetByID(id uint) (models.Service, error) {
+    var service models.Service
+    result := r.DB.First(&service, id)
+    return service, result.Error
+}
+
+func (r *ServiceRepository) Create(service *models.Service) error {
+    return r.DB.Create(service).Error
+}
+
+func (r *ServiceRepository) Update(service *models.Service) error {
+    result := r.DB.Model(service).Select("code", "name", "price").Updates(service)
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return gorm.ErrRecordNotFound
+    }
+    return nil
+}
+
+func (r *ServiceRepository) Delete(id uint) error {
+    result := r.DB.Delete(&models.Service{}, id)
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return gorm.ErrRecordNotFound
+    }
+    return nil
+}
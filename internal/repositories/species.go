@@ -0,0 +1,306 @@
+// internal/repositories/species.go
+package repositories
+
+import (
+    "context"
+    "sort"
+    "strings"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/tenant"
+    "gorm.io/gorm"
+)
+
+type SpeciesRepository struct {
+    DB *gorm.DB
+}
+
+func NewSpeciesRepository(db *gorm.DB) *SpeciesRepository {
+    return &SpeciesRepository{DB: db}
+}
+
+// WithContext devuelve una copia del repositorio que propaga el contexto
+// (actor, cancelación...) a las consultas.
+func (r *SpeciesRepository) WithContext(ctx context.Context) *SpeciesRepository {
+    return &SpeciesRepository{DB: r.DB.WithContext(ctx)}
+}
+
+// GetAll devuelve las especies del catálogo por orden de nombre, sin sus
+// razas.
+func (r *SpeciesRepository) GetAll() ([]models.Species, error) {
+    var species []models.Species
+    result := r.DB.Order("name").Find(&species)
+    return species, result.Error
+}
+
+// GetByID devuelve la especie con sus razas por orden de nombre.
+func (r *SpeciesRepository) GetByID(id uint) (models.Species, error) {
+    var species models.Species
+    result := r.DB.
+        Preload("Breeds", func(db *gorm.DB) *gorm.DB { return db.Order("name") }).
+        First(&species, id)
+    return species, result.Error
+}
+
+// Catalog devuelve todas las especies con sus razas.
+func (r *SpeciesRepository) Catalog() ([]models.Species, error) {
+    var species []models.Species
+    result := r.DB.Preload("Breeds").Order("name").Find(&species)
+    return species, result.Error
+}
+
+// Breeds devuelve las razas de la especie por orden de nombre. Con query
+// solo devuelve las que tienen algún nombre o alias que lo contiene, sin
+// distinguir mayúsculas ni tildes, para autocompletar.
+func (r *SpeciesRepository) Breeds(speciesID uint, query string) ([]models.Breed, error) {
+    species, err := r.GetByID(speciesID)
+    if err != nil {
+        return nil, err
+    }
+
+    key := models.FoldTerm(query)
+    breeds := []models.Breed{}
+    for _, b := range species.Breeds {
+        if key == "" || containsTerm(key, b) {
+            breeds = append(breeds, b)
+        }
+    }
+    return breeds, nil
+}
+
+// Resolve comprueba la especie y la raza de una mascota contra el catálogo
+// y devuelve sus nombres canónicos. La raza es opcional; si se indica tiene
+// que ser de esa especie.
+func (r *SpeciesRepository) Resolve(species, breed string) (string, string, error) {
+    catalog, err := r.Catalog()
+    if err != nil {
+        return "", "", err
+    }
+
+    found, ok := models.FindSpecies(catalog, species)
+    if !ok {
+        return "", "", ErrUnknownSpecies
+    }
+    if strings.TrimSpace(breed) == "" {
+        return found.Name, "", nil
+    }
+    b, ok := found.FindBreed(breed)
+    if !ok {
+        return "", "", ErrUnknownBreed
+    }
+    return found.Name, b.Name, nil
+}
+
+// Create da de alta una especie con sus razas. Devuelve ErrSpeciesTaken si
+// alguno de sus nombres o alias ya identifica a otra especie.
+func (r *SpeciesRepository) Create(species *models.Species) error {
+    return r.DB.Transaction(func(tx *gorm.DB) error {
+        if err := checkSpeciesTerms(tx, *species); err != nil {
+            return err
+        }
+        for i, b := range species.Breeds {
+            others := append(append([]models.Breed{}, species.Breeds[:i]...), species.Breeds[i+1:]...)
+            if err := checkBreedTerms(others, b); err != nil {
+                return err
+            }
+        }
+        return tx.Create(species).Error
+    })
+}
+
+// Update modifica los nombres y alias de la especie. Si cambia el nombre
+// canónico, las mascotas de esa especie pasan a tener el nuevo.
+func (r *SpeciesRepository) Update(species *models.Species) error {
+    return r.DB.Transaction(func(tx *gorm.DB) error {
+        var current models.Species
+        if err := tx.First(&current, species.ID).Error; err != nil {
+            return err
+        }
+        if err := checkSpeciesTerms(tx, *species); err != nil {
+            return err
+        }
+        previous := current.Name
+        err := tx.Model(&current).Select("name", "names", "aliases").Updates(models.Species{
+            Name:    species.Name,
+            Names:   species.Names,
+            Aliases: species.Aliases,
+        }).Error
+        if err != nil {
+            return err
+        }
+        if previous == species.Name {
+            return nil
+        }
+        return tenant.AllClinics(tx, tenant.ReasonCatalogRename).Unscoped().Model(&models.Pet{}).
+            Where("species = ?", previous).
+            UpdateColumns(map[string]interface{}{"species": species.Name, "version": gorm.Expr("version + 1")}).Error
+    })
+}
+
+// CreateBreed añade una raza a la especie. Devuelve ErrBreedTaken si alguno
+// de sus nombres o alias ya identifica a otra raza de la especie.
+func (r *SpeciesRepository) CreateBreed(breed *models.Breed) error {
+    return r.DB.Transaction(func(tx *gorm.DB) error {
+        var species models.Species
+        if err := tx.Preload("Breeds").First(&species, breed.SpeciesID).Error; err != nil {
+            return err
+        }
+        if err := checkBreedTerms(species.Breeds, *breed); err != nil {
+            return err
+        }
+        return tx.Create(breed).Error
+    })
+}
+
+// UpdateBreed modifica los nombres y alias de una raza de la especie. Si
+// cambia el nombre canónico, las mascotas de esa raza pasan a tener el
+// nuevo.
+func (r *SpeciesRepository) UpdateBreed(breed *models.Breed) error {
+    return r.DB.Transaction(func(tx *gorm.DB) error {
+        var species models.Species
+        if err := tx.Preload("Breeds").First(&species, breed.SpeciesID).Error; err != nil {
+            return err
+        }
+        var current models.Breed
+        others := []models.Breed{}
+        for _, b := range species.Breeds {
+            if b.ID == breed.ID {
+                current = b
+            } else {
+                others = append(others, b)
+            }
+        }
+        if current.ID == 0 {
+            return gorm.ErrRecordNotFound
+        }
+        if err := checkBreedTerms(others, *breed); err != nil {
+            return err
+        }
+        previous := current.Name
+        err := tx.Model(&current).Select("name", "names", "aliases").Updates(models.Breed{
+            Name:    breed.Name,
+            Names:   breed.Names,
+            Aliases: breed.Aliases,
+        }).Error
+        if err != nil {
+            return err
+        }
+        if previous == breed.Name {
+            return nil
+        }
+        return tenant.AllClinics(tx, tenant.ReasonCatalogRename).Unscoped().Model(&models.Pet{}).
+            Where("species = ? AND breed = ?", species.Name, previous).
+            UpdateColumns(map[string]interface{}{"breed": breed.Name, "version": gorm.Expr("version + 1")}).Error
+    })
+}
+
+// Seed carga el catálogo inicial si todavía no hay ninguna especie.
+func (r *SpeciesRepository) Seed(species []models.Species) error {
+    var count int64
+    if err := r.DB.Model(&models.Species{}).Count(&count).Error; err != nil {
+        return err
+    }
+    if count > 0 {
+        return nil
+    }
+    return r.DB.Create(&species).Error
+}
+
+// MigratePets sustituye la especie y la raza de las mascotas, también las
+// archivadas, por los nombres canónicos del catálogo ("perro" o "Canine"
+// pasan a ser "Dog"). Devuelve cuántas mascotas se han cambiado y los
+// valores que no corresponden a ninguna especie o raza del catálogo, que se
+// dejan como están. Cada mascota cambiada pasa a una nueva versión y, si la
+// auditoría está activa, el cambio queda registrado.
+func (r *SpeciesRepository) MigratePets() (int64, []string, error) {
+    catalog, err := r.Catalog()
+    if err != nil {
+        return 0, nil, err
+    }
+
+    var values []struct {
+        Species string
+        Breed   string
+    }
+    err = r.DB.Unscoped().Model(&models.Pet{}).Distinct("species", "breed").Scan(&values).Error
+    if err != nil {
+        return 0, nil, err
+    }
+
+    var updated int64
+    unmatched := []string{}
+    err = r.DB.Transaction(func(tx *gorm.DB) error {
+        for _, v := range values {
+            species, ok := models.FindSpecies(catalog, v.Species)
+            if !ok {
+                unmatched = append(unmatched, v.Species)
+                continue
+            }
+            breed := v.Breed
+            if b, ok := species.FindBreed(v.Breed); ok {
+                breed = b.Name
+            } else if strings.TrimSpace(v.Breed) != "" {
+                unmatched = append(unmatched, v.Species+" / "+v.Breed)
+            }
+            if species.Name == v.Species && breed == v.Breed {
+                continue
+            }
+
+            result := tx.Unscoped().Model(&models.Pet{}).
+                Where("species = ? AND breed = ?", v.Species, v.Breed).
+                UpdateColumns(map[string]interface{}{"species": species.Name, "breed": breed, "version": gorm.Expr("version + 1")})
+            if result.Error != nil {
+                return result.Error
+            }
+            updated += result.RowsAffected
+        }
+        return nil
+    })
+    sort.Strings(unmatched)
+    return updated, unmatched, err
+}
+
+// checkSpeciesTerms comprueba que ningún nombre ni alias de la especie
+// identifica ya a otra especie del catálogo.
+func checkSpeciesTerms(tx *gorm.DB, species models.Species) error {
+    var catalog []models.Species
+    if err := tx.Where("id <> ?", species.ID).Find(&catalog).Error; err != nil {
+        return err
+    }
+    for _, term := range terms(species.Name, species.Names, species.Aliases) {
+        if _, ok := models.FindSpecies(catalog, term); ok {
+            return ErrSpeciesTaken
+        }
+    }
+    return nil
+}
+
+// checkBreedTerms comprueba que ningún nombre ni alias de la raza identifica
+// ya a alguna de las otras razas de la especie.
+func checkBreedTerms(others []models.Breed, breed models.Breed) error {
+    for _, term := range terms(breed.Name, breed.Names, breed.Aliases) {
+        for _, b := range others {
+            if b.Matches(term) {
+                return ErrBreedTaken
+            }
+        }
+    }
+    return nil
+}
+
+func terms(name string, names models.Localized, aliases models.Terms) []string {
+    result := []string{name}
+    for _, n := range names {
+        result = append(result, n)
+    }
+    return append(result, aliases...)
+}
+
+func containsTerm(key string, breed models.Breed) bool {
+    for _, term := range terms(breed.Name, breed.Names, breed.Aliases) {
+        if strings.Contains(models.FoldTerm(term), key) {
+            return true
+        }
+    }
+    return false
+}
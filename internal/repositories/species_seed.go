@@ -0,0 +1,108 @@
+// internal/repositories/species_seed.go
+package repositories
+
+import "github.com/javice/vet-clinic-api/internal/models"
+
+// DefaultSpecies es el catálogo inicial de especies y razas. Los nombres
+// canónicos son los que ya usaban las mascotas ("Dog", "Cat"...); los alias
+// recogen las otras formas habituales de escribirlos.
+func DefaultSpecies() []models.Species {
+    breed := func(name, es string, aliases ...string) models.Breed {
+        b := models.Breed{Name: name, Names: models.Localized{"en": name}, Aliases: models.Terms(aliases)}
+        if es != "" {
+            b.Names["es"] = es
+        }
+        return b
+    }
+    mixed := breed("Mixed", "Mestizo", "Mixed breed", "Mestiza", "Cruce")
+
+    return []models.Species{
+        {
+            Name:    "Dog",
+            Names:   models.Localized{"en": "Dog", "es": "Perro"},
+            Aliases: models.Terms{"Canine", "Canino", "Perra", "Canis familiaris"},
+            Breeds: []models.Breed{
+                mixed,
+                breed("Beagle", ""),
+                breed("Border Collie", ""),
+                breed("Boxer", ""),
+                breed("Bulldog", "Bulldog inglés", "English Bulldog"),
+                breed("Chihuahua", ""),
+                breed("Dachshund", "Teckel", "Perro salchicha"),
+                breed("French Bulldog", "Bulldog francés"),
+                breed("German Shepherd", "Pastor alemán"),
+                breed("Golden Retriever", "", "Golden"),
+                breed("Labrador Retriever", "", "Labrador"),
+                breed("Poodle", "Caniche"),
+                breed("Spanish Greyhound", "Galgo español", "Galgo"),
+                breed("Spanish Water Dog", "Perro de agua español"),
+                breed("Yorkshire Terrier", "", "Yorkshire"),
+            },
+        },
+        {
+            Name:    "Cat",
+            Names:   models.Localized{"en": "Cat", "es": "Gato"},
+            Aliases: models.Terms{"Feline", "Felino", "Gata", "Felis catus"},
+            Breeds: []models.Breed{
+                mixed,
+                breed("British Shorthair", "Británico de pelo corto"),
+                breed("European Shorthair", "Común europeo", "Europeo"),
+                breed("Maine Coon", ""),
+                breed("Persian", "Persa"),
+                breed("Ragdoll", ""),
+                breed("Siamese", "Siamés"),
+                breed("Sphynx", "Esfinge"),
+            },
+        },
+        {
+            Name:    "Rabbit",
+            Names:   models.Localized{"en": "Rabbit", "es": "Conejo"},
+            Aliases: models.Terms{"Coneja"},
+            Breeds: []models.Breed{
+                mixed,
+                breed("Dwarf", "Enano", "Conejo enano"),
+                breed("Lionhead", "Cabeza de león"),
+                breed("Lop", "Belier"),
+            },
+        },
+        {
+            Name:    "Bird",
+            Names:   models.Localized{"en": "Bird", "es": "Ave"},
+            Aliases: models.Terms{"Pájaro", "Avian"},
+            Breeds: []models.Breed{
+                breed("Budgerigar", "Periquito", "Budgie"),
+                breed("Canary", "Canario"),
+                breed("Cockatiel", "Carolina", "Ninfa"),
+                breed("Lovebird", "Agapornis"),
+                breed("Parrot", "Loro"),
+            },
+        },
+        {
+            Name:    "Hamster",
+            Names:   models.Localized{"en": "Hamster", "es": "Hámster"},
+            Breeds: []models.Breed{
+                breed("Roborovski", ""),
+                breed("Russian Dwarf", "Ruso", "Enano ruso"),
+                breed("Syrian", "Sirio"),
+            },
+        },
+        {
+            Name:    "Guinea Pig",
+            Names:   models.Localized{"en": "Guinea Pig", "es": "Cobaya"},
+            Aliases: models.Terms{"Cobayo", "Conejillo de Indias", "Cavy"},
+        },
+        {
+            Name:    "Ferret",
+            Names:   models.Localized{"en": "Ferret", "es": "Hurón"},
+        },
+        {
+            Name:    "Reptile",
+            Names:   models.Localized{"en": "Reptile", "es": "Reptil"},
+            Breeds: []models.Breed{
+                breed("Bearded Dragon", "Dragón barbudo", "Pogona"),
+                breed("Gecko", ""),
+                breed("Tortoise", "Tortuga", "Turtle"),
+            },
+        },
+    }
+}
@@ -0,0 +1,13 @@
+// internal/repositories/versioning.go
+package repositories
+
+import "gorm.io/gorm"
+
+// archiveColumns devuelve las columnas a modificar al archivar (o, con nil,
+// restaurar) un registro versionado.
+func archiveColumns(deletedAt interface{}) map[string]interface{} {
+    return map[string]interface{}{
+        "deleted_at": deletedAt,
+        "version":    gorm.Expr("version + 1"),
+    }
+}
@@ -0,0 +1,77 @@
+// internal/repositories/vet.go
+package repositories
+
+import (
+    "context"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "gorm.io/gorm"
+)
+
+type VetRepository struct {
+    DB *gorm.DB
+}
+
+func NewVetRepository(db *gorm.DB) *VetRepository {
+    return &VetRepository{DB: db}
+}
+
+// WithContext devuelve una copia del repositorio que propaga el contexto
+// (actor, clínica, cancelación...) a las consultas.
+func (r *VetRepository) WithContext(ctx context.Context) *VetRepository {
+    return &VetRepository{DB: r.DB.WithContext(ctx)}
+}
+
+func (r *VetRepository) GetAll() ([]models.Vet, error) {
+    var vets []models.Vet
+    result := r.DB.Order("name").Find(&vets)
+    return vets, result.Error
+}
+
+func (r *VetRepository) GetByID(id uint) (models.Vet, error) {
+    var vet models.Vet
+    result := r.DB.First(&vet, id)
+    return vet, result.Error
+}
+
+func (r *VetRepository) Create(vet *models.Vet) error {
+    return r.DB.Create(vet).Error
+}
+
+func (r *VetRepository) Update(vet *models.Vet) error {
+    result := r.DB.Model(vet).Select("name", "email", "specialties").Updates(vet)
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return gorm.ErrRecordNotFound
+    }
+    return nil
+}
+
+// Delete da de baja al veterinario. Si tiene citas futuras pendientes
+// devuelve ErrHasFutureAppointments.
+func (r *VetRepository) Delete(id uint) error {
+    return r.DB.Transaction(func(tx *gorm.DB) error {
+        var count int64
+        err := tx.Model(&models.Appointment{}).
+            Where("vet_id = ? AND date > ? AND completed = ? AND status = ?", id, time.Now(), false, models.AppointmentScheduled).
+            Count(&count).Error
+        if err != nil {
+            return err
+        }
+        if count > 0 {
+            return ErrHasFutureAppointments
+        }
+
+        result := tx.Delete(&models.Vet{}, id)
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return gorm.ErrRecordNotFound
+        }
+        return nil
+    })
+}
@@ -0,0 +1,282 @@
+// internal/repositories/waitlist.go
+package repositories
+
+import (
+    "context"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// FreeSlot es un hueco de la agenda que ha quedado libre.
+type FreeSlot struct {
+    // AppointmentID es la cita cancelada o a la que no se presentaron
+    AppointmentID uint
+    ClinicID      uint
+    Start         time.Time
+    End           time.Time
+    VetID         *uint
+}
+
+// Minutes es la duración del hueco en minutos.
+func (s FreeSlot) Minutes() int {
+    return int(s.End.Sub(s.Start).Minutes())
+}
+
+// OfferTarget reúne los datos necesarios para avisar al cliente de una
+// oferta.
+type OfferTarget struct {
+    PetName    string
+    ClientName string
+    Email      string
+    Phone      string
+    // TimeZone es la zona horaria de la clínica de la entrada
+    TimeZone string
+}
+
+// urgencyOrder ordena las entradas de la lista de espera de más a menos
+// urgente y, a igual urgencia, por antigüedad
+const urgencyOrder = "CASE urgency WHEN '" + models.UrgencyHigh + "' THEN 0 WHEN '" + models.UrgencyNormal + "' THEN 1 ELSE 2 END, created_at, id"
+
+type WaitlistRepository struct {
+    DB *gorm.DB
+}
+
+func NewWaitlistRepository(db *gorm.DB) *WaitlistRepository {
+    return &WaitlistRepository{DB: db}
+}
+
+// WithContext devuelve una copia del repositorio que propaga el contexto
+// (actor, clínica, cancelación...) a las consultas.
+func (r *WaitlistRepository) WithContext(ctx context.Context) *WaitlistRepository {
+    return &WaitlistRepository{DB: r.DB.WithContext(ctx)}
+}
+
+// Create añade la entrada a la lista de espera junto con sus ventanas.
+func (r *WaitlistRepository) Create(entry *models.WaitlistEntry) error {
+    entry.Status = models.WaitlistWaiting
+    entry.AppointmentID = nil
+    if entry.Urgency == "" {
+        entry.Urgency = models.UrgencyNormal
+    }
+    return r.DB.Omit("Pet").Create(entry).Error
+}
+
+// GetAll devuelve las entradas por orden de prioridad, solo las del estado
+// indicado si status no está vacío.
+func (r *WaitlistRepository) GetAll(status string) ([]models.WaitlistEntry, error) {
+    var entries []models.WaitlistEntry
+    query := r.DB.Preload("Windows").Order(urgencyOrder)
+    if status != "" {
+        query = query.Where("status = ?", status)
+    }
+    result := query.Find(&entries)
+    return entries, result.Error
+}
+
+func (r *WaitlistRepository) GetByID(id uint) (models.WaitlistEntry, error) {
+    var entry models.WaitlistEntry
+    result := r.DB.Preload("Windows").First(&entry, id)
+    return entry, result.Error
+}
+
+// Cancel saca la entrada de la lista de espera y rechaza su oferta
+// pendiente, si la tiene, que se devuelve para ofrecer el hueco a otro.
+// Devuelve ErrWaitlistClosed si la entrada ya tiene cita o estaba cancelada.
+func (r *WaitlistRepository) Cancel(id uint) ([]models.WaitlistOffer, error) {
+    var offers []models.WaitlistOffer
+    err := r.DB.Transaction(func(tx *gorm.DB) error {
+        var entry models.WaitlistEntry
+        if err := tx.First(&entry, id).Error; err != nil {
+            return err
+        }
+        result := tx.Model(&entry).
+            Where("status IN ?", []string{models.WaitlistWaiting, models.WaitlistOffered}).
+            Update("status", models.WaitlistCancelled)
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return ErrWaitlistClosed
+        }
+
+        if err := tx.Where("entry_id = ? AND status = ?", id, models.OfferPending).Find(&offers).Error; err != nil {
+            return err
+        }
+        for i := range offers {
+            offers[i].Status = models.OfferDeclined
+            if err := tx.Model(&offers[i]).Update("status", models.OfferDeclined).Error; err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+    return offers, err
+}
+
+// Candidates devuelve las entradas en espera que podrían ocupar el hueco,
+// por orden de prioridad: de la misma clínica, que caben en él, sin otro
+// veterinario preferido y a las que aún no se les ha ofrecido.
+func (r *WaitlistRepository) Candidates(slot FreeSlot) ([]models.WaitlistEntry, error) {
+    offered := r.DB.Model(&models.WaitlistOffer{}).Select("entry_id").
+        Where("freed_appointment_id = ?", slot.AppointmentID)
+
+    query := r.DB.Preload("Windows").
+        Where("status = ? AND clinic_id = ? AND duration <= ?", models.WaitlistWaiting, slot.ClinicID, slot.Minutes()).
+        Where("id NOT IN (?)", offered)
+    if slot.VetID != nil {
+        query = query.Where("vet_id IS NULL OR vet_id = ?", *slot.VetID)
+    }
+
+    var entries []models.WaitlistEntry
+    result := query.Order(urgencyOrder).Find(&entries)
+    return entries, result.Error
+}
+
+// Filled indica si el hueco de la cita ya se ha ocupado con una oferta
+// aceptada o si hay una oferta pendiente para él.
+func (r *WaitlistRepository) Filled(appointmentID uint) (bool, error) {
+    var count int64
+    result := r.DB.Model(&models.WaitlistOffer{}).
+        Where("freed_appointment_id = ? AND status IN ?", appointmentID, []string{models.OfferPending, models.OfferAccepted}).
+        Count(&count)
+    return count > 0, result.Error
+}
+
+// CreateOffer registra la oferta y marca su entrada como ofertada. Devuelve
+// ErrWaitlistClosed si la entrada ya no está en espera.
+func (r *WaitlistRepository) CreateOffer(offer *models.WaitlistOffer) error {
+    offer.Status = models.OfferPending
+    return r.DB.Transaction(func(tx *gorm.DB) error {
+        result := tx.Model(&models.WaitlistEntry{}).
+            Where("id = ? AND status = ?", offer.EntryID, models.WaitlistWaiting).
+            Update("status", models.WaitlistOffered)
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return ErrWaitlistClosed
+        }
+        return tx.Omit("Entry").Create(offer).Error
+    })
+}
+
+// GetOffers devuelve las ofertas más recientes primero, solo las del estado
+// indicado si status no está vacío.
+func (r *WaitlistRepository) GetOffers(status string) ([]models.WaitlistOffer, error) {
+    var offers []models.WaitlistOffer
+    query := r.DB.Order("created_at DESC").Order("id DESC")
+    if status != "" {
+        query = query.Where("status = ?", status)
+    }
+    result := query.Find(&offers)
+    return offers, result.Error
+}
+
+func (r *WaitlistRepository) GetOffer(id uint) (models.WaitlistOffer, error) {
+    var offer models.WaitlistOffer
+    result := r.DB.Preload("Entry.Windows").First(&offer, id)
+    return offer, result.Error
+}
+
+// GetExpiredOffers devuelve las ofertas pendientes que han caducado.
+func (r *WaitlistRepository) GetExpiredOffers(now time.Time) ([]models.WaitlistOffer, error) {
+    var offers []models.WaitlistOffer
+    result := r.DB.Where("status = ? AND expires_at <= ?", models.OfferPending, now).Order("expires_at").Find(&offers)
+    return offers, result.Error
+}
+
+// CloseOffer rechaza o da por caducada una oferta pendiente y devuelve su
+// entrada a la lista de espera. Devuelve false si la oferta ya no estaba
+// pendiente.
+func (r *WaitlistRepository) CloseOffer(offer *models.WaitlistOffer, status string) (bool, error) {
+    closed := false
+    err := r.DB.Transaction(func(tx *gorm.DB) error {
+        result := tx.Model(&models.WaitlistOffer{}).
+            Where("id = ? AND status = ?", offer.ID, models.OfferPending).
+            Update("status", status)
+        if result.Error != nil || result.RowsAffected == 0 {
+            return result.Error
+        }
+        closed = true
+        offer.Status = status
+
+        return tx.Model(&models.WaitlistEntry{}).
+            Where("id = ? AND status = ?", offer.EntryID, models.WaitlistOffered).
+            Update("status", models.WaitlistWaiting).Error
+    })
+    return closed, err
+}
+
+// AcceptOffer crea la cita de una oferta pendiente y no caducada en now, y
+// da la entrada por atendida. Devuelve ErrOfferClosed si la oferta ya no
+// está pendiente y ErrOfferExpired si ha caducado; si el hueco se ha
+// ocupado entretanto, el *ScheduleConflictError correspondiente.
+func (r *WaitlistRepository) AcceptOffer(id uint, now time.Time) (models.Appointment, error) {
+    var appointment models.Appointment
+    err := r.DB.Transaction(func(tx *gorm.DB) error {
+        var offer models.WaitlistOffer
+        if err := tx.Preload("Entry").First(&offer, id).Error; err != nil {
+            return err
+        }
+        if offer.Status != models.OfferPending || offer.Entry == nil {
+            return ErrOfferClosed
+        }
+        if !now.Before(offer.ExpiresAt) {
+            return ErrOfferExpired
+        }
+
+        appointment = models.Appointment{
+            PetID:    offer.Entry.PetID,
+            ClinicID: offer.ClinicID,
+            Date:     offer.Date,
+            Duration: offer.Duration,
+            Reason:   offer.Entry.Reason,
+            VetID:    offer.VetID,
+            Status:   models.AppointmentScheduled,
+            Version:  1,
+        }
+        if err := checkSchedule(tx, &appointment); err != nil {
+            return err
+        }
+        if err := tx.Omit(clause.Associations).Create(&appointment).Error; err != nil {
+            return err
+        }
+
+        result := tx.Model(&models.WaitlistOffer{}).
+            Where("id = ? AND status = ?", offer.ID, models.OfferPending).
+            Updates(map[string]interface{}{"status": models.OfferAccepted, "appointment_id": appointment.ID})
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return ErrOfferClosed
+        }
+        return tx.Model(&models.WaitlistEntry{}).Where("id = ?", offer.EntryID).
+            Updates(map[string]interface{}{"status": models.WaitlistBooked, "appointment_id": appointment.ID}).Error
+    })
+    return appointment, err
+}
+
+// GetFreedAppointment devuelve la cita cuyo hueco se ofrece.
+func (r *WaitlistRepository) GetFreedAppointment(id uint) (models.Appointment, error) {
+    var appointment models.Appointment
+    result := r.DB.First(&appointment, id)
+    return appointment, result.Error
+}
+
+// GetOfferTarget devuelve los datos de contacto para avisar de la oferta.
+func (r *WaitlistRepository) GetOfferTarget(offer models.WaitlistOffer) (OfferTarget, error) {
+    var target OfferTarget
+    result := r.DB.Table("waitlist_entries").
+        Select("pets.name AS pet_name, clients.name AS client_name, clients.email, clients.phone, " +
+            "COALESCE(clinics.time_zone, '') AS time_zone").
+        Joins("JOIN pets ON pets.id = waitlist_entries.pet_id").
+        Joins("JOIN clients ON clients.id = pets.client_id").
+        Joins("LEFT JOIN clinics ON clinics.id = waitlist_entries.clinic_id").
+        Where("waitlist_entries.id = ?", offer.EntryID).
+        Scan(&target)
+    return target, result.Error
+}
@@ -1,68 +1,272 @@
 package routes
 
 import (
+    "log/slog"
+    "time"
+
     "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/auth"
     "github.com/javice/vet-clinic-api/internal/handlers"
+    "github.com/javice/vet-clinic-api/internal/metrics"
+    "github.com/javice/vet-clinic-api/internal/middleware"
+    "github.com/javice/vet-clinic-api/internal/ratelimit"
 
 	"github.com/swaggo/files" // swagger embed files
     "github.com/swaggo/gin-swagger" // gin-swagger middleware
 	_ "github.com/javice/vet-clinic-api/docs" // docs is generated by Swag CLI, you have to import it.
 )
 
-func SetupRouter(handler *handlers.Handler) *gin.Engine {
-    router := gin.Default()
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+// Prefijos de los grupos de rutas, para asignarles políticas CORS y límites
+// de peticiones propios
+const (
+    APIPrefix    = "/api/v1"
+    AdminPrefix  = APIPrefix + "/admin"
+    LookupPrefix = APIPrefix + "/pets/lookup"
+)
 
-    // Middleware para CORS
-    router.Use(func(c *gin.Context) {
-        c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-        c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-        c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+// Options agrupa la configuración de las rutas.
+type Options struct {
+    // AdminToken protege las rutas de administración; vacío las desactiva
+    AdminToken string
+    // AllowAnonymous deja pasar las peticiones a la API sin clave de API ni
+    // token de administración, con los permisos de auth.RoleAnonymous; en
+    // otro caso se rechazan con 401
+    AllowAnonymous bool
+    // TimeZone es la zona horaria de las clínicas que no indican otra; nil
+    // es la del sistema
+    TimeZone *time.Location
+    // AllowAllClinics deja a los administradores trabajar con los datos de
+    // todas las clínicas si no indican ninguna; en otro caso las peticiones
+    // a datos de las clínicas sin clínica se rechazan
+    AllowAllClinics bool
+    // CORS son las políticas CORS por prefijo de ruta ("" para el resto);
+    // nil permite cualquier origen sin credenciales
+    CORS map[string]middleware.CORSPolicy
+    // RateLimits son los límites de peticiones por prefijo de ruta ("" para
+    // el resto); sin RateLimitStore no se limita nada
+    RateLimits     map[string]ratelimit.Limit
+    RateLimitStore ratelimit.Store
+    // Logger recibe el log de peticiones; nil usa slog.Default()
+    Logger *slog.Logger
+    // Metrics mide las peticiones; nil desactiva las métricas
+    Metrics *metrics.Metrics
+    // ServeMetrics publica /metrics en este router. Si es false las
+    // métricas se sirven aparte (por ejemplo en un puerto de administración)
+    ServeMetrics bool
+}
 
-        if c.Request.Method == "OPTIONS" {
-            c.AbortWithStatus(204)
-            return
+func SetupRouter(handler *handlers.Handler, opts Options) *gin.Engine {
+    logger := opts.Logger
+    if logger == nil {
+        logger = slog.Default()
+    }
+
+    router := gin.New()
+    router.Use(middleware.RequestID(), middleware.Logger(logger), gin.Recovery())
+    if opts.Metrics != nil {
+        router.Use(opts.Metrics.Middleware())
+        if opts.ServeMetrics {
+            router.GET(metrics.Path, gin.WrapH(opts.Metrics.Handler()))
         }
+    }
+	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+    // Comprobaciones de vida y disponibilidad
+    router.GET("/healthz", handler.Healthz)
+    router.GET("/readyz", handler.Readyz)
+
+    // CORS, claves de API y límite de peticiones. Las comprobaciones previas
+    // de CORS no consumen fichas y las peticiones con clave se limitan por
+    // clave
+    cors := opts.CORS
+    if cors == nil {
+        cors = map[string]middleware.CORSPolicy{"": middleware.DefaultCORSPolicy()}
+    }
+    router.Use(middleware.CORS(cors))
+    router.Use(middleware.AdminSession(opts.AdminToken), middleware.APIKeyAuth(handler.APIKeyRepo))
+    if opts.RateLimitStore != nil && len(opts.RateLimits) > 0 {
+        router.Use(middleware.RateLimit(opts.RateLimitStore, opts.RateLimits, middleware.ClientKey, logger))
+    }
 
-        c.Next()
-    })
+    // Permisos que necesita el principal en cada grupo
+    scope := func(resource string) gin.HandlerFunc {
+        return middleware.RequireScope(resource)
+    }
+    // Los datos de la clínica necesitan además saber en qué clínica se
+    // trabaja
+    clinicData := func(resource string) []gin.HandlerFunc {
+        return []gin.HandlerFunc{scope(resource), middleware.RequireClinic(opts.AllowAllClinics)}
+    }
 
     // Grupo de rutas para la API
-    api := router.Group("/api/v1")
+    api := router.Group(APIPrefix, middleware.RequirePrincipal(opts.AllowAnonymous), middleware.Actor(), middleware.Clinic(handler.ClinicRepo, opts.TimeZone))
     {
+        // Clínicas del grupo
+        api.GET("/clinics", handler.GetClinics)
+
         // Rutas para clientes
-        clients := api.Group("/clients")
+        clients := api.Group("/clients", clinicData(auth.ResourceClients)...)
         {
             clients.GET("", handler.GetClients)
+            clients.GET("/duplicates", handler.GetClientDuplicates)
             clients.GET("/:id", handler.GetClient)
             clients.POST("", handler.CreateClient)
             clients.PUT("/:id", handler.UpdateClient)
-            clients.PATCH("/:id", handler.UpdateClient)
+            clients.PATCH("/:id", handler.PatchClient)
             clients.DELETE("/:id", handler.DeleteClient)
+            clients.POST("/:id/restore", handler.RestoreClient)
+            clients.GET("/:id/pets", scope(auth.ResourcePets), handler.GetPetsByClient)
+            clients.POST("/:id/merge", handler.MergeClient)
+            clients.GET("/:id/clinics", handler.GetClientClinics)
+            clients.POST("/:id/clinics", handler.ShareClient)
+            clients.DELETE("/:id/clinics/:clinic_id", handler.UnshareClient)
         }
 
         // Rutas para mascotas
-        pets := api.Group("/pets")
+        pets := api.Group("/pets", clinicData(auth.ResourcePets)...)
         {
             pets.GET("", handler.GetPets)
+            pets.GET("/lookup", handler.LookupPet)
             pets.GET("/:id", handler.GetPet)
             pets.POST("", handler.CreatePet)
             pets.PUT("/:id", handler.UpdatePet)
-            pets.PATCH("/:id", handler.UpdatePet)
+            pets.PATCH("/:id", handler.PatchPet)
             pets.DELETE("/:id", handler.DeletePet)
+            pets.POST("/:id/restore", handler.RestorePet)
+            pets.GET("/:id/identifiers", handler.GetPetIdentifiers)
+            pets.POST("/:id/identifiers", handler.CreatePetIdentifier)
+            pets.DELETE("/:id/identifiers/:identifier_id", handler.DeletePetIdentifier)
+            pets.GET("/:id/appointments", scope(auth.ResourceAppointments), handler.GetAppointmentsByPet)
         }
 
 		// Rutas para citas
-		appointments := api.Group("/appointments")
+		appointments := api.Group("/appointments", clinicData(auth.ResourceAppointments)...)
 		{
 			appointments.GET("", handler.GetAppointments)
 			appointments.GET("/:id", handler.GetAppointment)
 			appointments.POST("", handler.CreateAppointment)
 			appointments.PUT("/:id", handler.UpdateAppointment)
-			appointments.PATCH("/:id", handler.UpdateAppointment)
+			appointments.PATCH("/:id", handler.PatchAppointment)
 			appointments.DELETE("/:id", handler.DeleteAppointment)
+			appointments.POST("/:id/restore", handler.RestoreAppointment)
+			appointments.POST("/:id/cancel", handler.CancelAppointment)
+			appointments.POST("/:id/no-show", handler.MarkAppointmentNoShow)
 		}
+
+        // Veterinarios
+        vets := api.Group("/vets", clinicData(auth.ResourceVets)...)
+        {
+            vets.GET("", handler.GetVets)
+            vets.GET("/:id", handler.GetVet)
+            vets.POST("", handler.CreateVet)
+            vets.PUT("/:id", handler.UpdateVet)
+            vets.DELETE("/:id", handler.DeleteVet)
+        }
+
+        // Salas y equipos reservables
+        resources := api.Group("/resources", clinicData(auth.ResourceResources)...)
+        {
+            resources.GET("", handler.GetResources)
+            resources.GET("/utilization", handler.GetResourceUtilization)
+            resources.GET("/:id", handler.GetResource)
+            resources.POST("", handler.CreateResource)
+            resources.PUT("/:id", handler.UpdateResource)
+            resources.DELETE("/:id", handler.DeleteResource)
+        }
+
+        // Catálogo de tipos de cita de la clínica
+        appointmentTypes := api.Group("/appointment-types", clinicData(auth.ResourceAppointmentTypes)...)
+        {
+            appointmentTypes.GET("", handler.GetAppointmentTypes)
+            appointmentTypes.GET("/:id", handler.GetAppointmentType)
+            appointmentTypes.POST("", handler.CreateAppointmentType)
+            appointmentTypes.PUT("/:id", handler.UpdateAppointmentType)
+            appointmentTypes.DELETE("/:id", handler.DeleteAppointmentType)
+        }
+
+        // Servicios facturables, comunes a todas las clínicas
+        services := api.Group("/services", scope(auth.ResourceServices))
+        {
+            services.GET("", handler.GetServices)
+            services.GET("/:id", handler.GetService)
+            services.POST("", handler.CreateService)
+            services.PUT("/:id", handler.UpdateService)
+            services.DELETE("/:id", handler.DeleteService)
+        }
+
+        // Catálogo de especies y razas, común a todas las clínicas
+        species := api.Group("/species", scope(auth.ResourceSpecies))
+        {
+            species.GET("", handler.GetSpecies)
+            species.GET("/:id", handler.GetSpeciesByID)
+            species.POST("", handler.CreateSpecies)
+            species.PUT("/:id", handler.UpdateSpecies)
+            species.GET("/:id/breeds", handler.GetBreeds)
+            species.POST("/:id/breeds", handler.CreateBreed)
+            species.PUT("/:id/breeds/:breed_id", handler.UpdateBreed)
+        }
+
+        // Lista de espera y ofertas de huecos libres
+        waitlist := api.Group("/waitlist", clinicData(auth.ResourceWaitlist)...)
+        {
+            waitlist.GET("", handler.GetWaitlist)
+            waitlist.POST("", handler.CreateWaitlistEntry)
+            waitlist.GET("/offers", handler.GetWaitlistOffers)
+            waitlist.POST("/offers/:id/accept", handler.AcceptWaitlistOffer)
+            waitlist.POST("/offers/:id/decline", handler.DeclineWaitlistOffer)
+            waitlist.GET("/:id", handler.GetWaitlistEntry)
+            waitlist.DELETE("/:id", handler.CancelWaitlistEntry)
+        }
+
+        // Hospitalización: ingresos, hoja de tratamiento y tablero
+        admissions := api.Group("/admissions", clinicData(auth.ResourceAdmissions)...)
+        {
+            admissions.GET("", handler.GetAdmissions)
+            admissions.GET("/:id", handler.GetAdmission)
+            admissions.POST("", handler.CreateAdmission)
+            admissions.PUT("/:id/kennel", handler.MoveAdmissionKennel)
+            admissions.POST("/:id/discharge", handler.DischargeAdmission)
+            admissions.POST("/:id/treatments", handler.CreateTreatments)
+            admissions.POST("/:id/treatments/:treatment_id/done", handler.CompleteTreatment)
+        }
+        api.GET("/ward", append(clinicData(auth.ResourceAdmissions), handler.GetWard)...)
+
+        // Agenda diaria o semanal para la recepción
+        api.GET("/schedule", append(clinicData(auth.ResourceAppointments), handler.GetSchedule)...)
+
+        // Búsqueda de texto completo
+        api.GET("/search", append(clinicData(auth.ResourceSearch), handler.Search)...)
+
+        // Exportación de datos
+        exports := api.Group("/export", clinicData(auth.ResourceExport)...)
+        {
+            exports.GET("/:resource", handler.ExportData)
+            exports.GET("/jobs/:id", handler.GetExportJob)
+            exports.GET("/jobs/:id/download", handler.DownloadExportJob)
+        }
+
+        // Rutas de auditoría. Las entradas no son de ninguna clínica, así
+        // que solo las consultan los administradores
+        audit := api.Group("/audit", middleware.AdminOnly())
+        {
+            audit.GET("", handler.GetAuditLogs)
+            audit.GET("/verify", handler.VerifyAuditLog)
+        }
+
+        // Rutas de administración
+        admin := api.Group("/admin", middleware.AdminOnly())
+        {
+            admin.POST("/purge", handler.PurgeDeleted)
+            admin.POST("/import/:kind", handler.ImportData)
+            admin.POST("/clinics", handler.CreateClinic)
+
+            // Claves de API de las integraciones
+            admin.GET("/api-keys", handler.GetAPIKeys)
+            admin.POST("/api-keys", handler.CreateAPIKey)
+            admin.DELETE("/api-keys/:id", handler.RevokeAPIKey)
+            admin.POST("/api-keys/:id/rotate", handler.RotateAPIKey)
+        }
     }
 
     return router
-}
\ No newline at end of file
+}
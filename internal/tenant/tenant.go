@@ -0,0 +1,342 @@
+// Package tenant separa los datos de cada clínica del grupo. Cuando el
+// contexto de una consulta indica una clínica, los callbacks de GORM
+// restringen automáticamente las lecturas, modificaciones y borrados a sus
+// clientes, mascotas y citas, y asignan las altas a ella. Las sentencias SQL
+// escritas a mano no se pueden limitar, así que con clínica se rechazan.
+//
+// Sin clínica en el contexto (tareas internas y migraciones) no se filtra
+// nada. Las peticiones a datos de las clínicas siempre tienen una salvo la
+// vista de grupo de los administradores (middleware.RequireClinic); dentro de
+// ellas, abarcar todas las clínicas exige AllClinics, que lo audita.
+package tenant
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "reflect"
+    "strings"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/audit"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// DefaultClinicName es la clínica a la que se asignan los datos existentes
+// al activar las clínicas
+const DefaultClinicName = "Clínica principal"
+
+// ErrNotInClinic se devuelve al asociar un registro con otro que no es
+// visible desde la clínica de la petición.
+var ErrNotInClinic = errors.New("el registro no pertenece a la clínica")
+
+// ErrRawSQL se devuelve al ejecutar SQL escrito a mano con una clínica en el
+// contexto: el filtro de la clínica no se le puede aplicar.
+var ErrRawSQL = errors.New("las sentencias SQL escritas a mano no se pueden limitar a la clínica")
+
+// Motivos por los que una operación de una clínica puede abarcar todas
+const (
+    // ReasonCatalogRename renombra en las mascotas una especie o raza del
+    // catálogo común
+    ReasonCatalogRename = "catalog_rename"
+    // ReasonUniqueness comprueba que un microchip o una identificación no es
+    // ya de otra mascota, o un email de otro cliente
+    ReasonUniqueness = "uniqueness"
+    // ReasonLookup busca el dueño de una mascota perdida
+    ReasonLookup = "lookup"
+    // ReasonArchive comprueba y archiva las citas de un cliente o una
+    // mascota compartidos, que pueden tenerlas en otras clínicas
+    ReasonArchive = "archive"
+)
+
+type clinicKey struct{}
+
+// WithClinic devuelve un contexto cuyas consultas se limitan a la clínica.
+func WithClinic(ctx context.Context, clinicID uint) context.Context {
+    return context.WithValue(ctx, clinicKey{}, clinicID)
+}
+
+// ClinicFromContext devuelve la clínica del contexto, si la hay.
+func ClinicFromContext(ctx context.Context) (uint, bool) {
+    if ctx == nil {
+        return 0, false
+    }
+    id, ok := ctx.Value(clinicKey{}).(uint)
+    return id, ok && id != 0
+}
+
+// AllClinics quita la clínica del contexto de db para una operación que debe
+// abarcar todas, como las búsquedas de mascotas perdidas. Es la única forma
+// de salir de la clínica de una petición y queda en la auditoría con el
+// motivo, una de las constantes Reason*, y la clínica de la que se salió.
+func AllClinics(db *gorm.DB, reason string) *gorm.DB {
+    ctx := db.Statement.Context
+    clinicID, ok := ClinicFromContext(ctx)
+    if !ok {
+        return db
+    }
+
+    tx := db.WithContext(WithClinic(ctx, 0))
+    changes, err := json.Marshal(map[string]models.FieldChange{"reason": {After: reason}})
+    if err == nil {
+        err = audit.Record(db, &models.AuditLog{
+            Entity:   "clinic",
+            EntityID: clinicID,
+            Action:   models.AuditAllClinics,
+            Changes:  changes,
+        })
+    }
+    if err != nil {
+        tx.AddError(err)
+    }
+    return tx
+}
+
+// clientsInClinic son los clientes compartidos con la clínica
+const clientsInClinic = "SELECT client_id FROM client_clinics WHERE clinic_id = ?"
+
+// conditions indica cómo se filtra cada tabla por clínica. Cada ? recibe el
+// ID de la clínica. Los clientes se ven en todas las clínicas con las que
+// están compartidos y sus mascotas con ellos; las citas pertenecen a la
+// clínica donde se atienden, igual que los veterinarios, los recursos
+// (salas y equipos) y los tipos de cita.
+var conditions = map[string]string{
+    "clients":           "clients.id IN (" + clientsInClinic + ")",
+    "pets":              "pets.client_id IN (" + clientsInClinic + ")",
+    "appointments":      "appointments.clinic_id = ?",
+    "vets":              "vets.clinic_id = ?",
+    "resources":         "resources.clinic_id = ?",
+    "appointment_types": "appointment_types.clinic_id = ?",
+    // La lista de espera y sus ofertas son de cada clínica
+    "waitlist_entries":  "waitlist_entries.clinic_id = ?",
+    "waitlist_offers":   "waitlist_offers.clinic_id = ?",
+    // Los ingresos y su hoja de tratamiento, también
+    "admissions":        "admissions.clinic_id = ?",
+    "treatments":        "treatments.clinic_id = ?",
+    // Las identificaciones se ven con sus mascotas
+    "pet_identifiers":   "pet_identifiers.pet_id IN (SELECT id FROM pets WHERE client_id IN (" + clientsInClinic + "))",
+    "search_index":      "((search_index.entity = 'client' AND search_index.entity_id IN (" + clientsInClinic + ")) OR " +
+        "(search_index.entity = 'pet' AND search_index.entity_id IN (SELECT id FROM pets WHERE client_id IN (" + clientsInClinic + "))) OR " +
+        "(search_index.entity = 'appointment' AND search_index.entity_id IN (SELECT id FROM appointments WHERE clinic_id = ?)))",
+}
+
+// parents son las referencias que se comprueban al crear o modificar un
+// registro: la columna y la tabla a la que apunta, que debe ser visible
+// desde la clínica
+var parents = map[string]struct {
+    field string
+    table string
+}{
+    "pets":             {field: "ClientID", table: "clients"},
+    "appointments":     {field: "PetID", table: "pets"},
+    "waitlist_entries": {field: "PetID", table: "pets"},
+    "admissions":       {field: "PetID", table: "pets"},
+    "pet_identifiers":  {field: "PetID", table: "pets"},
+}
+
+// owned son las tablas cuyos registros pertenecen a una sola clínica, la
+// de la petición que los crea
+var owned = map[string]bool{
+    "appointments":      true,
+    "vets":              true,
+    "resources":         true,
+    "appointment_types": true,
+    "waitlist_entries":  true,
+    "waitlist_offers":   true,
+    "admissions":        true,
+    "treatments":        true,
+}
+
+const scopedKey = "tenant:scoped"
+
+// Register instala en db los callbacks que aplican la clínica del contexto.
+func Register(db *gorm.DB) error {
+    cb := db.Callback()
+    return errors.Join(
+        cb.Query().Before("gorm:query").Register("tenant:query", scope),
+        cb.Row().Before("gorm:row").Register("tenant:row", scope),
+        cb.Raw().Before("gorm:raw").Register("tenant:raw", forbidRaw),
+        cb.Delete().Before("gorm:delete").Register("tenant:delete", scope),
+        cb.Update().Before("gorm:update").Register("tenant:update", beforeUpdate),
+        cb.Create().Before("gorm:create").Register("tenant:before_create", beforeCreate),
+        cb.Create().After("gorm:create").Register("tenant:after_create", afterCreate),
+    )
+}
+
+// scope añade a la consulta la condición de la clínica.
+func scope(db *gorm.DB) {
+    stmt := db.Statement
+    clinicID, ok := ClinicFromContext(stmt.Context)
+    if db.Error != nil || !ok {
+        return
+    }
+    // Una misma sentencia puede ejecutarse varias veces (Count y Find)
+    if _, done := stmt.Settings.LoadOrStore(scopedKey, true); done {
+        return
+    }
+    // Si la primera vez ya trae el SQL es una consulta escrita a mano (Raw)
+    if stmt.SQL.Len() > 0 {
+        db.AddError(ErrRawSQL)
+        return
+    }
+    condition, ok := conditions[stmt.Table]
+    if !ok {
+        return
+    }
+
+    vars := make([]interface{}, strings.Count(condition, "?"))
+    for i := range vars {
+        vars[i] = clinicID
+    }
+    stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: condition, Vars: vars}}})
+}
+
+// forbidRaw rechaza las sentencias escritas a mano (Exec) con clínica.
+func forbidRaw(db *gorm.DB) {
+    if _, ok := ClinicFromContext(db.Statement.Context); ok && db.Error == nil {
+        db.AddError(ErrRawSQL)
+    }
+}
+
+func beforeUpdate(db *gorm.DB) {
+    scope(db)
+    checkParents(db)
+}
+
+// beforeCreate asigna a la clínica los registros de las tablas propias y comprueba que los registros
+// relacionados son visibles desde ella.
+func beforeCreate(db *gorm.DB) {
+    clinicID, ok := ClinicFromContext(db.Statement.Context)
+    if db.Error != nil || !ok || db.Statement.Schema == nil {
+        return
+    }
+
+    if field := db.Statement.Schema.LookUpField("ClinicID"); field != nil && owned[db.Statement.Table] {
+        eachRecord(db.Statement, func(record reflect.Value) {
+            if err := field.Set(db.Statement.Context, record, clinicID); err != nil {
+                db.AddError(err)
+            }
+        })
+    }
+    checkParents(db)
+}
+
+// afterCreate comparte con la clínica los clientes dados de alta en ella.
+func afterCreate(db *gorm.DB) {
+    clinicID, ok := ClinicFromContext(db.Statement.Context)
+    if db.Error != nil || !ok || db.Statement.Table != "clients" || db.Statement.Schema == nil {
+        return
+    }
+
+    primary := db.Statement.Schema.PrioritizedPrimaryField
+    now := time.Now()
+    var links []models.ClientClinic
+    eachRecord(db.Statement, func(record reflect.Value) {
+        if id, zero := primary.ValueOf(db.Statement.Context, record); !zero {
+            links = append(links, models.ClientClinic{ClientID: id.(uint), ClinicID: clinicID, CreatedAt: now})
+        }
+    })
+    if len(links) == 0 {
+        return
+    }
+
+    err := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).
+        Clauses(clause.OnConflict{DoNothing: true}).
+        Create(&links).Error
+    if err != nil {
+        db.AddError(err)
+    }
+}
+
+// checkParents impide asociar un registro con otro de una clínica distinta,
+// como una mascota con el cliente de otra sede.
+func checkParents(db *gorm.DB) {
+    stmt := db.Statement
+    _, ok := ClinicFromContext(stmt.Context)
+    parent, tracked := parents[stmt.Table]
+    if db.Error != nil || !ok || !tracked || stmt.Schema == nil {
+        return
+    }
+    field := stmt.Schema.LookUpField(parent.field)
+    if field == nil {
+        return
+    }
+
+    ids := map[interface{}]bool{}
+    if values, isMap := stmt.Dest.(map[string]interface{}); isMap {
+        if id, ok := values[field.DBName]; ok {
+            ids[id] = true
+        }
+    } else {
+        eachRecord(stmt, func(record reflect.Value) {
+            if id, zero := field.ValueOf(stmt.Context, record); !zero {
+                ids[id] = true
+            }
+        })
+    }
+
+    for id := range ids {
+        // La consulta hereda la clínica del contexto, así que solo cuenta
+        // el registro si es visible desde ella
+        var count int64
+        err := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).
+            Table(parent.table).Where("id = ?", id).Count(&count).Error
+        if err != nil {
+            db.AddError(err)
+            return
+        }
+        if count == 0 {
+            db.AddError(ErrNotInClinic)
+            return
+        }
+    }
+}
+
+// eachRecord llama a fn con cada registro de la sentencia (uno o un lote).
+func eachRecord(stmt *gorm.Statement, fn func(record reflect.Value)) {
+    value := reflect.Indirect(stmt.ReflectValue)
+    switch value.Kind() {
+    case reflect.Struct:
+        fn(value)
+    case reflect.Slice, reflect.Array:
+        for i := 0; i < value.Len(); i++ {
+            if record := reflect.Indirect(value.Index(i)); record.Kind() == reflect.Struct {
+                fn(record)
+            }
+        }
+    }
+}
+
+// Backfill prepara una base de datos anterior a las clínicas: crea la
+// clínica principal si no hay ninguna y le asigna los clientes que no están
+// compartidos con ninguna y los registros propios de una clínica (citas,
+// veterinarios...) que no tienen ninguna.
+func Backfill(db *gorm.DB) error {
+    return db.Transaction(func(tx *gorm.DB) error {
+        var clinic models.Clinic
+        if err := tx.Order("id").Limit(1).Find(&clinic).Error; err != nil {
+            return err
+        }
+        if clinic.ID == 0 {
+            clinic = models.Clinic{Name: DefaultClinicName}
+            if err := tx.Create(&clinic).Error; err != nil {
+                return err
+            }
+        }
+
+        err := tx.Exec("INSERT INTO client_clinics (client_id, clinic_id, created_at) "+
+            "SELECT id, ?, ? FROM clients WHERE id NOT IN (SELECT client_id FROM client_clinics)", clinic.ID, time.Now()).Error
+        if err != nil {
+            return err
+        }
+        for table := range owned {
+            err := tx.Exec("UPDATE "+table+" SET clinic_id = ? WHERE clinic_id IS NULL OR clinic_id = 0", clinic.ID).Error
+            if err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+}
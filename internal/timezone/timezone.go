@@ -0,0 +1,314 @@
+// Package timezone guarda todas las fechas en UTC y las devuelve en la zona
+// horaria de la clínica. SQLite compara las fechas como texto, así que solo
+// ordena bien las que se escriben con el mismo desplazamiento: la conexión
+// envuelta por Register convierte a UTC todos los parámetros de las
+// sentencias, y los callbacks pasan las fechas leídas o guardadas a la zona
+// horaria del contexto (o a time.Local, la de la instalación, si no hay
+// ninguna). Los campos con la etiqueta `timezone:"date"` son fechas de
+// calendario (por ejemplo la de nacimiento) y se guardan como la medianoche
+// UTC de ese día, sin cambiar de zona al leerlos.
+package timezone
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "reflect"
+    "sync"
+    "time"
+
+    "gorm.io/gorm"
+)
+
+type locationKey struct{}
+
+// WithLocation devuelve un contexto cuyas fechas se muestran en loc.
+func WithLocation(ctx context.Context, loc *time.Location) context.Context {
+    return context.WithValue(ctx, locationKey{}, loc)
+}
+
+// Location devuelve la zona horaria del contexto o time.Local si no hay
+// ninguna.
+func Location(ctx context.Context) *time.Location {
+    if ctx != nil {
+        if loc, ok := ctx.Value(locationKey{}).(*time.Location); ok && loc != nil {
+            return loc
+        }
+    }
+    return time.Local
+}
+
+// ErrInvalidTimeZone se devuelve con un nombre de zona horaria desconocido
+var ErrInvalidTimeZone = errors.New("zona horaria no válida")
+
+var locations sync.Map
+
+// Load devuelve la zona horaria IANA con ese nombre (por ejemplo
+// "Europe/Madrid"), o time.Local si el nombre está vacío. Las zonas se leen
+// una sola vez.
+func Load(name string) (*time.Location, error) {
+    if name == "" {
+        return time.Local, nil
+    }
+    if loc, ok := locations.Load(name); ok {
+        return loc.(*time.Location), nil
+    }
+    loc, err := time.LoadLocation(name)
+    if err != nil {
+        return nil, ErrInvalidTimeZone
+    }
+    locations.Store(name, loc)
+    return loc, nil
+}
+
+// In devuelve t en la zona horaria con ese nombre, o en fallback si está
+// vacío o no es válido.
+func In(t time.Time, name string, fallback *time.Location) time.Time {
+    loc, err := Load(name)
+    if err != nil || name == "" {
+        loc = fallback
+    }
+    return t.In(loc)
+}
+
+// Day devuelve el inicio del día date (AAAA-MM-DD) en loc y el del día
+// siguiente. Los días del cambio de hora duran 23 o 25 horas.
+func Day(date string, loc *time.Location) (time.Time, time.Time, error) {
+    day, err := time.ParseInLocation(time.DateOnly, date, loc)
+    if err != nil {
+        return time.Time{}, time.Time{}, err
+    }
+    return day, day.AddDate(0, 0, 1), nil
+}
+
+// Register envuelve la conexión de db para guardar las fechas en UTC e
+// instala los callbacks que devuelven las fechas en la zona horaria del
+// contexto.
+func Register(db *gorm.DB) error {
+    pool := &utcDB{ConnPool: db.ConnPool}
+    db.ConnPool = pool
+    db.Statement.ConnPool = pool
+
+    cb := db.Callback()
+    return errors.Join(
+        cb.Create().Before("gorm:create").Register("timezone:dates", calendarDates),
+        cb.Update().Before("gorm:update").Register("timezone:dates", calendarDates),
+        cb.Query().After("gorm:query").Register("timezone:query", localize),
+        cb.Create().After("gorm:create").Register("timezone:create", localize),
+        cb.Update().After("gorm:update").Register("timezone:update", localize),
+    )
+}
+
+// Normalize pasa a UTC las fechas que se guardaron con otro desplazamiento
+// antes de usar Register.
+func Normalize(db *gorm.DB, models ...interface{}) error {
+    for _, model := range models {
+        stmt := &gorm.Statement{DB: db}
+        if err := stmt.Parse(model); err != nil {
+            return err
+        }
+        for _, field := range stmt.Schema.Fields {
+            if field.DBName == "" || !isTime(field.FieldType) {
+                continue
+            }
+            column := stmt.Quote(field.DBName)
+            // Mismo formato que usa el driver al escribir: sin fracción de
+            // segundo si es cero. Las fechas de calendario conservan el día
+            value := "CASE WHEN strftime('%f', " + column + ") LIKE '%.000' " +
+                "THEN strftime('%Y-%m-%d %H:%M:%S', " + column + ") " +
+                "ELSE strftime('%Y-%m-%d %H:%M:%f', " + column + ") END || '+00:00'"
+            if field.Tag.Get("timezone") == DateTag {
+                value = "substr(" + column + ", 1, 10) || ' 00:00:00+00:00'"
+            }
+            err := db.Exec("UPDATE " + stmt.Quote(stmt.Schema.Table) + " SET " + column + " = " + value +
+                " WHERE " + column + " IS NOT NULL AND " + column + " NOT LIKE '%+00:00'").Error
+            if err != nil {
+                return err
+            }
+        }
+    }
+    return nil
+}
+
+var (
+    timeType      = reflect.TypeOf(time.Time{})
+    deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+)
+
+// DateTag marca los campos que son fechas de calendario
+const DateTag = "date"
+
+func isTime(t reflect.Type) bool {
+    if t.Kind() == reflect.Ptr {
+        t = t.Elem()
+    }
+    return t == timeType || t == deletedAtType
+}
+
+// localize pasa las fechas de los registros leídos o guardados a la zona
+// horaria del contexto.
+func localize(db *gorm.DB) {
+    loc := Location(db.Statement.Context)
+    eachTime(db, false, func(value reflect.Value) {
+        setIn(value, loc)
+    })
+}
+
+// calendarDates guarda las fechas de calendario como la medianoche UTC del
+// día que indican.
+func calendarDates(db *gorm.DB) {
+    eachTime(db, true, func(value reflect.Value) {
+        if value.Kind() == reflect.Ptr {
+            if value.IsNil() {
+                return
+            }
+            value = value.Elem()
+        }
+        if t, ok := value.Interface().(time.Time); ok && !t.IsZero() && value.CanSet() {
+            value.Set(reflect.ValueOf(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)))
+        }
+    })
+}
+
+// eachTime llama a fn con cada campo de fecha (o solo con las fechas de
+// calendario si dates es true) de los registros de la sentencia.
+func eachTime(db *gorm.DB, dates bool, fn func(value reflect.Value)) {
+    stmt := db.Statement
+    if db.Error != nil || stmt.Schema == nil {
+        return
+    }
+
+    var fields []int
+    for i, field := range stmt.Schema.Fields {
+        if isTime(field.FieldType) && (field.Tag.Get("timezone") == DateTag) == dates {
+            fields = append(fields, i)
+        }
+    }
+    if len(fields) == 0 {
+        return
+    }
+
+    var walk func(value reflect.Value)
+    walk = func(value reflect.Value) {
+        value = reflect.Indirect(value)
+        switch value.Kind() {
+        case reflect.Slice, reflect.Array:
+            for i := 0; i < value.Len(); i++ {
+                walk(value.Index(i))
+            }
+        case reflect.Struct:
+            if value.Type() != stmt.Schema.ModelType || !value.CanAddr() {
+                return
+            }
+            for _, i := range fields {
+                fn(stmt.Schema.Fields[i].ReflectValueOf(stmt.Context, value))
+            }
+        }
+    }
+    walk(stmt.ReflectValue)
+}
+
+func setIn(value reflect.Value, loc *time.Location) {
+    if value.Kind() == reflect.Ptr {
+        if value.IsNil() {
+            return
+        }
+        value = value.Elem()
+    }
+    if !value.CanSet() {
+        return
+    }
+    switch t := value.Interface().(type) {
+    case time.Time:
+        if !t.IsZero() {
+            value.Set(reflect.ValueOf(t.In(loc)))
+        }
+    case gorm.DeletedAt:
+        if t.Valid {
+            value.Set(reflect.ValueOf(gorm.DeletedAt{Time: t.Time.In(loc), Valid: true}))
+        }
+    }
+}
+
+// utc devuelve los parámetros de una sentencia con las fechas en UTC.
+func utc(args []interface{}) []interface{} {
+    args = append([]interface{}(nil), args...)
+    for i, arg := range args {
+        switch v := arg.(type) {
+        case time.Time:
+            args[i] = v.UTC()
+        case *time.Time:
+            if v != nil {
+                t := v.UTC()
+                args[i] = &t
+            }
+        case gorm.DeletedAt:
+            if v.Valid {
+                args[i] = gorm.DeletedAt{Time: v.Time.UTC(), Valid: true}
+            }
+        case sql.NullTime:
+            if v.Valid {
+                args[i] = sql.NullTime{Time: v.Time.UTC(), Valid: true}
+            }
+        }
+    }
+    return args
+}
+
+// utcDB envuelve la conexión y las transacciones que abre. No implementa
+// Commit para que GORM no lo tome por una transacción.
+type utcDB struct {
+    gorm.ConnPool
+}
+
+func (p *utcDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+    return p.ConnPool.ExecContext(ctx, query, utc(args)...)
+}
+
+func (p *utcDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+    return p.ConnPool.QueryContext(ctx, query, utc(args)...)
+}
+
+func (p *utcDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+    return p.ConnPool.QueryRowContext(ctx, query, utc(args)...)
+}
+
+func (p *utcDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+    var tx gorm.ConnPool
+    var err error
+    switch beginner := p.ConnPool.(type) {
+    case gorm.TxBeginner:
+        tx, err = beginner.BeginTx(ctx, opts)
+    case gorm.ConnPoolBeginner:
+        tx, err = beginner.BeginTx(ctx, opts)
+    default:
+        return nil, gorm.ErrInvalidTransaction
+    }
+    if err != nil {
+        return nil, err
+    }
+    return &utcTx{utcDB{ConnPool: tx}}, nil
+}
+
+// GetDBConn permite seguir usando db.DB() (pool de conexiones, ping...).
+func (p *utcDB) GetDBConn() (*sql.DB, error) {
+    switch pool := p.ConnPool.(type) {
+    case *sql.DB:
+        return pool, nil
+    case gorm.GetDBConnector:
+        return pool.GetDBConn()
+    }
+    return nil, gorm.ErrInvalidDB
+}
+
+type utcTx struct {
+    utcDB
+}
+
+func (t *utcTx) Commit() error {
+    return t.ConnPool.(gorm.TxCommitter).Commit()
+}
+
+func (t *utcTx) Rollback() error {
+    return t.ConnPool.(gorm.TxCommitter).Rollback()
+}
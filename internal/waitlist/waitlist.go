@@ -0,0 +1,270 @@
+// Package waitlist ofrece a la lista de espera los huecos que dejan las
+// citas canceladas o a las que no se presentaron. Cada hueco se ofrece al
+// candidato más prioritario que cabe en él; si no acepta antes de que
+// caduque la oferta, o la rechaza, se ofrece al siguiente.
+package waitlist
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log/slog"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/reminders"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "github.com/javice/vet-clinic-api/internal/timezone"
+)
+
+// Config define cuánto duran las ofertas y cada cuánto se revisan.
+type Config struct {
+    // OfferTTL es el plazo para aceptar una oferta
+    OfferTTL time.Duration
+    // Interval es la frecuencia con la que se buscan ofertas caducadas
+    Interval time.Duration
+    // TimeZone es la zona horaria de las clínicas que no indican otra
+    TimeZone *time.Location
+    // Now permite fijar el reloj en los tests
+    Now func() time.Time
+}
+
+// Service gestiona las ofertas de la lista de espera. Los avisos se envían
+// por los mismos canales que los recordatorios.
+type Service struct {
+    repo      *repositories.WaitlistRepository
+    providers map[string]reminders.Provider
+    cfg       Config
+}
+
+func New(repo *repositories.WaitlistRepository, providers map[string]reminders.Provider, cfg Config) *Service {
+    if cfg.OfferTTL <= 0 {
+        cfg.OfferTTL = 30 * time.Minute
+    }
+    if cfg.Interval <= 0 {
+        cfg.Interval = time.Minute
+    }
+    if cfg.Now == nil {
+        cfg.Now = time.Now
+    }
+    if cfg.TimeZone == nil {
+        cfg.TimeZone = time.Local
+    }
+    return &Service{repo: repo, providers: providers, cfg: cfg}
+}
+
+// FreedSlot devuelve el hueco que deja libre la cita a partir de now: desde
+// su hora, o desde el minuto siguiente si ya ha empezado, hasta su fin.
+// Devuelve false si ya no queda tiempo libre.
+func FreedSlot(appointment models.Appointment, now time.Time) (repositories.FreeSlot, bool) {
+    slot := repositories.FreeSlot{
+        AppointmentID: appointment.ID,
+        ClinicID:      appointment.ClinicID,
+        Start:         appointment.Date,
+        End:           appointment.End(),
+        VetID:         appointment.VetID,
+    }
+    if now.After(slot.Start) {
+        slot.Start = now.Truncate(time.Minute).Add(time.Minute)
+    }
+    return slot, slot.Minutes() > 0
+}
+
+// SlotFreed ofrece el hueco de una cita cancelada o ausencia al mejor
+// candidato de la lista de espera. Devuelve nil si nadie cabe en él o si el
+// hueco ya está ofrecido u ocupado.
+func (s *Service) SlotFreed(ctx context.Context, appointment models.Appointment) (*models.WaitlistOffer, error) {
+    if appointment.Active() {
+        return nil, nil
+    }
+    slot, ok := FreedSlot(appointment, s.cfg.Now())
+    if !ok {
+        return nil, nil
+    }
+
+    repo := s.repo.WithContext(ctx)
+    filled, err := repo.Filled(appointment.ID)
+    if err != nil || filled {
+        return nil, err
+    }
+
+    candidates, err := repo.Candidates(slot)
+    if err != nil {
+        return nil, err
+    }
+    for _, entry := range candidates {
+        if !entry.Fits(slot.Start) {
+            continue
+        }
+
+        offer := models.WaitlistOffer{
+            EntryID:            entry.ID,
+            ClinicID:           slot.ClinicID,
+            FreedAppointmentID: slot.AppointmentID,
+            Date:               slot.Start,
+            Duration:           entry.Duration,
+            VetID:              slot.VetID,
+            ExpiresAt:          s.cfg.Now().Add(s.cfg.OfferTTL),
+        }
+        if err := repo.CreateOffer(&offer); err != nil {
+            // Otra petición se ha adelantado con esta entrada
+            if errors.Is(err, repositories.ErrWaitlistClosed) {
+                continue
+            }
+            return nil, err
+        }
+
+        s.notify(ctx, offer)
+        return &offer, nil
+    }
+    return nil, nil
+}
+
+// Accept convierte la oferta en una cita. Si ha caducado la cierra, ofrece
+// el hueco al siguiente candidato y devuelve ErrOfferExpired.
+func (s *Service) Accept(ctx context.Context, offerID uint) (models.Appointment, error) {
+    appointment, err := s.repo.WithContext(ctx).AcceptOffer(offerID, s.cfg.Now())
+    if errors.Is(err, repositories.ErrOfferExpired) {
+        if expireErr := s.close(ctx, offerID, models.OfferExpired); expireErr != nil {
+            return appointment, expireErr
+        }
+    }
+    return appointment, err
+}
+
+// Decline rechaza la oferta y ofrece el hueco al siguiente candidato.
+// Devuelve ErrOfferClosed si la oferta ya no estaba pendiente.
+func (s *Service) Decline(ctx context.Context, offerID uint) error {
+    offer, err := s.repo.WithContext(ctx).GetOffer(offerID)
+    if err != nil {
+        return err
+    }
+    if offer.Status != models.OfferPending {
+        return repositories.ErrOfferClosed
+    }
+    return s.close(ctx, offerID, models.OfferDeclined)
+}
+
+// Cancel saca una entrada de la lista de espera. Si tenía una oferta
+// pendiente, el hueco se ofrece al siguiente candidato.
+func (s *Service) Cancel(ctx context.Context, entryID uint) error {
+    offers, err := s.repo.WithContext(ctx).Cancel(entryID)
+    if err != nil {
+        return err
+    }
+    for _, offer := range offers {
+        s.reoffer(ctx, offer)
+    }
+    return nil
+}
+
+// Start revisa las ofertas caducadas hasta que se cancele el contexto.
+func (s *Service) Start(ctx context.Context) {
+    ticker := time.NewTicker(s.cfg.Interval)
+    defer ticker.Stop()
+
+    for {
+        if err := s.RunOnce(ctx); err != nil {
+            slog.ErrorContext(ctx, "Error revisando la lista de espera", "error", err)
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// RunOnce cierra las ofertas caducadas y ofrece sus huecos al siguiente
+// candidato.
+func (s *Service) RunOnce(ctx context.Context) error {
+    offers, err := s.repo.WithContext(ctx).GetExpiredOffers(s.cfg.Now())
+    if err != nil {
+        return err
+    }
+    for _, offer := range offers {
+        if ctx.Err() != nil {
+            return ctx.Err()
+        }
+        if err := s.close(ctx, offer.ID, models.OfferExpired); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// close rechaza o da por caducada la oferta y, si estaba pendiente, ofrece
+// su hueco al siguiente candidato.
+func (s *Service) close(ctx context.Context, offerID uint, status string) error {
+    repo := s.repo.WithContext(ctx)
+    offer, err := repo.GetOffer(offerID)
+    if err != nil {
+        return err
+    }
+    closed, err := repo.CloseOffer(&offer, status)
+    if err != nil || !closed {
+        return err
+    }
+    s.reoffer(ctx, offer)
+    return nil
+}
+
+// reoffer ofrece al siguiente candidato el hueco de una oferta cerrada. Los
+// errores solo se registran: la oferta ya está cerrada.
+func (s *Service) reoffer(ctx context.Context, offer models.WaitlistOffer) {
+    appointment, err := s.repo.WithContext(ctx).GetFreedAppointment(offer.FreedAppointmentID)
+    if err == nil {
+        _, err = s.SlotFreed(ctx, appointment)
+    }
+    if err != nil {
+        slog.ErrorContext(ctx, "Error ofreciendo el hueco al siguiente candidato", "appointment_id", offer.FreedAppointmentID, "error", err)
+    }
+}
+
+// notify avisa al cliente de la oferta por cada canal configurado. Los
+// errores solo se registran: la oferta sigue disponible para el personal
+// de la clínica.
+func (s *Service) notify(ctx context.Context, offer models.WaitlistOffer) {
+    if len(s.providers) == 0 {
+        return
+    }
+    target, err := s.repo.WithContext(ctx).GetOfferTarget(offer)
+    if err != nil {
+        slog.ErrorContext(ctx, "Error avisando de la oferta", "offer_id", offer.ID, "error", err)
+        return
+    }
+
+    for channel, provider := range s.providers {
+        msg := offerMessage(channel, target, offer, s.cfg.TimeZone)
+        if msg.To == "" {
+            continue
+        }
+        if err := provider.Send(ctx, msg); err != nil {
+            slog.ErrorContext(ctx, "Error avisando de la oferta", "offer_id", offer.ID, "channel", channel, "error", err)
+        }
+    }
+}
+
+func offerMessage(channel string, target repositories.OfferTarget, offer models.WaitlistOffer, fallback *time.Location) reminders.Message {
+    // Las horas se muestran en la zona horaria de la clínica
+    offer.Date = timezone.In(offer.Date, target.TimeZone, fallback)
+    offer.ExpiresAt = timezone.In(offer.ExpiresAt, target.TimeZone, fallback)
+    if channel == reminders.ChannelSMS {
+        return reminders.Message{
+            Channel: channel,
+            To:      target.Phone,
+            Body: fmt.Sprintf("Hay un hueco para %s el %s a las %s. Confirme antes de las %s (oferta %d).",
+                target.PetName, offer.Date.Format("02/01"), offer.Date.Format("15:04"), offer.ExpiresAt.Format("15:04"), offer.ID),
+        }
+    }
+    return reminders.Message{
+        Channel: channel,
+        To:      target.Email,
+        Subject: fmt.Sprintf("Hueco disponible para %s el %s", target.PetName, offer.Date.Format("02/01/2006")),
+        Body: fmt.Sprintf("Hola %s,\n\nSe ha liberado un hueco para %s el %s a las %s (%d minutos).\n"+
+            "Si le interesa, confírmelo antes de las %s del %s indicando la oferta %d; después se ofrecerá a otro cliente.\n",
+            target.ClientName, target.PetName, offer.Date.Format("02/01/2006"), offer.Date.Format("15:04"), offer.Duration,
+            offer.ExpiresAt.Format("15:04"), offer.ExpiresAt.Format("02/01/2006"), offer.ID),
+    }
+}
@@ -0,0 +1,256 @@
+package tests
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "testing"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/handlers"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestAdmissions(t *testing.T) {
+    router, db, err := setupTestRouter()
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    request := func(method, url string, body interface{}) *httptest.ResponseRecorder {
+        var payload []byte
+        if body != nil {
+            payload, _ = json.Marshal(body)
+        }
+        req, _ := newRequest(method, url, bytes.NewBuffer(payload))
+        req.Header.Set("Content-Type", "application/json")
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+    decode := func(resp *httptest.ResponseRecorder, v interface{}) {
+        json.Unmarshal(resp.Body.Bytes(), v)
+    }
+    create := func(url string, body interface{}) uint {
+        resp := request("POST", url, body)
+        if !assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String()) {
+            t.FailNow()
+        }
+        var created struct {
+            ID uint `json:"id"`
+        }
+        decode(resp, &created)
+        return created.ID
+    }
+
+    client := models.Client{Name: "Elena Ruiz", Email: "elena@example.com", Phone: "600777888"}
+    assert.NoError(t, db.Create(&client).Error)
+    rex := models.Pet{Name: "Rex", Species: "Dog", ClientID: client.ID}
+    luna := models.Pet{Name: "Luna", Species: "Cat", ClientID: client.ID}
+    assert.NoError(t, db.Create(&rex).Error)
+    assert.NoError(t, db.Create(&luna).Error)
+
+    now := time.Now()
+    surgery := create("/api/v1/appointments", map[string]interface{}{
+        "pet_id": rex.ID, "date": now.Add(-4 * time.Hour), "reason": "Castración", "duration": 60,
+    })
+    kennel1 := create("/api/v1/resources", map[string]interface{}{"name": "Jaula 1", "kind": "kennel"})
+    kennel2 := create("/api/v1/resources", map[string]interface{}{"name": "Jaula 2", "kind": "kennel"})
+    room := create("/api/v1/resources", map[string]interface{}{"name": "Quirófano", "kind": "room"})
+
+    var rexAdmission, lunaAdmission uint
+    t.Run("Admit", func(t *testing.T) {
+        resp := request("POST", "/api/v1/admissions", map[string]interface{}{
+            "pet_id": rex.ID, "appointment_id": surgery, "kennel_id": kennel1, "reason": "Postoperatorio",
+        })
+        if !assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String()) {
+            return
+        }
+        var admission models.Admission
+        decode(resp, &admission)
+        rexAdmission = admission.ID
+        assert.True(t, admission.Current())
+        assert.WithinDuration(t, now, admission.AdmittedAt, time.Minute)
+        if assert.NotNil(t, admission.Kennel) {
+            assert.Equal(t, "Jaula 1", admission.Kennel.Name)
+        }
+
+        // Con la mascota y la cita que originó el ingreso
+        resp = request("GET", "/api/v1/admissions/"+strconv.Itoa(int(rexAdmission))+"?include=pet,appointment", nil)
+        decode(resp, &admission)
+        if assert.NotNil(t, admission.Pet) && assert.NotNil(t, admission.Appointment) {
+            assert.Equal(t, "Rex", admission.Pet.Name)
+            assert.Equal(t, surgery, admission.Appointment.ID)
+        }
+
+        // La misma mascota no puede ingresar dos veces, ni dos en la misma jaula
+        resp = request("POST", "/api/v1/admissions", map[string]interface{}{"pet_id": rex.ID, "reason": "Otra vez"})
+        assert.Equal(t, http.StatusConflict, resp.Code)
+        resp = request("POST", "/api/v1/admissions", map[string]interface{}{"pet_id": luna.ID, "kennel_id": kennel1, "reason": "Observación"})
+        assert.Equal(t, http.StatusConflict, resp.Code)
+
+        // La jaula tiene que ser una jaula y la cita, de la mascota
+        resp = request("POST", "/api/v1/admissions", map[string]interface{}{"pet_id": luna.ID, "kennel_id": room, "reason": "Observación"})
+        assert.Equal(t, http.StatusNotFound, resp.Code)
+        resp = request("POST", "/api/v1/admissions", map[string]interface{}{"pet_id": luna.ID, "appointment_id": surgery, "reason": "Observación"})
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+        resp = request("POST", "/api/v1/admissions", map[string]interface{}{"pet_id": 999, "reason": "Observación"})
+        assert.Equal(t, http.StatusNotFound, resp.Code)
+        resp = request("POST", "/api/v1/admissions", map[string]interface{}{"pet_id": luna.ID})
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+        lunaAdmission = create("/api/v1/admissions", map[string]interface{}{
+            "pet_id": luna.ID, "kennel_id": kennel2, "reason": "Fluidoterapia", "admitted_at": now.Add(-2 * time.Hour),
+        })
+
+        // Una jaula ocupada no se puede dar de baja
+        assert.Equal(t, http.StatusConflict, request("DELETE", "/api/v1/resources/"+strconv.Itoa(int(kennel2)), nil).Code)
+    })
+
+    rexURL := "/api/v1/admissions/" + strconv.Itoa(int(rexAdmission))
+    lunaURL := "/api/v1/admissions/" + strconv.Itoa(int(lunaAdmission))
+
+    var doses []models.Treatment
+    t.Run("Treatment Sheet", func(t *testing.T) {
+        // Tres dosis cada ocho horas, la primera hace una hora
+        resp := request("POST", rexURL+"/treatments", map[string]interface{}{
+            "kind": "medication", "description": "Meloxicam", "dose": "0,1 mg/kg SC",
+            "scheduled_at": now.Add(-time.Hour), "repeat_every": 480, "repeat_times": 3,
+        })
+        if !assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String()) {
+            return
+        }
+        decode(resp, &doses)
+        if assert.Len(t, doses, 3) {
+            assert.Equal(t, 8*time.Hour, doses[1].ScheduledAt.Sub(doses[0].ScheduledAt))
+            assert.Equal(t, 16*time.Hour, doses[2].ScheduledAt.Sub(doses[0].ScheduledAt))
+        }
+        create(rexURL+"/treatments", map[string]interface{}{
+            "kind": "observation", "description": "Temperatura y herida", "scheduled_at": now.Add(2 * time.Hour),
+        })
+        create(lunaURL+"/treatments", map[string]interface{}{
+            "kind": "observation", "description": "Diuresis", "scheduled_at": now.Add(-3 * time.Hour),
+        })
+
+        resp = request("POST", rexURL+"/treatments", map[string]interface{}{
+            "kind": "medication", "description": "Meloxicam", "scheduled_at": now, "repeat_times": 3,
+        })
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+        resp = request("POST", rexURL+"/treatments", map[string]interface{}{
+            "kind": "bath", "description": "Baño", "scheduled_at": now,
+        })
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+        assert.Equal(t, http.StatusNotFound, request("POST", "/api/v1/admissions/999/treatments", map[string]interface{}{
+            "kind": "observation", "description": "Peso", "scheduled_at": now,
+        }).Code)
+    })
+
+    t.Run("Ward Board", func(t *testing.T) {
+        var board []handlers.WardPatient
+        decode(request("GET", "/api/v1/ward", nil), &board)
+        if !assert.Len(t, board, 2) {
+            return
+        }
+        // Primero la mascota con la tarea más atrasada
+        assert.Equal(t, lunaAdmission, board[0].Admission.ID)
+        assert.Len(t, board[0].Overdue, 1)
+        assert.Equal(t, rexAdmission, board[1].Admission.ID)
+        if assert.NotNil(t, board[1].Admission.Pet) && assert.NotNil(t, board[1].Admission.Kennel) {
+            assert.Equal(t, "Rex", board[1].Admission.Pet.Name)
+            assert.Equal(t, "Jaula 1", board[1].Admission.Kennel.Name)
+        }
+        if assert.Len(t, board[1].Overdue, 1) {
+            assert.Equal(t, "Meloxicam", board[1].Overdue[0].Description)
+        }
+        assert.Len(t, board[1].Upcoming, 3)
+    })
+
+    t.Run("Tick Off", func(t *testing.T) {
+        if len(doses) == 0 {
+            t.Skip("sin dosis programadas")
+        }
+        url := rexURL + "/treatments/" + strconv.Itoa(int(doses[0].ID)) + "/done"
+        resp := request("POST", url, map[string]interface{}{"initials": "MG", "notes": "Sin incidencias"})
+        if !assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+            return
+        }
+        var done models.Treatment
+        decode(resp, &done)
+        assert.Equal(t, "MG", done.DoneBy)
+        if assert.NotNil(t, done.DoneAt) {
+            assert.WithinDuration(t, time.Now(), *done.DoneAt, time.Minute)
+        }
+
+        assert.Equal(t, http.StatusConflict, request("POST", url, map[string]interface{}{"initials": "MG"}).Code)
+        assert.Equal(t, http.StatusBadRequest, request("POST", rexURL+"/treatments/"+strconv.Itoa(int(doses[1].ID))+"/done", map[string]interface{}{}).Code)
+        // La tarea tiene que ser del ingreso
+        assert.Equal(t, http.StatusNotFound, request("POST", lunaURL+"/treatments/"+strconv.Itoa(int(doses[1].ID))+"/done", map[string]interface{}{"initials": "MG"}).Code)
+
+        var board []handlers.WardPatient
+        decode(request("GET", "/api/v1/ward", nil), &board)
+        if assert.Len(t, board, 2) {
+            assert.Equal(t, rexAdmission, board[1].Admission.ID)
+            assert.Empty(t, board[1].Overdue)
+        }
+
+        // La hoja de tratamiento completa, por orden de hora
+        var admission models.Admission
+        decode(request("GET", rexURL, nil), &admission)
+        if assert.Len(t, admission.Treatments, 4) {
+            assert.NotNil(t, admission.Treatments[0].DoneAt)
+            assert.Equal(t, "Temperatura y herida", admission.Treatments[1].Description)
+        }
+    })
+
+    t.Run("Move Kennel", func(t *testing.T) {
+        assert.Equal(t, http.StatusConflict, request("PUT", rexURL+"/kennel", map[string]interface{}{"kennel_id": kennel2}).Code)
+        kennel3 := create("/api/v1/resources", map[string]interface{}{"name": "Jaula 3", "kind": "kennel"})
+        resp := request("PUT", rexURL+"/kennel", map[string]interface{}{"kennel_id": kennel3})
+        if assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+            var admission models.Admission
+            decode(resp, &admission)
+            assert.Equal(t, kennel3, *admission.KennelID)
+        }
+    })
+
+    t.Run("Discharge", func(t *testing.T) {
+        resp := request("POST", lunaURL+"/discharge", map[string]interface{}{"discharged_at": now.Add(-3 * time.Hour)})
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+        resp = request("POST", lunaURL+"/discharge", map[string]interface{}{"notes": "Control en una semana"})
+        if !assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+            return
+        }
+        var admission models.Admission
+        decode(resp, &admission)
+        assert.False(t, admission.Current())
+        assert.Equal(t, "Control en una semana", admission.DischargeNotes)
+
+        assert.Equal(t, http.StatusConflict, request("POST", lunaURL+"/discharge", nil).Code)
+        assert.Equal(t, http.StatusConflict, request("POST", lunaURL+"/treatments", map[string]interface{}{
+            "kind": "observation", "description": "Peso", "scheduled_at": now,
+        }).Code)
+        // Ni se marcan las tareas que quedaron pendientes
+        var pending models.Treatment
+        if assert.NoError(t, db.Where("admission_id = ? AND done_at IS NULL", lunaAdmission).First(&pending).Error) {
+            url := lunaURL + "/treatments/" + strconv.Itoa(int(pending.ID)) + "/done"
+            assert.Equal(t, http.StatusConflict, request("POST", url, map[string]interface{}{"initials": "MG"}).Code)
+        }
+
+        // Fuera del tablero, la jaula queda libre y se puede volver a ingresar
+        var board []handlers.WardPatient
+        decode(request("GET", "/api/v1/ward", nil), &board)
+        assert.Len(t, board, 1)
+        var current []models.Admission
+        decode(request("GET", "/api/v1/admissions?current=true", nil), &current)
+        assert.Len(t, current, 1)
+        var history []models.Admission
+        decode(request("GET", "/api/v1/admissions?pet_id="+strconv.Itoa(int(luna.ID)), nil), &history)
+        assert.Len(t, history, 1)
+
+        create("/api/v1/admissions", map[string]interface{}{"pet_id": luna.ID, "kennel_id": kennel2, "reason": "Reingreso"})
+    })
+}
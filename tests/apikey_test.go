@@ -0,0 +1,241 @@
+package tests
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/auth"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/routes"
+    "github.com/stretchr/testify/assert"
+    "gorm.io/gorm"
+)
+
+func TestAPIKeys(t *testing.T) {
+    router, db, err := setupTestRouter()
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    request := func(method, url, apiKey string, body interface{}, headers ...string) *httptest.ResponseRecorder {
+        var payload []byte
+        if body != nil {
+            payload, _ = json.Marshal(body)
+        }
+        req, _ := newRequest(method, url, bytes.NewBuffer(payload))
+        req.Header.Set("Content-Type", "application/json")
+        req.Header.Set("X-Admin-Token", testAdminToken)
+        if apiKey != "" {
+            req.Header.Set("X-API-Key", apiKey)
+        }
+        for i := 0; i+1 < len(headers); i += 2 {
+            req.Header.Set(headers[i], headers[i+1])
+        }
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+
+    create := func(body interface{}) (map[string]interface{}, *httptest.ResponseRecorder) {
+        resp := request("POST", "/api/v1/admin/api-keys", "", body)
+        var created map[string]interface{}
+        json.Unmarshal(resp.Body.Bytes(), &created)
+        return created, resp
+    }
+
+    var labKey string
+    var labID uint
+
+    // Las claves solo ven los datos de su clínica
+    clinic := models.Clinic{Name: "Laboratorio central"}
+    assert.NoError(t, db.Create(&clinic).Error)
+
+    t.Run("Create", func(t *testing.T) {
+        created, resp := create(map[string]interface{}{
+            "name":      "laboratorio",
+            "scopes":    []string{"pets:read", "appointments:read", "appointments:write"},
+            "clinic_id": clinic.ID,
+        })
+        if !assert.Equal(t, http.StatusCreated, resp.Code) {
+            return
+        }
+
+        labKey = created["key"].(string)
+        labID = uint(created["id"].(float64))
+        assert.True(t, strings.HasPrefix(labKey, auth.APIKeyPrefix))
+        assert.True(t, strings.HasPrefix(labKey, created["prefix"].(string)))
+        assert.NotContains(t, created, "hash")
+
+        // Solo se guarda el hash
+        var stored models.APIKey
+        assert.NoError(t, db.First(&stored, labID).Error)
+        assert.Equal(t, auth.HashAPIKey(labKey), stored.Hash)
+        assert.NotContains(t, stored.Hash, labKey)
+        assert.Equal(t, models.Scopes{"pets:read", "appointments:read", "appointments:write"}, stored.Scopes)
+    })
+
+    t.Run("Invalid Requests", func(t *testing.T) {
+        _, resp := create(map[string]interface{}{"name": "x", "scopes": []string{"pets:read", "pets:delete"}})
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+        assert.Contains(t, resp.Body.String(), "pets:delete")
+
+        _, resp = create(map[string]interface{}{"name": "x"})
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+        _, resp = create(map[string]interface{}{"name": "x", "scopes": []string{"pets:read"}, "expires_at": time.Now().Add(-time.Hour)})
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+        req, _ := http.NewRequest("GET", "/api/v1/admin/api-keys", nil)
+        resp = httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+        // Una clave de API no sirve para administrar, aunque llegue con el token
+        assert.Equal(t, http.StatusForbidden, request("GET", "/api/v1/admin/api-keys", labKey, nil).Code)
+    })
+
+    t.Run("Scopes Enforced", func(t *testing.T) {
+        assert.Equal(t, http.StatusOK, request("GET", "/api/v1/pets", labKey, nil).Code)
+        assert.Equal(t, http.StatusOK, request("GET", "/api/v1/appointments", labKey, nil).Code)
+
+        resp := request("GET", "/api/v1/clients", labKey, nil)
+        assert.Equal(t, http.StatusForbidden, resp.Code)
+        assert.Contains(t, resp.Body.String(), "clients:read")
+
+        resp = request("POST", "/api/v1/pets", labKey, map[string]interface{}{"name": "Luna"})
+        assert.Equal(t, http.StatusForbidden, resp.Code)
+        assert.Contains(t, resp.Body.String(), "pets:write")
+
+        // Solo la clínica de la clave
+        resp = request("GET", "/api/v1/pets", labKey, nil, "X-Clinic-ID", "999")
+        assert.Equal(t, http.StatusForbidden, resp.Code)
+
+        // Las rutas anidadas exigen también el permiso del recurso que devuelven
+        assert.Equal(t, http.StatusForbidden, request("GET", "/api/v1/clients/1/pets", labKey, nil).Code)
+
+        // El token de administración lo permite todo
+        assert.Equal(t, http.StatusOK, request("GET", "/api/v1/clients", "", nil).Code)
+
+        // Sin clave ni token no se identifica a nadie
+        req, _ := http.NewRequest("GET", "/api/v1/clients", nil)
+        resp = httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        assert.Equal(t, http.StatusUnauthorized, resp.Code)
+        assert.Contains(t, resp.Body.String(), "X-API-Key")
+    })
+
+    t.Run("Principal Is Audit Actor", func(t *testing.T) {
+        client := models.Client{Name: "Ana", Email: "ana@example.com", Phone: "600111222"}
+        assert.NoError(t, db.Create(&client).Error)
+        assert.NoError(t, db.Create(&models.ClientClinic{ClientID: client.ID, ClinicID: clinic.ID}).Error)
+        pet := models.Pet{Name: "Luna", Species: "Dog", ClientID: client.ID}
+        assert.NoError(t, db.Create(&pet).Error)
+
+        resp := request("POST", "/api/v1/appointments", labKey, map[string]interface{}{
+            "pet_id":   pet.ID,
+            "date":     time.Now().Add(48 * time.Hour).Format(time.RFC3339),
+            "reason":   "Analítica",
+            "duration": 30,
+        })
+        if !assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String()) {
+            return
+        }
+
+        var log models.AuditLog
+        assert.NoError(t, db.Where("entity = ?", "appointment").Last(&log).Error)
+        assert.Equal(t, "apikey:laboratorio", log.Actor)
+    })
+
+    t.Run("Last Used", func(t *testing.T) {
+        var stored models.APIKey
+        assert.NoError(t, db.First(&stored, labID).Error)
+        if assert.NotNil(t, stored.LastUsedAt) {
+            assert.WithinDuration(t, time.Now(), *stored.LastUsedAt, time.Minute)
+        }
+    })
+
+    t.Run("List Hides Keys", func(t *testing.T) {
+        resp := request("GET", "/api/v1/admin/api-keys", "", nil)
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.NotContains(t, resp.Body.String(), labKey)
+        assert.NotContains(t, resp.Body.String(), `"hash"`)
+        assert.Contains(t, resp.Body.String(), `"last_used_at":"`)
+    })
+
+    t.Run("Rotate", func(t *testing.T) {
+        resp := request("POST", "/api/v1/admin/api-keys/"+strconv.Itoa(int(labID))+"/rotate", "", nil)
+        if !assert.Equal(t, http.StatusOK, resp.Code) {
+            return
+        }
+        var rotated map[string]interface{}
+        json.Unmarshal(resp.Body.Bytes(), &rotated)
+        newKey := rotated["key"].(string)
+        assert.NotEqual(t, labKey, newKey)
+        assert.Equal(t, float64(labID), rotated["id"])
+        assert.Len(t, rotated["scopes"], 3)
+
+        assert.Equal(t, http.StatusUnauthorized, request("GET", "/api/v1/pets", labKey, nil).Code)
+        assert.Equal(t, http.StatusOK, request("GET", "/api/v1/pets", newKey, nil).Code)
+        labKey = newKey
+    })
+
+    t.Run("Revoke", func(t *testing.T) {
+        url := "/api/v1/admin/api-keys/" + strconv.Itoa(int(labID))
+        resp := request("DELETE", url, "", nil)
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.Contains(t, resp.Body.String(), `"revoked_at":"`)
+
+        resp = request("GET", "/api/v1/pets", labKey, nil)
+        assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+        assert.Equal(t, http.StatusConflict, request("DELETE", url, "", nil).Code)
+        assert.Equal(t, http.StatusConflict, request("POST", url+"/rotate", "", nil).Code)
+        assert.Equal(t, http.StatusNotFound, request("DELETE", "/api/v1/admin/api-keys/999", "", nil).Code)
+    })
+
+    t.Run("Expired And Unknown Keys", func(t *testing.T) {
+        created, resp := create(map[string]interface{}{
+            "name":       "widget",
+            "scopes":     []string{"appointments:read"},
+            "expires_at": time.Now().Add(time.Hour),
+        })
+        if !assert.Equal(t, http.StatusCreated, resp.Code) {
+            return
+        }
+        assert.NoError(t, db.Model(&models.APIKey{}).Where("id = ?", created["id"]).
+            Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+        assert.Equal(t, http.StatusUnauthorized, request("GET", "/api/v1/appointments", created["key"].(string), nil).Code)
+        assert.Equal(t, http.StatusUnauthorized, request("GET", "/api/v1/appointments", "vck_desconocida", nil).Code)
+    })
+
+    t.Run("Allow Anonymous", func(t *testing.T) {
+        router, _, err := setupTestRouterWith(func(db *gorm.DB, opts *routes.Options) error {
+            opts.AllowAnonymous = true
+            return nil
+        })
+        if !assert.NoError(t, err) {
+            return
+        }
+
+        anonymous := func(method, url string) int {
+            req, _ := http.NewRequest(method, url, nil)
+            resp := httptest.NewRecorder()
+            router.ServeHTTP(resp, req)
+            return resp.Code
+        }
+
+        // Los anónimos solo leen los catálogos comunes
+        assert.Equal(t, http.StatusOK, anonymous("GET", "/api/v1/species"))
+        assert.Equal(t, http.StatusOK, anonymous("GET", "/api/v1/services"))
+        assert.Equal(t, http.StatusForbidden, anonymous("GET", "/api/v1/clients"))
+        assert.Equal(t, http.StatusForbidden, anonymous("POST", "/api/v1/species"))
+        assert.Equal(t, http.StatusForbidden, anonymous("GET", "/api/v1/admin/api-keys"))
+    })
+}
@@ -3,8 +3,9 @@ package tests
 
 import (
     "testing"
-    /* "time"
-    "bytes"
+    "time"
+    "strconv"
+    /* "bytes"
     "encoding/json" */
     "net/http"
     "net/http/httptest"
@@ -31,7 +32,7 @@ func TestAppointmentEndpoints(t *testing.T) {
         }
 
         jsonData, _ := json.Marshal(appt)
-        req, _ := http.NewRequest("POST", "/api/v1/appointments", bytes.NewBuffer(jsonData))
+        req, _ := newRequest("POST", "/api/v1/appointments", bytes.NewBuffer(jsonData))
         req.Header.Set("Content-Type", "application/json")
         
         resp := httptest.NewRecorder()
@@ -41,7 +42,7 @@ func TestAppointmentEndpoints(t *testing.T) {
     }) */
 
     t.Run("Get All Appointments", func(t *testing.T) {
-		req, _ := http.NewRequest("GET", "/api/v1/appointments", nil)
+		req, _ := newRequest("GET", "/api/v1/appointments", nil)
 		req.Header.Set("Content-Type", "application/json")
 		
 		resp := httptest.NewRecorder()
@@ -51,7 +52,7 @@ func TestAppointmentEndpoints(t *testing.T) {
 	})
 
 	/* t.Run("Get Appointment By ID", func(t *testing.T) {
-		req, _ := http.NewRequest("GET", "/api/v1/appointments/1", nil)
+		req, _ := newRequest("GET", "/api/v1/appointments/1", nil)
 		req.Header.Set("Content-Type", "application/json")
 		
 		resp := httptest.NewRecorder()
@@ -68,7 +69,7 @@ func TestAppointmentEndpoints(t *testing.T) {
 		}
 
 		jsonData, _ := json.Marshal(appt)
-		req, _ := http.NewRequest("PUT", "/api/v1/appointments/1", bytes.NewBuffer(jsonData))
+		req, _ := newRequest("PUT", "/api/v1/appointments/1", bytes.NewBuffer(jsonData))
 		req.Header.Set("Content-Type", "application/json")
 		
 		resp := httptest.NewRecorder()
@@ -78,8 +79,12 @@ func TestAppointmentEndpoints(t *testing.T) {
 	}) */
 
 	t.Run("Delete Appointment", func(t *testing.T) {
-		req, _ := http.NewRequest("DELETE", "/api/v1/appointments/1", nil)
+		appt := models.Appointment{PetID: pet.ID, Date: time.Now().Add(-24 * time.Hour), Reason: "Checkup", Duration: 30}
+		db.Create(&appt)
+
+		req, _ := newRequest("DELETE", "/api/v1/appointments/"+strconv.FormatUint(uint64(appt.ID), 10), nil)
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", etagFor(appt.Version))
 		
 		resp := httptest.NewRecorder()
 		router.ServeHTTP(resp, req)
@@ -0,0 +1,206 @@
+package tests
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "testing"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestAppointmentTypes(t *testing.T) {
+    router, db, err := setupTestRouter()
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    request := func(method, url string, body interface{}) *httptest.ResponseRecorder {
+        var payload []byte
+        if body != nil {
+            payload, _ = json.Marshal(body)
+        }
+        req, _ := newRequest(method, url, bytes.NewBuffer(payload))
+        req.Header.Set("Content-Type", "application/json")
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+    create := func(url string, body interface{}) uint {
+        resp := request("POST", url, body)
+        if !assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String()) {
+            t.FailNow()
+        }
+        var created struct {
+            ID uint `json:"id"`
+        }
+        json.Unmarshal(resp.Body.Bytes(), &created)
+        return created.ID
+    }
+
+    client := models.Client{Name: "Catálogo", Email: "catalogo@example.com", Phone: "600777888"}
+    assert.NoError(t, db.Create(&client).Error)
+    pet := models.Pet{Name: "Luna", Species: "Cat", ClientID: client.ID}
+    assert.NoError(t, db.Create(&pet).Error)
+
+    day := time.Now().AddDate(0, 0, 5)
+    at := func(hour, minute int) time.Time {
+        return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, time.Local)
+    }
+
+    var surgeon, generalist, theatre, surgeryService, surgery, vaccination uint
+    t.Run("Create Catalog", func(t *testing.T) {
+        surgeon = create("/api/v1/vets", map[string]interface{}{"name": "Ana", "specialties": []string{"Cirugía", "Traumatología"}})
+        generalist = create("/api/v1/vets", map[string]interface{}{"name": "Luis"})
+        theatre = create("/api/v1/resources", map[string]interface{}{"name": "Quirófano", "kind": "room"})
+
+        surgeryService = create("/api/v1/services", map[string]interface{}{"code": "CIR-01", "name": "Cirugía menor", "price": 18000})
+        resp := request("POST", "/api/v1/services", map[string]interface{}{"code": "X", "name": "Negativo", "price": -1})
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+        surgery = create("/api/v1/appointment-types", map[string]interface{}{
+            "name":          "Cirugía",
+            "duration":      120,
+            "buffer_before": 15,
+            "buffer_after":  30,
+            "specialty":     "cirugía",
+            "resource_ids":  []uint{theatre},
+            "service_id":    surgeryService,
+            "color":         "#d9534f",
+        })
+        vaccination = create("/api/v1/appointment-types", map[string]interface{}{"name": "Vacunación", "duration": 15, "color": "#5cb85c"})
+        create("/api/v1/appointment-types", map[string]interface{}{"name": "Consulta", "duration": 30})
+
+        resp = request("POST", "/api/v1/appointment-types", map[string]interface{}{"name": "Sin duración"})
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+        resp = request("POST", "/api/v1/appointment-types", map[string]interface{}{"name": "Color", "duration": 30, "color": "rojo"})
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+        resp = request("POST", "/api/v1/appointment-types", map[string]interface{}{"name": "Servicio", "duration": 30, "service_id": 999})
+        assert.Equal(t, http.StatusNotFound, resp.Code)
+        resp = request("POST", "/api/v1/appointment-types", map[string]interface{}{"name": "Sala", "duration": 30, "resource_ids": []uint{999}})
+        assert.Equal(t, http.StatusNotFound, resp.Code)
+
+        var types []models.AppointmentType
+        json.Unmarshal(request("GET", "/api/v1/appointment-types", nil).Body.Bytes(), &types)
+        if assert.Len(t, types, 3) {
+            assert.Equal(t, "Cirugía", types[0].Name)
+            if assert.NotNil(t, types[0].Service) {
+                assert.Equal(t, int64(18000), types[0].Service.Price)
+            }
+        }
+    })
+
+    var operation uint
+    t.Run("Defaults From Type", func(t *testing.T) {
+        resp := request("POST", "/api/v1/appointments", map[string]interface{}{
+            "pet_id": pet.ID, "date": at(10, 0), "type_id": surgery, "vet_id": surgeon,
+        })
+        if !assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String()) {
+            return
+        }
+        var appointment models.Appointment
+        json.Unmarshal(resp.Body.Bytes(), &appointment)
+        operation = appointment.ID
+        assert.Equal(t, "Cirugía", appointment.Reason)
+        assert.Equal(t, 120, appointment.Duration)
+        if assert.NotNil(t, appointment.BufferBefore) && assert.NotNil(t, appointment.BufferAfter) {
+            assert.Equal(t, 15, *appointment.BufferBefore)
+            assert.Equal(t, 30, *appointment.BufferAfter)
+        }
+        assert.Equal(t, models.IDs{theatre}, appointment.ResourceIDs)
+
+        // Los valores indicados tienen prioridad sobre los del tipo
+        resp = request("POST", "/api/v1/appointments", map[string]interface{}{
+            "pet_id": pet.ID, "date": at(9, 0), "type_id": vaccination, "duration": 20, "reason": "Vacuna de la rabia",
+        })
+        if assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String()) {
+            json.Unmarshal(resp.Body.Bytes(), &appointment)
+            assert.Equal(t, "Vacuna de la rabia", appointment.Reason)
+            assert.Equal(t, 20, appointment.Duration)
+        }
+
+        // También un margen 0 indicado expresamente
+        resp = request("POST", "/api/v1/appointments", map[string]interface{}{
+            "pet_id": pet.ID, "date": at(19, 0), "type_id": surgery, "vet_id": surgeon, "buffer_before": 0, "buffer_after": 0,
+        })
+        if assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String()) {
+            var unbuffered models.Appointment
+            json.Unmarshal(resp.Body.Bytes(), &unbuffered)
+            if assert.NotNil(t, unbuffered.BufferBefore) && assert.NotNil(t, unbuffered.BufferAfter) {
+                assert.Equal(t, 0, *unbuffered.BufferBefore)
+                assert.Equal(t, 0, *unbuffered.BufferAfter)
+            }
+        }
+
+        resp = request("GET", "/api/v1/appointments/"+strconv.Itoa(int(operation))+"?include=type", nil)
+        json.Unmarshal(resp.Body.Bytes(), &appointment)
+        if assert.NotNil(t, appointment.Type) {
+            assert.Equal(t, "#d9534f", appointment.Type.Color)
+        }
+    })
+
+    t.Run("Required Data", func(t *testing.T) {
+        resp := request("POST", "/api/v1/appointments", map[string]interface{}{"pet_id": pet.ID, "date": at(16, 0), "reason": "Sin duración"})
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+        resp = request("POST", "/api/v1/appointments", map[string]interface{}{"pet_id": pet.ID, "date": at(16, 0), "type_id": 999})
+        assert.Equal(t, http.StatusNotFound, resp.Code)
+    })
+
+    t.Run("Required Specialty", func(t *testing.T) {
+        resp := request("POST", "/api/v1/appointments", map[string]interface{}{
+            "pet_id": pet.ID, "date": at(16, 0), "type_id": surgery, "vet_id": generalist,
+        })
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+        resp = request("POST", "/api/v1/appointments", map[string]interface{}{
+            "pet_id": pet.ID, "date": at(16, 0), "type_id": surgery,
+        })
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+    })
+
+    t.Run("Buffers Block Schedule", func(t *testing.T) {
+        // La cirugía de 10:00 a 12:00 ocupa el quirófano de 9:45 a 12:30
+        resp := request("POST", "/api/v1/appointments", map[string]interface{}{
+            "pet_id": pet.ID, "date": at(12, 15), "duration": 30, "reason": "Limpieza", "resource_ids": []uint{theatre},
+        })
+        assert.Equal(t, http.StatusConflict, resp.Code)
+        resp = request("POST", "/api/v1/appointments", map[string]interface{}{
+            "pet_id": pet.ID, "date": at(9, 30), "duration": 20, "reason": "Revisión", "vet_id": surgeon,
+        })
+        assert.Equal(t, http.StatusConflict, resp.Code)
+
+        resp = request("POST", "/api/v1/appointments", map[string]interface{}{
+            "pet_id": pet.ID, "date": at(12, 30), "duration": 30, "reason": "Limpieza", "resource_ids": []uint{theatre},
+        })
+        assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+
+        // El margen anterior de la siguiente cirugía choca con la cita de
+        // las 12:30
+        resp = request("POST", "/api/v1/appointments", map[string]interface{}{
+            "pet_id": pet.ID, "date": at(13, 10), "type_id": surgery, "vet_id": surgeon,
+        })
+        assert.Equal(t, http.StatusConflict, resp.Code)
+        resp = request("POST", "/api/v1/appointments", map[string]interface{}{
+            "pet_id": pet.ID, "date": at(13, 15), "type_id": surgery, "vet_id": surgeon,
+        })
+        assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+    })
+
+    t.Run("Changing Type Keeps Appointments", func(t *testing.T) {
+        resp := request("PUT", "/api/v1/appointment-types/"+strconv.Itoa(int(surgery)), map[string]interface{}{"name": "Cirugía mayor", "duration": 180})
+        assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+        var appointment models.Appointment
+        assert.NoError(t, db.First(&appointment, operation).Error)
+        assert.Equal(t, 120, appointment.Duration)
+        assert.Equal(t, "Cirugía", appointment.Reason)
+
+        resp = request("DELETE", "/api/v1/appointment-types/"+strconv.Itoa(int(vaccination)), nil)
+        assert.Equal(t, http.StatusOK, resp.Code)
+        resp = request("GET", "/api/v1/appointment-types/"+strconv.Itoa(int(vaccination)), nil)
+        assert.Equal(t, http.StatusNotFound, resp.Code)
+    })
+}
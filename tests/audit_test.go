@@ -0,0 +1,196 @@
+package tests
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "testing"
+
+    "github.com/javice/vet-clinic-api/internal/audit"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestAuditLog(t *testing.T) {
+    router, db, err := setupTestRouter()
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    do := func(method, url string, body interface{}) *httptest.ResponseRecorder {
+        var payload []byte
+        if body != nil {
+            payload, _ = json.Marshal(body)
+        }
+        req, _ := newRequest(method, url, bytes.NewBuffer(payload))
+        req.Header.Set("Content-Type", "application/json")
+        // El actor es el principal; la cabecera no lo cambia
+        req.Header.Set("X-Actor", "recepcion@clinica")
+        if method == "PUT" || method == "DELETE" {
+            req.Header.Set("If-Match", "*")
+        }
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+
+    client := models.Client{Name: "Audit Owner", Email: "audit@example.com", Phone: "600000002"}
+    resp := do("POST", "/api/v1/clients", client)
+    assert.Equal(t, http.StatusCreated, resp.Code)
+    json.Unmarshal(resp.Body.Bytes(), &client)
+
+    pet := models.Pet{Name: "Kira", Species: "Dog", Weight: 12.5, ClientID: client.ID}
+    resp = do("POST", "/api/v1/pets", pet)
+    assert.Equal(t, http.StatusCreated, resp.Code)
+    json.Unmarshal(resp.Body.Bytes(), &pet)
+    petURL := "/api/v1/pets/" + strconv.FormatUint(uint64(pet.ID), 10)
+
+    t.Run("Record Field Diff", func(t *testing.T) {
+        pet.Weight = 14
+        assert.Equal(t, http.StatusOK, do("PUT", petURL, pet).Code)
+
+        resp := do("GET", "/api/v1/audit?entity=pet&id="+strconv.FormatUint(uint64(pet.ID), 10), nil)
+        assert.Equal(t, http.StatusOK, resp.Code)
+
+        var logs []models.AuditLog
+        assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &logs))
+        if assert.Len(t, logs, 2) {
+            assert.Equal(t, models.AuditUpdate, logs[0].Action)
+            assert.Equal(t, models.AuditCreate, logs[1].Action)
+            assert.Equal(t, "admin", logs[0].Actor)
+
+            var changes map[string]models.FieldChange
+            assert.NoError(t, json.Unmarshal(logs[0].Changes, &changes))
+            assert.Len(t, changes, 1)
+            assert.Equal(t, 12.5, changes["weight"].Before)
+            assert.Equal(t, 14.0, changes["weight"].After)
+        }
+    })
+
+    t.Run("Record Delete", func(t *testing.T) {
+        assert.Equal(t, http.StatusOK, do("DELETE", petURL, nil).Code)
+
+        resp := do("GET", "/api/v1/audit?entity=pet&action=delete", nil)
+        var logs []models.AuditLog
+        assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &logs))
+        assert.Len(t, logs, 1)
+    })
+
+    t.Run("Detect Tampering", func(t *testing.T) {
+        var verification repositories.AuditVerification
+        resp := do("GET", "/api/v1/audit/verify", nil)
+        assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &verification))
+        assert.True(t, verification.Valid)
+        assert.Equal(t, 4, verification.Checked)
+
+        var entry models.AuditLog
+        db.Where("entity = ? AND action = ?", "pet", models.AuditUpdate).First(&entry)
+        db.Model(&entry).UpdateColumn("actor", "otra persona")
+
+        resp = do("GET", "/api/v1/audit/verify", nil)
+        assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &verification))
+        assert.False(t, verification.Valid)
+        assert.Equal(t, entry.ID, verification.BrokenAt)
+    })
+
+    t.Run("Requires Permission", func(t *testing.T) {
+        key := func(scopes ...string) string {
+            resp := do("POST", "/api/v1/admin/api-keys", map[string]interface{}{"name": "auditoria", "scopes": scopes})
+            var created map[string]interface{}
+            json.Unmarshal(resp.Body.Bytes(), &created)
+            key, _ := created["key"].(string)
+            return key
+        }
+        get := func(url, apiKey string) int {
+            req, _ := http.NewRequest("GET", url, nil)
+            if apiKey != "" {
+                req.Header.Set("X-API-Key", apiKey)
+            }
+            resp := httptest.NewRecorder()
+            router.ServeHTTP(resp, req)
+            return resp.Code
+        }
+
+        assert.Equal(t, http.StatusUnauthorized, get("/api/v1/audit", ""))
+        assert.Equal(t, http.StatusUnauthorized, get("/api/v1/audit/verify", ""))
+
+        // Las claves no leen la auditoría, que abarca todas las clínicas
+        petsOnly := key("pets:read")
+        assert.Equal(t, http.StatusForbidden, get("/api/v1/audit", petsOnly))
+        assert.Equal(t, http.StatusForbidden, get("/api/v1/audit/verify", petsOnly))
+        assert.Empty(t, key("audit:read"))
+    })
+}
+
+func TestAuditChain(t *testing.T) {
+    db, err := setupTestDB()
+    if err != nil {
+        t.Fatalf("Error inicializando la base de datos: %v", err)
+    }
+    repo := repositories.NewAuditRepository(db)
+
+    record := func(entityID uint) error {
+        return audit.Record(db, &models.AuditLog{Entity: "pet", EntityID: entityID, Action: models.AuditLookup})
+    }
+
+    t.Run("Head Follows Entries", func(t *testing.T) {
+        assert.NoError(t, record(1))
+        assert.NoError(t, record(2))
+
+        var last models.AuditLog
+        var head models.AuditChainHead
+        assert.NoError(t, db.Last(&last).Error)
+        assert.NoError(t, db.First(&head).Error)
+        assert.Equal(t, last.Hash, head.Hash)
+        assert.Equal(t, last.ID, head.LastEntryID)
+    })
+
+    t.Run("Missing Head Is Rebuilt", func(t *testing.T) {
+        // Bases de datos anteriores a la cabeza de la cadena
+        assert.NoError(t, db.Where("1 = 1").Delete(&models.AuditChainHead{}).Error)
+        assert.NoError(t, record(3))
+
+        verification, err := repo.Verify()
+        assert.NoError(t, err)
+        assert.True(t, verification.Valid)
+        assert.Equal(t, 3, verification.Checked)
+    })
+
+    t.Run("Fork Rejected", func(t *testing.T) {
+        // Otro proceso que encadenase sobre el mismo hash
+        var last models.AuditLog
+        assert.NoError(t, db.Last(&last).Error)
+        fork := models.AuditLog{Actor: "otro", Entity: "pet", EntityID: 4, Action: models.AuditLookup, Changes: []byte("{}"), PrevHash: last.PrevHash}
+        fork.Hash = fork.ComputeHash()
+        assert.Error(t, db.Create(&fork).Error)
+
+        // Si la cabeza no es la que se reclamó, la entrada no se escribe
+        assert.NoError(t, db.Exec("CREATE TRIGGER move_head AFTER INSERT ON audit_logs BEGIN UPDATE audit_chain_heads SET hash = 'otro'; END").Error)
+        assert.ErrorIs(t, record(5), audit.ErrChainConflict)
+        assert.NoError(t, db.Exec("DROP TRIGGER move_head").Error)
+
+        verification, err := repo.Verify()
+        assert.NoError(t, err)
+        assert.True(t, verification.Valid)
+        assert.Equal(t, 3, verification.Checked)
+    })
+
+    t.Run("Deleted Tail Detected", func(t *testing.T) {
+        assert.NoError(t, record(6))
+        assert.NoError(t, record(7))
+
+        // Borrar las entradas más recientes deja una cadena coherente, pero
+        // no llega a la cabeza
+        var newest []models.AuditLog
+        assert.NoError(t, db.Order("id DESC").Limit(2).Find(&newest).Error)
+        assert.NoError(t, db.Delete(&newest).Error)
+
+        verification, err := repo.Verify()
+        assert.NoError(t, err)
+        assert.False(t, verification.Valid)
+        assert.Equal(t, newest[0].ID, verification.BrokenAt)
+    })
+}
@@ -33,7 +33,7 @@ func TestClientEndpoints(t *testing.T) {
         }
 
         jsonData, _ := json.Marshal(clientData)
-        req, _ := http.NewRequest("POST", "/api/v1/clients", bytes.NewBuffer(jsonData))
+        req, _ := newRequest("POST", "/api/v1/clients", bytes.NewBuffer(jsonData))
         req.Header.Set("Content-Type", "application/json")
 
         resp := httptest.NewRecorder()
@@ -51,7 +51,7 @@ func TestClientEndpoints(t *testing.T) {
 
     // Test GET /clients
     t.Run("Get All Clients", func(t *testing.T) {
-        req, _ := http.NewRequest("GET", "/api/v1/clients", nil)
+        req, _ := newRequest("GET", "/api/v1/clients", nil)
         resp := httptest.NewRecorder()
         router.ServeHTTP(resp, req)
 
@@ -76,7 +76,7 @@ func TestClientEndpoints(t *testing.T) {
 		}
         db.First(&client)
 
-        req, _ := http.NewRequest("GET", "/api/v1/clients/"+strconv.FormatUint(uint64(client.ID), 10), nil)
+        req, _ := newRequest("GET", "/api/v1/clients/"+strconv.FormatUint(uint64(client.ID), 10), nil)
         resp := httptest.NewRecorder()
         router.ServeHTTP(resp, req)
 
@@ -105,8 +105,9 @@ func TestClientEndpoints(t *testing.T) {
             }
 
             jsonData, _ := json.Marshal(updateData)
-            req, _ := http.NewRequest("PUT", "/api/v1/clients/"+strconv.FormatUint(uint64(client.ID), 10), bytes.NewBuffer(jsonData))
+            req, _ := newRequest("PUT", "/api/v1/clients/"+strconv.FormatUint(uint64(client.ID), 10), bytes.NewBuffer(jsonData))
             req.Header.Set("Content-Type", "application/json")
+            req.Header.Set("If-Match", etagFor(client.Version))
 
             resp := httptest.NewRecorder()
             router.ServeHTTP(resp, req)
@@ -132,7 +133,8 @@ func TestClientEndpoints(t *testing.T) {
         }
         db.Create(&client)
 
-        req, _ := http.NewRequest("DELETE", "/api/v1/clients/"+strconv.FormatUint(uint64(client.ID), 10), nil)
+        req, _ := newRequest("DELETE", "/api/v1/clients/"+strconv.FormatUint(uint64(client.ID), 10), nil)
+        req.Header.Set("If-Match", etagFor(client.Version))
         resp := httptest.NewRecorder()
         router.ServeHTTP(resp, req)
 
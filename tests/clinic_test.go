@@ -0,0 +1,344 @@
+package tests
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/routes"
+    "github.com/javice/vet-clinic-api/internal/tenant"
+    "github.com/stretchr/testify/assert"
+    "gorm.io/gorm"
+)
+
+func TestClinics(t *testing.T) {
+    router, db, err := setupTestRouter()
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    request := func(method, url string, clinic uint, body interface{}, headers ...string) *httptest.ResponseRecorder {
+        var payload []byte
+        if body != nil {
+            payload, _ = json.Marshal(body)
+        }
+        req, _ := newRequest(method, url, bytes.NewBuffer(payload))
+        req.Header.Set("Content-Type", "application/json")
+        req.Header.Set("X-Admin-Token", testAdminToken)
+        if clinic != 0 {
+            req.Header.Set("X-Clinic-ID", strconv.Itoa(int(clinic)))
+        }
+        for i := 0; i+1 < len(headers); i += 2 {
+            req.Header.Set(headers[i], headers[i+1])
+        }
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+    decode := func(resp *httptest.ResponseRecorder, v interface{}) {
+        json.Unmarshal(resp.Body.Bytes(), v)
+    }
+    names := func(resp *httptest.ResponseRecorder) []string {
+        var items []map[string]interface{}
+        decode(resp, &items)
+        result := []string{}
+        for _, item := range items {
+            result = append(result, item["name"].(string))
+        }
+        return result
+    }
+
+    // Datos anteriores a las clínicas
+    legacy := models.Client{Name: "Antiguo", Email: "antiguo@example.com", Phone: "600000000"}
+    assert.NoError(t, db.Create(&legacy).Error)
+
+    var main, north uint
+    t.Run("Backfill", func(t *testing.T) {
+        assert.NoError(t, tenant.Backfill(db))
+        assert.NoError(t, tenant.Backfill(db))
+
+        var clinics []models.Clinic
+        assert.NoError(t, db.Find(&clinics).Error)
+        if assert.Len(t, clinics, 1) {
+            assert.Equal(t, tenant.DefaultClinicName, clinics[0].Name)
+            main = clinics[0].ID
+        }
+
+        var links []models.ClientClinic
+        assert.NoError(t, db.Find(&links).Error)
+        assert.Equal(t, []uint{legacy.ID}, []uint{links[0].ClientID})
+        assert.Len(t, links, 1)
+    })
+
+    t.Run("Create Clinic", func(t *testing.T) {
+        resp := request("POST", "/api/v1/admin/clinics", 0, map[string]interface{}{"name": "Sede Norte"})
+        if !assert.Equal(t, http.StatusCreated, resp.Code) {
+            return
+        }
+        var clinic models.Clinic
+        decode(resp, &clinic)
+        north = clinic.ID
+
+        assert.Equal(t, http.StatusConflict, request("POST", "/api/v1/admin/clinics", 0, map[string]interface{}{"name": "sede norte"}).Code)
+        assert.Equal(t, []string{tenant.DefaultClinicName, "Sede Norte"}, names(request("GET", "/api/v1/clinics", 0, nil)))
+    })
+
+    var northPet, mainPet models.Pet
+    var northClientID uint
+    t.Run("Records Belong To Their Clinic", func(t *testing.T) {
+        resp := request("POST", "/api/v1/clients", north, map[string]interface{}{
+            "name": "Norteña", "email": "nortena@example.com", "phone": "611222333",
+        })
+        if !assert.Equal(t, http.StatusCreated, resp.Code) {
+            return
+        }
+        var client models.Client
+        decode(resp, &client)
+        northClientID = client.ID
+
+        resp = request("POST", "/api/v1/pets", north, map[string]interface{}{"name": "Nube", "species": "Cat", "client_id": northClientID})
+        assert.Equal(t, http.StatusCreated, resp.Code)
+        decode(resp, &northPet)
+
+        resp = request("POST", "/api/v1/pets", main, map[string]interface{}{"name": "Rex", "species": "Dog", "client_id": legacy.ID})
+        assert.Equal(t, http.StatusCreated, resp.Code)
+        decode(resp, &mainPet)
+
+        assert.Equal(t, []string{"Norteña"}, names(request("GET", "/api/v1/clients", north, nil)))
+        assert.Equal(t, []string{"Antiguo"}, names(request("GET", "/api/v1/clients", main, nil)))
+        assert.Equal(t, []string{"Nube"}, names(request("GET", "/api/v1/pets", north, nil)))
+
+        // Sin clínica se ve todo el grupo
+        assert.ElementsMatch(t, []string{"Antiguo", "Norteña"}, names(request("GET", "/api/v1/clients", 0, nil)))
+    })
+
+    t.Run("Cross Clinic Access Denied", func(t *testing.T) {
+        id := strconv.Itoa(int(northClientID))
+        assert.Equal(t, http.StatusNotFound, request("GET", "/api/v1/clients/"+id, main, nil).Code)
+        assert.Equal(t, http.StatusNotFound, request("GET", "/api/v1/pets/"+strconv.Itoa(int(northPet.ID)), main, nil).Code)
+        assert.Equal(t, http.StatusNotFound, request("GET", "/api/v1/clients/"+id+"/clinics", main, nil).Code)
+
+        // No se pueden crear mascotas ni citas con registros de otra clínica
+        resp := request("POST", "/api/v1/pets", main, map[string]interface{}{"name": "Intrusa", "species": "Cat", "client_id": northClientID})
+        assert.Equal(t, http.StatusNotFound, resp.Code)
+        resp = request("POST", "/api/v1/appointments", main, map[string]interface{}{
+            "pet_id": northPet.ID, "date": time.Now().Add(48 * time.Hour), "reason": "Revisión", "duration": 30,
+        })
+        assert.Equal(t, http.StatusNotFound, resp.Code)
+
+        // Ni modificar o borrar lo que no se ve
+        resp = request("DELETE", "/api/v1/clients/"+id, main, nil, "If-Match", etagFor(1))
+        assert.Equal(t, http.StatusNotFound, resp.Code)
+
+        var count int64
+        db.Model(&models.Pet{}).Where("name = ?", "Intrusa").Count(&count)
+        assert.Zero(t, count)
+    })
+
+    t.Run("Appointments Stay In Their Clinic", func(t *testing.T) {
+        resp := request("POST", "/api/v1/appointments", main, map[string]interface{}{
+            "pet_id": mainPet.ID, "date": time.Now().Add(48 * time.Hour), "reason": "Vacuna", "duration": 30,
+            "clinic_id": north,
+        })
+        if !assert.Equal(t, http.StatusCreated, resp.Code) {
+            return
+        }
+        var appointment models.Appointment
+        decode(resp, &appointment)
+        assert.Equal(t, main, appointment.ClinicID)
+
+        // Al modificarla no cambia de clínica
+        resp = request("PUT", "/api/v1/appointments/"+strconv.Itoa(int(appointment.ID)), main, map[string]interface{}{
+            "pet_id": mainPet.ID, "date": appointment.Date, "reason": "Vacuna anual", "duration": 30,
+        }, "If-Match", etagFor(appointment.Version))
+        assert.Equal(t, http.StatusOK, resp.Code)
+        var stored models.Appointment
+        db.First(&stored, appointment.ID)
+        assert.Equal(t, main, stored.ClinicID)
+
+        var list []models.Appointment
+        decode(request("GET", "/api/v1/appointments", north, nil), &list)
+        assert.Empty(t, list)
+        decode(request("GET", "/api/v1/appointments", main, nil), &list)
+        assert.Len(t, list, 1)
+    })
+
+    t.Run("Share Client", func(t *testing.T) {
+        url := "/api/v1/clients/" + strconv.Itoa(int(legacy.ID)) + "/clinics"
+        resp := request("POST", url, main, map[string]interface{}{"clinic_id": north})
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.Equal(t, []string{tenant.DefaultClinicName, "Sede Norte"}, names(resp))
+
+        // El cliente y sus mascotas se ven en la otra sede, pero no sus citas
+        assert.ElementsMatch(t, []string{"Antiguo", "Norteña"}, names(request("GET", "/api/v1/clients", north, nil)))
+        assert.ElementsMatch(t, []string{"Rex", "Nube"}, names(request("GET", "/api/v1/pets", north, nil)))
+        var list []models.Appointment
+        decode(request("GET", "/api/v1/pets/"+strconv.Itoa(int(mainPet.ID))+"/appointments", north, nil), &list)
+        assert.Empty(t, list)
+
+        assert.Equal(t, http.StatusNotFound, request("POST", url, main, map[string]interface{}{"clinic_id": 999}).Code)
+
+        // Dejar de compartir, pero nunca la última clínica
+        resp = request("DELETE", url+"/"+strconv.Itoa(int(main)), north, nil)
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.Equal(t, []string{"Sede Norte"}, names(resp))
+        assert.Equal(t, http.StatusNotFound, request("GET", "/api/v1/clients/"+strconv.Itoa(int(legacy.ID)), main, nil).Code)
+        assert.Equal(t, http.StatusConflict, request("DELETE", url+"/"+strconv.Itoa(int(north)), north, nil).Code)
+    })
+
+    t.Run("Archive Counts Every Clinic", func(t *testing.T) {
+        // Rex tiene una cita futura en la clínica principal, que ya no ve
+        // al cliente: la sede norte no puede archivarlo sin anularla
+        var client models.Client
+        db.First(&client, legacy.ID)
+        resp := request("DELETE", "/api/v1/clients/"+strconv.Itoa(int(legacy.ID))+"?cascade=true", north, nil, "If-Match", etagFor(client.Version))
+        assert.Equal(t, http.StatusConflict, resp.Code)
+
+        var pet models.Pet
+        db.First(&pet, mainPet.ID)
+        resp = request("DELETE", "/api/v1/pets/"+strconv.Itoa(int(mainPet.ID)), north, nil, "If-Match", etagFor(pet.Version))
+        assert.Equal(t, http.StatusConflict, resp.Code)
+
+        var active int64
+        db.Model(&models.Appointment{}).Where("pet_id = ?", mainPet.ID).Count(&active)
+        assert.EqualValues(t, 1, active)
+    })
+
+    t.Run("Search Is Scoped", func(t *testing.T) {
+        var results []map[string]interface{}
+        decode(request("GET", "/api/v1/search?q=nube", main, nil), &results)
+        assert.Empty(t, results)
+        decode(request("GET", "/api/v1/search?q=nube", north, nil), &results)
+        assert.Len(t, results, 1)
+    })
+
+    t.Run("Export Is Scoped", func(t *testing.T) {
+        resp := request("GET", "/api/v1/export/pets?format=ndjson", north, nil)
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.Equal(t, 2, strings.Count(resp.Body.String(), "\n"))
+        assert.NotContains(t, resp.Body.String(), "Intrusa")
+    })
+
+    t.Run("Invalid Clinic", func(t *testing.T) {
+        req, _ := newRequest("GET", "/api/v1/clients", nil)
+        req.Header.Set("X-Clinic-ID", "norte")
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+        assert.Equal(t, http.StatusBadRequest, request("GET", "/api/v1/clients", 999, nil).Code)
+    })
+
+    t.Run("API Key Bound To Clinic", func(t *testing.T) {
+        resp := request("POST", "/api/v1/admin/api-keys", 0, map[string]interface{}{
+            "name": "widget-norte", "scopes": []string{"clients:read"}, "clinic_id": north,
+        })
+        if !assert.Equal(t, http.StatusCreated, resp.Code) {
+            return
+        }
+        var created map[string]interface{}
+        decode(resp, &created)
+        key := created["key"].(string)
+
+        assert.ElementsMatch(t, []string{"Antiguo", "Norteña"}, names(request("GET", "/api/v1/clients", 0, nil, "X-API-Key", key)))
+        assert.Equal(t, http.StatusForbidden, request("GET", "/api/v1/clients", main, nil, "X-API-Key", key).Code)
+
+        resp = request("POST", "/api/v1/admin/api-keys", 0, map[string]interface{}{
+            "name": "x", "scopes": []string{"clients:read"}, "clinic_id": 999,
+        })
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+    })
+
+    t.Run("API Key Without Clinic", func(t *testing.T) {
+        resp := request("POST", "/api/v1/admin/api-keys", 0, map[string]interface{}{
+            "name": "grupo", "scopes": []string{"clients:read", "species:read"},
+        })
+        if !assert.Equal(t, http.StatusCreated, resp.Code) {
+            return
+        }
+        var created map[string]interface{}
+        decode(resp, &created)
+        key := created["key"].(string)
+
+        // Sin clínica no ve los datos de ninguna, ni puede elegirla
+        get := func(clinic uint) int {
+            req, _ := http.NewRequest("GET", "/api/v1/clients", nil)
+            req.Header.Set("X-API-Key", key)
+            if clinic != 0 {
+                req.Header.Set("X-Clinic-ID", strconv.Itoa(int(clinic)))
+            }
+            resp := httptest.NewRecorder()
+            router.ServeHTTP(resp, req)
+            return resp.Code
+        }
+        assert.Equal(t, http.StatusForbidden, get(0))
+        assert.Equal(t, http.StatusForbidden, get(main))
+
+        // Los catálogos comunes no son de ninguna clínica
+        req, _ := http.NewRequest("GET", "/api/v1/species", nil)
+        req.Header.Set("X-API-Key", key)
+        resp = httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        assert.Equal(t, http.StatusOK, resp.Code)
+    })
+
+    t.Run("Raw SQL Rejected", func(t *testing.T) {
+        scoped := db.WithContext(tenant.WithClinic(context.Background(), north))
+
+        var count int64
+        assert.ErrorIs(t, scoped.Raw("SELECT count(*) FROM clients").Scan(&count).Error, tenant.ErrRawSQL)
+        assert.ErrorIs(t, scoped.Exec("UPDATE clients SET name = name").Error, tenant.ErrRawSQL)
+
+        // Sin clínica (tareas internas) se permite
+        assert.NoError(t, db.Raw("SELECT count(*) FROM clients").Scan(&count).Error)
+    })
+
+    t.Run("All Clinics Is Audited", func(t *testing.T) {
+        scoped := db.WithContext(tenant.WithClinic(context.Background(), north))
+
+        var count int64
+        assert.NoError(t, tenant.AllClinics(scoped, tenant.ReasonUniqueness).Model(&models.Client{}).Count(&count).Error)
+        var total int64
+        db.Model(&models.Client{}).Count(&total)
+        assert.Equal(t, total, count)
+
+        var entry models.AuditLog
+        assert.NoError(t, db.Where("action = ?", models.AuditAllClinics).Last(&entry).Error)
+        assert.Equal(t, "clinic", entry.Entity)
+        assert.Equal(t, north, entry.EntityID)
+        assert.Contains(t, string(entry.Changes), tenant.ReasonUniqueness)
+
+        // Sin clínica no hay nada de lo que salir
+        var entries int64
+        db.Model(&models.AuditLog{}).Where("action = ?", models.AuditAllClinics).Count(&entries)
+        assert.NoError(t, tenant.AllClinics(db, tenant.ReasonUniqueness).Model(&models.Client{}).Count(&count).Error)
+        var after int64
+        db.Model(&models.AuditLog{}).Where("action = ?", models.AuditAllClinics).Count(&after)
+        assert.Equal(t, entries, after)
+    })
+
+    t.Run("Require Clinic", func(t *testing.T) {
+        router, _, err := setupTestRouterWith(func(db *gorm.DB, opts *routes.Options) error {
+            opts.AllowAllClinics = false
+            return nil
+        })
+        if !assert.NoError(t, err) {
+            return
+        }
+
+        // También los administradores deben indicar la clínica
+        for url, code := range map[string]int{"/api/v1/clients": http.StatusBadRequest, "/api/v1/clinics": http.StatusOK} {
+            req, _ := newRequest("GET", url, nil)
+            resp := httptest.NewRecorder()
+            router.ServeHTTP(resp, req)
+            assert.Equal(t, code, resp.Code, url)
+        }
+    })
+}
@@ -0,0 +1,181 @@
+package tests
+
+import (
+    "bytes"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/config"
+    "github.com/javice/vet-clinic-api/internal/middleware"
+    "github.com/javice/vet-clinic-api/internal/routes"
+    "github.com/stretchr/testify/assert"
+    "gorm.io/gorm"
+)
+
+func TestConfig(t *testing.T) {
+    dir := t.TempDir()
+    write := func(name, content string) string {
+        path := filepath.Join(dir, name)
+        if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+            t.Fatal(err)
+        }
+        return path
+    }
+    env := func(values map[string]string) config.LookupFunc {
+        return func(key string) (string, bool) {
+            value, ok := values[key]
+            return value, ok
+        }
+    }
+    noEnvFile := write("empty.env", "")
+
+    t.Run("Defaults", func(t *testing.T) {
+        cfg, err := config.Load([]string{"-env-file", noEnvFile}, env(nil))
+        if !assert.NoError(t, err) {
+            return
+        }
+        assert.Equal(t, 8080, cfg.Server.Port)
+        assert.Equal(t, 30*time.Second, cfg.Server.ReadTimeout)
+        assert.Equal(t, "vet_clinic.db", cfg.Database.Path)
+        assert.Equal(t, []string{"*"}, cfg.CORS.AllowedOrigins)
+        assert.Equal(t, []time.Duration{24 * time.Hour, 2 * time.Hour}, cfg.Reminders.LeadTimes)
+        assert.Equal(t, 1825, cfg.RetentionDays)
+    })
+
+    yamlFile := write("config.yaml", `
+server:
+  port: 9000
+  read_timeout: 10s
+database:
+  path: /var/lib/vet/clinic.db
+cors:
+  allowed_origins:
+    - https://clinica.example.com
+    - https://admin.example.com
+auth:
+  admin_token: desde-fichero
+log:
+  level: warn
+`)
+    dotenv := write(".env", `
+# Comentario
+PORT=9100
+export LOG_PRETTY=true
+SMTP_FROM="Clínica <citas@example.com>" 
+ADMIN_TOKEN='desde-dotenv' # comentario
+`)
+
+    t.Run("Layer Precedence", func(t *testing.T) {
+        cfg, err := config.Load(
+            []string{"-config", yamlFile, "-env-file", dotenv, "-log-level", "error"},
+            env(map[string]string{"ADMIN_TOKEN": "desde-entorno", "LOG_LEVEL": "debug"}),
+        )
+        if !assert.NoError(t, err) {
+            return
+        }
+        // Fichero
+        assert.Equal(t, 10*time.Second, cfg.Server.ReadTimeout)
+        assert.Equal(t, "/var/lib/vet/clinic.db", cfg.Database.Path)
+        assert.Equal(t, []string{"https://clinica.example.com", "https://admin.example.com"}, cfg.CORS.AllowedOrigins)
+        // .env sobre el fichero
+        assert.Equal(t, 9100, cfg.Server.Port)
+        assert.True(t, cfg.Log.Pretty)
+        assert.Equal(t, "Clínica <citas@example.com>", cfg.Reminders.SMTP.From)
+        // Entorno sobre .env
+        assert.Equal(t, "desde-entorno", cfg.Auth.AdminToken)
+        // Opciones sobre el entorno
+        assert.Equal(t, "error", cfg.Log.Level)
+    })
+
+    t.Run("TOML From Environment", func(t *testing.T) {
+        tomlFile := write("config.toml", `
+retention_days = 30
+
+[server]
+shutdown_timeout = "1m"
+
+[reminders]
+lead_times = ["48h", "1h"]
+`)
+        cfg, err := config.Load([]string{"-env-file", noEnvFile}, env(map[string]string{config.ConfigFileEnv: tomlFile}))
+        if !assert.NoError(t, err) {
+            return
+        }
+        assert.Equal(t, 30, cfg.RetentionDays)
+        assert.Equal(t, time.Minute, cfg.Server.ShutdownTimeout)
+        assert.Equal(t, []time.Duration{48 * time.Hour, time.Hour}, cfg.Reminders.LeadTimes)
+    })
+
+    t.Run("Clear Errors", func(t *testing.T) {
+        badFile := write("bad.yaml", "server:\n  prot: 80\n")
+        _, err := config.Load([]string{"-config", badFile, "-env-file", noEnvFile}, env(nil))
+        assert.ErrorContains(t, err, "clave desconocida server.prot")
+
+        _, err = config.Load([]string{"-env-file", noEnvFile}, env(map[string]string{"SERVER_READ_TIMEOUT": "diez"}))
+        assert.ErrorContains(t, err, `variable SERVER_READ_TIMEOUT: duración inválida "diez"`)
+
+        _, err = config.Load([]string{"-env-file", noEnvFile, "-port", "70000"}, env(map[string]string{
+            "REMINDER_EMAIL_PROVIDER": "smtp",
+            "CORS_ALLOWED_ORIGINS":    "clinica.example.com",
+        }))
+        assert.ErrorContains(t, err, "server.port: debe estar entre 1 y 65535")
+        assert.ErrorContains(t, err, "reminders.smtp.host: es obligatorio")
+        assert.ErrorContains(t, err, `cors.allowed_origins: origen inválido "clinica.example.com"`)
+
+        _, err = config.Load([]string{"-env-file", noEnvFile}, env(map[string]string{
+            "CORS_ALLOW_CREDENTIALS": "true",
+            "RATE_LIMIT_REQUESTS":    "0",
+        }))
+        assert.ErrorContains(t, err, "cors.allowed_origins: * no puede combinarse con allow_credentials")
+        assert.ErrorContains(t, err, "rate_limit.requests: debe ser mayor que cero")
+
+        // Un .env indicado explícitamente tiene que existir
+        _, err = config.Load([]string{"-env-file", filepath.Join(dir, "no-existe.env")}, env(nil))
+        assert.ErrorContains(t, err, "no-existe.env")
+
+        badEnv := write("bad.env", "PORT=8080\nesto no es una variable\n")
+        _, err = config.Load([]string{"-env-file", badEnv}, env(nil))
+        assert.ErrorContains(t, err, "línea 2: se esperaba CLAVE=valor")
+    })
+
+    t.Run("Print Redacts Secrets", func(t *testing.T) {
+        cfg, err := config.Load([]string{"-config", yamlFile, "-env-file", noEnvFile}, env(map[string]string{"SMS_GATEWAY_TOKEN": "token-sms"}))
+        if !assert.NoError(t, err) {
+            return
+        }
+
+        var out bytes.Buffer
+        assert.NoError(t, cfg.Print(&out))
+        assert.Contains(t, out.String(), "server:\n  port: 9000\n")
+        assert.Contains(t, out.String(), "admin_token: '[REDACTED]'")
+        assert.Contains(t, out.String(), "password: \"\"")
+        assert.NotContains(t, out.String(), "desde-fichero")
+        assert.NotContains(t, out.String(), "token-sms")
+    })
+
+    t.Run("CORS Allowed Origins", func(t *testing.T) {
+        router, _, err := setupTestRouterWith(func(db *gorm.DB, opts *routes.Options) error {
+            opts.CORS = map[string]middleware.CORSPolicy{"": {AllowedOrigins: []string{"https://clinica.example.com"}}}
+            return nil
+        })
+        if !assert.NoError(t, err) {
+            return
+        }
+
+        request := func(origin string) *httptest.ResponseRecorder {
+            req, _ := newRequest("GET", "/api/v1/clients", nil)
+            req.Header.Set("Origin", origin)
+            resp := httptest.NewRecorder()
+            router.ServeHTTP(resp, req)
+            return resp
+        }
+
+        resp := request("https://clinica.example.com")
+        assert.Equal(t, "https://clinica.example.com", resp.Header().Get("Access-Control-Allow-Origin"))
+        assert.Equal(t, "Origin", resp.Header().Get("Vary"))
+        assert.Empty(t, request("https://otra.example.com").Header().Get("Access-Control-Allow-Origin"))
+    })
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/middleware"
+    "github.com/javice/vet-clinic-api/internal/routes"
+    "github.com/stretchr/testify/assert"
+    "gorm.io/gorm"
+)
+
+func TestCORS(t *testing.T) {
+    router, _, err := setupTestRouterWith(func(db *gorm.DB, opts *routes.Options) error {
+        opts.CORS = map[string]middleware.CORSPolicy{
+            "": {
+                AllowedOrigins:   []string{"https://clinica.example.com", "https://*.portal.example.com"},
+                AllowCredentials: true,
+                MaxAge:           10 * time.Minute,
+            },
+            routes.AdminPrefix: {AllowedOrigins: []string{"https://admin.example.com"}},
+        }
+        return nil
+    })
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    request := func(method, url, origin string) *httptest.ResponseRecorder {
+        req, _ := newRequest(method, url, nil)
+        req.Header.Set("Origin", origin)
+        if method == "OPTIONS" {
+            req.Header.Set("Access-Control-Request-Method", "POST")
+        }
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+
+    t.Run("Exact Origin With Credentials", func(t *testing.T) {
+        resp := request("GET", "/api/v1/clients", "https://clinica.example.com")
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.Equal(t, "https://clinica.example.com", resp.Header().Get("Access-Control-Allow-Origin"))
+        assert.Equal(t, "true", resp.Header().Get("Access-Control-Allow-Credentials"))
+        assert.Contains(t, resp.Header().Values("Vary"), "Origin")
+
+        exposed := resp.Header().Get("Access-Control-Expose-Headers")
+        for _, header := range []string{"ETag", "Link", "X-Request-ID", "RateLimit-Remaining", "Retry-After"} {
+            assert.Contains(t, exposed, header)
+        }
+    })
+
+    t.Run("Wildcard Subdomain", func(t *testing.T) {
+        resp := request("GET", "/api/v1/clients", "https://citas.portal.example.com")
+        assert.Equal(t, "https://citas.portal.example.com", resp.Header().Get("Access-Control-Allow-Origin"))
+
+        // El comodín no incluye el dominio base ni otros esquemas
+        assert.Empty(t, request("GET", "/api/v1/clients", "https://portal.example.com").Header().Get("Access-Control-Allow-Origin"))
+        assert.Empty(t, request("GET", "/api/v1/clients", "http://citas.portal.example.com").Header().Get("Access-Control-Allow-Origin"))
+        assert.Empty(t, request("GET", "/api/v1/clients", "https://malportal.example.com").Header().Get("Access-Control-Allow-Origin"))
+    })
+
+    t.Run("Disallowed Origin", func(t *testing.T) {
+        resp := request("GET", "/api/v1/clients", "https://otra.example.com")
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.Empty(t, resp.Header().Get("Access-Control-Allow-Origin"))
+        assert.Empty(t, resp.Header().Get("Access-Control-Allow-Credentials"))
+    })
+
+    t.Run("Preflight", func(t *testing.T) {
+        resp := request("OPTIONS", "/api/v1/clients", "https://clinica.example.com")
+        assert.Equal(t, http.StatusNoContent, resp.Code)
+        assert.Equal(t, "https://clinica.example.com", resp.Header().Get("Access-Control-Allow-Origin"))
+        assert.Contains(t, resp.Header().Get("Access-Control-Allow-Methods"), "PATCH")
+        assert.Contains(t, resp.Header().Get("Access-Control-Allow-Headers"), "If-Match")
+        assert.Equal(t, "600", resp.Header().Get("Access-Control-Max-Age"))
+
+        resp = request("OPTIONS", "/api/v1/clients", "https://otra.example.com")
+        assert.Equal(t, http.StatusNoContent, resp.Code)
+        assert.Empty(t, resp.Header().Get("Access-Control-Allow-Origin"))
+        assert.Empty(t, resp.Header().Get("Access-Control-Allow-Methods"))
+    })
+
+    t.Run("Route Group Policy", func(t *testing.T) {
+        resp := request("OPTIONS", "/api/v1/admin/purge", "https://admin.example.com")
+        assert.Equal(t, "https://admin.example.com", resp.Header().Get("Access-Control-Allow-Origin"))
+        assert.Empty(t, resp.Header().Get("Access-Control-Allow-Credentials"))
+        assert.Empty(t, resp.Header().Get("Access-Control-Max-Age"))
+
+        resp = request("OPTIONS", "/api/v1/admin/purge", "https://clinica.example.com")
+        assert.Empty(t, resp.Header().Get("Access-Control-Allow-Origin"))
+    })
+
+    t.Run("Default Policy", func(t *testing.T) {
+        router, _, err := setupTestRouter()
+        if !assert.NoError(t, err) {
+            return
+        }
+
+        req, _ := newRequest("GET", "/api/v1/clients", nil)
+        req.Header.Set("Origin", "https://cualquiera.example.com")
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        assert.Equal(t, "*", resp.Header().Get("Access-Control-Allow-Origin"))
+        assert.Empty(t, resp.Header().Get("Access-Control-Allow-Credentials"))
+    })
+}
@@ -0,0 +1,117 @@
+package tests
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "testing"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/dedupe"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestClientDuplicates(t *testing.T) {
+    router, db, err := setupTestRouter()
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    do := func(method, url string, body interface{}) *httptest.ResponseRecorder {
+        var payload []byte
+        if body != nil {
+            payload, _ = json.Marshal(body)
+        }
+        req, _ := newRequest(method, url, bytes.NewBuffer(payload))
+        req.Header.Set("Content-Type", "application/json")
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+
+    original := models.Client{Name: "José Luis Martínez", Email: "jlmartinez@example.com", Phone: "612 345 678", Address: "C/ Mayor 12, 3º dcha"}
+    db.Create(&original)
+    duplicate := models.Client{Name: "Jose Luis Martinez Ruiz", Email: "joseluis.m@example.com", Phone: "+34 612-345-678"}
+    db.Create(&duplicate)
+    samePlace := models.Client{Name: "Marta Gómez", Email: "marta@example.com", Phone: "699 111 222", Address: "Avda. Libertad 4"}
+    db.Create(&samePlace)
+    sameName := models.Client{Name: "Marta Gomez", Email: "mgomez@example.com", Phone: "655 000 000", Address: "Avenida de la Libertad, 4"}
+    db.Create(&sameName)
+    db.Create(&models.Client{Name: "Luis Pérez", Email: "luis@example.com", Phone: "611 000 111", Address: "Plaza España 1"})
+
+    pet := models.Pet{Name: "Thor", Species: "Dog", ClientID: duplicate.ID}
+    db.Create(&pet)
+    appointment := models.Appointment{PetID: pet.ID, Date: time.Now().Add(72 * time.Hour), Reason: "Revisión", Duration: 30}
+    db.Create(&appointment)
+
+    t.Run("Detection", func(t *testing.T) {
+        assert.True(t, dedupe.NormalizePhone("+34 612-345-678") == dedupe.NormalizePhone("612345678"))
+
+        resp := do("GET", "/api/v1/clients/duplicates", nil)
+        assert.Equal(t, http.StatusOK, resp.Code)
+
+        var candidates []dedupe.Candidate
+        assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &candidates))
+        if assert.Len(t, candidates, 2) {
+            // Mismo teléfono con otro formato y nombre casi igual
+            assert.Equal(t, original.ID, candidates[0].Clients[0].ID)
+            assert.Equal(t, duplicate.ID, candidates[0].Clients[1].ID)
+            assert.True(t, candidates[0].PhoneMatch)
+            assert.Greater(t, candidates[0].NameSimilarity, 0.8)
+
+            // Mismo nombre sin tilde y misma dirección abreviada
+            assert.Equal(t, samePlace.ID, candidates[1].Clients[0].ID)
+            assert.False(t, candidates[1].PhoneMatch)
+            assert.Equal(t, 1.0, candidates[1].NameSimilarity)
+            assert.Equal(t, 1.0, candidates[1].AddressSimilarity)
+        }
+
+        resp = do("GET", "/api/v1/clients/duplicates?min_score=0.7", nil)
+        assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &candidates))
+        assert.Len(t, candidates, 1)
+
+        assert.Equal(t, http.StatusBadRequest, do("GET", "/api/v1/clients/duplicates?min_score=2", nil).Code)
+    })
+
+    survivorURL := "/api/v1/clients/" + strconv.FormatUint(uint64(original.ID), 10)
+
+    t.Run("Merge", func(t *testing.T) {
+        resp := do("POST", survivorURL+"/merge", map[string]interface{}{"duplicate_id": duplicate.ID})
+        assert.Equal(t, http.StatusOK, resp.Code)
+
+        var survivor models.Client
+        assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &survivor))
+        assert.Equal(t, uint(2), survivor.Version)
+        if assert.Len(t, survivor.Pets, 1) {
+            assert.Equal(t, pet.ID, survivor.Pets[0].ID)
+        }
+
+        // Las citas siguen a la mascota
+        resp = do("GET", "/api/v1/appointments/"+strconv.FormatUint(uint64(appointment.ID), 10)+"?include=pet.client", nil)
+        var moved models.Appointment
+        assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &moved))
+        if assert.NotNil(t, moved.Pet) && assert.NotNil(t, moved.Pet.Client) {
+            assert.Equal(t, original.ID, moved.Pet.Client.ID)
+        }
+
+        // El duplicado queda archivado
+        assert.Equal(t, http.StatusNotFound, do("GET", "/api/v1/clients/"+strconv.FormatUint(uint64(duplicate.ID), 10), nil).Code)
+
+        var entry models.AuditLog
+        assert.NoError(t, db.Where("entity = ? AND entity_id = ? AND action = ?", "client", original.ID, models.AuditMerge).First(&entry).Error)
+        var changes map[string]models.FieldChange
+        assert.NoError(t, json.Unmarshal(entry.Changes, &changes))
+        assert.Equal(t, float64(duplicate.ID), changes["merged_client_id"].After)
+    })
+
+    t.Run("Invalid Merge", func(t *testing.T) {
+        assert.Equal(t, http.StatusBadRequest, do("POST", survivorURL+"/merge", map[string]interface{}{"duplicate_id": original.ID}).Code)
+        assert.Equal(t, http.StatusBadRequest, do("POST", survivorURL+"/merge", map[string]interface{}{}).Code)
+        // El duplicado ya está archivado
+        assert.Equal(t, http.StatusNotFound, do("POST", survivorURL+"/merge", map[string]interface{}{"duplicate_id": duplicate.ID}).Code)
+    })
+}
+
@@ -0,0 +1,113 @@
+package tests
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "testing"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestOptimisticConcurrency(t *testing.T) {
+    router, db, err := setupTestRouter()
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    do := func(method, url string, body interface{}, headers map[string]string) *httptest.ResponseRecorder {
+        var payload []byte
+        if body != nil {
+            payload, _ = json.Marshal(body)
+        }
+        req, _ := newRequest(method, url, bytes.NewBuffer(payload))
+        req.Header.Set("Content-Type", "application/json")
+        for key, value := range headers {
+            req.Header.Set(key, value)
+        }
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+
+    client := models.Client{Name: "ETag Owner", Email: "etag@example.com", Phone: "600000003"}
+    resp := do("POST", "/api/v1/clients", client, nil)
+    assert.Equal(t, http.StatusCreated, resp.Code)
+    assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &client))
+    assert.Equal(t, uint(1), client.Version)
+    assert.Equal(t, etagFor(1), resp.Header().Get("ETag"))
+
+    url := "/api/v1/clients/" + strconv.FormatUint(uint64(client.ID), 10)
+
+    t.Run("Conditional GET", func(t *testing.T) {
+        resp := do("GET", url, nil, nil)
+        assert.Equal(t, http.StatusOK, resp.Code)
+        tag := resp.Header().Get("ETag")
+        assert.Equal(t, etagFor(1), tag)
+
+        resp = do("GET", url, nil, map[string]string{"If-None-Match": tag})
+        assert.Equal(t, http.StatusNotModified, resp.Code)
+        assert.Empty(t, resp.Body.Bytes())
+
+        resp = do("GET", url, nil, map[string]string{"If-None-Match": etagFor(7)})
+        assert.Equal(t, http.StatusOK, resp.Code)
+    })
+
+    t.Run("Conditional GET With Include", func(t *testing.T) {
+        pet := models.Pet{Name: "Toby", Species: "Dog", ClientID: client.ID, Version: 1}
+        db.Create(&pet)
+
+        resp := do("GET", url+"?include=pets", nil, nil)
+        assert.Equal(t, http.StatusOK, resp.Code)
+        tag := resp.Header().Get("ETag")
+        assert.NotEqual(t, etagFor(1), tag)
+        assert.Equal(t, http.StatusNotModified, do("GET", url+"?include=pets", nil, map[string]string{"If-None-Match": tag}).Code)
+
+        // Al cambiar la mascota cambia la respuesta, aunque no el cliente
+        db.Model(&pet).Updates(map[string]interface{}{"name": "Tobías", "version": 2})
+        resp = do("GET", url+"?include=pets", nil, map[string]string{"If-None-Match": tag})
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.NotEqual(t, tag, resp.Header().Get("ETag"))
+        assert.Contains(t, resp.Body.String(), "Tobías")
+
+        // Sin include la respuesta es solo el cliente
+        resp = do("GET", url, nil, map[string]string{"If-None-Match": etagFor(1)})
+        assert.Equal(t, http.StatusNotModified, resp.Code)
+        db.Delete(&pet)
+    })
+
+    t.Run("Require If-Match", func(t *testing.T) {
+        client.Name = "Sin condición"
+        assert.Equal(t, http.StatusPreconditionRequired, do("PUT", url, client, nil).Code)
+        assert.Equal(t, http.StatusPreconditionRequired, do("DELETE", url, nil, nil).Code)
+    })
+
+    t.Run("Lost Update Is Rejected", func(t *testing.T) {
+        // Dos recepcionistas leen la versión 1
+        first := client
+        first.Name = "Primera edición"
+        second := client
+        second.Phone = "611111111"
+
+        resp := do("PUT", url, first, map[string]string{"If-Match": etagFor(1)})
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.Equal(t, etagFor(2), resp.Header().Get("ETag"))
+
+        resp = do("PUT", url, second, map[string]string{"If-Match": etagFor(1)})
+        assert.Equal(t, http.StatusPreconditionFailed, resp.Code)
+
+        var stored models.Client
+        db.First(&stored, client.ID)
+        assert.Equal(t, "Primera edición", stored.Name)
+        assert.Equal(t, "600000003", stored.Phone)
+        assert.Equal(t, uint(2), stored.Version)
+    })
+
+    t.Run("Delete With Stale ETag", func(t *testing.T) {
+        assert.Equal(t, http.StatusPreconditionFailed, do("DELETE", url, nil, map[string]string{"If-Match": etagFor(1)}).Code)
+        assert.Equal(t, http.StatusOK, do("DELETE", url, nil, map[string]string{"If-Match": etagFor(2)}).Code)
+    })
+}
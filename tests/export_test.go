@@ -0,0 +1,195 @@
+package tests
+
+import (
+    "archive/zip"
+    "bufio"
+    "bytes"
+    "encoding/csv"
+    "encoding/json"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/export"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+    router, db, err := setupTestRouter()
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    get := func(url string) *httptest.ResponseRecorder {
+        req, _ := newRequest("GET", url, nil)
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+
+    owner := models.Client{Name: "Export, Owner", Email: "export@example.com", Phone: "600000008"}
+    db.Create(&owner)
+    other := models.Client{Name: "Other Owner", Email: "other@example.com", Phone: "600000009"}
+    db.Create(&other)
+    archived := models.Client{Name: "Archived Owner", Email: "archived@example.com", Phone: "600000010"}
+    db.Create(&archived)
+    db.Delete(&archived)
+
+    pets := []models.Pet{
+        {Name: "Nube", Species: "Cat", Weight: 4.25, ClientID: owner.ID, BirthDate: time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)},
+        {Name: "Trueno", Species: "Dog", Weight: 30, ClientID: owner.ID},
+        {Name: "Bola", Species: "Hamster", Weight: 0.1, ClientID: other.ID},
+    }
+    db.Create(&pets)
+    db.Create(&models.Appointment{PetID: pets[0].ID, Date: time.Now().Add(24 * time.Hour), Reason: "Vacuna <anual>", Duration: 15, Completed: true})
+
+    t.Run("CSV With Filters", func(t *testing.T) {
+        resp := get("/api/v1/export/pets?client_id=" + strconv.FormatUint(uint64(owner.ID), 10))
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.Equal(t, "text/csv; charset=utf-8", resp.Header().Get("Content-Type"))
+        assert.Contains(t, resp.Header().Get("Content-Disposition"), `filename="pets.csv"`)
+
+        records, err := csv.NewReader(resp.Body).ReadAll()
+        assert.NoError(t, err)
+        if assert.Len(t, records, 3) {
+            assert.Equal(t, []string{"id", "name", "species", "breed", "birth_date", "weight", "client_id", "description", "created_at", "updated_at"}, records[0])
+            assert.Equal(t, "Nube", records[1][1])
+            assert.Equal(t, "2020-05-01T00:00:00Z", records[1][4])
+            assert.Equal(t, "4.25", records[1][5])
+            assert.Equal(t, "Trueno", records[2][1])
+            assert.Equal(t, "", records[2][4])
+        }
+    })
+
+    t.Run("Archived Rows Are Excluded", func(t *testing.T) {
+        resp := get("/api/v1/export/clients")
+        records, err := csv.NewReader(resp.Body).ReadAll()
+        assert.NoError(t, err)
+        if assert.Len(t, records, 3) {
+            assert.Equal(t, "Export, Owner", records[1][1])
+        }
+    })
+
+    t.Run("NDJSON", func(t *testing.T) {
+        resp := get("/api/v1/export/appointments?format=ndjson")
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.Equal(t, "application/x-ndjson", resp.Header().Get("Content-Type"))
+
+        var appointments []models.Appointment
+        scanner := bufio.NewScanner(resp.Body)
+        for scanner.Scan() {
+            var appointment models.Appointment
+            assert.NoError(t, json.Unmarshal(scanner.Bytes(), &appointment))
+            appointments = append(appointments, appointment)
+        }
+        if assert.Len(t, appointments, 1) {
+            assert.Equal(t, "Vacuna <anual>", appointments[0].Reason)
+            assert.Equal(t, pets[0].ID, appointments[0].PetID)
+        }
+    })
+
+    t.Run("XLSX", func(t *testing.T) {
+        resp := get("/api/v1/export/appointments?format=xlsx")
+        assert.Equal(t, http.StatusOK, resp.Code)
+
+        body := resp.Body.Bytes()
+        archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+        if !assert.NoError(t, err) {
+            return
+        }
+
+        var sheet string
+        for _, f := range archive.File {
+            if f.Name == "xl/worksheets/sheet1.xml" {
+                r, _ := f.Open()
+                content, _ := io.ReadAll(r)
+                sheet = string(content)
+            }
+        }
+        assert.Contains(t, sheet, `<c r="E2" t="inlineStr"><is><t xml:space="preserve">Vacuna &lt;anual&gt;</t></is></c>`)
+        assert.Contains(t, sheet, `<c r="D2"><v>15</v></c>`)
+        assert.Contains(t, sheet, `<c r="G2" t="b"><v>1</v></c>`)
+    })
+
+    t.Run("List Filters", func(t *testing.T) {
+        // Los mismos filtros que GET /pets y GET /appointments
+        resp := get("/api/v1/export/pets?format=ndjson&life_stage=adult")
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.Equal(t, 1, strings.Count(resp.Body.String(), "\n"))
+        assert.Contains(t, resp.Body.String(), `"name":"Nube"`)
+
+        day := time.Now().Add(24 * time.Hour).Format("2006-01-02")
+        resp = get("/api/v1/export/appointments?format=ndjson&date=" + day)
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.Equal(t, 1, strings.Count(resp.Body.String(), "\n"))
+        resp = get("/api/v1/export/appointments?format=ndjson&date=" + time.Now().Add(-72*time.Hour).Format("2006-01-02"))
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.Empty(t, resp.Body.String())
+
+        assert.Equal(t, http.StatusBadRequest, get("/api/v1/export/pets?life_stage=old").Code)
+        assert.Equal(t, http.StatusBadRequest, get("/api/v1/export/appointments?date=ayer").Code)
+    })
+
+    t.Run("Invalid Parameters", func(t *testing.T) {
+        assert.Equal(t, http.StatusBadRequest, get("/api/v1/export/vets").Code)
+        assert.Equal(t, http.StatusBadRequest, get("/api/v1/export/pets?format=pdf").Code)
+        assert.Equal(t, http.StatusBadRequest, get("/api/v1/export/pets?client_id=abc").Code)
+    })
+
+    t.Run("Async Job", func(t *testing.T) {
+        resp := get("/api/v1/export/pets?format=ndjson&async=true")
+        assert.Equal(t, http.StatusAccepted, resp.Code)
+
+        var job export.Job
+        assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &job))
+        assert.Equal(t, "/api/v1/export/jobs/"+job.ID, resp.Header().Get("Location"))
+
+        for i := 0; i < 100 && job.Status != export.JobDone && job.Status != export.JobFailed; i++ {
+            time.Sleep(10 * time.Millisecond)
+            json.Unmarshal(get("/api/v1/export/jobs/"+job.ID).Body.Bytes(), &job)
+        }
+        if !assert.Equal(t, export.JobDone, job.Status, job.Error) {
+            return
+        }
+        assert.Equal(t, 3, job.Rows)
+
+        resp = get(job.DownloadURL)
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.Contains(t, resp.Header().Get("Content-Disposition"), `filename="pets.ndjson"`)
+        assert.Equal(t, 3, strings.Count(resp.Body.String(), "\n"))
+
+        assert.Equal(t, http.StatusNotFound, get("/api/v1/export/jobs/desconocido").Code)
+
+        // Solo la ve quien la pidió: ni otra clínica ni una clave de API
+        clinic := models.Clinic{Name: "Sede Sur"}
+        db.Create(&clinic)
+        req, _ := newRequest("POST", "/api/v1/admin/api-keys", bytes.NewBufferString(
+            `{"name": "exportador", "scopes": ["export:read"], "clinic_id": `+strconv.Itoa(int(clinic.ID))+`}`))
+        req.Header.Set("Content-Type", "application/json")
+        resp = httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        var created map[string]interface{}
+        json.Unmarshal(resp.Body.Bytes(), &created)
+        key, _ := created["key"].(string)
+        assert.NotEmpty(t, key)
+
+        for _, url := range []string{"/api/v1/export/jobs/" + job.ID, job.DownloadURL} {
+            req, _ := newRequest("GET", url, nil)
+            req.Header.Set("X-Clinic-ID", strconv.Itoa(int(clinic.ID)))
+            resp := httptest.NewRecorder()
+            router.ServeHTTP(resp, req)
+            assert.Equal(t, http.StatusNotFound, resp.Code, url)
+
+            req, _ = http.NewRequest("GET", url, nil)
+            req.Header.Set("X-API-Key", key)
+            resp = httptest.NewRecorder()
+            router.ServeHTTP(resp, req)
+            assert.Equal(t, http.StatusNotFound, resp.Code, url)
+        }
+    })
+}
@@ -0,0 +1,63 @@
+package tests
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestHealthChecks(t *testing.T) {
+    router, db, err := setupTestRouter()
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    get := func(url string) (int, map[string]interface{}) {
+        req, _ := newRequest("GET", url, nil)
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+
+        var body map[string]interface{}
+        json.Unmarshal(resp.Body.Bytes(), &body)
+        return resp.Code, body
+    }
+
+    t.Run("Liveness", func(t *testing.T) {
+        code, body := get("/healthz")
+        assert.Equal(t, http.StatusOK, code)
+        assert.Equal(t, "ok", body["status"])
+    })
+
+    t.Run("Ready", func(t *testing.T) {
+        code, body := get("/readyz")
+        assert.Equal(t, http.StatusOK, code)
+        assert.Equal(t, "ready", body["status"])
+    })
+
+    t.Run("Pending Migration", func(t *testing.T) {
+        assert.NoError(t, db.Exec("ALTER TABLE clients DROP COLUMN address").Error)
+        assert.NoError(t, db.Migrator().DropTable("reminders"))
+
+        code, body := get("/readyz")
+        assert.Equal(t, http.StatusServiceUnavailable, code)
+        checks := body["checks"].(map[string]interface{})
+        assert.Equal(t, "ok", checks["database"])
+        assert.ElementsMatch(t, []interface{}{"clients.address", "reminders"}, checks["migrations"])
+    })
+
+    t.Run("Database Down", func(t *testing.T) {
+        sqlDB, _ := db.DB()
+        sqlDB.Close()
+
+        code, body := get("/readyz")
+        assert.Equal(t, http.StatusServiceUnavailable, code)
+        assert.Equal(t, "not ready", body["status"])
+
+        // La comprobación de vida no depende de la base de datos
+        code, _ = get("/healthz")
+        assert.Equal(t, http.StatusOK, code)
+    })
+}
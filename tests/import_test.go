@@ -0,0 +1,200 @@
+package tests
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "strings"
+    "testing"
+
+    "github.com/javice/vet-clinic-api/internal/importer"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestImport(t *testing.T) {
+    router, db, err := setupTestRouter()
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    clinic := models.Clinic{Name: "Clínica principal"}
+    db.Create(&clinic)
+    clinicID := strconv.Itoa(int(clinic.ID))
+
+    post := func(url, contentType, body string) (*importer.Report, *httptest.ResponseRecorder) {
+        req, _ := newRequest("POST", url, strings.NewReader(body))
+        req.Header.Set("Content-Type", contentType)
+        req.Header.Set("X-Admin-Token", testAdminToken)
+        req.Header.Set("X-Clinic-ID", clinicID)
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+
+        var report importer.Report
+        json.Unmarshal(resp.Body.Bytes(), &report)
+        return &report, resp
+    }
+    countClients := func() int64 {
+        var count int64
+        db.Model(&models.Client{}).Count(&count)
+        return count
+    }
+
+    existing := models.Client{Name: "Existing Owner", Email: "existing@example.com", Phone: "600000007"}
+    db.Create(&existing)
+    db.Create(&models.ClientClinic{ClientID: existing.ID, ClinicID: clinic.ID})
+
+    clientsURL := "/api/v1/admin/import/clients?map[Nombre]=name&map[Correo]=email&map[Teléfono]=phone&map[Observaciones]=-"
+    invalidCSV := "Nombre,Correo,Teléfono,Observaciones\n" +
+        "Ana Ruiz,ana@example.com,611 111 111,Paga en efectivo\n" +
+        "Luis Gil,no-es-un-email,622 222 222,\n" +
+        "Eva Sanz,eva@example.com,,\n" +
+        "Otro,EXISTING@example.com,633 333 333,\n" +
+        "Ana Ruiz bis,ana@example.com,644 444 444,\n"
+
+    t.Run("Dry Run Report", func(t *testing.T) {
+        report, resp := post(clientsURL, "text/csv", invalidCSV)
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.True(t, report.DryRun)
+        assert.Equal(t, 5, report.Total)
+        assert.Equal(t, 1, report.Valid)
+        assert.Equal(t, 4, report.Invalid)
+        assert.Equal(t, 0, report.Imported)
+
+        assert.Contains(t, report.Errors, importer.RowError{Row: 3, Field: "email", Message: "no es un email válido"})
+        assert.Contains(t, report.Errors, importer.RowError{Row: 4, Field: "phone", Message: "es obligatorio"})
+        assert.Contains(t, report.Errors, importer.RowError{Row: 5, Field: "email", Message: "ya existe un cliente con este email"})
+        assert.Contains(t, report.Errors, importer.RowError{Row: 6, Field: "email", Message: "email repetido en la fila 2"})
+
+        assert.Equal(t, int64(1), countClients())
+    })
+
+    t.Run("Commit Is All Or Nothing", func(t *testing.T) {
+        report, resp := post(clientsURL+"&dry_run=false", "text/csv", invalidCSV)
+        assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+        assert.Equal(t, 4, report.Invalid)
+        assert.Equal(t, int64(1), countClients())
+    })
+
+    t.Run("Unknown Column", func(t *testing.T) {
+        report, _ := post("/api/v1/admin/import/clients", "text/csv", "name,email,phone,fax\nAna,ana@example.com,611,612\n")
+        if assert.Len(t, report.Errors, 1) {
+            assert.Equal(t, "fax", report.Errors[0].Field)
+        }
+    })
+
+    t.Run("Commit Clients", func(t *testing.T) {
+        csv := "Nombre,Correo,Teléfono,Observaciones\n" +
+            "Ana Ruiz,ana@example.com,611 111 111,Paga en efectivo\n" +
+            "Luis Gil,luis@example.com,622 222 222,\n"
+        report, resp := post(clientsURL+"&dry_run=false", "text/csv", csv)
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.Equal(t, 2, report.Imported)
+        assert.Equal(t, int64(3), countClients())
+
+        var luis models.Client
+        assert.NoError(t, db.Where("email = ?", "luis@example.com").First(&luis).Error)
+        assert.Equal(t, uint(1), luis.Version)
+
+        // Los clientes importados quedan en la clínica de destino
+        var links int64
+        db.Model(&models.ClientClinic{}).Where("client_id = ? AND clinic_id = ?", luis.ID, clinic.ID).Count(&links)
+        assert.EqualValues(t, 1, links)
+    })
+
+    t.Run("Pets Linked By Email", func(t *testing.T) {
+        ndjson := `{"nombre": "Kiwi", "species": "Bird", "birth_date": "12/03/2021", "weight": 0.1, "client_email": "ana@example.com"}
+{"nombre": "Rex", "species": "Dog", "weight": "31,5", "client_email": "Luis@Example.com"}
+{"nombre": "Sin dueño", "species": "Cat", "client_email": "nadie@example.com"}
+{"nombre": "Fecha mala", "species": "Cat", "birth_date": "ayer", "client_email": "ana@example.com"}
+{"nombre": "Sin especie", "client_email": "ana@example.com"}
+{esto no es json
+`
+        url := "/api/v1/admin/import/pets?map[nombre]=name"
+        report, resp := post(url, "application/x-ndjson", ndjson)
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.Equal(t, 6, report.Total)
+        assert.Equal(t, 2, report.Valid)
+        assert.Contains(t, report.Errors, importer.RowError{Row: 3, Field: "client_email", Message: "no existe ningún cliente con este email"})
+        assert.Contains(t, report.Errors, importer.RowError{Row: 4, Field: "birth_date", Message: "fecha inválida; use AAAA-MM-DD o DD/MM/AAAA"})
+        assert.Contains(t, report.Errors, importer.RowError{Row: 5, Field: "species", Message: "es obligatorio"})
+        if assert.Len(t, report.Errors, 4) {
+            assert.Equal(t, 6, report.Errors[3].Row)
+        }
+
+        valid := strings.Join(strings.Split(ndjson, "\n")[:2], "\n")
+        report, resp = post(url+"&dry_run=false", "application/x-ndjson", valid)
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.Equal(t, 2, report.Imported)
+
+        var rex models.Pet
+        assert.NoError(t, db.Preload("Client").Where("name = ?", "Rex").First(&rex).Error)
+        assert.Equal(t, 31.5, rex.Weight)
+        if assert.NotNil(t, rex.Client) {
+            assert.Equal(t, "luis@example.com", rex.Client.Email)
+        }
+
+        var kiwi models.Pet
+        assert.NoError(t, db.Where("name = ?", "Kiwi").First(&kiwi).Error)
+        assert.Equal(t, "2021-03-12", kiwi.BirthDate.Format("2006-01-02"))
+    })
+
+    t.Run("Invalid Requests", func(t *testing.T) {
+        _, resp := post("/api/v1/admin/import/vets", "text/csv", "name\nx\n")
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+        _, resp = post("/api/v1/admin/import/clients", "application/xml", "<x/>")
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+        req, _ := http.NewRequest("POST", "/api/v1/admin/import/clients", strings.NewReader("name\n"))
+        req.Header.Set("Content-Type", "text/csv")
+        resp = httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        assert.Equal(t, http.StatusUnauthorized, resp.Code)
+    })
+
+    t.Run("Emails Taken Elsewhere", func(t *testing.T) {
+        archived := models.Client{Name: "Archivada", Email: "archivada@example.com", Phone: "666 666 666"}
+        db.Create(&archived)
+        db.Create(&models.ClientClinic{ClientID: archived.ID, ClinicID: clinic.ID})
+        db.Delete(&archived)
+        south := models.Clinic{Name: "Sede Sur"}
+        db.Create(&south)
+        elsewhere := models.Client{Name: "Sureña", Email: "surena@example.com", Phone: "677 777 777"}
+        db.Create(&elsewhere)
+        db.Create(&models.ClientClinic{ClientID: elsewhere.ID, ClinicID: south.ID})
+
+        // El email es único en todas las clínicas, también entre los archivados
+        csv := "name,email,phone\nOtra,Archivada@example.com,688 888 888\nOtra más,surena@example.com,699 999 999\n"
+        report, resp := post("/api/v1/admin/import/clients", "text/csv", csv)
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.Contains(t, report.Errors, importer.RowError{Row: 2, Field: "email", Message: "ya existe un cliente archivado con este email"})
+        assert.Contains(t, report.Errors, importer.RowError{Row: 3, Field: "email", Message: "ya existe un cliente con este email"})
+
+        _, resp = post("/api/v1/admin/import/clients?dry_run=false", "text/csv", csv)
+        assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+    })
+
+    t.Run("Target Clinic", func(t *testing.T) {
+        csv := "name,email,phone\nMarta Gil,marta@example.com,655 555 555\n"
+        request := func(url string) *httptest.ResponseRecorder {
+            req, _ := newRequest("POST", url, strings.NewReader(csv))
+            req.Header.Set("Content-Type", "text/csv")
+            resp := httptest.NewRecorder()
+            router.ServeHTTP(resp, req)
+            return resp
+        }
+
+        // Sin clínica los clientes no se verían desde ninguna
+        assert.Equal(t, http.StatusBadRequest, request("/api/v1/admin/import/clients?dry_run=false").Code)
+        assert.Equal(t, http.StatusNotFound, request("/api/v1/admin/import/clients?clinic_id=999").Code)
+
+        resp := request("/api/v1/admin/import/clients?dry_run=false&clinic_id=" + clinicID)
+        assert.Equal(t, http.StatusOK, resp.Code)
+        var marta models.Client
+        assert.NoError(t, db.Where("email = ?", "marta@example.com").First(&marta).Error)
+        var links int64
+        db.Model(&models.ClientClinic{}).Where("client_id = ? AND clinic_id = ?", marta.ID, clinic.ID).Count(&links)
+        assert.EqualValues(t, 1, links)
+    })
+}
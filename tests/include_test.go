@@ -0,0 +1,107 @@
+package tests
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "testing"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestNestedRoutesAndIncludes(t *testing.T) {
+    router, db, err := setupTestRouter()
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    get := func(url string) *httptest.ResponseRecorder {
+        req, _ := newRequest("GET", url, nil)
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+
+    client := models.Client{Name: "Include Owner", Email: "include@example.com", Phone: "600000005"}
+    db.Create(&client)
+    other := models.Client{Name: "Other Owner", Email: "other@example.com", Phone: "600000006"}
+    db.Create(&other)
+    pet := models.Pet{Name: "Rocky", Species: "Dog", ClientID: client.ID}
+    db.Create(&pet)
+    db.Create(&models.Pet{Name: "Mishi", Species: "Cat", ClientID: other.ID})
+    appointment := models.Appointment{PetID: pet.ID, Date: time.Now().Add(24 * time.Hour), Reason: "Vacuna", Duration: 15}
+    db.Create(&appointment)
+
+    clientID := strconv.FormatUint(uint64(client.ID), 10)
+    petID := strconv.FormatUint(uint64(pet.ID), 10)
+
+    t.Run("Pets By Client", func(t *testing.T) {
+        resp := get("/api/v1/clients/" + clientID + "/pets")
+        assert.Equal(t, http.StatusOK, resp.Code)
+
+        var pets []models.Pet
+        assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &pets))
+        if assert.Len(t, pets, 1) {
+            assert.Equal(t, "Rocky", pets[0].Name)
+            assert.Nil(t, pets[0].Appointments)
+        }
+
+        assert.Equal(t, http.StatusNotFound, get("/api/v1/clients/9999/pets").Code)
+    })
+
+    t.Run("Appointments By Pet", func(t *testing.T) {
+        resp := get("/api/v1/pets/" + petID + "/appointments?include=pet.client")
+        assert.Equal(t, http.StatusOK, resp.Code)
+
+        var appointments []models.Appointment
+        assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &appointments))
+        if assert.Len(t, appointments, 1) && assert.NotNil(t, appointments[0].Pet) && assert.NotNil(t, appointments[0].Pet.Client) {
+            assert.Equal(t, "Include Owner", appointments[0].Pet.Client.Name)
+        }
+
+        assert.Equal(t, http.StatusNotFound, get("/api/v1/pets/9999/appointments").Code)
+    })
+
+    t.Run("Include Nested Associations", func(t *testing.T) {
+        resp := get("/api/v1/clients/" + clientID + "?include=pets.appointments")
+        assert.Equal(t, http.StatusOK, resp.Code)
+
+        var result models.Client
+        assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+        if assert.Len(t, result.Pets, 1) {
+            assert.Len(t, result.Pets[0].Appointments, 1)
+        }
+
+        resp = get("/api/v1/pets/" + petID + "?include=client,appointments")
+        assert.Equal(t, http.StatusOK, resp.Code)
+
+        var withClient models.Pet
+        assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &withClient))
+        if assert.NotNil(t, withClient.Client) {
+            assert.Equal(t, client.ID, withClient.Client.ID)
+        }
+        assert.Len(t, withClient.Appointments, 1)
+    })
+
+    t.Run("Without Include", func(t *testing.T) {
+        resp := get("/api/v1/pets/" + petID)
+        var plain models.Pet
+        assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &plain))
+        assert.Nil(t, plain.Client)
+        assert.Nil(t, plain.Appointments)
+
+        resp = get("/api/v1/clients/" + clientID)
+        var owner models.Client
+        assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &owner))
+        assert.Nil(t, owner.Pets)
+    })
+
+    t.Run("Reject Unknown Include", func(t *testing.T) {
+        assert.Equal(t, http.StatusBadRequest, get("/api/v1/pets?include=client.pets").Code)
+        assert.Equal(t, http.StatusBadRequest, get("/api/v1/clients?include=password").Code)
+        assert.Equal(t, http.StatusBadRequest, get("/api/v1/appointments/1?include=pets").Code)
+    })
+}
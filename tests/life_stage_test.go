@@ -0,0 +1,143 @@
+package tests
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "sort"
+    "strconv"
+    "testing"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestPetAgeAndLifeStage(t *testing.T) {
+    router, db, err := setupTestRouter()
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    request := func(method, url string, body interface{}) *httptest.ResponseRecorder {
+        var payload []byte
+        if body != nil {
+            payload, _ = json.Marshal(body)
+        }
+        req, _ := newRequest(method, url, bytes.NewBuffer(payload))
+        req.Header.Set("Content-Type", "application/json")
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+    create := func(body map[string]interface{}) map[string]interface{} {
+        resp := request("POST", "/api/v1/pets", body)
+        if !assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String()) {
+            t.FailNow()
+        }
+        var created map[string]interface{}
+        json.Unmarshal(resp.Body.Bytes(), &created)
+        return created
+    }
+    names := func(url string) []string {
+        resp := request("GET", url, nil)
+        result := []string{}
+        if assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+            var pets []models.Pet
+            json.Unmarshal(resp.Body.Bytes(), &pets)
+            for _, p := range pets {
+                result = append(result, p.Name)
+            }
+        }
+        sort.Strings(result)
+        return result
+    }
+
+    // Fechas de nacimiento relativas a hoy, el día 1 para que los meses
+    // cumplidos no dependan del día en que se ejecuta
+    now := time.Now()
+    born := func(years, months int) string {
+        return time.Date(now.Year()-years, now.Month()-time.Month(months), 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+    }
+
+    client := models.Client{Name: "Elena Vidal", Email: "elena@example.com", Phone: "600555666"}
+    other := models.Client{Name: "Jorge Sanz", Email: "jorge@example.com", Phone: "600777888"}
+    assert.NoError(t, db.Create(&client).Error)
+    assert.NoError(t, db.Create(&other).Error)
+
+    t.Run("Unknown Birth Date", func(t *testing.T) {
+        pet := create(map[string]interface{}{"name": "Sin fecha", "species": "Dog", "client_id": client.ID})
+        assert.Nil(t, pet["birth_date"])
+        assert.Nil(t, pet["age"])
+        assert.Equal(t, "", pet["birth_date_precision"])
+        assert.NotContains(t, pet, "life_stage")
+    })
+
+    t.Run("Exact Birth Date", func(t *testing.T) {
+        pet := create(map[string]interface{}{
+            "name": "Toby", "species": "Dog", "client_id": client.ID, "birth_date": born(3, 2) + "T00:00:00Z",
+        })
+        assert.Equal(t, "day", pet["birth_date_precision"])
+        assert.Equal(t, map[string]interface{}{"years": 3.0, "months": 2.0, "approximate": false}, pet["age"])
+        assert.Equal(t, "adult", pet["life_stage"])
+    })
+
+    t.Run("Approximate Birth Date", func(t *testing.T) {
+        // Solo se conoce el año: se guarda el 1 de enero
+        pet := create(map[string]interface{}{
+            "name": "Coco", "species": "Cat", "client_id": client.ID,
+            "birth_date": "2019-08-20T00:00:00Z", "birth_date_precision": "year",
+        })
+        assert.Equal(t, "2019-01-01T00:00:00Z", pet["birth_date"])
+        age := pet["age"].(map[string]interface{})
+        assert.Equal(t, true, age["approximate"])
+
+        pet = create(map[string]interface{}{
+            "name": "Kira", "species": "Dog", "client_id": client.ID,
+            "birth_date": "2021-05-17T00:00:00Z", "birth_date_precision": "month",
+        })
+        assert.Equal(t, "2021-05-01T00:00:00Z", pet["birth_date"])
+
+        resp := request("POST", "/api/v1/pets", map[string]interface{}{
+            "name": "Max", "species": "Dog", "client_id": client.ID,
+            "birth_date": "2021-05-17T00:00:00Z", "birth_date_precision": "more or less",
+        })
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+    })
+
+    t.Run("Life Stage Filter", func(t *testing.T) {
+        assert.NoError(t, db.Where("1 = 1").Delete(&models.Pet{}).Error)
+        pets := []map[string]interface{}{
+            // Un perro es senior a los 7 años y un gato a los 11
+            {"name": "Rex", "species": "Dog", "client_id": client.ID, "birth_date": born(8, 0) + "T00:00:00Z"},
+            {"name": "Misi", "species": "Cat", "client_id": client.ID, "birth_date": born(8, 0) + "T00:00:00Z"},
+            {"name": "Bola", "species": "Hamster", "client_id": client.ID, "birth_date": born(2, 0) + "T00:00:00Z"},
+            {"name": "Nube", "species": "Cat", "client_id": other.ID, "birth_date": born(12, 0) + "T00:00:00Z"},
+            {"name": "Lolo", "species": "Dog", "client_id": client.ID, "birth_date": born(0, 3) + "T00:00:00Z"},
+            {"name": "Pío", "species": "Bird", "client_id": client.ID},
+        }
+        for _, p := range pets {
+            create(p)
+        }
+
+        assert.Equal(t, []string{"Bola", "Nube", "Rex"}, names("/api/v1/pets?life_stage=senior"))
+        assert.Equal(t, []string{"Misi"}, names("/api/v1/pets?life_stage=adult"))
+        assert.Equal(t, []string{"Lolo"}, names("/api/v1/pets?life_stage=puppy"))
+        assert.Equal(t, []string{"Bola", "Rex"}, names("/api/v1/pets?life_stage=senior&client_id="+strconv.Itoa(int(client.ID))))
+        assert.Equal(t, http.StatusBadRequest, request("GET", "/api/v1/pets?life_stage=old", nil).Code)
+    })
+
+    t.Run("Age Boundaries", func(t *testing.T) {
+        // El 31 de marzo se cumple un mes si se nació hasta el 28 de febrero
+        march := time.Date(2030, 3, 31, 12, 0, 0, 0, time.UTC)
+        assert.Equal(t, time.Date(2030, 2, 28, 0, 0, 0, 0, time.UTC), models.BornBy(march, 1))
+        assert.Equal(t, time.Date(2023, 3, 31, 0, 0, 0, 0, time.UTC), models.BornBy(march, 84))
+
+        dog := models.Pet{Species: "Dog", BirthDate: time.Date(2023, 3, 31, 0, 0, 0, 0, time.UTC)}
+        assert.Equal(t, "senior", dog.LifeStage(march))
+        dog.BirthDate = dog.BirthDate.AddDate(0, 0, 1)
+        assert.Equal(t, "adult", dog.LifeStage(march))
+        assert.Equal(t, &models.PetAge{Years: 6, Months: 11}, dog.Age(march))
+    })
+}
@@ -0,0 +1,116 @@
+package tests
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/json"
+    "log/slog"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "github.com/javice/vet-clinic-api/internal/logging"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestStructuredLogging(t *testing.T) {
+    var output bytes.Buffer
+    logger := logging.New(&output, logging.Config{Level: slog.LevelDebug})
+
+    previous := slog.Default()
+    slog.SetDefault(logger)
+    defer slog.SetDefault(previous)
+
+    router, db, err := setupTestRouter()
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+    db.Logger = logging.NewGormLogger(logger)
+
+    get := func(url string, headers map[string]string) *httptest.ResponseRecorder {
+        req, _ := newRequest("GET", url, nil)
+        for key, value := range headers {
+            req.Header.Set(key, value)
+        }
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+
+    // entries devuelve las líneas de log con ese ID de petición
+    entries := func(requestID string) []map[string]interface{} {
+        var result []map[string]interface{}
+        scanner := bufio.NewScanner(strings.NewReader(output.String()))
+        for scanner.Scan() {
+            var entry map[string]interface{}
+            if json.Unmarshal(scanner.Bytes(), &entry) == nil && entry[logging.RequestIDKey] == requestID {
+                result = append(result, entry)
+            }
+        }
+        return result
+    }
+
+    t.Run("Generate Request ID", func(t *testing.T) {
+        resp := get("/api/v1/clients", nil)
+        id := resp.Header().Get("X-Request-ID")
+        assert.Len(t, id, 32)
+        assert.NotEqual(t, id, get("/api/v1/clients", nil).Header().Get("X-Request-ID"))
+    })
+
+    t.Run("Propagate Request ID", func(t *testing.T) {
+        db.Create(&models.Client{Name: "Log Owner", Email: "private@example.com", Phone: "+34 611 222 333"})
+
+        resp := get("/api/v1/clients?q=private@example.com", map[string]string{"X-Request-ID": "req-123"})
+        assert.Equal(t, "req-123", resp.Header().Get("X-Request-ID"))
+
+        logs := entries("req-123")
+        var request, query map[string]interface{}
+        for _, entry := range logs {
+            if entry["sql"] != nil {
+                query = entry
+            } else if entry["status"] != nil {
+                request = entry
+            }
+        }
+        if assert.NotNil(t, request, "falta el log de la petición") {
+            assert.Equal(t, "INFO", request["level"])
+            assert.Equal(t, "/api/v1/clients", request["path"])
+            assert.Equal(t, float64(http.StatusOK), request["status"])
+        }
+        if assert.NotNil(t, query, "falta el log de la consulta") {
+            assert.Equal(t, "DEBUG", query["level"])
+        }
+    })
+
+    t.Run("Reject Invalid Request ID", func(t *testing.T) {
+        resp := get("/api/v1/clients", map[string]string{"X-Request-ID": strings.Repeat("x", 200)})
+        assert.Len(t, resp.Header().Get("X-Request-ID"), 32)
+    })
+
+    t.Run("Redact Personal Data", func(t *testing.T) {
+        db.Create(&models.Client{Name: "Otro", Email: "secreto@example.com", Phone: "622-333-444"})
+
+        assert.NotContains(t, output.String(), "private@example.com")
+        assert.NotContains(t, output.String(), "secreto@example.com")
+        assert.NotContains(t, output.String(), "622-333-444")
+        assert.NotContains(t, output.String(), "611 222 333")
+        assert.Contains(t, output.String(), logging.Redacted)
+    })
+
+    t.Run("Warn On Client Errors", func(t *testing.T) {
+        get("/api/v1/clients/9999", map[string]string{"X-Request-ID": "req-404"})
+        logs := entries("req-404")
+        if assert.NotEmpty(t, logs) {
+            assert.Equal(t, "WARN", logs[len(logs)-1]["level"])
+        }
+    })
+}
+
+func TestRedact(t *testing.T) {
+    assert.Equal(t, "email [REDACTED], tel [REDACTED]", logging.Redact("email ana.ruiz@example.com, tel +34 (611) 22-33-44"))
+    // Las fechas, horas e identificadores no son teléfonos
+    text := `{"date": "2024-03-01 10:30:00", "id": 12345, "updated_at": "2024-03-01T10:30:00Z"}`
+    assert.Equal(t, text, logging.Redact(text))
+}
@@ -2,15 +2,40 @@ package tests
 
 import (
 	"github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/audit"
     "github.com/javice/vet-clinic-api/internal/handlers"
     "github.com/javice/vet-clinic-api/internal/models"
     "github.com/javice/vet-clinic-api/internal/repositories"
     "github.com/javice/vet-clinic-api/internal/routes"
+    "github.com/javice/vet-clinic-api/internal/tenant"
+    "github.com/javice/vet-clinic-api/internal/timezone"
     "gorm.io/driver/sqlite"
     "gorm.io/gorm"
+    "io"
+    "net/http"
+    "strconv"
     "testing"
 )
 
+const testAdminToken = "test-admin-token"
+
+// etagFor devuelve el ETag que la API asigna a una versión
+func etagFor(version uint) string {
+    return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// newRequest crea una petición identificada con el token de administración,
+// como las del personal de la clínica. Las pruebas de autenticación usan
+// http.NewRequest directamente.
+func newRequest(method, url string, body io.Reader) (*http.Request, error) {
+    req, err := http.NewRequest(method, url, body)
+    if err != nil {
+        return nil, err
+    }
+    req.Header.Set("X-Admin-Token", testAdminToken)
+    return req, nil
+}
+
 func setupTestDB() (*gorm.DB, error) {
     db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
     if err != nil {
@@ -18,7 +43,7 @@ func setupTestDB() (*gorm.DB, error) {
     }
 
     // Migrar esquemas
-    err = db.AutoMigrate(&models.Client{}, &models.Pet{}, &models.Appointment{})
+    err = db.AutoMigrate(models.All()...)
     if err != nil {
         return nil, err
     }
@@ -27,6 +52,12 @@ func setupTestDB() (*gorm.DB, error) {
 } 
 
 func setupTestRouter() (*gin.Engine, *gorm.DB, error) {
+    return setupTestRouterWith(nil)
+}
+
+// setupTestRouterWith permite completar las opciones del router con la base
+// de datos ya migrada.
+func setupTestRouterWith(configure func(db *gorm.DB, opts *routes.Options) error) (*gin.Engine, *gorm.DB, error) {
     // Configurar la base de datos en memoria para pruebas
     //db, err := setupTestDB()
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
@@ -35,21 +66,51 @@ func setupTestRouter() (*gin.Engine, *gorm.DB, error) {
     }
 
 	// Ejecutar migraciones
-    err = db.AutoMigrate(&models.Client{}, &models.Pet{}, &models.Appointment{})
+    err = db.AutoMigrate(models.All()...)
     if err != nil {
         return nil, nil, err
     }
 
+    if err := timezone.Register(db); err != nil {
+        return nil, nil, err
+    }
+
+    if err := repositories.NewSpeciesRepository(db).Seed(repositories.DefaultSpecies()); err != nil {
+        return nil, nil, err
+    }
+
+    if err := audit.Register(db, &models.Client{}, &models.Pet{}, &models.Appointment{}); err != nil {
+        return nil, nil, err
+    }
+
+    if err := repositories.SetupSearchIndex(db); err != nil {
+        return nil, nil, err
+    }
+
+    if err := tenant.Register(db); err != nil {
+        return nil, nil, err
+    }
+
     // Crear repositorios
     clientRepo := repositories.NewClientRepository(db)
     petRepo := repositories.NewPetRepository(db)
 	appointmentRepo := repositories.NewAppointmentRepository(db)
+    auditRepo := repositories.NewAuditRepository(db)
+    searchRepo := repositories.NewSearchRepository(db)
 
     // Crear handler
-    handler := handlers.NewHandler(clientRepo, petRepo, appointmentRepo)
+    handler := handlers.NewHandler(clientRepo, petRepo, appointmentRepo, auditRepo, searchRepo)
 
     // Configurar rutas
-    router := routes.SetupRouter(handler)
+    // Las pruebas trabajan como administrador y, salvo que indiquen una
+    // clínica, con todo el grupo
+    opts := routes.Options{AdminToken: testAdminToken, AllowAllClinics: true}
+    if configure != nil {
+        if err := configure(db, &opts); err != nil {
+            return nil, nil, err
+        }
+    }
+    router := routes.SetupRouter(handler, opts)
 
     return router, db, nil
 }
@@ -0,0 +1,97 @@
+package tests
+
+import (
+    "net/http/httptest"
+    "strconv"
+    "testing"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/metrics"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/routes"
+    "github.com/prometheus/client_golang/prometheus/testutil"
+    "github.com/stretchr/testify/assert"
+    "gorm.io/gorm"
+)
+
+func TestMetrics(t *testing.T) {
+    var appMetrics *metrics.Metrics
+    router, db, err := setupTestRouterWith(func(db *gorm.DB, opts *routes.Options) error {
+        var err error
+        appMetrics, err = metrics.New(db)
+        opts.Metrics = appMetrics
+        opts.ServeMetrics = true
+        return err
+    })
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    get := func(url string) *httptest.ResponseRecorder {
+        req, _ := newRequest("GET", url, nil)
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+
+    client := models.Client{Name: "Metrics Owner", Email: "metrics@example.com", Phone: "600000011"}
+    db.Create(&client)
+    archived := models.Client{Name: "Archived", Email: "archived-metrics@example.com", Phone: "600000012"}
+    db.Create(&archived)
+    db.Delete(&archived)
+
+    pet := models.Pet{Name: "Luna", Species: "Cat", ClientID: client.ID}
+    db.Create(&pet)
+
+    now := time.Now()
+    db.Create(&[]models.Appointment{
+        // Pasadas: una completada y dos ausencias
+        {PetID: pet.ID, Date: now.AddDate(0, 0, -3), Reason: "Vacuna", Duration: 15, Completed: true},
+        {PetID: pet.ID, Date: now.AddDate(0, 0, -2), Reason: "Revisión", Duration: 30},
+        {PetID: pet.ID, Date: now.AddDate(0, 0, -1), Reason: "Control", Duration: 30},
+        // Fuera de la ventana de ausencias
+        {PetID: pet.ID, Date: now.AddDate(0, -3, 0), Reason: "Antigua", Duration: 30},
+        // Hoy
+        {PetID: pet.ID, Date: time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, now.Location()), Reason: "Hoy", Duration: 15, Completed: true},
+    })
+
+    t.Run("Request Metrics By Route Template", func(t *testing.T) {
+        petURL := "/api/v1/pets/" + strconv.FormatUint(uint64(pet.ID), 10)
+        get(petURL)
+        get(petURL)
+        get("/api/v1/pets/99999")
+        get("/no/existe")
+
+        body := get(metrics.Path).Body.String()
+        assert.Contains(t, body, `vetclinic_http_requests_total{method="GET",route="/api/v1/pets/:id",status="200"} 2`)
+        assert.Contains(t, body, `vetclinic_http_requests_total{method="GET",route="/api/v1/pets/:id",status="404"} 1`)
+        assert.Contains(t, body, `vetclinic_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+        assert.Contains(t, body, `vetclinic_http_request_duration_seconds_bucket{method="GET",route="/api/v1/pets/:id",status="200",le="+Inf"} 2`)
+    })
+
+    t.Run("Database Metrics", func(t *testing.T) {
+        body := get(metrics.Path).Body.String()
+        assert.Contains(t, body, `vetclinic_db_query_duration_seconds_count{operation="query",table="pets"}`)
+        assert.Contains(t, body, `vetclinic_db_query_duration_seconds_count{operation="create",table="appointments"}`)
+        assert.Contains(t, body, `go_sql_open_connections{db_name="sqlite"}`)
+    })
+
+    t.Run("Business Gauges", func(t *testing.T) {
+        // Si hoy es muy temprano, la cita de las 12:00 todavía no cuenta
+        // como pasada
+        past, noShows := 3.0, 2.0
+        if time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, now.Location()).Before(time.Now()) {
+            past++
+        }
+
+        count, err := testutil.GatherAndCount(appMetrics.Registry, "vetclinic_appointments_today")
+        assert.NoError(t, err)
+        assert.Equal(t, 1, count)
+
+        body := get(metrics.Path).Body.String()
+        assert.Contains(t, body, "vetclinic_appointments_today 1\n")
+        assert.Contains(t, body, "vetclinic_clients_active 1\n")
+        assert.Contains(t, body, "vetclinic_appointments_no_show_ratio "+strconv.FormatFloat(noShows/past, 'g', -1, 64)+"\n")
+        assert.Contains(t, body, "vetclinic_business_metrics_errors 0\n")
+    })
+}
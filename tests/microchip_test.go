@@ -0,0 +1,234 @@
+package tests
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/importer"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/ratelimit"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "github.com/javice/vet-clinic-api/internal/routes"
+    "github.com/javice/vet-clinic-api/internal/tenant"
+    "github.com/stretchr/testify/assert"
+    "gorm.io/gorm"
+)
+
+func TestMicrochip(t *testing.T) {
+    router, db, err := setupTestRouter()
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    request := func(method, url string, clinic uint, body interface{}) *httptest.ResponseRecorder {
+        var payload []byte
+        if body != nil {
+            payload, _ = json.Marshal(body)
+        }
+        req, _ := newRequest(method, url, bytes.NewBuffer(payload))
+        req.Header.Set("Content-Type", "application/json")
+        req.Header.Set("X-Admin-Token", testAdminToken)
+        if clinic != 0 {
+            req.Header.Set("X-Clinic-ID", strconv.Itoa(int(clinic)))
+        }
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+    decode := func(resp *httptest.ResponseRecorder, v interface{}) {
+        assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), v))
+    }
+    lookups := func() []models.AuditLog {
+        var logs []models.AuditLog
+        assert.NoError(t, db.Where("action = ?", models.AuditLookup).Order("id").Find(&logs).Error)
+        return logs
+    }
+
+    // Dos clínicas: el registro de microchips es común a ambas
+    assert.NoError(t, tenant.Backfill(db))
+    var main models.Clinic
+    assert.NoError(t, db.First(&main).Error)
+    var north models.Clinic
+    decode(request("POST", "/api/v1/admin/clinics", 0, map[string]interface{}{"name": "Sede Norte"}), &north)
+
+    var owner, neighbour models.Client
+    decode(request("POST", "/api/v1/clients", north.ID, map[string]interface{}{
+        "name": "Lucía Moreno", "email": "lucia@example.com", "phone": "611000111", "address": "Calle Mayor 1",
+    }), &owner)
+    decode(request("POST", "/api/v1/clients", main.ID, map[string]interface{}{
+        "name": "Andrés Gil", "email": "andres@example.com", "phone": "622000222",
+    }), &neighbour)
+
+    var luna models.Pet
+    t.Run("Validate And Normalize", func(t *testing.T) {
+        for _, chip := range []string{"12345", "72409810012345A", "000000000000000", "9991234567890123"} {
+            resp := request("POST", "/api/v1/pets", north.ID, map[string]interface{}{
+                "name": "Luna", "species": "Cat", "client_id": owner.ID, "microchip": chip,
+            })
+            assert.Equal(t, http.StatusBadRequest, resp.Code, chip)
+            assert.Contains(t, resp.Body.String(), `"field":"microchip"`)
+        }
+
+        resp := request("POST", "/api/v1/pets", north.ID, map[string]interface{}{
+            "name": "Luna", "species": "Cat", "client_id": owner.ID, "microchip": "724 098-100.123 456",
+        })
+        if assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String()) {
+            decode(resp, &luna)
+            assert.Equal(t, "724098100123456", luna.Microchip)
+        }
+
+        // Sin microchip no hay conflicto entre mascotas
+        for _, name := range []string{"Toby", "Kira"} {
+            resp := request("POST", "/api/v1/pets", main.ID, map[string]interface{}{"name": name, "species": "Dog", "client_id": neighbour.ID})
+            assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+        }
+    })
+
+    t.Run("Unique Across Clinics", func(t *testing.T) {
+        resp := request("POST", "/api/v1/pets", main.ID, map[string]interface{}{
+            "name": "Otra", "species": "Dog", "client_id": neighbour.ID, "microchip": "724098100123456",
+        })
+        assert.Equal(t, http.StatusConflict, resp.Code)
+
+        var rex models.Pet
+        decode(request("POST", "/api/v1/pets", main.ID, map[string]interface{}{
+            "name": "Rex", "species": "Dog", "client_id": neighbour.ID, "microchip": "941000012345678",
+        }), &rex)
+        url := "/api/v1/pets/" + strconv.Itoa(int(rex.ID))
+        req, _ := newRequest("PATCH", url, bytes.NewBufferString(`{"microchip": "724098100123456"}`))
+        req.Header.Set("Content-Type", "application/merge-patch+json")
+        req.Header.Set("X-Clinic-ID", strconv.Itoa(int(main.ID)))
+        req.Header.Set("If-Match", etagFor(rex.Version))
+        patch := httptest.NewRecorder()
+        router.ServeHTTP(patch, req)
+        assert.Equal(t, http.StatusConflict, patch.Code)
+
+        // Un microchip archivado sigue sin poder reutilizarse
+        assert.NoError(t, db.Delete(&rex).Error)
+        resp = request("POST", "/api/v1/pets", main.ID, map[string]interface{}{
+            "name": "Rex II", "species": "Dog", "client_id": neighbour.ID, "microchip": "941000012345678",
+        })
+        assert.Equal(t, http.StatusConflict, resp.Code)
+    })
+
+    t.Run("Lookup", func(t *testing.T) {
+        // Desde otra clínica se encuentra al dueño, con lo mínimo para
+        // contactarle
+        resp := request("GET", "/api/v1/pets/lookup?chip=724-098-100-123-456", main.ID, nil)
+        if assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+            var found map[string]interface{}
+            decode(resp, &found)
+            assert.Equal(t, map[string]interface{}{
+                "pet_id": float64(luna.ID), "name": "Luna", "species": "Cat", "breed": "",
+                "owner_name": "Lucía Moreno", "phone": "611000111", "email": "lucia@example.com",
+            }, found)
+        }
+
+        assert.Equal(t, http.StatusNotFound, request("GET", "/api/v1/pets/lookup?chip=724098100999999", main.ID, nil).Code)
+        assert.Equal(t, http.StatusBadRequest, request("GET", "/api/v1/pets/lookup?chip=1234", main.ID, nil).Code)
+        assert.Equal(t, http.StatusBadRequest, request("GET", "/api/v1/pets/lookup", main.ID, nil).Code)
+        assert.Equal(t, http.StatusBadRequest, request("GET", "/api/v1/pets/lookup?chip=724098100123456&tattoo=ABC", main.ID, nil).Code)
+
+        // Las búsquedas, con y sin resultado, quedan en la auditoría
+        logs := lookups()
+        if assert.Len(t, logs, 2) {
+            assert.Equal(t, luna.ID, logs[0].EntityID)
+            assert.JSONEq(t, `{"microchip": {"before": null, "after": "724098100123456"}, "found": {"before": null, "after": true}}`, string(logs[0].Changes))
+            assert.Equal(t, uint(0), logs[1].EntityID)
+            assert.JSONEq(t, `{"microchip": {"before": null, "after": "724098100999999"}, "found": {"before": null, "after": false}}`, string(logs[1].Changes))
+        }
+    })
+
+    t.Run("Tattoos And License Tags", func(t *testing.T) {
+        url := "/api/v1/pets/" + strconv.Itoa(int(luna.ID)) + "/identifiers"
+        resp := request("POST", url, north.ID, map[string]interface{}{"kind": "tattoo", "value": " abc  123 "})
+        if !assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String()) {
+            return
+        }
+        var tattoo models.PetIdentifier
+        decode(resp, &tattoo)
+        assert.Equal(t, "ABC 123", tattoo.Value)
+
+        assert.Equal(t, http.StatusCreated, request("POST", url, north.ID, map[string]interface{}{"kind": "license_tag", "value": "M-2024-881", "issuer": "Ayto. Madrid"}).Code)
+        assert.Equal(t, http.StatusConflict, request("POST", url, north.ID, map[string]interface{}{"kind": "tattoo", "value": "abc 123"}).Code)
+        assert.Equal(t, http.StatusBadRequest, request("POST", url, north.ID, map[string]interface{}{"kind": "collar", "value": "X"}).Code)
+        assert.Equal(t, http.StatusNotFound, request("POST", "/api/v1/pets/999/identifiers", north.ID, map[string]interface{}{"kind": "tattoo", "value": "X"}).Code)
+        // Los identificadores de una mascota no se ven desde otra clínica
+        assert.Equal(t, http.StatusNotFound, request("GET", url, main.ID, nil).Code)
+
+        var identifiers []models.PetIdentifier
+        decode(request("GET", url, north.ID, nil), &identifiers)
+        assert.Len(t, identifiers, 2)
+
+        var pet models.Pet
+        decode(request("GET", "/api/v1/pets/"+strconv.Itoa(int(luna.ID))+"?include=identifiers", north.ID, nil), &pet)
+        assert.Len(t, pet.Identifiers, 2)
+
+        resp = request("GET", "/api/v1/pets/lookup?license_tag=m-2024-881", main.ID, nil)
+        if assert.Equal(t, http.StatusOK, resp.Code) {
+            var found repositories.PetLookup
+            decode(resp, &found)
+            assert.Equal(t, "Lucía Moreno", found.OwnerName)
+        }
+        assert.Equal(t, http.StatusNotFound, request("GET", "/api/v1/pets/lookup?tattoo=M-2024-881", main.ID, nil).Code)
+
+        tattooURL := url + "/" + strconv.Itoa(int(tattoo.ID))
+        assert.Equal(t, http.StatusNotFound, request("DELETE", tattooURL, main.ID, nil).Code)
+        assert.Equal(t, http.StatusOK, request("DELETE", tattooURL, north.ID, nil).Code)
+        assert.Equal(t, http.StatusNotFound, request("GET", "/api/v1/pets/lookup?tattoo=abc 123", main.ID, nil).Code)
+        assert.Len(t, lookups(), 5)
+    })
+
+    t.Run("Import", func(t *testing.T) {
+        csv := "name,species,microchip,client_email\n" +
+            "Nala,Cat,724 098 100 555 555,andres@example.com\n" +
+            "Simba,Cat,724098100555555,andres@example.com\n" +
+            "Coco,Dog,724098100123456,andres@example.com\n" +
+            "Bimba,Dog,72409810,andres@example.com\n"
+        report, err := importer.Run(db, strings.NewReader(csv), importer.Options{Format: importer.FormatCSV, Kind: importer.KindPets})
+        assert.ErrorIs(t, err, importer.ErrInvalidRows)
+        assert.Equal(t, 1, report.Valid)
+        assert.Contains(t, report.Errors, importer.RowError{Row: 3, Field: "microchip", Message: "microchip repetido en la fila 2"})
+        assert.Contains(t, report.Errors, importer.RowError{Row: 4, Field: "microchip", Message: "ya existe una mascota con este microchip"})
+        assert.Contains(t, report.Errors, importer.RowError{Row: 5, Field: "microchip", Message: "microchip inválido; debe tener 15 dígitos"})
+    })
+}
+
+func TestLookupRateLimit(t *testing.T) {
+    router, db, err := setupTestRouterWith(func(db *gorm.DB, opts *routes.Options) error {
+        opts.RateLimitStore = ratelimit.NewMemoryStore()
+        opts.RateLimits = map[string]ratelimit.Limit{
+            "":                  {Requests: 100, Period: time.Minute},
+            routes.LookupPrefix: {Requests: 2, Period: time.Minute},
+        }
+        return nil
+    })
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    get := func(url string) *httptest.ResponseRecorder {
+        req, _ := newRequest("GET", url, nil)
+        req.RemoteAddr = "10.0.0.7:1234"
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+
+    for i := 0; i < 2; i++ {
+        assert.Equal(t, http.StatusNotFound, get("/api/v1/pets/lookup?chip=724098100123456").Code)
+    }
+    assert.Equal(t, http.StatusTooManyRequests, get("/api/v1/pets/lookup?chip=724098100123457").Code)
+    // El resto de la API tiene su propio límite
+    assert.Equal(t, http.StatusOK, get("/api/v1/pets").Code)
+
+    var count int64
+    db.Model(&models.AuditLog{}).Where("action = ?", models.AuditLookup).Count(&count)
+    assert.Equal(t, int64(2), count)
+}
@@ -0,0 +1,133 @@
+package tests
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "testing"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestPatchEndpoints(t *testing.T) {
+    router, db, err := setupTestRouter()
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    patch := func(url, contentType, body string, version uint) *httptest.ResponseRecorder {
+        req, _ := newRequest("PATCH", url, bytes.NewBufferString(body))
+        req.Header.Set("Content-Type", contentType)
+        req.Header.Set("If-Match", etagFor(version))
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+
+    client := models.Client{Name: "Patch Owner", Email: "patch@example.com", Phone: "600000004", Address: "Calle Mayor 1"}
+    db.Create(&client)
+    birthDate := time.Date(2020, 5, 17, 0, 0, 0, 0, time.UTC)
+    pet := models.Pet{Name: "Nala", Species: "Cat", Breed: "Siamese", BirthDate: birthDate, Weight: 4.2, ClientID: client.ID, Description: "Tímida"}
+    db.Create(&pet)
+
+    clientURL := "/api/v1/clients/" + strconv.FormatUint(uint64(client.ID), 10)
+    petURL := "/api/v1/pets/" + strconv.FormatUint(uint64(pet.ID), 10)
+
+    t.Run("Merge Patch Keeps Omitted Fields", func(t *testing.T) {
+        resp := patch(petURL, "application/merge-patch+json", `{"weight": 4.8}`, 1)
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.Equal(t, etagFor(2), resp.Header().Get("ETag"))
+
+        var updated models.Pet
+        assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &updated))
+        assert.Equal(t, 4.8, updated.Weight)
+        assert.Equal(t, "Nala", updated.Name)
+        assert.Equal(t, "Siamese", updated.Breed)
+        assert.Equal(t, "Tímida", updated.Description)
+        assert.True(t, birthDate.Equal(updated.BirthDate))
+    })
+
+    t.Run("Merge Patch Null Clears Field", func(t *testing.T) {
+        resp := patch(clientURL, "application/merge-patch+json", `{"address": null}`, 1)
+        assert.Equal(t, http.StatusOK, resp.Code)
+
+        var stored models.Client
+        db.First(&stored, client.ID)
+        assert.Equal(t, "", stored.Address)
+        assert.Equal(t, "Patch Owner", stored.Name)
+    })
+
+    t.Run("Plain JSON Is Merge Patch", func(t *testing.T) {
+        resp := patch(clientURL, "application/json", `{"phone": "611111111"}`, 2)
+        assert.Equal(t, http.StatusOK, resp.Code)
+
+        var stored models.Client
+        db.First(&stored, client.ID)
+        assert.Equal(t, "611111111", stored.Phone)
+        assert.Equal(t, "patch@example.com", stored.Email)
+    })
+
+    t.Run("JSON Patch", func(t *testing.T) {
+        body := `[
+            {"op": "test", "path": "/name", "value": "Nala"},
+            {"op": "replace", "path": "/description", "value": "Sociable"},
+            {"op": "remove", "path": "/breed"}
+        ]`
+        resp := patch(petURL, "application/json-patch+json", body, 2)
+        assert.Equal(t, http.StatusOK, resp.Code)
+
+        var stored models.Pet
+        db.First(&stored, pet.ID)
+        assert.Equal(t, "Sociable", stored.Description)
+        assert.Equal(t, "", stored.Breed)
+        assert.Equal(t, 4.8, stored.Weight)
+        assert.Equal(t, uint(3), stored.Version)
+    })
+
+    t.Run("JSON Patch Test Failure", func(t *testing.T) {
+        body := `[{"op": "test", "path": "/name", "value": "Otra"}, {"op": "replace", "path": "/name", "value": "Luna"}]`
+        assert.Equal(t, http.StatusConflict, patch(petURL, "application/json-patch+json", body, 3).Code)
+    })
+
+    t.Run("Revalidate Result", func(t *testing.T) {
+        resp := patch(petURL, "application/merge-patch+json", `{"name": null}`, 3)
+        assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+        resp = patch(clientURL, "application/json-patch+json", `[{"op": "replace", "path": "/email", "value": "no-es-un-email"}]`, 3)
+        assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+        var stored models.Pet
+        db.First(&stored, pet.ID)
+        assert.Equal(t, "Nala", stored.Name)
+    })
+
+    t.Run("Invalid Patch", func(t *testing.T) {
+        assert.Equal(t, http.StatusBadRequest, patch(petURL, "application/json-patch+json", `{"op": "replace"}`, 3).Code)
+        assert.Equal(t, http.StatusBadRequest, patch(petURL, "application/json-patch+json", `[{"op": "replace", "path": "/noexiste/x", "value": 1}]`, 3).Code)
+        assert.Equal(t, http.StatusUnsupportedMediaType, patch(petURL, "text/plain", `weight=5`, 3).Code)
+    })
+
+    t.Run("Preconditions", func(t *testing.T) {
+        assert.Equal(t, http.StatusPreconditionFailed, patch(petURL, "application/merge-patch+json", `{"weight": 5}`, 1).Code)
+        assert.Equal(t, http.StatusNotFound, patch("/api/v1/pets/9999", "application/merge-patch+json", `{"weight": 5}`, 1).Code)
+    })
+
+    t.Run("Appointment", func(t *testing.T) {
+        appointment := models.Appointment{PetID: pet.ID, Date: time.Now().Add(24 * time.Hour), Reason: "Revisión", Notes: "Traer cartilla", Duration: 30}
+        db.Create(&appointment)
+        url := "/api/v1/appointments/" + strconv.FormatUint(uint64(appointment.ID), 10)
+
+        resp := patch(url, "application/merge-patch+json", `{"completed": true}`, 1)
+        assert.Equal(t, http.StatusOK, resp.Code)
+
+        var stored models.Appointment
+        db.First(&stored, appointment.ID)
+        assert.True(t, stored.Completed)
+        assert.Equal(t, "Traer cartilla", stored.Notes)
+        assert.Equal(t, 30, stored.Duration)
+    })
+}
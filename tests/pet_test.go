@@ -44,7 +44,7 @@ func TestPetEndpoints(t *testing.T) {
         }
 
         jsonData, _ := json.Marshal(petData)
-        req, _ := http.NewRequest("POST", "/api/v1/pets", bytes.NewBuffer(jsonData))
+        req, _ := newRequest("POST", "/api/v1/pets", bytes.NewBuffer(jsonData))
         req.Header.Set("Content-Type", "application/json")
 
         resp := httptest.NewRecorder()
@@ -62,7 +62,7 @@ func TestPetEndpoints(t *testing.T) {
 
     // Test GET /pets
     t.Run("Get All Pets", func(t *testing.T) {
-        req, _ := http.NewRequest("GET", "/api/v1/pets", nil)
+        req, _ := newRequest("GET", "/api/v1/pets", nil)
         resp := httptest.NewRecorder()
         router.ServeHTTP(resp, req)
 
@@ -80,7 +80,7 @@ func TestPetEndpoints(t *testing.T) {
         var pet models.Pet
         db.First(&pet)
 
-        req, _ := http.NewRequest("GET", "/api/v1/pets/"+strconv.FormatUint(uint64(pet.ID), 10), nil)
+        req, _ := newRequest("GET", "/api/v1/pets/"+strconv.FormatUint(uint64(pet.ID), 10), nil)
         resp := httptest.NewRecorder()
         router.ServeHTTP(resp, req)
 
@@ -96,7 +96,7 @@ func TestPetEndpoints(t *testing.T) {
 
     // Test GET /pets?client_id=X
     t.Run("Get Pets By Client ID", func(t *testing.T) {
-        req, _ := http.NewRequest("GET", "/api/v1/pets?client_id="+strconv.FormatUint(uint64(client.ID), 10), nil)
+        req, _ := newRequest("GET", "/api/v1/pets?client_id="+strconv.FormatUint(uint64(client.ID), 10), nil)
         resp := httptest.NewRecorder()
         router.ServeHTTP(resp, req)
 
@@ -121,14 +121,15 @@ func TestPetEndpoints(t *testing.T) {
             updateData := models.Pet{
                 Name:        "Updated Pet Name",
                 Species:     pet.Species,
-                Breed:       "Updated Breed",
+                Breed:       "Labrador Retriever",
                 ClientID:    pet.ClientID,
                 Description: "Updated description",
             }
 
             jsonData, _ := json.Marshal(updateData)
-            req, _ := http.NewRequest("PUT", "/api/v1/pets/"+strconv.FormatUint(uint64(pet.ID), 10), bytes.NewBuffer(jsonData))
+            req, _ := newRequest("PUT", "/api/v1/pets/"+strconv.FormatUint(uint64(pet.ID), 10), bytes.NewBuffer(jsonData))
             req.Header.Set("Content-Type", "application/json")
+            req.Header.Set("If-Match", etagFor(pet.Version))
 
             resp := httptest.NewRecorder()
             router.ServeHTTP(resp, req)
@@ -139,7 +140,7 @@ func TestPetEndpoints(t *testing.T) {
             err := json.Unmarshal(resp.Body.Bytes(), &updatedPet)
             assert.NoError(t, err)
             assert.Equal(t, "Updated Pet Name", updatedPet.Name)
-            assert.Equal(t, "Updated Breed", updatedPet.Breed)
+            assert.Equal(t, "Labrador Retriever", updatedPet.Breed)
         }
     })
 
@@ -156,7 +157,8 @@ func TestPetEndpoints(t *testing.T) {
 		}
 		db.Create(&pet)
 
-		req, _ := http.NewRequest("DELETE", "/api/v1/pets/"+strconv.FormatUint(uint64(pet.ID), 10), nil)
+		req, _ := newRequest("DELETE", "/api/v1/pets/"+strconv.FormatUint(uint64(pet.ID), 10), nil)
+		req.Header.Set("If-Match", etagFor(pet.Version))
 		resp := httptest.NewRecorder()
 		router.ServeHTTP(resp, req)
 
@@ -0,0 +1,127 @@
+package tests
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/javice/vet-clinic-api/internal/middleware"
+    "github.com/javice/vet-clinic-api/internal/ratelimit"
+    "github.com/javice/vet-clinic-api/internal/routes"
+    "github.com/stretchr/testify/assert"
+    "gorm.io/gorm"
+)
+
+// failingStore simula un almacén compartido que no responde
+type failingStore struct{}
+
+func (failingStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+    return ratelimit.Result{}, errors.New("almacén no disponible")
+}
+
+func TestRateLimit(t *testing.T) {
+    now := time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC)
+    store := ratelimit.NewMemoryStore()
+    store.Now = func() time.Time { return now }
+
+    router, _, err := setupTestRouterWith(func(db *gorm.DB, opts *routes.Options) error {
+        opts.RateLimitStore = store
+        opts.RateLimits = map[string]ratelimit.Limit{
+            "":                 {Requests: 3, Period: time.Minute},
+            routes.AdminPrefix: {Requests: 1, Period: time.Minute},
+        }
+        return nil
+    })
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    request := func(method, url, ip string) *httptest.ResponseRecorder {
+        req, _ := newRequest(method, url, nil)
+        req.RemoteAddr = ip + ":1234"
+        req.Header.Set("X-Admin-Token", testAdminToken)
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+
+    t.Run("Headers And 429", func(t *testing.T) {
+        for i := 2; i >= 0; i-- {
+            resp := request("GET", "/api/v1/clients", "10.0.0.1")
+            assert.Equal(t, http.StatusOK, resp.Code)
+            assert.Equal(t, "3", resp.Header().Get("RateLimit-Limit"))
+            assert.Equal(t, "3;w=60", resp.Header().Get("RateLimit-Policy"))
+            assert.Equal(t, strconv.Itoa(i), resp.Header().Get("RateLimit-Remaining"))
+        }
+
+        resp := request("GET", "/api/v1/clients", "10.0.0.1")
+        assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+        assert.Equal(t, "0", resp.Header().Get("RateLimit-Remaining"))
+        // Se recarga una ficha cada 20 segundos
+        assert.Equal(t, "20", resp.Header().Get("Retry-After"))
+        assert.Equal(t, "60", resp.Header().Get("RateLimit-Reset"))
+        assert.Contains(t, resp.Body.String(), "Demasiadas peticiones")
+    })
+
+    t.Run("Per Client", func(t *testing.T) {
+        assert.Equal(t, http.StatusOK, request("GET", "/api/v1/clients", "10.0.0.2").Code)
+    })
+
+    t.Run("Refill", func(t *testing.T) {
+        now = now.Add(20 * time.Second)
+        resp := request("GET", "/api/v1/clients", "10.0.0.1")
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.Equal(t, "0", resp.Header().Get("RateLimit-Remaining"))
+        assert.Equal(t, http.StatusTooManyRequests, request("GET", "/api/v1/clients", "10.0.0.1").Code)
+    })
+
+    t.Run("Stricter Group Limit", func(t *testing.T) {
+        resp := request("POST", "/api/v1/admin/purge", "10.0.0.3")
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.Equal(t, "1", resp.Header().Get("RateLimit-Limit"))
+
+        resp = request("POST", "/api/v1/admin/purge", "10.0.0.3")
+        assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+        assert.Equal(t, "60", resp.Header().Get("Retry-After"))
+
+        // Cada grupo tiene sus propios cubos
+        assert.Equal(t, http.StatusOK, request("GET", "/api/v1/clients", "10.0.0.3").Code)
+    })
+
+    t.Run("Preflight And Health Not Limited", func(t *testing.T) {
+        for i := 0; i < 5; i++ {
+            assert.Equal(t, http.StatusNoContent, request("OPTIONS", "/api/v1/clients", "10.0.0.1").Code)
+            assert.Equal(t, http.StatusOK, request("GET", "/healthz", "10.0.0.1").Code)
+        }
+    })
+
+    t.Run("Subject Key", func(t *testing.T) {
+        c, _ := gin.CreateTestContext(httptest.NewRecorder())
+        c.Request = httptest.NewRequest("GET", "/", nil)
+        assert.Equal(t, "ip:192.0.2.1", middleware.ClientKey(c))
+        middleware.SetRateLimitSubject(c, "apikey:42")
+        assert.Equal(t, "apikey:42", middleware.ClientKey(c))
+    })
+
+    t.Run("Store Failure Lets Requests Through", func(t *testing.T) {
+        router, _, err := setupTestRouterWith(func(db *gorm.DB, opts *routes.Options) error {
+            opts.RateLimitStore = failingStore{}
+            opts.RateLimits = map[string]ratelimit.Limit{"": {Requests: 1, Period: time.Minute}}
+            return nil
+        })
+        if !assert.NoError(t, err) {
+            return
+        }
+
+        req, _ := newRequest("GET", "/api/v1/clients", nil)
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        assert.Equal(t, http.StatusOK, resp.Code)
+        assert.Empty(t, resp.Header().Get("RateLimit-Limit"))
+    })
+}
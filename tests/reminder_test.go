@@ -0,0 +1,259 @@
+package tests
+
+import (
+    "bufio"
+    "bytes"
+    "context"
+    "encoding/json"
+    "net"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/reminders"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestReminderScheduler(t *testing.T) {
+    db, err := setupTestDB()
+    if err != nil {
+        t.Fatalf("Error inicializando la base de datos: %v", err)
+    }
+
+    now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+    client := models.Client{Name: "Ana López", Email: "ana@example.com", Phone: "600111222"}
+    db.Create(&client)
+    pet := models.Pet{Name: "Luna", Species: "Cat", ClientID: client.ID}
+    db.Create(&pet)
+
+    soon := models.Appointment{PetID: pet.ID, Date: now.Add(90 * time.Minute), Reason: "Vacuna", Duration: 15}
+    tomorrow := models.Appointment{PetID: pet.ID, Date: now.Add(20 * time.Hour), Reason: "Revisión", Duration: 30}
+    later := models.Appointment{PetID: pet.ID, Date: now.Add(72 * time.Hour), Reason: "Control", Duration: 30}
+    done := models.Appointment{PetID: pet.ID, Date: now.Add(time.Hour), Reason: "Cura", Duration: 15, Completed: true}
+    db.Create(&soon)
+    db.Create(&tomorrow)
+    db.Create(&later)
+    db.Create(&done)
+
+    // Las citas archivadas, o de clientes archivados, no tienen recordatorio
+    archived := models.Appointment{PetID: pet.ID, Date: now.Add(80 * time.Minute), Reason: "Anulada", Duration: 15}
+    db.Create(&archived)
+    db.Delete(&archived)
+    former := models.Client{Name: "Pablo Ruiz", Email: "pablo@example.com", Phone: "600333444"}
+    db.Create(&former)
+    formerPet := models.Pet{Name: "Coco", Species: "Dog", ClientID: former.ID}
+    db.Create(&formerPet)
+    orphan := models.Appointment{PetID: formerPet.ID, Date: now.Add(100 * time.Minute), Reason: "Vacuna", Duration: 15}
+    db.Create(&orphan)
+    db.Delete(&former)
+
+    var out bytes.Buffer
+    provider := reminders.NewLogProvider(&out)
+    repo := repositories.NewReminderRepository(db)
+
+    clock := now
+    scheduler := reminders.NewScheduler(repo, map[string]reminders.Provider{
+        reminders.ChannelEmail: provider,
+        reminders.ChannelSMS:   provider,
+    }, reminders.Config{Now: func() time.Time { return clock }})
+
+    readMessages := func() []reminders.Message {
+        var messages []reminders.Message
+        scanner := bufio.NewScanner(bytes.NewReader(out.Bytes()))
+        for scanner.Scan() {
+            var msg reminders.Message
+            assert.NoError(t, json.Unmarshal(scanner.Bytes(), &msg))
+            messages = append(messages, msg)
+        }
+        return messages
+    }
+
+    t.Run("Send Due Reminders", func(t *testing.T) {
+        assert.NoError(t, scheduler.RunOnce(context.Background()))
+
+        messages := readMessages()
+        // Email y SMS para las dos citas próximas
+        assert.Len(t, messages, 4)
+
+        for _, msg := range messages {
+            if msg.Channel == reminders.ChannelEmail {
+                assert.Equal(t, client.Email, msg.To)
+                assert.Contains(t, msg.Subject, "Luna")
+            } else {
+                assert.Equal(t, client.Phone, msg.To)
+            }
+        }
+
+        sent, err := repo.GetByAppointmentID(soon.ID)
+        assert.NoError(t, err)
+        assert.Len(t, sent, 2)
+        for _, reminder := range sent {
+            assert.Equal(t, models.ReminderSent, reminder.Status)
+            assert.Equal(t, (2 * time.Hour).String(), reminder.LeadTime)
+        }
+
+        for _, id := range []uint{archived.ID, orphan.ID} {
+            sent, err = repo.GetByAppointmentID(id)
+            assert.NoError(t, err)
+            assert.Empty(t, sent)
+        }
+    })
+
+    t.Run("Send Each Reminder Once", func(t *testing.T) {
+        assert.NoError(t, scheduler.RunOnce(context.Background()))
+        assert.Len(t, readMessages(), 4)
+    })
+
+    t.Run("Send Next Lead Time", func(t *testing.T) {
+        // A menos de 2h de la cita de mañana corresponde el segundo aviso
+        clock = now.Add(19 * time.Hour)
+        assert.NoError(t, scheduler.RunOnce(context.Background()))
+        assert.Len(t, readMessages(), 6)
+
+        sent, err := repo.GetByAppointmentID(tomorrow.ID)
+        assert.NoError(t, err)
+        assert.Len(t, sent, 4)
+    })
+
+    t.Run("Rescheduled Appointment Is Reminded Again", func(t *testing.T) {
+        var moved models.Appointment
+        db.First(&moved, tomorrow.ID)
+        moved.Date = clock.Add(90 * time.Minute)
+        assert.NoError(t, repositories.NewAppointmentRepository(db).Update(&moved, moved.Version))
+
+        sent, err := repo.GetByAppointmentID(tomorrow.ID)
+        assert.NoError(t, err)
+        assert.Empty(t, sent)
+
+        assert.NoError(t, scheduler.RunOnce(context.Background()))
+        assert.Len(t, readMessages(), 8)
+    })
+
+    t.Run("Stale Claim Is Retried", func(t *testing.T) {
+        reminder := models.Reminder{AppointmentID: later.ID, LeadTime: "24h0m0s", Channel: reminders.ChannelEmail}
+        claimed, err := repo.Claim(&reminder, 1, time.Now().Add(-time.Minute))
+        assert.NoError(t, err)
+        assert.True(t, claimed)
+
+        // Mientras la reserva es reciente nadie más la toma
+        again := reminder
+        claimed, err = repo.Claim(&again, 1, time.Now().Add(-time.Minute))
+        assert.NoError(t, err)
+        assert.False(t, claimed)
+
+        // El proceso que la reservó se detuvo: pasado el plazo se reintenta y
+        // cuenta como un intento
+        db.Model(&reminder).UpdateColumn("updated_at", time.Now().Add(-time.Hour))
+        claimed, err = repo.Claim(&again, 1, time.Now().Add(-time.Minute))
+        assert.NoError(t, err)
+        assert.True(t, claimed)
+        assert.Equal(t, 1, again.Attempts)
+
+        db.Model(&reminder).UpdateColumn("updated_at", time.Now().Add(-time.Hour))
+        claimed, err = repo.Claim(&again, 1, time.Now().Add(-time.Minute))
+        assert.NoError(t, err)
+        assert.False(t, claimed)
+    })
+}
+
+// fakeSMTP atiende una conversación SMTP mínima y devuelve por el canal el
+// contenido del mensaje recibido.
+func fakeSMTP(t *testing.T) (net.Listener, <-chan string) {
+    ln, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatalf("Error abriendo el servidor SMTP: %v", err)
+    }
+    data := make(chan string, 1)
+    go func() {
+        conn, err := ln.Accept()
+        if err != nil {
+            return
+        }
+        defer conn.Close()
+        r := bufio.NewReader(conn)
+        conn.Write([]byte("220 localhost\r\n"))
+        for {
+            line, err := r.ReadString('\n')
+            if err != nil {
+                return
+            }
+            switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+            case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"),
+                strings.HasPrefix(cmd, "MAIL"), strings.HasPrefix(cmd, "RCPT"):
+                conn.Write([]byte("250 OK\r\n"))
+            case cmd == "DATA":
+                conn.Write([]byte("354 Adelante\r\n"))
+                var msg strings.Builder
+                for {
+                    line, err := r.ReadString('\n')
+                    if err != nil || line == ".\r\n" {
+                        break
+                    }
+                    msg.WriteString(line)
+                }
+                data <- msg.String()
+                conn.Write([]byte("250 OK\r\n"))
+            case cmd == "QUIT":
+                conn.Write([]byte("221 Adiós\r\n"))
+                return
+            default:
+                conn.Write([]byte("500 Desconocido\r\n"))
+            }
+        }
+    }()
+    return ln, data
+}
+
+func TestSMTPProvider(t *testing.T) {
+    t.Run("No Header Injection", func(t *testing.T) {
+        ln, data := fakeSMTP(t)
+        defer ln.Close()
+        host, port, _ := net.SplitHostPort(ln.Addr().String())
+        provider := &reminders.SMTPProvider{Host: host, Port: port, From: "clinica@example.com"}
+
+        err := provider.Send(context.Background(), reminders.Message{
+            Channel: reminders.ChannelEmail,
+            To:      "ana@example.com",
+            Subject: "Recordatorio: cita de Toby\r\nBcc: intruso@example.com",
+            Body:    "Hola",
+        })
+        if !assert.NoError(t, err) {
+            return
+        }
+        msg := <-data
+        headers := msg[:strings.Index(msg, "\r\n\r\n")]
+        assert.NotContains(t, headers, "\r\nBcc:")
+        assert.Contains(t, headers, "Subject: Recordatorio: cita de Toby Bcc: intruso@example.com\r\n")
+
+        err = provider.Send(context.Background(), reminders.Message{To: "ana@example.com\r\nBcc: intruso@example.com", Body: "Hola"})
+        assert.Error(t, err)
+    })
+
+    t.Run("Context Cancels Send", func(t *testing.T) {
+        // Un servidor que acepta la conexión y nunca saluda
+        ln, err := net.Listen("tcp", "127.0.0.1:0")
+        if err != nil {
+            t.Fatalf("Error abriendo el servidor SMTP: %v", err)
+        }
+        defer ln.Close()
+        go func() {
+            conn, err := ln.Accept()
+            if err == nil {
+                defer conn.Close()
+                time.Sleep(5 * time.Second)
+            }
+        }()
+        host, port, _ := net.SplitHostPort(ln.Addr().String())
+        provider := &reminders.SMTPProvider{Host: host, Port: port, From: "clinica@example.com"}
+
+        ctx, cancel := context.WithCancel(context.Background())
+        time.AfterFunc(100*time.Millisecond, cancel)
+        start := time.Now()
+        err = provider.Send(ctx, reminders.Message{To: "ana@example.com", Subject: "Hola", Body: "Hola"})
+        assert.ErrorIs(t, err, context.Canceled)
+        assert.Less(t, time.Since(start), 2*time.Second)
+    })
+}
@@ -0,0 +1,217 @@
+package tests
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "testing"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestResourceBooking(t *testing.T) {
+    router, db, err := setupTestRouter()
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    request := func(method, url string, body interface{}, headers ...string) *httptest.ResponseRecorder {
+        var payload []byte
+        if body != nil {
+            payload, _ = json.Marshal(body)
+        }
+        req, _ := newRequest(method, url, bytes.NewBuffer(payload))
+        req.Header.Set("Content-Type", "application/json")
+        for i := 0; i+1 < len(headers); i += 2 {
+            req.Header.Set(headers[i], headers[i+1])
+        }
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+    create := func(url string, body interface{}) uint {
+        resp := request("POST", url, body)
+        if !assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String()) {
+            t.FailNow()
+        }
+        var created struct {
+            ID uint `json:"id"`
+        }
+        json.Unmarshal(resp.Body.Bytes(), &created)
+        return created.ID
+    }
+
+    client := models.Client{Name: "Reserva", Email: "reserva@example.com", Phone: "600111222"}
+    assert.NoError(t, db.Create(&client).Error)
+    pet := models.Pet{Name: "Toby", Species: "Dog", ClientID: client.ID}
+    assert.NoError(t, db.Create(&pet).Error)
+
+    // Un día futuro para que las citas cuenten como pendientes
+    day := time.Now().AddDate(0, 0, 7)
+    at := func(hour, minute int) time.Time {
+        return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, time.Local)
+    }
+
+    var ana, luis, theatre, xray, anesthesia uint
+    t.Run("Create Vets And Resources", func(t *testing.T) {
+        ana = create("/api/v1/vets", map[string]interface{}{"name": "Ana"})
+        luis = create("/api/v1/vets", map[string]interface{}{"name": "Luis"})
+        theatre = create("/api/v1/resources", map[string]interface{}{"name": "Quirófano", "kind": "room"})
+        xray = create("/api/v1/resources", map[string]interface{}{"name": "Rayos X", "kind": "room"})
+        anesthesia = create("/api/v1/resources", map[string]interface{}{"name": "Máquina de anestesia", "kind": "equipment"})
+
+        resp := request("POST", "/api/v1/resources", map[string]interface{}{"name": "Camilla", "kind": "furniture"})
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+        resp = request("GET", "/api/v1/resources?kind=room", nil)
+        var rooms []models.Resource
+        json.Unmarshal(resp.Body.Bytes(), &rooms)
+        assert.Len(t, rooms, 2)
+    })
+
+    appointment := func(vet uint, start time.Time, duration int, resources ...uint) map[string]interface{} {
+        body := map[string]interface{}{
+            "pet_id":       pet.ID,
+            "date":         start,
+            "duration":     duration,
+            "reason":       "Cirugía",
+            "resource_ids": resources,
+        }
+        if vet != 0 {
+            body["vet_id"] = vet
+        }
+        return body
+    }
+
+    var surgery uint
+    t.Run("Book Resources", func(t *testing.T) {
+        surgery = create("/api/v1/appointments", appointment(ana, at(10, 0), 120, theatre, anesthesia, theatre))
+
+        var stored models.Appointment
+        assert.NoError(t, db.First(&stored, surgery).Error)
+        assert.Equal(t, models.IDs{theatre, anesthesia}, stored.ResourceIDs)
+        assert.Equal(t, ana, *stored.VetID)
+    })
+
+    t.Run("Resource Conflict", func(t *testing.T) {
+        resp := request("POST", "/api/v1/appointments", appointment(luis, at(11, 30), 30, anesthesia))
+        assert.Equal(t, http.StatusConflict, resp.Code)
+
+        var body struct {
+            Conflicts []struct {
+                AppointmentID uint   `json:"appointment_id"`
+                VetID         *uint  `json:"vet_id"`
+                ResourceIDs   []uint `json:"resource_ids"`
+            } `json:"conflicts"`
+        }
+        json.Unmarshal(resp.Body.Bytes(), &body)
+        if assert.Len(t, body.Conflicts, 1) {
+            assert.Equal(t, surgery, body.Conflicts[0].AppointmentID)
+            assert.Nil(t, body.Conflicts[0].VetID)
+            assert.Equal(t, []uint{anesthesia}, body.Conflicts[0].ResourceIDs)
+        }
+    })
+
+    t.Run("Vet Conflict", func(t *testing.T) {
+        resp := request("POST", "/api/v1/appointments", appointment(ana, at(9, 30), 45, xray))
+        assert.Equal(t, http.StatusConflict, resp.Code)
+    })
+
+    var xrayExam uint
+    t.Run("No Conflict", func(t *testing.T) {
+        // Empieza justo cuando termina la cirugía
+        create("/api/v1/appointments", appointment(luis, at(12, 0), 30, theatre))
+        // Otro veterinario y otra sala a la misma hora
+        xrayExam = create("/api/v1/appointments", appointment(luis, at(10, 0), 30, xray))
+        // Sin veterinario ni recursos no se comprueba nada
+        create("/api/v1/appointments", appointment(0, at(10, 0), 30))
+    })
+
+    t.Run("Update Into Conflict", func(t *testing.T) {
+        var current models.Appointment
+        assert.NoError(t, db.First(&current, xrayExam).Error)
+
+        moved := appointment(luis, at(11, 0), 30, theatre)
+        resp := request("PUT", "/api/v1/appointments/"+strconv.Itoa(int(xrayExam)), moved, "If-Match", etagFor(current.Version))
+        assert.Equal(t, http.StatusConflict, resp.Code)
+
+        // Guardar la cita sin cambios no choca consigo misma
+        resp = request("PUT", "/api/v1/appointments/"+strconv.Itoa(int(xrayExam)), appointment(luis, at(10, 0), 45, xray), "If-Match", etagFor(current.Version))
+        assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+    })
+
+    t.Run("Unknown Vet Or Resource", func(t *testing.T) {
+        resp := request("POST", "/api/v1/appointments", appointment(0, at(15, 0), 30, 999))
+        assert.Equal(t, http.StatusNotFound, resp.Code)
+
+        resp = request("POST", "/api/v1/appointments", appointment(999, at(15, 0), 30))
+        assert.Equal(t, http.StatusNotFound, resp.Code)
+    })
+
+    t.Run("Day View", func(t *testing.T) {
+        resp := request("GET", "/api/v1/resources/utilization?date="+day.Format("2006-01-02"), nil)
+        if !assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+            return
+        }
+
+        var view struct {
+            Date      string `json:"date"`
+            Resources []struct {
+                Resource      models.Resource `json:"resource"`
+                BookedMinutes int             `json:"booked_minutes"`
+                Utilization   float64         `json:"utilization"`
+                Bookings      []struct {
+                    AppointmentID uint `json:"appointment_id"`
+                } `json:"bookings"`
+            } `json:"resources"`
+        }
+        json.Unmarshal(resp.Body.Bytes(), &view)
+        assert.Equal(t, day.Format("2006-01-02"), view.Date)
+
+        usage := map[uint]int{}
+        bookings := map[uint]int{}
+        for _, r := range view.Resources {
+            usage[r.Resource.ID] = r.BookedMinutes
+            bookings[r.Resource.ID] = len(r.Bookings)
+        }
+        // Jornada de 08:00 a 20:00 (720 minutos)
+        assert.Equal(t, map[uint]int{theatre: 150, xray: 45, anesthesia: 120}, usage)
+        assert.Equal(t, map[uint]int{theatre: 2, xray: 1, anesthesia: 1}, bookings)
+        for _, r := range view.Resources {
+            if r.Resource.ID == anesthesia {
+                assert.InDelta(t, 0.17, r.Utilization, 0.001)
+            }
+        }
+
+        // Solo parte de la cirugía cae en el horario pedido
+        resp = request("GET", "/api/v1/resources/utilization?kind=equipment&from=11:00&to=13:00&date="+day.Format("2006-01-02"), nil)
+        json.Unmarshal(resp.Body.Bytes(), &view)
+        if assert.Len(t, view.Resources, 1) {
+            assert.Equal(t, 60, view.Resources[0].BookedMinutes)
+            assert.InDelta(t, 0.5, view.Resources[0].Utilization, 0.001)
+        }
+
+        resp = request("GET", "/api/v1/resources/utilization?date=mañana", nil)
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+        resp = request("GET", "/api/v1/resources/utilization?from=14:00&to=09:00&date="+day.Format("2006-01-02"), nil)
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+    })
+
+    t.Run("Delete Booked Resource", func(t *testing.T) {
+        resp := request("DELETE", "/api/v1/resources/"+strconv.Itoa(int(anesthesia)), nil)
+        assert.Equal(t, http.StatusConflict, resp.Code)
+
+        resp = request("DELETE", "/api/v1/vets/"+strconv.Itoa(int(ana)), nil)
+        assert.Equal(t, http.StatusConflict, resp.Code)
+
+        unused := create("/api/v1/resources", map[string]interface{}{"name": "Ecógrafo", "kind": "equipment"})
+        resp = request("DELETE", "/api/v1/resources/"+strconv.Itoa(int(unused)), nil)
+        assert.Equal(t, http.StatusOK, resp.Code)
+        resp = request("GET", "/api/v1/resources/"+strconv.Itoa(int(unused)), nil)
+        assert.Equal(t, http.StatusNotFound, resp.Code)
+    })
+}
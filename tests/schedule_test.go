@@ -0,0 +1,196 @@
+package tests
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "sync/atomic"
+    "testing"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/handlers"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/stretchr/testify/assert"
+    "gorm.io/gorm"
+)
+
+func TestSchedule(t *testing.T) {
+    router, db, err := setupTestRouter()
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    request := func(method, url string, body interface{}) *httptest.ResponseRecorder {
+        var payload []byte
+        if body != nil {
+            payload, _ = json.Marshal(body)
+        }
+        req, _ := newRequest(method, url, bytes.NewBuffer(payload))
+        req.Header.Set("Content-Type", "application/json")
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+    create := func(url string, body interface{}) uint {
+        resp := request("POST", url, body)
+        if !assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String()) {
+            t.FailNow()
+        }
+        var created struct {
+            ID uint `json:"id"`
+        }
+        json.Unmarshal(resp.Body.Bytes(), &created)
+        return created.ID
+    }
+    schedule := func(query string) handlers.ScheduleView {
+        var view handlers.ScheduleView
+        resp := request("GET", "/api/v1/schedule?"+query, nil)
+        if assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+            json.Unmarshal(resp.Body.Bytes(), &view)
+        }
+        return view
+    }
+
+    // Lunes 7 de enero de 2030
+    at := func(day, hour, minute int) time.Time {
+        return time.Date(2030, 1, day, hour, minute, 0, 0, time.Local)
+    }
+    gap := func(from, to time.Time) [2]time.Time {
+        return [2]time.Time{from, to}
+    }
+    gaps := func(column handlers.ScheduleColumn) [][2]time.Time {
+        result := [][2]time.Time{}
+        for _, g := range column.Gaps {
+            result = append(result, gap(g.Start.In(time.Local), g.End.In(time.Local)))
+            assert.Equal(t, int(g.End.Sub(g.Start).Minutes()), g.Minutes)
+        }
+        return result
+    }
+
+    client := models.Client{Name: "Lucía Pardo", Email: "lucia@example.com", Phone: "600123123"}
+    assert.NoError(t, db.Create(&client).Error)
+    toby := models.Pet{Name: "Toby", Species: "Dog", ClientID: client.ID}
+    mia := models.Pet{Name: "Mía", Species: "Cat", ClientID: client.ID}
+    assert.NoError(t, db.Create(&toby).Error)
+    assert.NoError(t, db.Create(&mia).Error)
+
+    ana := create("/api/v1/vets", map[string]interface{}{"name": "Ana"})
+    luis := create("/api/v1/vets", map[string]interface{}{"name": "Luis"})
+    consulta := create("/api/v1/resources", map[string]interface{}{"name": "Consulta 1", "kind": "room"})
+    create("/api/v1/resources", map[string]interface{}{"name": "Ecógrafo", "kind": "equipment"})
+
+    checkup := create("/api/v1/appointments", map[string]interface{}{
+        "pet_id": toby.ID, "vet_id": ana, "resource_ids": []uint{consulta}, "date": at(7, 9, 0),
+        "reason": "Revisión", "duration": 30, "buffer_after": 10,
+    })
+    create("/api/v1/appointments", map[string]interface{}{
+        "pet_id": mia.ID, "vet_id": ana, "date": at(7, 11, 0), "reason": "Vacuna", "duration": 60,
+    })
+    walkIn := create("/api/v1/appointments", map[string]interface{}{
+        "pet_id": mia.ID, "resource_ids": []uint{consulta}, "date": at(7, 12, 0), "reason": "Cura", "duration": 30,
+    })
+    cancelled := create("/api/v1/appointments", map[string]interface{}{
+        "pet_id": toby.ID, "vet_id": ana, "date": at(7, 15, 0), "reason": "Control", "duration": 30,
+    })
+    assert.Equal(t, http.StatusOK, request("POST", "/api/v1/appointments/"+strconv.Itoa(int(cancelled))+"/cancel", nil).Code)
+    create("/api/v1/appointments", map[string]interface{}{
+        "pet_id": toby.ID, "vet_id": ana, "date": at(9, 10, 0), "reason": "Control", "duration": 30,
+    })
+
+    t.Run("Day View", func(t *testing.T) {
+        view := schedule("date=2030-01-07")
+        if !assert.Len(t, view.Days, 1) {
+            return
+        }
+        day := view.Days[0]
+        assert.Equal(t, "2030-01-07", day.Date)
+        assert.True(t, view.From.Equal(at(7, 0, 0)))
+        assert.True(t, view.To.Equal(at(8, 0, 0)))
+
+        if assert.Len(t, day.Vets, 2) {
+            assert.Equal(t, "Ana", day.Vets[0].Name)
+            if assert.Len(t, day.Vets[0].Appointments, 2) {
+                entry := day.Vets[0].Appointments[0]
+                assert.Equal(t, checkup, entry.AppointmentID)
+                assert.Equal(t, "Toby", entry.PetName)
+                assert.Equal(t, "Dog", entry.Species)
+                assert.Equal(t, "Lucía Pardo", entry.ClientName)
+                assert.Equal(t, "600123123", entry.Phone)
+                assert.True(t, entry.End.Equal(at(7, 9, 30)))
+            }
+            // El margen de limpieza de la revisión no queda libre
+            assert.Equal(t, [][2]time.Time{
+                gap(at(7, 8, 0), at(7, 9, 0)),
+                gap(at(7, 9, 40), at(7, 11, 0)),
+                gap(at(7, 12, 0), at(7, 20, 0)),
+            }, gaps(day.Vets[0]))
+
+            assert.Equal(t, "Luis", day.Vets[1].Name)
+            assert.Empty(t, day.Vets[1].Appointments)
+            assert.Equal(t, [][2]time.Time{gap(at(7, 8, 0), at(7, 20, 0))}, gaps(day.Vets[1]))
+        }
+
+        // Solo salas, no equipos
+        if assert.Len(t, day.Rooms, 1) {
+            assert.Len(t, day.Rooms[0].Appointments, 2)
+            assert.Equal(t, [][2]time.Time{
+                gap(at(7, 8, 0), at(7, 9, 0)),
+                gap(at(7, 9, 40), at(7, 12, 0)),
+                gap(at(7, 12, 30), at(7, 20, 0)),
+            }, gaps(day.Rooms[0]))
+        }
+
+        if assert.Len(t, day.Unassigned, 1) {
+            assert.Equal(t, walkIn, day.Unassigned[0].AppointmentID)
+        }
+    })
+
+    t.Run("Working Hours", func(t *testing.T) {
+        view := schedule("date=2030-01-07&vet_id=" + strconv.Itoa(int(luis)) + "&from=09:00&to=13:00")
+        if assert.Len(t, view.Days, 1) && assert.Len(t, view.Days[0].Vets, 1) {
+            assert.Equal(t, [][2]time.Time{gap(at(7, 9, 0), at(7, 13, 0))}, gaps(view.Days[0].Vets[0]))
+            assert.Empty(t, view.Days[0].Unassigned)
+        }
+    })
+
+    t.Run("Week View", func(t *testing.T) {
+        // Cualquier día de la semana devuelve de lunes a domingo
+        view := schedule("view=week&date=2030-01-10&vet_id=" + strconv.Itoa(int(ana)))
+        if !assert.Len(t, view.Days, 7) {
+            return
+        }
+        assert.Equal(t, "2030-01-07", view.Days[0].Date)
+        assert.Equal(t, "2030-01-13", view.Days[6].Date)
+        assert.True(t, view.To.Equal(at(14, 0, 0)))
+        counts := []int{}
+        for _, day := range view.Days {
+            counts = append(counts, len(day.Vets[0].Appointments))
+        }
+        assert.Equal(t, []int{2, 0, 1, 0, 0, 0, 0}, counts)
+    })
+
+    t.Run("No N+1 Queries", func(t *testing.T) {
+        var queries int32
+        count := func(*gorm.DB) { atomic.AddInt32(&queries, 1) }
+        assert.NoError(t, db.Callback().Query().After("gorm:query").Register("test:count_query", count))
+        assert.NoError(t, db.Callback().Row().After("gorm:row").Register("test:count_row", count))
+        defer func() {
+            db.Callback().Query().Remove("test:count_query")
+            db.Callback().Row().Remove("test:count_row")
+        }()
+
+        schedule("view=week&date=2030-01-07")
+        // Veterinarios, salas y citas con sus mascotas y propietarios
+        assert.Equal(t, int32(3), atomic.LoadInt32(&queries))
+    })
+
+    t.Run("Invalid Parameters", func(t *testing.T) {
+        assert.Equal(t, http.StatusBadRequest, request("GET", "/api/v1/schedule?view=month", nil).Code)
+        assert.Equal(t, http.StatusBadRequest, request("GET", "/api/v1/schedule?date=07/01/2030", nil).Code)
+        assert.Equal(t, http.StatusBadRequest, request("GET", "/api/v1/schedule?from=20:00&to=08:00", nil).Code)
+        assert.Equal(t, http.StatusBadRequest, request("GET", "/api/v1/schedule?vet_id=ana", nil).Code)
+        assert.Equal(t, http.StatusNotFound, request("GET", "/api/v1/schedule?vet_id=999", nil).Code)
+    })
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "testing"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestSearch(t *testing.T) {
+    router, db, err := setupTestRouter()
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    search := func(params string) ([]repositories.SearchResult, int) {
+        req, _ := newRequest("GET", "/api/v1/search?"+params, nil)
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+
+        var results []repositories.SearchResult
+        json.Unmarshal(resp.Body.Bytes(), &results)
+        return results, resp.Code
+    }
+    q := func(text string) string {
+        return "q=" + url.QueryEscape(text)
+    }
+
+    client := models.Client{Name: "María García López", Email: "maria.garcia@example.com", Phone: "612 345 678"}
+    db.Create(&client)
+    db.Create(&models.Client{Name: "Pedro Sánchez", Email: "pedro@example.com", Phone: "699 000 111"})
+    pet := models.Pet{Name: "Bolita", Species: "Dog", Breed: "Golden Retriever", Description: "Alérgico al pollo", ClientID: client.ID}
+    db.Create(&pet)
+    appointment := models.Appointment{PetID: pet.ID, Date: time.Now().Add(24 * time.Hour), Reason: "Vacunación anual", Notes: "Bolita se pone nerviosa", Duration: 20}
+    db.Create(&appointment)
+
+    t.Run("Partial Phone", func(t *testing.T) {
+        for _, text := range []string{"345 678", "345678", "612-345"} {
+            results, code := search(q(text))
+            assert.Equal(t, http.StatusOK, code)
+            if assert.Len(t, results, 1, text) {
+                assert.Equal(t, repositories.SearchClient, results[0].Type)
+                assert.Equal(t, client.ID, results[0].ID)
+            }
+        }
+    })
+
+    t.Run("Surname With Highlight", func(t *testing.T) {
+        results, _ := search(q("garcía"))
+        if assert.Len(t, results, 1) {
+            assert.Equal(t, "María García López", results[0].Title)
+            assert.Contains(t, results[0].Snippet, "<mark>García</mark>")
+        }
+    })
+
+    t.Run("Ranked And Typed", func(t *testing.T) {
+        results, _ := search(q("bolita"))
+        if assert.Len(t, results, 2) {
+            // El nombre de la mascota pesa más que las notas de la cita
+            assert.Equal(t, repositories.SearchPet, results[0].Type)
+            assert.Equal(t, pet.ID, results[0].ID)
+            assert.Equal(t, repositories.SearchAppointment, results[1].Type)
+            assert.Equal(t, appointment.ID, results[1].ID)
+            assert.Greater(t, results[0].Score, results[1].Score)
+        }
+
+        results, _ = search(q("bolita") + "&type=appointment")
+        if assert.Len(t, results, 1) {
+            assert.Equal(t, repositories.SearchAppointment, results[0].Type)
+            assert.Contains(t, results[0].Snippet, "<mark>Bolita</mark>")
+        }
+
+        results, _ = search(q("retriever pollo"))
+        assert.Len(t, results, 1)
+    })
+
+    t.Run("Index Follows Writes", func(t *testing.T) {
+        db.Model(&pet).Update("name", "Canela")
+        results, _ := search(q("bolita") + "&type=pet")
+        assert.Len(t, results, 0)
+        results, _ = search(q("canela"))
+        assert.Len(t, results, 1)
+
+        db.Delete(&pet)
+        results, _ = search(q("canela"))
+        assert.Len(t, results, 0)
+
+        db.Unscoped().Model(&pet).Update("deleted_at", nil)
+        results, _ = search(q("canela"))
+        assert.Len(t, results, 1)
+
+        db.Unscoped().Delete(&appointment)
+        results, _ = search(q("vacunación"))
+        assert.Len(t, results, 0)
+    })
+
+    t.Run("Invalid Parameters", func(t *testing.T) {
+        _, code := search("")
+        assert.Equal(t, http.StatusBadRequest, code)
+        _, code = search(q("maría") + "&type=vet")
+        assert.Equal(t, http.StatusBadRequest, code)
+        _, code = search(q("maría") + "&limit=0")
+        assert.Equal(t, http.StatusBadRequest, code)
+    })
+}
@@ -0,0 +1,122 @@
+package tests
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "testing"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestSoftDelete(t *testing.T) {
+    router, db, err := setupTestRouter()
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    do := func(method, url string, headers map[string]string) *httptest.ResponseRecorder {
+        req, _ := newRequest(method, url, nil)
+        if method == "DELETE" {
+            req.Header.Set("If-Match", "*")
+        }
+        for key, value := range headers {
+            req.Header.Set(key, value)
+        }
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+
+    client := models.Client{Name: "Soft Delete Owner", Email: "soft@example.com", Phone: "600000001"}
+    db.Create(&client)
+    pet := models.Pet{Name: "Toby", Species: "Dog", ClientID: client.ID}
+    db.Create(&pet)
+    past := models.Appointment{PetID: pet.ID, Date: time.Now().Add(-48 * time.Hour), Reason: "Vacuna", Duration: 15}
+    db.Create(&past)
+
+    clientURL := "/api/v1/clients/" + strconv.FormatUint(uint64(client.ID), 10)
+    petURL := "/api/v1/pets/" + strconv.FormatUint(uint64(pet.ID), 10)
+
+    t.Run("Block Delete With Active Pets", func(t *testing.T) {
+        resp := do("DELETE", clientURL, nil)
+        assert.Equal(t, http.StatusConflict, resp.Code)
+    })
+
+    t.Run("Block Delete With Future Appointments", func(t *testing.T) {
+        future := models.Appointment{PetID: pet.ID, Date: time.Now().Add(48 * time.Hour), Reason: "Revisión", Duration: 30}
+        db.Create(&future)
+        defer db.Unscoped().Delete(&future)
+
+        assert.Equal(t, http.StatusConflict, do("DELETE", petURL, nil).Code)
+        assert.Equal(t, http.StatusConflict, do("DELETE", clientURL+"?cascade=true", nil).Code)
+    })
+
+    t.Run("Cascade Archive", func(t *testing.T) {
+        resp := do("DELETE", clientURL+"?cascade=true", nil)
+        assert.Equal(t, http.StatusOK, resp.Code)
+
+        assert.Equal(t, http.StatusNotFound, do("GET", clientURL, nil).Code)
+        assert.Equal(t, http.StatusNotFound, do("GET", petURL, nil).Code)
+
+        // Los datos siguen en la base de datos
+        var count int64
+        db.Unscoped().Model(&models.Appointment{}).Where("id = ? AND deleted_at IS NOT NULL", past.ID).Count(&count)
+        assert.Equal(t, int64(1), count)
+    })
+
+    t.Run("Restore Pet Requires Client", func(t *testing.T) {
+        assert.Equal(t, http.StatusConflict, do("POST", petURL+"/restore", nil).Code)
+    })
+
+    t.Run("Restore Client", func(t *testing.T) {
+        resp := do("POST", clientURL+"/restore", nil)
+        assert.Equal(t, http.StatusOK, resp.Code)
+
+        assert.Equal(t, http.StatusOK, do("GET", petURL, nil).Code)
+        var count int64
+        db.Model(&models.Appointment{}).Where("id = ?", past.ID).Count(&count)
+        assert.Equal(t, int64(1), count)
+
+        assert.Equal(t, http.StatusConflict, do("POST", clientURL+"/restore", nil).Code)
+    })
+
+    t.Run("Purge Requires Admin", func(t *testing.T) {
+        assert.Equal(t, http.StatusUnauthorized, do("POST", "/api/v1/admin/purge", map[string]string{"X-Admin-Token": ""}).Code)
+        assert.Equal(t, http.StatusUnauthorized, do("POST", "/api/v1/admin/purge", map[string]string{"X-Admin-Token": "wrong"}).Code)
+    })
+
+    t.Run("Purge After Retention", func(t *testing.T) {
+        assert.Equal(t, http.StatusOK, do("DELETE", clientURL+"?cascade=true", nil).Code)
+
+        admin := map[string]string{"X-Admin-Token": testAdminToken}
+
+        // Recién archivado: todavía dentro del periodo de conservación
+        assert.Equal(t, http.StatusOK, do("POST", "/api/v1/admin/purge", admin).Code)
+        var count int64
+        db.Unscoped().Model(&models.Client{}).Where("id = ?", client.ID).Count(&count)
+        assert.Equal(t, int64(1), count)
+
+        old := time.Now().AddDate(-10, 0, 0)
+        db.Unscoped().Model(&models.Appointment{}).Where("pet_id = ?", pet.ID).Update("deleted_at", old)
+        db.Unscoped().Model(&models.Pet{}).Where("id = ?", pet.ID).Update("deleted_at", old)
+        db.Unscoped().Model(&models.Client{}).Where("id = ?", client.ID).Update("deleted_at", old)
+
+        // Si falla la purga de los clientes no se elimina nada
+        assert.NoError(t, db.Exec("ALTER TABLE client_clinics RENAME TO client_clinics_old").Error)
+        assert.Equal(t, http.StatusInternalServerError, do("POST", "/api/v1/admin/purge", admin).Code)
+        assert.NoError(t, db.Exec("ALTER TABLE client_clinics_old RENAME TO client_clinics").Error)
+        db.Unscoped().Model(&models.Appointment{}).Where("pet_id = ?", pet.ID).Count(&count)
+        assert.Equal(t, int64(1), count)
+        db.Unscoped().Model(&models.Pet{}).Where("id = ?", pet.ID).Count(&count)
+        assert.Equal(t, int64(1), count)
+
+        assert.Equal(t, http.StatusOK, do("POST", "/api/v1/admin/purge", admin).Code)
+        db.Unscoped().Model(&models.Client{}).Where("id = ?", client.ID).Count(&count)
+        assert.Equal(t, int64(0), count)
+        db.Unscoped().Model(&models.Pet{}).Where("id = ?", pet.ID).Count(&count)
+        assert.Equal(t, int64(0), count)
+    })
+}
@@ -0,0 +1,267 @@
+package tests
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "strings"
+    "testing"
+
+    "github.com/javice/vet-clinic-api/internal/importer"
+    "github.com/javice/vet-clinic-api/internal/audit"
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestSpeciesCatalog(t *testing.T) {
+    router, db, err := setupTestRouter()
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    request := func(method, url string, body interface{}) *httptest.ResponseRecorder {
+        var payload []byte
+        if body != nil {
+            payload, _ = json.Marshal(body)
+        }
+        req, _ := newRequest(method, url, bytes.NewBuffer(payload))
+        req.Header.Set("Content-Type", "application/json")
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+    decode := func(resp *httptest.ResponseRecorder, target interface{}) {
+        assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), target))
+    }
+    speciesID := func(name string) string {
+        var species models.Species
+        assert.NoError(t, db.Where("name = ?", name).First(&species).Error)
+        return strconv.FormatUint(uint64(species.ID), 10)
+    }
+
+    client := models.Client{Name: "Marta Gil", Email: "marta@example.com", Phone: "600111222"}
+    assert.NoError(t, db.Create(&client).Error)
+
+    t.Run("Create Pet Normalizes Names", func(t *testing.T) {
+        cases := []struct{ species, breed, wantSpecies, wantBreed string }{
+            {"perro", "labrador", "Dog", "Labrador Retriever"},
+            {"Canine", "Pastor Aleman", "Dog", "German Shepherd"},
+            {" GATO ", "siamés", "Cat", "Siamese"},
+            {"Hámster", "", "Hamster", ""},
+        }
+        for _, tc := range cases {
+            resp := request("POST", "/api/v1/pets", map[string]interface{}{
+                "name": "Mascota", "species": tc.species, "breed": tc.breed, "client_id": client.ID,
+            })
+            if !assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String()) {
+                continue
+            }
+            var pet models.Pet
+            decode(resp, &pet)
+            assert.Equal(t, tc.wantSpecies, pet.Species)
+            assert.Equal(t, tc.wantBreed, pet.Breed)
+        }
+    })
+
+    t.Run("Unknown Species Or Breed", func(t *testing.T) {
+        resp := request("POST", "/api/v1/pets", map[string]interface{}{
+            "name": "Nemo", "species": "Pez payaso", "client_id": client.ID,
+        })
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+        assert.Contains(t, resp.Body.String(), `"field":"species"`)
+
+        // Siamés es una raza de gato, no de perro
+        resp = request("POST", "/api/v1/pets", map[string]interface{}{
+            "name": "Toby", "species": "Dog", "breed": "Siamese", "client_id": client.ID,
+        })
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+        assert.Contains(t, resp.Body.String(), `"field":"breed"`)
+    })
+
+    t.Run("Update And Patch Validate Species", func(t *testing.T) {
+        pet := models.Pet{Name: "Luna", Species: "Cat", ClientID: client.ID}
+        assert.NoError(t, db.Create(&pet).Error)
+        url := "/api/v1/pets/" + strconv.FormatUint(uint64(pet.ID), 10)
+
+        put := func(body map[string]interface{}) *httptest.ResponseRecorder {
+            payload, _ := json.Marshal(body)
+            req, _ := newRequest("PUT", url, bytes.NewBuffer(payload))
+            req.Header.Set("Content-Type", "application/json")
+            req.Header.Set("If-Match", etagFor(pet.Version))
+            resp := httptest.NewRecorder()
+            router.ServeHTTP(resp, req)
+            return resp
+        }
+        assert.Equal(t, http.StatusBadRequest, put(map[string]interface{}{"name": "Luna", "species": "Unicornio", "client_id": client.ID}).Code)
+        resp := put(map[string]interface{}{"name": "Luna", "species": "felino", "breed": "persa", "client_id": client.ID})
+        if assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+            decode(resp, &pet)
+            assert.Equal(t, "Cat", pet.Species)
+            assert.Equal(t, "Persian", pet.Breed)
+        }
+
+        req, _ := newRequest("PATCH", url, bytes.NewBufferString(`{"breed": "Golden Retriever"}`))
+        req.Header.Set("Content-Type", "application/merge-patch+json")
+        req.Header.Set("If-Match", etagFor(pet.Version))
+        resp = httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+    })
+
+    t.Run("Breed Autocomplete", func(t *testing.T) {
+        var breeds []models.Breed
+        resp := request("GET", "/api/v1/species/"+speciesID("Dog")+"/breeds?q=pastor", nil)
+        if assert.Equal(t, http.StatusOK, resp.Code) {
+            decode(resp, &breeds)
+            if assert.Len(t, breeds, 1) {
+                assert.Equal(t, "German Shepherd", breeds[0].Name)
+                assert.Equal(t, "Pastor alemán", breeds[0].Names["es"])
+            }
+        }
+
+        resp = request("GET", "/api/v1/species/"+speciesID("Dog")+"/breeds?q=RETRIEVER", nil)
+        decode(resp, &breeds)
+        names := []string{}
+        for _, b := range breeds {
+            names = append(names, b.Name)
+        }
+        assert.Equal(t, []string{"Golden Retriever", "Labrador Retriever"}, names)
+
+        resp = request("GET", "/api/v1/species/"+speciesID("Cat")+"/breeds", nil)
+        decode(resp, &breeds)
+        assert.Greater(t, len(breeds), 2)
+
+        assert.Equal(t, http.StatusNotFound, request("GET", "/api/v1/species/999/breeds", nil).Code)
+        assert.Equal(t, http.StatusBadRequest, request("GET", "/api/v1/species/perro/breeds", nil).Code)
+    })
+
+    t.Run("Manage Catalog", func(t *testing.T) {
+        var list []models.Species
+        decode(request("GET", "/api/v1/species", nil), &list)
+        assert.NotEmpty(t, list)
+
+        // Un alias no puede identificar a dos especies
+        resp := request("POST", "/api/v1/species", map[string]interface{}{"name": "Wolf", "aliases": []string{"Canino"}})
+        assert.Equal(t, http.StatusConflict, resp.Code)
+
+        resp = request("POST", "/api/v1/species", map[string]interface{}{
+            "name": "Horse", "names": map[string]string{"es": "Caballo"},
+            "breeds": []map[string]interface{}{{"name": "Andalusian", "names": map[string]string{"es": "Pura raza española"}}},
+        })
+        if !assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String()) {
+            return
+        }
+        var horse models.Species
+        decode(resp, &horse)
+        horseURL := "/api/v1/species/" + strconv.FormatUint(uint64(horse.ID), 10)
+
+        resp = request("POST", "/api/v1/pets", map[string]interface{}{
+            "name": "Rayo", "species": "caballo", "breed": "pura raza española", "client_id": client.ID,
+        })
+        if assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String()) {
+            var pet models.Pet
+            decode(resp, &pet)
+            assert.Equal(t, "Horse", pet.Species)
+            assert.Equal(t, "Andalusian", pet.Breed)
+        }
+
+        assert.Equal(t, http.StatusConflict, request("POST", horseURL+"/breeds", map[string]interface{}{"name": "Andaluz", "aliases": []string{"Andalusian"}}).Code)
+        assert.Equal(t, http.StatusCreated, request("POST", horseURL+"/breeds", map[string]interface{}{"name": "Arabian", "names": map[string]string{"es": "Árabe"}}).Code)
+        assert.Equal(t, http.StatusBadRequest, request("POST", horseURL+"/breeds", map[string]interface{}{"names": map[string]string{"es": "Frisón"}}).Code)
+
+        // Cambiar el nombre canónico actualiza las mascotas
+        resp = request("PUT", horseURL, map[string]interface{}{"name": "Equine", "names": map[string]string{"es": "Caballo"}, "aliases": []string{"Horse"}})
+        assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+        breedURL := horseURL + "/breeds/" + strconv.FormatUint(uint64(horse.Breeds[0].ID), 10)
+        resp = request("PUT", breedURL, map[string]interface{}{"name": "PRE", "aliases": []string{"Andalusian"}})
+        assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+        var pet models.Pet
+        assert.NoError(t, db.Where("name = ?", "Rayo").First(&pet).Error)
+        assert.Equal(t, "Equine", pet.Species)
+        assert.Equal(t, "PRE", pet.Breed)
+        // Cada cambio de nombre es una nueva versión de la mascota
+        assert.Equal(t, uint(3), pet.Version)
+        var renames int64
+        db.Model(&models.AuditLog{}).Where("entity = ? AND entity_id = ? AND action = ?", "pet", pet.ID, models.AuditUpdate).Count(&renames)
+        assert.Equal(t, int64(2), renames)
+
+        assert.Equal(t, http.StatusNotFound, request("PUT", horseURL+"/breeds/999", map[string]interface{}{"name": "Frisón"}).Code)
+        assert.Equal(t, http.StatusNotFound, request("PUT", "/api/v1/species/999", map[string]interface{}{"name": "Llama"}).Code)
+    })
+}
+
+func TestSpeciesMigration(t *testing.T) {
+    db, err := setupTestDB()
+    if err != nil {
+        t.Fatalf("Error inicializando la base de datos: %v", err)
+    }
+    // Como al arrancar, la migración se audita
+    assert.NoError(t, audit.Register(db, &models.Client{}, &models.Pet{}, &models.Appointment{}))
+    repo := repositories.NewSpeciesRepository(db)
+    assert.NoError(t, repo.Seed(repositories.DefaultSpecies()))
+    // Con el catálogo ya cargado no se vuelve a sembrar
+    assert.NoError(t, repo.Seed(repositories.DefaultSpecies()))
+    var count int64
+    db.Model(&models.Species{}).Where("name = ?", "Dog").Count(&count)
+    assert.Equal(t, int64(1), count)
+
+    client := models.Client{Name: "Pedro Ruiz", Email: "pedro@example.com", Phone: "600333444"}
+    assert.NoError(t, db.Create(&client).Error)
+    pets := []models.Pet{
+        {Name: "Toby", Species: "dog", Breed: "golden", ClientID: client.ID},
+        {Name: "Rex", Species: "Perro", ClientID: client.ID},
+        {Name: "Lola", Species: "canine", Breed: "Chucho", ClientID: client.ID},
+        {Name: "Misi", Species: "Gato", Breed: "siames", ClientID: client.ID},
+        {Name: "Kiwi", Species: "Bird", ClientID: client.ID},
+        {Name: "Draco", Species: "Dragón", ClientID: client.ID},
+    }
+    assert.NoError(t, db.Create(&pets).Error)
+    assert.NoError(t, db.Delete(&pets[1]).Error)
+
+    updated, unmatched, err := repo.MigratePets()
+    assert.NoError(t, err)
+    assert.Equal(t, int64(4), updated)
+    assert.Equal(t, []string{"Dragón", "canine / Chucho"}, unmatched)
+
+    var stored []models.Pet
+    db.Unscoped().Order("id").Find(&stored)
+    got := []string{}
+    for _, p := range stored {
+        got = append(got, p.Species+"/"+p.Breed)
+    }
+    assert.Equal(t, []string{"Dog/Golden Retriever", "Dog/", "Dog/Chucho", "Cat/Siamese", "Bird/", "Dragón/"}, got)
+
+    // Las mascotas cambiadas pasan a una nueva versión y el cambio queda en
+    // la auditoría
+    versions := []uint{}
+    for _, p := range stored {
+        versions = append(versions, p.Version)
+    }
+    assert.Equal(t, []uint{2, 2, 2, 2, 1, 1}, versions)
+    var logs []models.AuditLog
+    assert.NoError(t, db.Where("entity = ? AND action <> ?", "pet", models.AuditCreate).Order("entity_id").Find(&logs).Error)
+    ids := []uint{}
+    for _, l := range logs {
+        if l.Action == models.AuditUpdate {
+            ids = append(ids, l.EntityID)
+            assert.Equal(t, audit.SystemActor, l.Actor)
+            assert.Contains(t, string(l.Changes), `"species"`)
+        }
+    }
+    assert.Equal(t, []uint{stored[0].ID, stored[1].ID, stored[2].ID, stored[3].ID}, ids)
+
+    t.Run("Import Validates Species", func(t *testing.T) {
+        ndjson := `{"name": "Kira", "species": "perra", "breed": "galgo", "client_email": "pedro@example.com"}
+{"name": "Nemo", "species": "Pez", "client_email": "pedro@example.com"}
+{"name": "Coco", "species": "Cat", "breed": "Labrador", "client_email": "pedro@example.com"}`
+        report, err := importer.Run(db, strings.NewReader(ndjson), importer.Options{Format: importer.FormatNDJSON, Kind: importer.KindPets})
+        assert.ErrorIs(t, err, importer.ErrInvalidRows)
+        assert.Equal(t, 1, report.Valid)
+        assert.Contains(t, report.Errors, importer.RowError{Row: 2, Field: "species", Message: "especie desconocida"})
+        assert.Contains(t, report.Errors, importer.RowError{Row: 3, Field: "breed", Message: "raza desconocida para esta especie"})
+    })
+}
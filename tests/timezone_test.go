@@ -0,0 +1,209 @@
+package tests
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "testing"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/routes"
+    "github.com/javice/vet-clinic-api/internal/timezone"
+    "github.com/stretchr/testify/assert"
+    "gorm.io/gorm"
+)
+
+func TestClinicTimeZone(t *testing.T) {
+    router, db, err := setupTestRouter()
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    request := func(method, url string, clinic uint, body interface{}) *httptest.ResponseRecorder {
+        var payload []byte
+        if body != nil {
+            payload, _ = json.Marshal(body)
+        }
+        req, _ := newRequest(method, url, bytes.NewBuffer(payload))
+        req.Header.Set("Content-Type", "application/json")
+        req.Header.Set("X-Admin-Token", testAdminToken)
+        if clinic != 0 {
+            req.Header.Set("X-Clinic-ID", strconv.Itoa(int(clinic)))
+        }
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+    create := func(url string, clinic uint, body interface{}) map[string]interface{} {
+        resp := request("POST", url, clinic, body)
+        if !assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String()) {
+            t.FailNow()
+        }
+        var created map[string]interface{}
+        json.Unmarshal(resp.Body.Bytes(), &created)
+        return created
+    }
+    dates := func(url string, clinic uint) []string {
+        resp := request("GET", url, clinic, nil)
+        if !assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+            return nil
+        }
+        var items []map[string]interface{}
+        json.Unmarshal(resp.Body.Bytes(), &items)
+        result := []string{}
+        for _, item := range items {
+            result = append(result, item["date"].(string))
+        }
+        return result
+    }
+    stored := func(id interface{}) string {
+        var date string
+        db.Raw("SELECT CAST(date AS TEXT) FROM appointments WHERE id = ?", id).Scan(&date)
+        return date
+    }
+
+    t.Run("Invalid Time Zone", func(t *testing.T) {
+        resp := request("POST", "/api/v1/admin/clinics", 0, map[string]interface{}{"name": "Sede Marte", "time_zone": "Mars/Olympus"})
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+    })
+
+    madrid := uint(create("/api/v1/admin/clinics", 0, map[string]interface{}{"name": "Sede Madrid", "time_zone": "Europe/Madrid"})["id"].(float64))
+    client := create("/api/v1/clients", madrid, map[string]interface{}{"name": "Horaria", "email": "horaria@example.com", "phone": "600999888"})
+    pet := create("/api/v1/pets", madrid, map[string]interface{}{
+        "name": "Reloj", "species": "Cat", "client_id": client["id"], "birth_date": "2020-03-29T00:00:00+01:00",
+    })
+    vet := create("/api/v1/vets", madrid, map[string]interface{}{"name": "Marta"})
+    appointment := func(date string) map[string]interface{} {
+        return create("/api/v1/appointments", madrid, map[string]interface{}{
+            "pet_id": pet["id"], "date": date, "reason": "Revisión", "duration": 30,
+        })
+    }
+
+    t.Run("Stored In UTC And Rendered In Clinic Time", func(t *testing.T) {
+        // Mismo horario local antes y después del cambio de hora
+        summer := create("/api/v1/appointments", madrid, map[string]interface{}{
+            "pet_id": pet["id"], "date": "2026-10-20T10:00:00+02:00", "reason": "Revisión", "duration": 30, "vet_id": vet["id"],
+        })
+        winter := appointment("2026-10-27T10:00:00+01:00")
+        assert.Equal(t, "2026-10-20 08:00:00+00:00", stored(summer["id"]))
+        assert.Equal(t, "2026-10-27 09:00:00+00:00", stored(winter["id"]))
+        assert.Equal(t, "2026-10-20T10:00:00+02:00", summer["date"])
+        assert.Equal(t, "2026-10-27T10:00:00+01:00", winter["date"])
+
+        // Las fechas recibidas en UTC se devuelven con la hora de la clínica
+        utc := appointment("2026-10-21T07:30:00Z")
+        assert.Equal(t, "2026-10-21T09:30:00+02:00", utc["date"])
+        resp := request("GET", "/api/v1/appointments/"+strconv.Itoa(int(utc["id"].(float64))), madrid, nil)
+        assert.Contains(t, resp.Body.String(), `"date":"2026-10-21T09:30:00+02:00"`)
+
+        // Las fechas de calendario no cambian de día
+        resp = request("GET", "/api/v1/pets/"+strconv.Itoa(int(pet["id"].(float64))), madrid, nil)
+        assert.Contains(t, resp.Body.String(), `"birth_date":"2020-03-29T00:00:00Z"`)
+    })
+
+    t.Run("Conflicts Across Offsets", func(t *testing.T) {
+        // 08:15 UTC es 10:15 en Madrid, dentro de la cita de las 10:00
+        resp := request("POST", "/api/v1/appointments", madrid, map[string]interface{}{
+            "pet_id": pet["id"], "date": "2026-10-20T08:15:00Z", "reason": "Control", "duration": 30, "vet_id": vet["id"],
+        })
+        assert.Equal(t, http.StatusConflict, resp.Code)
+    })
+
+    t.Run("Date Filter On DST Days", func(t *testing.T) {
+        // El 25 de octubre dura 25 horas: de 22:00 UTC del 24 a 23:00 UTC del 25
+        appointment("2026-10-24T21:30:00Z") // 23:30 del 24 en Madrid
+        appointment("2026-10-24T22:00:00Z") // 00:00 del 25
+        appointment("2026-10-25T22:30:00Z") // 23:30 del 25
+        appointment("2026-10-25T23:00:00Z") // 00:00 del 26
+        assert.Equal(t, []string{"2026-10-25T00:00:00+02:00", "2026-10-25T23:30:00+01:00"},
+            dates("/api/v1/appointments?date=2026-10-25", madrid))
+
+        // El 29 de marzo dura 23 horas: de 23:00 UTC del 28 a 22:00 UTC del 29
+        appointment("2026-03-28T22:30:00Z") // 23:30 del 28
+        appointment("2026-03-28T23:00:00Z") // 00:00 del 29
+        appointment("2026-03-29T21:30:00Z") // 23:30 del 29
+        appointment("2026-03-29T22:00:00Z") // 00:00 del 30
+        assert.Equal(t, []string{"2026-03-29T00:00:00+01:00", "2026-03-29T23:30:00+02:00"},
+            dates("/api/v1/appointments?date=2026-03-29", madrid))
+
+        // Junto con la mascota
+        assert.Len(t, dates("/api/v1/appointments?date=2026-10-20&pet_id="+strconv.Itoa(int(pet["id"].(float64))), madrid), 1)
+
+        assert.Equal(t, http.StatusBadRequest, request("GET", "/api/v1/appointments?date=25-10-2026", madrid, nil).Code)
+    })
+
+    t.Run("Day View On DST Day", func(t *testing.T) {
+        resp := request("GET", "/api/v1/resources/utilization?date=2026-10-25&from=00:00&to=24:00", madrid, nil)
+        if !assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+            return
+        }
+        var view struct {
+            From time.Time `json:"from"`
+            To   time.Time `json:"to"`
+        }
+        json.Unmarshal(resp.Body.Bytes(), &view)
+        assert.Equal(t, 25*time.Hour, view.To.Sub(view.From))
+        assert.Equal(t, "2026-10-25T00:00:00+02:00", view.From.Format(time.RFC3339))
+    })
+
+    t.Run("Normalize", func(t *testing.T) {
+        // Fechas guardadas con desplazamiento antes de usar UTC
+        db.Exec("UPDATE appointments SET date = '2026-10-20 10:00:00+02:00' WHERE date = '2026-10-20 08:00:00+00:00'")
+        db.Exec("UPDATE pets SET birth_date = '2020-03-29 00:00:00+01:00'")
+        assert.NoError(t, timezone.Normalize(db, &models.Appointment{}, &models.Pet{}))
+
+        var count int64
+        db.Raw("SELECT COUNT(*) FROM appointments WHERE date = '2026-10-20 08:00:00+00:00'").Scan(&count)
+        assert.Equal(t, int64(1), count)
+        var birth string
+        db.Raw("SELECT CAST(birth_date AS TEXT) FROM pets").Scan(&birth)
+        assert.Equal(t, "2020-03-29 00:00:00+00:00", birth)
+    })
+}
+
+func TestDefaultTimeZone(t *testing.T) {
+    local := time.Local
+    tokyo, err := time.LoadLocation("Asia/Tokyo")
+    if err != nil {
+        t.Fatalf("Error cargando la zona horaria: %v", err)
+    }
+    router, _, err := setupTestRouterWith(func(db *gorm.DB, opts *routes.Options) error {
+        opts.TimeZone = tokyo
+        return nil
+    })
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    create := func(url string, clinic interface{}, body interface{}) map[string]interface{} {
+        payload, _ := json.Marshal(body)
+        req, _ := newRequest("POST", url, bytes.NewBuffer(payload))
+        req.Header.Set("Content-Type", "application/json")
+        if clinic != nil {
+            req.Header.Set("X-Clinic-ID", strconv.Itoa(int(clinic.(float64))))
+        }
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        if !assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String()) {
+            t.FailNow()
+        }
+        var created map[string]interface{}
+        json.Unmarshal(resp.Body.Bytes(), &created)
+        return created
+    }
+
+    // La clínica no indica zona horaria: se usa la de la configuración
+    clinic := create("/api/v1/admin/clinics", nil, map[string]interface{}{"name": "Sede sin zona"})["id"]
+    client := create("/api/v1/clients", clinic, map[string]interface{}{"name": "Nipona", "email": "nipona@example.com", "phone": "600999777"})
+    pet := create("/api/v1/pets", clinic, map[string]interface{}{"name": "Sakura", "species": "Cat", "client_id": client["id"]})
+    appointment := create("/api/v1/appointments", clinic, map[string]interface{}{
+        "pet_id": pet["id"], "date": "2026-10-20T01:00:00Z", "reason": "Revisión", "duration": 30,
+    })
+    assert.Equal(t, "2026-10-20T10:00:00+09:00", appointment["date"])
+
+    // Sin cambiar la zona horaria del proceso
+    assert.Same(t, local, time.Local)
+}
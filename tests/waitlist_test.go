@@ -0,0 +1,325 @@
+package tests
+
+import (
+    "bufio"
+    "bytes"
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "testing"
+    "time"
+
+    "github.com/javice/vet-clinic-api/internal/models"
+    "github.com/javice/vet-clinic-api/internal/reminders"
+    "github.com/javice/vet-clinic-api/internal/repositories"
+    "github.com/javice/vet-clinic-api/internal/waitlist"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestWaitlistEndpoints(t *testing.T) {
+    router, db, err := setupTestRouter()
+    if err != nil {
+        t.Fatalf("Error inicializando el router: %v", err)
+    }
+
+    request := func(method, url string, body interface{}) *httptest.ResponseRecorder {
+        var payload []byte
+        if body != nil {
+            payload, _ = json.Marshal(body)
+        }
+        req, _ := newRequest(method, url, bytes.NewBuffer(payload))
+        req.Header.Set("Content-Type", "application/json")
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, req)
+        return resp
+    }
+    decode := func(resp *httptest.ResponseRecorder, v interface{}) {
+        assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), v))
+    }
+
+    client := models.Client{Name: "Marta Ruiz", Email: "marta@example.com", Phone: "600333444"}
+    assert.NoError(t, db.Create(&client).Error)
+    pets := make([]models.Pet, 4)
+    for i := range pets {
+        pets[i] = models.Pet{Name: "Mascota " + strconv.Itoa(i), Species: "Dog", ClientID: client.ID}
+        assert.NoError(t, db.Create(&pets[i]).Error)
+    }
+    ana := models.Vet{Name: "Ana"}
+    luis := models.Vet{Name: "Luis"}
+    assert.NoError(t, db.Create(&ana).Error)
+    assert.NoError(t, db.Create(&luis).Error)
+
+    day := time.Now().AddDate(0, 0, 3)
+    at := func(hour int) time.Time {
+        return time.Date(day.Year(), day.Month(), day.Day(), hour, 0, 0, 0, time.Local)
+    }
+
+    surgery := models.Appointment{PetID: pets[0].ID, Date: at(10), Duration: 60, Reason: "Cirugía", VetID: &ana.ID}
+    assert.NoError(t, db.Create(&surgery).Error)
+
+    entry := func(pet models.Pet, urgency string, duration int, vet *uint, from, to time.Time) uint {
+        body := map[string]interface{}{
+            "pet_id":   pet.ID,
+            "reason":   "Revisión",
+            "duration": duration,
+            "urgency":  urgency,
+            "windows":  []map[string]interface{}{{"start": from, "end": to}},
+        }
+        if vet != nil {
+            body["vet_id"] = *vet
+        }
+        resp := request("POST", "/api/v1/waitlist", body)
+        if !assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String()) {
+            t.FailNow()
+        }
+        var created models.WaitlistEntry
+        decode(resp, &created)
+        assert.Equal(t, models.WaitlistWaiting, created.Status)
+        return created.ID
+    }
+
+    var normal, urgent uint
+    t.Run("Create Entries", func(t *testing.T) {
+        normal = entry(pets[1], "", 30, nil, at(9), at(12))
+        urgent = entry(pets[2], models.UrgencyHigh, 45, nil, at(9), at(12))
+        // No caben: otra franja, demasiado largo y otro veterinario
+        entry(pets[3], models.UrgencyHigh, 30, nil, at(16), at(18))
+        entry(pets[3], models.UrgencyHigh, 90, nil, at(9), at(12))
+        entry(pets[3], models.UrgencyHigh, 30, &luis.ID, at(9), at(12))
+
+        resp := request("POST", "/api/v1/waitlist", map[string]interface{}{"pet_id": pets[1].ID, "reason": "Sin ventanas", "duration": 30})
+        assert.Equal(t, http.StatusBadRequest, resp.Code)
+        resp = request("POST", "/api/v1/waitlist", map[string]interface{}{
+            "pet_id": 999, "reason": "Revisión", "duration": 30,
+            "windows": []map[string]interface{}{{"start": at(9), "end": at(12)}},
+        })
+        assert.Equal(t, http.StatusNotFound, resp.Code)
+
+        var entries []models.WaitlistEntry
+        decode(request("GET", "/api/v1/waitlist?status=waiting", nil), &entries)
+        if assert.Len(t, entries, 5) {
+            // Primero las urgentes
+            assert.Equal(t, models.UrgencyHigh, entries[0].Urgency)
+            assert.Equal(t, normal, entries[4].ID)
+            assert.Len(t, entries[0].Windows, 1)
+        }
+    })
+
+    pendingOffer := func() models.WaitlistOffer {
+        var offers []models.WaitlistOffer
+        decode(request("GET", "/api/v1/waitlist/offers?status=pending", nil), &offers)
+        if !assert.Len(t, offers, 1) {
+            t.FailNow()
+        }
+        return offers[0]
+    }
+
+    t.Run("Cancel Offers Slot", func(t *testing.T) {
+        resp := request("POST", "/api/v1/appointments/"+strconv.Itoa(int(surgery.ID))+"/cancel", nil)
+        if !assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+            return
+        }
+        var cancelled models.Appointment
+        decode(resp, &cancelled)
+        assert.Equal(t, models.AppointmentCancelled, cancelled.Status)
+
+        offer := pendingOffer()
+        assert.Equal(t, urgent, offer.EntryID)
+        assert.Equal(t, surgery.ID, offer.FreedAppointmentID)
+        assert.True(t, offer.Date.Equal(at(10)))
+        assert.Equal(t, 45, offer.Duration)
+        assert.Equal(t, ana.ID, *offer.VetID)
+
+        var offered models.WaitlistEntry
+        decode(request("GET", "/api/v1/waitlist/"+strconv.Itoa(int(urgent)), nil), &offered)
+        assert.Equal(t, models.WaitlistOffered, offered.Status)
+
+        resp = request("POST", "/api/v1/appointments/"+strconv.Itoa(int(surgery.ID))+"/cancel", nil)
+        assert.Equal(t, http.StatusConflict, resp.Code)
+    })
+
+    t.Run("Decline Offers Next Candidate", func(t *testing.T) {
+        offer := pendingOffer()
+        resp := request("POST", "/api/v1/waitlist/offers/"+strconv.Itoa(int(offer.ID))+"/decline", nil)
+        assert.Equal(t, http.StatusOK, resp.Code)
+
+        next := pendingOffer()
+        assert.Equal(t, normal, next.EntryID)
+        assert.Equal(t, 30, next.Duration)
+
+        var back models.WaitlistEntry
+        decode(request("GET", "/api/v1/waitlist/"+strconv.Itoa(int(urgent)), nil), &back)
+        assert.Equal(t, models.WaitlistWaiting, back.Status)
+    })
+
+    t.Run("Accept Offer", func(t *testing.T) {
+        offer := pendingOffer()
+        resp := request("POST", "/api/v1/waitlist/offers/"+strconv.Itoa(int(offer.ID))+"/accept", nil)
+        if !assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String()) {
+            return
+        }
+
+        var appointment models.Appointment
+        decode(resp, &appointment)
+        assert.Equal(t, pets[1].ID, appointment.PetID)
+        assert.True(t, appointment.Date.Equal(at(10)))
+        assert.Equal(t, 30, appointment.Duration)
+        assert.Equal(t, ana.ID, *appointment.VetID)
+        assert.Equal(t, models.AppointmentScheduled, appointment.Status)
+
+        var booked models.WaitlistEntry
+        decode(request("GET", "/api/v1/waitlist/"+strconv.Itoa(int(normal)), nil), &booked)
+        assert.Equal(t, models.WaitlistBooked, booked.Status)
+        assert.Equal(t, appointment.ID, *booked.AppointmentID)
+
+        resp = request("POST", "/api/v1/waitlist/offers/"+strconv.Itoa(int(offer.ID))+"/accept", nil)
+        assert.Equal(t, http.StatusConflict, resp.Code)
+        resp = request("DELETE", "/api/v1/waitlist/"+strconv.Itoa(int(normal)), nil)
+        assert.Equal(t, http.StatusConflict, resp.Code)
+    })
+
+    t.Run("No Show", func(t *testing.T) {
+        future := models.Appointment{PetID: pets[0].ID, Date: at(15), Duration: 30, Reason: "Control"}
+        assert.NoError(t, db.Create(&future).Error)
+        resp := request("POST", "/api/v1/appointments/"+strconv.Itoa(int(future.ID))+"/no-show", nil)
+        assert.Equal(t, http.StatusConflict, resp.Code)
+
+        started := models.Appointment{PetID: pets[0].ID, Date: time.Now().Add(-10 * time.Minute), Duration: 30, Reason: "Control"}
+        assert.NoError(t, db.Create(&started).Error)
+        resp = request("POST", "/api/v1/appointments/"+strconv.Itoa(int(started.ID))+"/no-show", nil)
+        assert.Equal(t, http.StatusOK, resp.Code)
+
+        var stored models.Appointment
+        assert.NoError(t, db.First(&stored, started.ID).Error)
+        assert.Equal(t, models.AppointmentNoShow, stored.Status)
+    })
+
+    t.Run("Cancel Entry", func(t *testing.T) {
+        resp := request("DELETE", "/api/v1/waitlist/"+strconv.Itoa(int(urgent)), nil)
+        assert.Equal(t, http.StatusOK, resp.Code)
+        var cancelled models.WaitlistEntry
+        decode(resp, &cancelled)
+        assert.Equal(t, models.WaitlistCancelled, cancelled.Status)
+    })
+}
+
+func TestWaitlistOfferExpiry(t *testing.T) {
+    db, err := setupTestDB()
+    if err != nil {
+        t.Fatalf("Error inicializando la base de datos: %v", err)
+    }
+    ctx := context.Background()
+
+    now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+    clock := now
+
+    client := models.Client{Name: "Pedro Gil", Email: "pedro@example.com", Phone: "600555666"}
+    assert.NoError(t, db.Create(&client).Error)
+    first := models.Pet{Name: "Kira", Species: "Dog", ClientID: client.ID}
+    second := models.Pet{Name: "Nala", Species: "Cat", ClientID: client.ID}
+    assert.NoError(t, db.Create(&first).Error)
+    assert.NoError(t, db.Create(&second).Error)
+
+    window := []models.WaitlistWindow{{Start: now, End: now.Add(8 * time.Hour)}}
+    repo := repositories.NewWaitlistRepository(db)
+    firstEntry := models.WaitlistEntry{PetID: first.ID, Reason: "Vacuna", Duration: 20, Windows: window}
+    assert.NoError(t, repo.Create(&firstEntry))
+    secondEntry := models.WaitlistEntry{PetID: second.ID, Reason: "Vacuna", Duration: 20, Windows: []models.WaitlistWindow{{Start: now, End: now.Add(8 * time.Hour)}}}
+    assert.NoError(t, repo.Create(&secondEntry))
+
+    freed := models.Appointment{PetID: first.ID, Date: now.Add(2 * time.Hour), Duration: 30, Reason: "Revisión", Status: models.AppointmentCancelled}
+    assert.NoError(t, db.Create(&freed).Error)
+
+    var out bytes.Buffer
+    provider := reminders.NewLogProvider(&out)
+    service := waitlist.New(repo, map[string]reminders.Provider{
+        reminders.ChannelEmail: provider,
+        reminders.ChannelSMS:   provider,
+    }, waitlist.Config{OfferTTL: 15 * time.Minute, Now: func() time.Time { return clock }})
+
+    offer, err := service.SlotFreed(ctx, freed)
+    if !assert.NoError(t, err) || !assert.NotNil(t, offer) {
+        return
+    }
+    assert.Equal(t, firstEntry.ID, offer.EntryID)
+    assert.True(t, offer.ExpiresAt.Equal(now.Add(15*time.Minute)))
+
+    t.Run("Notify Client", func(t *testing.T) {
+        var messages []reminders.Message
+        scanner := bufio.NewScanner(bytes.NewReader(out.Bytes()))
+        for scanner.Scan() {
+            var msg reminders.Message
+            assert.NoError(t, json.Unmarshal(scanner.Bytes(), &msg))
+            messages = append(messages, msg)
+        }
+        if assert.Len(t, messages, 2) {
+            recipients := []string{messages[0].To, messages[1].To}
+            assert.ElementsMatch(t, []string{"pedro@example.com", "600555666"}, recipients)
+            for _, msg := range messages {
+                assert.Contains(t, msg.Body, "Kira")
+                // Sin clínica, la hora se muestra en la zona de la instalación
+                assert.Contains(t, msg.Body, freed.Date.In(time.Local).Format("15:04"))
+            }
+        }
+    })
+
+    t.Run("Slot Offered Once", func(t *testing.T) {
+        again, err := service.SlotFreed(ctx, freed)
+        assert.NoError(t, err)
+        assert.Nil(t, again)
+    })
+
+    t.Run("Expire And Offer Next", func(t *testing.T) {
+        clock = now.Add(10 * time.Minute)
+        assert.NoError(t, service.RunOnce(ctx))
+        stored, err := repo.GetOffer(offer.ID)
+        assert.NoError(t, err)
+        assert.Equal(t, models.OfferPending, stored.Status)
+
+        clock = now.Add(16 * time.Minute)
+        _, err = service.Accept(ctx, offer.ID)
+        assert.ErrorIs(t, err, repositories.ErrOfferExpired)
+
+        stored, err = repo.GetOffer(offer.ID)
+        assert.NoError(t, err)
+        assert.Equal(t, models.OfferExpired, stored.Status)
+
+        pending, err := repo.GetOffers(models.OfferPending)
+        assert.NoError(t, err)
+        if assert.Len(t, pending, 1) {
+            assert.Equal(t, secondEntry.ID, pending[0].EntryID)
+            assert.True(t, pending[0].ExpiresAt.Equal(clock.Add(15*time.Minute)))
+        }
+
+        entry, err := repo.GetByID(firstEntry.ID)
+        assert.NoError(t, err)
+        assert.Equal(t, models.WaitlistWaiting, entry.Status)
+
+        // El sweeper caduca la segunda y ya no queda nadie a quien ofrecerlo
+        clock = now.Add(40 * time.Minute)
+        assert.NoError(t, service.RunOnce(ctx))
+        pending, err = repo.GetOffers(models.OfferPending)
+        assert.NoError(t, err)
+        assert.Empty(t, pending)
+    })
+
+    t.Run("Slot Taken Meanwhile", func(t *testing.T) {
+        vet := models.Vet{Name: "Eva"}
+        assert.NoError(t, db.Create(&vet).Error)
+        cancelled := models.Appointment{PetID: first.ID, Date: now.Add(4 * time.Hour), Duration: 30, Reason: "Cura", VetID: &vet.ID, Status: models.AppointmentCancelled}
+        assert.NoError(t, db.Create(&cancelled).Error)
+
+        offer, err := service.SlotFreed(ctx, cancelled)
+        if !assert.NoError(t, err) || !assert.NotNil(t, offer) {
+            return
+        }
+
+        taken := models.Appointment{PetID: second.ID, Date: now.Add(4 * time.Hour), Duration: 30, Reason: "Urgencia", VetID: &vet.ID}
+        assert.NoError(t, db.Create(&taken).Error)
+
+        _, err = service.Accept(ctx, offer.ID)
+        assert.ErrorIs(t, err, repositories.ErrScheduleConflict)
+    })
+}